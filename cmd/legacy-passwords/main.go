@@ -0,0 +1,50 @@
+// Command legacy-passwords reports how many accounts still have an AES
+// encrypted password. Those passwords are rehashed with argon2id on the
+// user's next successful login, so the count should drop to zero over time.
+//
+//	go run ./cmd/legacy-passwords
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	authDb "rawuh-service/internal/auth/repository"
+	"rawuh-service/internal/shared/config"
+	"rawuh-service/internal/shared/db"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	chosenDSN := os.Getenv("DB_DSN")
+	if chosenDSN == "" {
+		chosenDSN = os.Getenv("DATABASE_URL")
+	}
+
+	if chosenDSN == "" {
+		log.Fatal("No database DSN found — check your environment variables")
+	}
+
+	gormDB, err := config.InitDB(chosenDSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+
+	authRepo := authDb.NewAuthRepository(db.NewProvider(gormDB))
+
+	total, legacy, err := authRepo.CountLegacyPasswords(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to count passwords: %v", err)
+	}
+
+	fmt.Printf("accounts:        %d\n", total)
+	fmt.Printf("argon2id:        %d\n", total-legacy)
+	fmt.Printf("legacy AES:      %d\n", legacy)
+}
@@ -0,0 +1,69 @@
+// Command purge permanently removes rows that were soft deleted longer ago
+// than SOFT_DELETE_RETENTION. The server does the same every PURGE_INTERVAL;
+// run this from cron instead when PURGE_INTERVAL is 0.
+//
+//	go run ./cmd/purge
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	eventDb "rawuh-service/internal/event/repository"
+	guestDb "rawuh-service/internal/guest/repository"
+	projectDb "rawuh-service/internal/project/repository"
+	"rawuh-service/internal/purge"
+	"rawuh-service/internal/shared/config"
+	"rawuh-service/internal/shared/db"
+	userDb "rawuh-service/internal/user/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	chosenDSN := os.Getenv("DB_DSN")
+	if chosenDSN == "" {
+		chosenDSN = os.Getenv("DATABASE_URL")
+	}
+
+	if chosenDSN == "" {
+		log.Fatal("No database DSN found — check your environment variables")
+	}
+
+	cfg, err := purge.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid purge config: %v", err)
+	}
+
+	gormDB, err := config.InitDB(chosenDSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+
+	dbProvider := db.NewProvider(gormDB)
+	job := purge.NewJob(
+		guestDb.NewGuestRepository(dbProvider),
+		eventDb.NewEventRepository(dbProvider),
+		projectDb.NewProjectRepository(dbProvider),
+		userDb.NewUserRepository(dbProvider),
+		cfg,
+		nil,
+	)
+
+	result, err := job.Run(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to purge: %v", err)
+	}
+
+	fmt.Printf("deleted before:  %s ago\n", cfg.Retention)
+	fmt.Printf("guests:          %d\n", result.Guests)
+	fmt.Printf("events:          %d\n", result.Events)
+	fmt.Printf("projects:        %d\n", result.Projects)
+	fmt.Printf("users:           %d\n", result.Users)
+}
@@ -12,21 +12,46 @@ import (
 	"log"
 	"net/http"
 	"os"
+	auditHandler "rawuh-service/internal/audit/handler"
+	auditDb "rawuh-service/internal/audit/repository"
+	auditService "rawuh-service/internal/audit/service"
 	eventHandler "rawuh-service/internal/event/handler"
 	eventDb "rawuh-service/internal/event/repository"
 	eventService "rawuh-service/internal/event/service"
 	guestHandler "rawuh-service/internal/guest/handler"
 	guestDb "rawuh-service/internal/guest/repository"
 	guestService "rawuh-service/internal/guest/service"
+	invitationHandler "rawuh-service/internal/invitation/handler"
+	invitationDb "rawuh-service/internal/invitation/repository"
+	invitationService "rawuh-service/internal/invitation/service"
+	jobHandler "rawuh-service/internal/job/handler"
+	jobDb "rawuh-service/internal/job/repository"
+	jobService "rawuh-service/internal/job/service"
+	liveHandler "rawuh-service/internal/live/handler"
+	liveDb "rawuh-service/internal/live/repository"
+	liveService "rawuh-service/internal/live/service"
+	messageHandler "rawuh-service/internal/message/handler"
+	messageDb "rawuh-service/internal/message/repository"
+	messageService "rawuh-service/internal/message/service"
 	projectHandler "rawuh-service/internal/project/handler"
 	projectDb "rawuh-service/internal/project/repository"
 	projectService "rawuh-service/internal/project/service"
+	"rawuh-service/internal/purge"
+	rsvpHandler "rawuh-service/internal/rsvp/handler"
+	rsvpDb "rawuh-service/internal/rsvp/repository"
+	rsvpService "rawuh-service/internal/rsvp/service"
+	"rawuh-service/internal/shared/cache"
 	"rawuh-service/internal/shared/config"
 	"rawuh-service/internal/shared/db"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/mailer"
+	"rawuh-service/internal/shared/messenger"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/migration"
 	"rawuh-service/internal/shared/redis"
 	"rawuh-service/internal/shared/router"
+	"rawuh-service/internal/shared/session"
 
 	docs "rawuh-service/docs"
 
@@ -36,6 +61,9 @@ import (
 	userHandler "rawuh-service/internal/user/handler"
 	userDb "rawuh-service/internal/user/repository"
 	userService "rawuh-service/internal/user/service"
+	webhookHandler "rawuh-service/internal/webhook/handler"
+	webhookDb "rawuh-service/internal/webhook/repository"
+	webhookService "rawuh-service/internal/webhook/service"
 	"strconv"
 
 	"github.com/jackc/pgx/v4"
@@ -98,11 +126,34 @@ func main() {
 
 	zapLog.Info("Success connecting to db ", appConfig.Dsn)
 
+	if utils.GetEnv("DB_AUTO_MIGRATE", "false") == "true" {
+		sqlDB, err := gormDB.DB()
+		if err != nil {
+			zapLog.Fatal("Failed to get sql DB:", err)
+		}
+		migrator, err := migration.New(sqlDB)
+		if err != nil {
+			zapLog.Fatal("Failed to load migrations:", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			zapLog.Fatal("Failed to run migrations:", err)
+		}
+		zapLog.Info("Migrations applied: ", len(applied))
+	}
+
 	dbProvider := db.NewProvider(gormDB)
 	guestDB := guestDb.NewGuestRepository(dbProvider)
 	eventDB := eventDb.NewEventRepository(dbProvider)
 	projectDB := projectDb.NewProjectRepository(dbProvider)
 	userDB := userDb.NewUserRepository(dbProvider)
+	rsvpDB := rsvpDb.NewRsvpRepository(dbProvider)
+	auditDB := auditDb.NewAuditRepository(dbProvider)
+	invitationDB := invitationDb.NewInvitationRepository(dbProvider)
+	messageDB := messageDb.NewMessageRepository(dbProvider)
+	jobDB := jobDb.NewJobRepository(dbProvider)
+	webhookDB := webhookDb.NewWebhookRepository(dbProvider)
+	liveDB := liveDb.NewLiveRepository(dbProvider)
 
 	var rdb *redis.Redis
 	redisURL := utils.GetEnv("REDIS_URL", "")
@@ -122,24 +173,124 @@ func main() {
 		rdb = redis.NewRedis(redisAddr, redisPass, redisDB)
 	}
 
+	proxies, err := middleware.ParseTrustedProxies(utils.GetEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	middleware.SetTrustedProxies(proxies)
+
+	sessionCfg, err := session.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid session config: %v", err)
+	}
+	sessions, err := session.NewStore(rdb, sessionCfg)
+	if err != nil {
+		log.Fatalf("Invalid session config: %v", err)
+	}
+	log.Printf("Access tokens: %s", sessions.Mode())
+
+	purgeCfg, err := purge.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid purge config: %v", err)
+	}
+	if purgeCfg.Interval > 0 {
+		purgeJob := purge.NewJob(guestDB, eventDB, projectDB, userDB, purgeCfg, zapLog)
+		go purgeJob.Start(context.Background())
+		log.Printf("Purging rows deleted more than %s ago every %s", purgeCfg.Retention, purgeCfg.Interval)
+	}
+
+	cacheCfg, err := cache.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid cache config: %v", err)
+	}
+	readCache := cache.New(rdb, cacheCfg)
+
+	mailerCfg, err := mailer.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid SMTP config: %v", err)
+	}
+	mail := mailer.New(mailerCfg)
+	if mail == nil {
+		log.Println("Email is off, set SMTP_HOST to send invitations")
+	}
+
+	senderCfg, err := invitationService.SenderConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid invitation sender config: %v", err)
+	}
+	if mail != nil && senderCfg.Interval > 0 {
+		sender := invitationService.NewSender(invitationDB, mail, mailerCfg, senderCfg, zapLog)
+		go sender.Start(context.Background())
+		log.Printf("Sending queued invitations every %s", senderCfg.Interval)
+	}
+
+	messageSenderCfg, err := messageService.SenderConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid message sender config: %v", err)
+	}
+	providers := map[string]messenger.Provider{}
+	for _, channel := range messenger.Channels {
+		messengerCfg, err := messenger.ConfigFromEnv(channel)
+		if err != nil {
+			log.Fatalf("Invalid %s config: %v", channel, err)
+		}
+		provider := messenger.New(messengerCfg)
+		if provider == nil {
+			continue
+		}
+		providers[channel] = provider
+		if messageSenderCfg.Interval > 0 {
+			sender := messageService.NewSender(messageDB, provider, rdb, messengerCfg, messageSenderCfg, zapLog)
+			go sender.Start(context.Background())
+			log.Printf("Sending queued %s messages through %s every %s, at most %d a minute", channel, provider.Name(), messageSenderCfg.Interval, messengerCfg.Rate)
+		}
+	}
+
+	webhookCfg, err := webhookService.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid webhook config: %v", err)
+	}
+	if webhookCfg.Interval > 0 {
+		dispatcher := webhookService.NewDispatcher(webhookDB, webhookCfg, zapLog)
+		go dispatcher.Start(context.Background())
+		log.Printf("Sending queued webhooks every %s", webhookCfg.Interval)
+	}
+
+	liveHub := liveService.NewHub(rdb, zapLog)
+	go liveHub.Start(context.Background())
+
 	// repositories
 	authRepo := authDb.NewAuthRepository(dbProvider)
 
 	// services
-	guestService := guestService.NewGuestService(guestDB, zapLog)
-	eventService := eventService.NewEventService(eventDB, zapLog)
-	userService := userService.NewUserService(userDB, authRepo, rdb, zapLog)
-	projectService := projectService.NewProjectService(projectDB, zapLog)
+	auditService := auditService.NewAuditService(auditDB, zapLog)
+	webhookService := webhookService.NewWebhookService(webhookDB, auditService, webhookCfg, zapLog)
+	liveService := liveService.NewLiveService(liveDB, liveHub, zapLog)
+	guestService := guestService.NewGuestService(guestDB, auditService, liveService, readCache, zapLog)
+	eventService := eventService.NewEventService(eventDB, auditService, readCache, zapLog)
+	userService := userService.NewUserService(userDB, authRepo, sessions, auditService, zapLog)
+	projectService := projectService.NewProjectService(projectDB, auditService, readCache, zapLog)
 	authService := authService.NewAuthService(authRepo, zapLog)
+	rsvpService := rsvpService.NewRsvpService(rsvpDB, liveService, readCache, zapLog)
+	invitationService := invitationService.NewInvitationService(invitationDB, mail, auditService, zapLog)
+	messageService := messageService.NewMessageService(messageDB, guestService, providers, auditService, zapLog)
+	jobService := jobService.NewJobService(jobDB, auditService, zapLog)
 
 	// handlers
 	guestHandler := guestHandler.NewGuestHandler(guestService)
 	eventHandler := eventHandler.NewEventHandler(eventService)
 	projectHandler := projectHandler.NewProjectHandler(projectService)
 	userHandler := userHandler.NewUserHandler(userService)
-	authHandler := authHandler.NewAuthHandler(authService, userDB, rdb, zapLog)
+	authHandler := authHandler.NewAuthHandler(authService, userDB, sessions, zapLog)
+	rsvpHandler := rsvpHandler.NewRsvpHandler(rsvpService)
+	auditHandler := auditHandler.NewAuditHandler(auditService)
+	invitationHandler := invitationHandler.NewInvitationHandler(invitationService)
+	messageHandler := messageHandler.NewMessageHandler(messageService)
+	jobHandler := jobHandler.NewJobHandler(jobService)
+	webhookHandler := webhookHandler.NewWebhookHandler(webhookService)
+	liveHandler := liveHandler.NewLiveHandler(liveService, sessions)
 
-	r := router.NewRouter(guestHandler, eventHandler, projectHandler, userHandler, authHandler, rdb)
+	r := router.NewRouter(guestHandler, eventHandler, projectHandler, userHandler, authHandler, rsvpHandler, auditHandler, invitationHandler, messageHandler, jobHandler, webhookHandler, liveHandler, rdb, sessions)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,82 @@
+// Command worker runs the background jobs queued in public.jobs, such as
+// cancelling the invitations and messages of deleted guests and queueing
+// webhook deliveries. Run as many as
+// needed; they share the queue. It stops on SIGINT or SIGTERM once the job
+// at hand is done.
+//
+//	go run ./cmd/worker
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	invitationDb "rawuh-service/internal/invitation/repository"
+	jobDb "rawuh-service/internal/job/repository"
+	jobService "rawuh-service/internal/job/service"
+	messageDb "rawuh-service/internal/message/repository"
+	"rawuh-service/internal/shared/config"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	webhookDb "rawuh-service/internal/webhook/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	fluentbitPortInt, _ := strconv.Atoi(utils.GetEnv("FLUENTBIT_PORT", "24224"))
+	zapLog := logger.New(&logger.LoggerConfig{
+		Env:           utils.GetEnv("ENV", "development"),
+		ProductName:   "rawuh-service",
+		ServiceName:   "rawuh-worker",
+		LogLevel:      utils.GetEnv("LOG_LEVEL", "info"),
+		LogOutput:     utils.GetEnv("LOG_OUTPUT", "console"),
+		FluentbitHost: utils.GetEnv("FLUENTBIT_HOST", "localhost"),
+		FluentbitPort: fluentbitPortInt,
+		ProcessId:     utils.GetEnv("PROCESS_ID", "rawuh-worker-1"),
+	})
+
+	chosenDSN := os.Getenv("DB_DSN")
+	if chosenDSN == "" {
+		chosenDSN = os.Getenv("DATABASE_URL")
+	}
+
+	if chosenDSN == "" {
+		log.Fatal("No database DSN found — check your environment variables")
+	}
+
+	cfg, err := jobService.WorkerConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid job worker config: %v", err)
+	}
+
+	gormDB, err := config.InitDB(chosenDSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+
+	dbProvider := db.NewProvider(gormDB)
+	worker := jobService.NewWorker(jobDb.NewJobRepository(dbProvider), cfg, zapLog)
+	worker.Register(constant.JobKindCancelDeliveries, jobService.CancelDeliveries(
+		invitationDb.NewInvitationRepository(dbProvider),
+		messageDb.NewMessageRepository(dbProvider),
+	))
+	worker.Register(constant.JobKindPublishWebhook, jobService.PublishWebhook(webhookDb.NewWebhookRepository(dbProvider)))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Running queued jobs, polling every %s", cfg.Interval)
+	worker.Start(ctx)
+	log.Println("Worker stopped")
+}
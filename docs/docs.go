@@ -15,9 +15,135 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get paginated list of recorded creates, updates and deletes, newest first. System admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "List audit logs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "dir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "create, update, delete, restore, import, check_in, undo_check_in, merge, send or retry",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "project, event, guest, user, user_role, invitation_template, invitation_batch, message_template, message_broadcast, job or webhook",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "entity id",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "user id of the actor",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Request-ID of the request that made the change",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time, inclusive",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time, exclusive",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListAuditLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
             "post": {
-                "description": "Authenticate user and return an access token",
+                "description": "Swap a refresh token for a new access token and refresh token. Each refresh token works once; presenting a used one again revokes the whole session.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,15 +153,15 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Login with username and password",
+                "summary": "Refresh the access token",
                 "parameters": [
                     {
-                        "description": "Login credentials",
+                        "description": "Refresh token",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.loginRequest"
+                            "$ref": "#/definitions/handler.refreshRequest"
                         }
                     }
                 ],
@@ -46,14 +172,42 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.loginResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "description": "List the active access tokens of the current user. A system admin may pass user_id to see the sessions of another user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List active sessions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user id, system admin only",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.listSessionsResponse"
+                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
@@ -61,14 +215,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/project": {
+        "/auth/switch-project": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Create a new project",
+                "description": "Move the current session to another project, and optionally one of its events, the user is a member of. Returns a new token pair; the old access and refresh tokens stop working. Roles are read again, so role changes apply without logging in.",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,17 +230,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "auth"
                 ],
-                "summary": "Create a project",
+                "summary": "Switch the active project",
                 "parameters": [
                     {
-                        "description": "CreateProjectRequest",
+                        "description": "Project to switch to",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateProjectRequest"
+                            "$ref": "#/definitions/handler.switchProjectRequest"
                         }
                     }
                 ],
@@ -94,7 +248,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.CreateProjectResponse"
+                            "$ref": "#/definitions/handler.loginResponse"
                         }
                     },
                     "400": {
@@ -102,18 +256,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/project/list": {
+        "/jobs/dead": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Get paginated list of projects",
+                "description": "Get paginated list of the background jobs that failed every attempt, the last failed first. System admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -121,9 +281,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "job"
                 ],
-                "summary": "List projects",
+                "summary": "List dead jobs",
                 "parameters": [
                     {
                         "type": "integer",
@@ -136,13 +296,37 @@ const docTemplate = `{
                         "description": "limit",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "dir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "job kind, such as deliveries.cancel",
+                        "name": "kind",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ListProjectResponse"
+                            "$ref": "#/definitions/model.ListDeadJobsResponse"
                         }
                     },
                     "400": {
@@ -150,13 +334,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/project/{project_id}": {
+        "/jobs/dead/{job_id}": {
             "get": {
-                "description": "Retrieve project details by ID",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get a dead job with its payload and the error of its last attempt. System admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -164,14 +359,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "job"
                 ],
-                "summary": "Get project detail",
+                "summary": "Get a dead job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "project id",
-                        "name": "project_id",
+                        "description": "job id",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
                     }
@@ -180,7 +375,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.GetProjectDetailResponse"
+                            "$ref": "#/definitions/model.GetDeadJobResponse"
                         }
                     },
                     "404": {
@@ -191,53 +386,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Update project by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "project"
-                ],
-                "summary": "Update a project",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "project id",
-                        "name": "project_id",
-                        "in": "path",
-                        "required": true
-                    },
+            "delete": {
+                "security": [
                     {
-                        "description": "UpdateProjectRequest",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.UpdateProjectRequest"
-                        }
+                        "Bearer": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.UpdateProjectResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIErrorResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete project by ID",
+                "description": "Drop a dead job for good. System admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -245,14 +400,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "job"
                 ],
-                "summary": "Delete a project",
+                "summary": "Delete a dead job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "project id",
-                        "name": "project_id",
+                        "description": "job id",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
                     }
@@ -261,11 +416,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.DeleteProjectResponse"
+                            "$ref": "#/definitions/model.DeleteDeadJobResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
@@ -273,14 +428,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/jobs/dead/{job_id}/retry": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Create user and create auth row when password provided",
+                "description": "Put a dead job back in the queue under the same id, with its attempts reset. System admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -288,29 +443,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "job"
                 ],
-                "summary": "Create a new user",
+                "summary": "Retry a dead job",
                 "parameters": [
                     {
-                        "description": "CreateUserRequest",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateUserRequest"
-                        }
+                        "type": "string",
+                        "description": "job id",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.CreateUserResponse"
+                            "$ref": "#/definitions/model.RetryDeadJobResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
@@ -318,14 +471,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/list": {
+        "/jobs/list": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Get paginated list of users",
+                "description": "Get paginated list of the background jobs waiting or running, the first due first. System admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -333,9 +486,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "job"
                 ],
-                "summary": "List users",
+                "summary": "List queued jobs",
                 "parameters": [
                     {
                         "type": "integer",
@@ -348,13 +501,43 @@ const docTemplate = `{
                         "description": "limit",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc (default) or desc",
+                        "name": "dir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "job kind, such as deliveries.cancel",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PENDING or RUNNING",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ListUserResponse"
+                            "$ref": "#/definitions/model.ListJobsResponse"
                         }
                     },
                     "400": {
@@ -362,59 +545,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/users/{user_id}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Retrieve user details by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "user"
-                ],
-                "summary": "Get user by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "user id",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.GetUserByIDResponse"
-                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Update user details by ID",
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate user and return an access token, together with the projects and events the user is a member of. The session starts in the user's default project, or in the first membership when the user no longer belongs to it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -422,24 +565,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "auth"
                 ],
-                "summary": "Update a user",
+                "summary": "Login with username and password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "user id",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "UpdateUserRequest",
+                        "description": "Login credentials",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateUserRequest"
+                            "$ref": "#/definitions/handler.loginRequest"
                         }
                     }
                 ],
@@ -447,7 +583,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateUserResponse"
+                            "$ref": "#/definitions/handler.loginResponse"
                         }
                     },
                     "400": {
@@ -455,44 +591,35 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Delete a user",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Revoke the access token used for this request together with its refresh token",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
-                ],
-                "summary": "Delete user by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "user id",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Logout",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.GetUserByIDResponse"
+                            "$ref": "#/definitions/handler.logoutResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
@@ -500,9 +627,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/{project_id}/events": {
+        "/project": {
             "post": {
-                "description": "Create a new event within a project",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a new project",
                 "consumes": [
                     "application/json"
                 ],
@@ -510,17 +642,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "project"
                 ],
-                "summary": "Create an event",
+                "summary": "Create a project",
                 "parameters": [
                     {
-                        "description": "CreateEventRequest",
+                        "description": "CreateProjectRequest",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateEventRequest"
+                            "$ref": "#/definitions/model.CreateProjectRequest"
                         }
                     }
                 ],
@@ -528,7 +660,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.CreateEventResponse"
+                            "$ref": "#/definitions/model.CreateProjectResponse"
                         }
                     },
                     "400": {
@@ -540,9 +672,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/{project_id}/events/list": {
+        "/project/list": {
             "get": {
-                "description": "Get paginated list of events for a project",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get paginated list of projects. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.",
                 "consumes": [
                     "application/json"
                 ],
@@ -550,9 +687,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "project"
                 ],
-                "summary": "List events",
+                "summary": "List projects",
                 "parameters": [
                     {
                         "type": "integer",
@@ -565,13 +702,37 @@ const docTemplate = `{
                         "description": "limit",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort column, any filterable column",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "dir",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ListEventResponse"
+                            "$ref": "#/definitions/model.ListProjectResponse"
                         }
                     },
                     "400": {
@@ -583,9 +744,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/{project_id}/events/{event_id}": {
+        "/project/{project_id}": {
             "get": {
-                "description": "Get details for a specific event",
+                "description": "Retrieve project details by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -593,14 +754,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "project"
                 ],
-                "summary": "Get event detail",
+                "summary": "Get project detail",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "event id",
-                        "name": "event_id",
+                        "description": "project id",
+                        "name": "project_id",
                         "in": "path",
                         "required": true
                     }
@@ -609,7 +770,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.DetailEventResponse"
+                            "$ref": "#/definitions/model.GetProjectDetailResponse"
                         }
                     },
                     "404": {
@@ -621,7 +782,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update event details by ID",
+                "description": "Update project by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -629,24 +790,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "project"
                 ],
-                "summary": "Update an event",
+                "summary": "Update a project",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "event id",
-                        "name": "event_id",
+                        "description": "project id",
+                        "name": "project_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "UpdateEventRequest",
+                        "description": "UpdateProjectRequest",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateEventRequest"
+                            "$ref": "#/definitions/model.UpdateProjectRequest"
                         }
                     }
                 ],
@@ -654,7 +815,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateEventResponse"
+                            "$ref": "#/definitions/model.UpdateProjectResponse"
                         }
                     },
                     "400": {
@@ -666,7 +827,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete event by ID",
+                "description": "Delete project by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -674,14 +835,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "project"
                 ],
-                "summary": "Delete an event",
+                "summary": "Delete a project",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "event id",
-                        "name": "event_id",
+                        "description": "project id",
+                        "name": "project_id",
                         "in": "path",
                         "required": true
                     }
@@ -690,7 +851,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.DeleteEventResponse"
+                            "$ref": "#/definitions/model.DeleteProjectResponse"
                         }
                     },
                     "400": {
@@ -702,9 +863,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/{project_id}/events/{event_id}/guests": {
+        "/project/{project_id}/restore": {
             "post": {
-                "description": "Create guest for an event",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Restore a deleted project together with the events and guests that were deleted with it",
                 "consumes": [
                     "application/json"
                 ],
@@ -712,29 +878,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "guest"
+                    "project"
                 ],
-                "summary": "Create a new guest",
+                "summary": "Restore a deleted project",
                 "parameters": [
                     {
-                        "description": "CreateGuestRequest",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateGuestRequest"
-                        }
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.CreateGuestResponse"
+                            "$ref": "#/definitions/model.RestoreProjectResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
@@ -742,9 +906,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/{project_id}/events/{event_id}/guests/list": {
+        "/project/{project_id}/webhooks": {
             "get": {
-                "description": "Get list of guests for an event",
+                "description": "Get the webhooks of a project. Secrets are not shown.",
                 "consumes": [
                     "application/json"
                 ],
@@ -752,28 +916,307 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "guest"
+                    "webhook"
                 ],
-                "summary": "List guests",
+                "summary": "List webhooks",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ListGuestResponse"
+                            "$ref": "#/definitions/model.ListWebhooksResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe a URL to changes of the project. EventTypes lists guest.created, guest.updated, guest.deleted, guest.restored, guest.merged, guest.imported, guest.checked_in, guest.check_in_undone, event.created, event.updated, event.deleted, event.restored and rsvp.responded, or holds \"*\" for all of them. Without a Secret one is generated; it is only shown in this answer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "CreateWebhookRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/project/{project_id}/webhooks/{webhook_id}": {
+            "get": {
+                "description": "Get a webhook with its failures in a row and, once it was turned off, when and why.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetWebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the URL and event types of a webhook. Active turns it off, or on again after it was turned off for failing, which clears its failures; left out, it is kept. A Secret replaces the old one; left out, the old one is kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateWebhookRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a webhook with its delivery log. Deliveries still queued are dropped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeleteWebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/project/{project_id}/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "description": "Get the delivery log of a webhook, newest first: each change sent with its status, attempts, and the answer and error of its last attempt.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "dir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "event type, such as guest.checked_in",
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PENDING, SENDING, SENT or FAILED",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_webhook_model.ListDeliveriesResponse"
                         }
                     },
                     "400": {
@@ -781,143 +1224,4536 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/utils.APIErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/{project_id}/events/{event_id}/guests/{guest_id}": {
-            "get": {
-                "description": "Get guest details",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "guest"
-                ],
-                "summary": "Get guest by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "guest id",
-                        "name": "guest_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.GetGuestByIDResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIErrorResponse"
-                        }
-                    }
+        "/project/{project_id}/webhooks/{webhook_id}/test": {
+            "post": {
+                "description": "Send a webhook.test delivery right away and answer with it, including the status and body the endpoint answered with. It is sent even when the webhook is off and does not count as a failure.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Send a test event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TestWebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rsvp/{token}": {
+            "get": {
+                "description": "Public endpoint returning the invited guest and the event details for an invitation token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rsvp"
+                ],
+                "summary": "Get an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "invitation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetInvitationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Public endpoint to accept or decline an invitation, set the number of attendees and answer the guest form",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rsvp"
+                ],
+                "summary": "Respond to an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "invitation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "RespondInvitationRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RespondInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RespondInvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create user and create auth row when password provided",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "CreateUserRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/list": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get paginated list of users. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort column, any filterable column",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "dir",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve user details by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetUserByIDResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update user details by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateUserRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetUserByIDResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Restore a deleted user, who can then log in again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Restore a deleted user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RestoreUserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Roles a user holds per project and event. Users may list their own roles; project owners see the roles held in their projects.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "List the roles of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListUserRolesResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Grant PROJECT_OWNER, EVENT_MANAGER, USHER or VIEWER in a project, or in one event when EventID is set. Needs role management rights on the project. The user is logged out so the next login carries the role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Assign a role to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "AssignUserRoleRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AssignUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AssignUserRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/roles/{user_role_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Take a role away. Needs role management rights on the role's project. The user is logged out right away.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Remove a role from a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user role id",
+                        "name": "user_role_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RemoveUserRoleResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Log a user out everywhere by revoking every access token. System admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Revoke all sessions of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RevokeUserSessionsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events": {
+            "post": {
+                "description": "Create a new event within a project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Create an event",
+                "parameters": [
+                    {
+                        "description": "CreateEventRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/list": {
+            "get": {
+                "description": "Get paginated list of events for a project. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "List events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort column, any filterable column",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "dir",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}": {
+            "get": {
+                "description": "Get details for a specific event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get event detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DetailEventResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update event details by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Update an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateEventRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete event by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Delete an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeleteEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests": {
+            "post": {
+                "description": "Create guest for an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Create a new guest",
+                "parameters": [
+                    {
+                        "description": "CreateGuestRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateGuestRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also return the guests of the event that look like the same person",
+                        "name": "check_duplicates",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateGuestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/duplicates": {
+            "get": {
+                "description": "Group the guests of an event that look like the same person: the same phone number once normalized to E.164, the same email ignoring case, or similar names. Reasons lists what linked a group.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "List likely duplicate guests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListGuestDuplicatesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/export": {
+            "get": {
+                "description": "Download every guest matching the filter[...] parameters of the list as CSV, XLSX or PDF. JSON keys of GuestData and EventData are exported as their own columns.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Export guests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv (default), xlsx or pdf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort column",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "dir",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/import": {
+            "post": {
+                "description": "Bulk create guests from a CSV or XLSX file. Columns name, address, phone and email map onto the guest, any other column is stored in GuestData. Nothing is written unless every row is valid.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Import guests from a spreadsheet",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or XLSX guest list",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate only, do not write",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportGuestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportGuestResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/list": {
+            "get": {
+                "description": "Get list of guests for an event. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "List guests",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort column, any filterable column",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "dir",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListGuestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/schema": {
+            "get": {
+                "description": "Get the custom guest fields the event defines in GuestOptions, for rendering the guest form. GuestData of the event's guests is validated against these fields. Fields is empty when the event defines none.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Get guest field schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetGuestSchemaResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/{guest_id}": {
+            "get": {
+                "description": "Get guest details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Get guest by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetGuestByIDResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update guest details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Update guest by ID",
+                "parameters": [
+                    {
+                        "description": "UpdateGuestRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateGuestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateGuestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a guest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Delete guest by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetGuestByIDResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/{guest_id}/checkin": {
+            "post": {
+                "description": "Mark a guest as arrived, recording the usher and the number of companions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Check in a guest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "CheckInGuestRequest",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.CheckInGuestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CheckInGuestResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Clear the attendance state of a checked in guest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Undo a guest check-in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UndoCheckInGuestResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/{guest_id}/invitation": {
+            "get": {
+                "description": "Get the token and link the guest uses to RSVP without logging in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Get guest invitation token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetGuestInvitationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/{guest_id}/merge": {
+            "post": {
+                "description": "Merge DuplicateGuestID into the guest and delete the duplicate. The guest keeps its own values except for the fields listed in Take and the ones it has empty; GuestData and EventData keep the keys of both. A check-in or RSVP answer of the duplicate is kept when the guest has none.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Merge a duplicate into a guest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the guest that is kept",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "MergeGuestRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeGuestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeGuestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/{guest_id}/qr": {
+            "get": {
+                "description": "Render the signed door-scanning token of a guest as a QR image",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Get guest QR code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "png (default) or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "image size in pixels",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/guests/{guest_id}/restore": {
+            "post": {
+                "description": "Restore a deleted guest. The event must not be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Restore a deleted guest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RestoreGuestResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/invitations/batches/{batch_id}": {
+            "get": {
+                "description": "Get a batch of invitations with its deliveries counted per status, to follow its progress.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Get an invitation batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "batch id",
+                        "name": "batch_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetBatchResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/invitations/list": {
+            "get": {
+                "description": "Get paginated list of the invitation emails of an event with their status, newest first. LastError holds why the last attempt failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "List invitation deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "dir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "batch id",
+                        "name": "batch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PENDING, SENDING, SENT or FAILED",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_invitation_model.ListDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/invitations/preview": {
+            "post": {
+                "description": "Render the invitation email of GuestID without sending it. Send Subject, HtmlBody and TextBody to preview a template before saving it, or leave them out to preview the saved one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Preview the invitation of a guest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "PreviewInvitationRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PreviewInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PreviewInvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/invitations/send": {
+            "post": {
+                "description": "Queue the invitation email for GuestIDs, or for every guest of the event when GuestIDs is empty, and return before it is sent. Guests without a valid email, with an invitation still queued, or already sent one are skipped; set Resend to send those again. Follow the batch with GET .../invitations/batches/{batch_id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Send invitations by email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SendInvitationsRequest",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.SendInvitationsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "nothing to send",
+                        "schema": {
+                            "$ref": "#/definitions/model.SendInvitationsResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.SendInvitationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "email is not configured or the event has no template",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/invitations/template": {
+            "get": {
+                "description": "Get the subject and bodies of the invitation email, with their merge fields.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Get the invitation template of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_invitation_model.GetTemplateResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the invitation email. Subject, HtmlBody and TextBody may use the merge fields {{Name}}, {{Address}}, {{Email}}, {{Phone}}, {{EventName}}, {{RsvpUrl}}, {{GuestData.key}} and {{EventOptions.key}}; unknown fields are rejected. Values are HTML escaped in HtmlBody.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitation"
+                ],
+                "summary": "Save the invitation template of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateTemplateRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_invitation_model.UpdateTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_invitation_model.UpdateTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/live": {
+            "get": {
+                "description": "Server-Sent Events for a dashboard of the event. The first event is a snapshot with the counters and the latest check-ins, then every guest.created, guest.updated and guest.checked_in comes with the guest and the counters, and every other change to the guests as a counts event with the counters only. The stream ends when the session does; reconnect with a fresh token.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Stream the live dashboard of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "latest check-ins in the snapshot, default 10, at most 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Snapshot"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/messages/broadcast": {
+            "post": {
+                "description": "Queue the template TemplateID on Channel, whatsapp or sms, for every guest matching the filter and return before it is sent. Filter guests as on the guest list, with filter[column][op]=value query parameters; without any the whole event is sent to. Guests without a valid phone number, with a message of the template still queued on the channel, or already sent it are skipped; set Resend to send those again. Follow the broadcast with GET .../messages/broadcasts/{broadcast_id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Broadcast a message by WhatsApp or SMS",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "BroadcastRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BroadcastRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "nothing to send",
+                        "schema": {
+                            "$ref": "#/definitions/model.BroadcastResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.BroadcastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "the channel is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/messages/broadcasts/{broadcast_id}": {
+            "get": {
+                "description": "Get a broadcast with its messages counted per status, to follow its progress.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get a message broadcast",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "broadcast id",
+                        "name": "broadcast_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetBroadcastResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/messages/list": {
+            "get": {
+                "description": "Get paginated list of the messages of an event with their status, newest first. LastError holds why the last attempt failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "List WhatsApp and SMS messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count TotalRows and TotalPages, by default true when paging by page and false by cursor",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "dir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "broadcast id",
+                        "name": "broadcast_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "guest id",
+                        "name": "guest_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PENDING, SENDING, SENT or FAILED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "whatsapp or sms",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListMessagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/messages/preview": {
+            "post": {
+                "description": "Render a message for GuestID without sending it, from the template TemplateID, or from Body to preview a text before saving it. To is the phone number it would go to, empty when the guest has no valid one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Preview the message of a guest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "PreviewMessageRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PreviewMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PreviewMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/messages/templates": {
+            "get": {
+                "description": "Get the WhatsApp and SMS templates of an event, by name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "List message templates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListTemplatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a WhatsApp or SMS text to the event. Name is unique per event. Body may use the merge fields {{Name}}, {{Address}}, {{Email}}, {{Phone}}, {{EventName}}, {{RsvpUrl}}, {{GuestData.key}} and {{EventOptions.key}}; unknown fields are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Create a message template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "CreateTemplateRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "name already used",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/messages/templates/{template_id}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get a message template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_message_model.GetTemplateResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the name and body of a template. Messages already queued keep the text they were rendered with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Update a message template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UpdateTemplateRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_message_model.UpdateTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rawuh-service_internal_message_model.UpdateTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "name already used",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a template for good. Messages already queued from it are still sent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Delete a message template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeleteTemplateResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/restore": {
+            "post": {
+                "description": "Restore a deleted event together with the guests that were deleted with it. The project must not be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Restore a deleted event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "project id",
+                        "name": "project_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RestoreEventResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{project_id}/events/{event_id}/scan": {
+            "post": {
+                "description": "Resolve a scanned guest token and check the guest in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guest"
+                ],
+                "summary": "Scan a guest QR code",
+                "parameters": [
+                    {
+                        "description": "ScanGuestRequest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ScanGuestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CheckInGuestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.APIErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "guestschema.Field": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "max": {},
+                "min": {
+                    "description": "Min and Max bound a number, the length of a text, the number of\nchoices of a multi_select, or a date given as YYYY-MM-DD."
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "guestschema.Schema": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/guestschema.Field"
+                    }
+                }
+            }
+        },
+        "handler.listSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.sessionResponse"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.loginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.loginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "active_event_id": {
+                    "type": "integer"
+                },
+                "active_project_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "memberships": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.membershipResponse"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "refresh_expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.logoutResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.membershipResponse": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "project_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.refreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.sessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "refreshed_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.switchProjectRequest": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AssignUserRoleRequest": {
+            "type": "object",
+            "properties": {
+                "eventID": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AssignUserRoleResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.UserRole"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actorID": {
+                    "type": "integer"
+                },
+                "actorName": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "string"
+                },
+                "auditLogID": {
+                    "type": "integer"
+                },
+                "before": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "diff": {
+                    "type": "string"
+                },
+                "entityID": {
+                    "type": "string"
+                },
+                "entityType": {
+                    "type": "string"
+                },
+                "eventID": {
+                    "type": "integer"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "requestID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BatchStatus": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdById": {
+                    "type": "integer"
+                },
+                "createdByName": {
+                    "type": "string"
+                },
+                "eventID": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "invitationBatchID": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "sending": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "sent": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.BroadcastRequest": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "resend": {
+                    "type": "boolean"
+                },
+                "templateID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BroadcastResponse": {
+            "type": "object",
+            "properties": {
+                "broadcastID": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "queued": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "skipped": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int32"
+                    }
+                }
+            }
+        },
+        "model.BroadcastStatus": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdById": {
+                    "type": "integer"
+                },
+                "createdByName": {
+                    "type": "string"
+                },
+                "eventID": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "filter": {
+                    "type": "string"
+                },
+                "messageBroadcastID": {
+                    "type": "integer"
+                },
+                "messageTemplateID": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "sending": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "sent": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CheckInGuestRequest": {
+            "type": "object",
+            "properties": {
+                "companionCount": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "guestID": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CheckInGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Counts": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "guests that accepted the RSVP",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "arrived": {
+                    "description": "people checked in, the guests and their companions",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "checkedIn": {
+                    "description": "guests checked in",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "declined": {
+                    "description": "guests that declined it",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "expected": {
+                    "description": "attendees of the guests that accepted",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "guests": {
+                    "description": "guests of the event",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "pending": {
+                    "description": "guests that have not answered",
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "model.CreateEventRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "eventName": {
+                    "type": "string"
+                },
+                "eventOptions": {
+                    "type": "string"
+                },
+                "guestOptions": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateEventResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateGuestRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "eventData": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "guestData": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GuestMatch"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateProjectRequest": {
+            "type": "object",
+            "properties": {
+                "projectName": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateProjectResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_message_model.Template"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is granted to a PROJECT_USER on ProjectID, and on EventId when\nset. EVENT_MANAGER when empty.",
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                },
+                "userType": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateUserResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.Webhook"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeadJob": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "failedAt": {
+                    "type": "string"
+                },
+                "jobID": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeleteDeadJobResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeleteEventResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeleteProjectResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeleteTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeleteWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DetailEventResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_event_model.Event"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetBatchResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.BatchStatus"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetBroadcastResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.BroadcastStatus"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetDeadJobResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.DeadJob"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetGuestByIDResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetGuestInvitationResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetGuestSchemaResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/guestschema.Schema"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetInvitationResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "event": {
+                    "$ref": "#/definitions/rawuh-service_internal_rsvp_model.Event"
+                },
+                "guest": {
+                    "$ref": "#/definitions/rawuh-service_internal_rsvp_model.Guest"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetProjectDetailResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.Project"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetUserByIDResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_user_model.User"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.Webhook"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GuestDuplicateGroup": {
+            "type": "object",
+            "properties": {
+                "guests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                    }
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.GuestMatch": {
+            "type": "object",
+            "properties": {
+                "guest": {
+                    "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.ImportGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportGuestRowError"
+                    }
+                },
+                "importedRows": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "totalRows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ImportGuestRowError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "jobID": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lockedAt": {
+                    "type": "string"
+                },
+                "lockedBy": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "runAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ListAuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditLog"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListDeadJobsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeadJob"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListEventResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_event_model.Event"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListGuestDuplicatesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GuestDuplicateGroup"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ListGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListJobsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Job"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_message_model.Message"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListProjectResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Project"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_message_model.Template"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ListUserResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_user_model.User"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
+        "model.ListUserRolesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserRole"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Webhook"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MergeGuestRequest": {
+            "type": "object",
+            "properties": {
+                "duplicateGuestID": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "guestID": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "take": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.MergeGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PaginationResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "nextCursor": {
+                    "description": "NextCursor and PrevCursor are set in cursor mode while there are rows\nthat way.",
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "prevCursor": {
+                    "type": "string"
+                },
+                "totalPages": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "totalRows": {
+                    "type": "integer",
+                    "format": "int64"
+                }
+            }
+        },
+        "model.PreviewInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string"
+                },
+                "guestID": {
+                    "type": "string"
+                },
+                "htmlBody": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "textBody": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PreviewInvitationResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_invitation_model.Preview"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PreviewMessageRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "guestID": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "templateID": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PreviewMessageResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_message_model.Preview"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Project": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdById": {
+                    "type": "integer"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "deletedById": {
+                    "type": "integer"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "projectName": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "statusDesc": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "updatedById": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RemoveUserRoleResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RespondInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "guestData": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RespondInvitationResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "guest": {
+                    "$ref": "#/definitions/rawuh-service_internal_rsvp_model.Guest"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RestoreEventResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_event_model.Event"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RestoreGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_guest_model.Guest"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RestoreProjectResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.Project"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RestoreUserResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_user_model.User"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RetryDeadJobResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.Job"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
                 }
-            },
-            "put": {
-                "description": "Update guest details",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "guest"
-                ],
-                "summary": "Update guest by ID",
-                "parameters": [
-                    {
-                        "description": "UpdateGuestRequest",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.UpdateGuestRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.UpdateGuestResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIErrorResponse"
-                        }
-                    }
+            }
+        },
+        "model.RevokeUserSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "revoked": {
+                    "type": "integer"
                 }
-            },
-            "delete": {
-                "description": "Delete a guest",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "guest"
-                ],
-                "summary": "Delete guest by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "guest id",
-                        "name": "guest_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.GetGuestByIDResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/utils.APIErrorResponse"
-                        }
-                    }
+            }
+        },
+        "model.ScanGuestRequest": {
+            "type": "object",
+            "properties": {
+                "companionCount": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.loginRequest": {
+        },
+        "model.SendInvitationsRequest": {
             "type": "object",
             "properties": {
-                "password": {
+                "eventId": {
                     "type": "string"
                 },
-                "username": {
+                "guestIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "projectID": {
                     "type": "string"
+                },
+                "resend": {
+                    "type": "boolean"
                 }
             }
         },
-        "handler.loginResponse": {
+        "model.SendInvitationsResponse": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "batchID": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "queued": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "skipped": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int32"
+                    }
+                }
+            }
+        },
+        "model.Snapshot": {
+            "type": "object",
+            "properties": {
+                "at": {
                     "type": "string"
                 },
+                "counts": {
+                    "$ref": "#/definitions/model.Counts"
+                },
+                "eventID": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "projectID": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "recentCheckIns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_live_model.Guest"
+                    }
+                }
+            }
+        },
+        "model.TestWebhookResponse": {
+            "type": "object",
+            "properties": {
                 "code": {
-                    "type": "integer"
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_webhook_model.Delivery"
                 },
                 "error": {
                     "type": "boolean"
@@ -927,7 +5763,22 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateEventRequest": {
+        "model.UndoCheckInGuestResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpdateEventRequest": {
             "type": "object",
             "properties": {
                 "description": {
@@ -936,6 +5787,9 @@ const docTemplate = `{
                 "endDate": {
                     "type": "string"
                 },
+                "eventID": {
+                    "type": "string"
+                },
                 "eventName": {
                     "type": "string"
                 },
@@ -956,7 +5810,7 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateEventResponse": {
+        "model.UpdateEventResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -971,7 +5825,7 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateGuestRequest": {
+        "model.UpdateGuestRequest": {
             "type": "object",
             "properties": {
                 "address": {
@@ -989,6 +5843,9 @@ const docTemplate = `{
                 "guestData": {
                     "type": "string"
                 },
+                "guestID": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -1000,7 +5857,7 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateGuestResponse": {
+        "model.UpdateGuestResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1015,18 +5872,31 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateProjectRequest": {
+        "model.UpdateProjectRequest": {
             "type": "object",
             "properties": {
+                "options": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
                 "projectName": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "statusDesc": {
+                    "type": "string"
+                },
                 "userID": {
                     "type": "string"
                 }
             }
         },
-        "model.CreateProjectResponse": {
+        "model.UpdateProjectResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1041,7 +5911,7 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateUserRequest": {
+        "model.UpdateUserRequest": {
             "type": "object",
             "properties": {
                 "email": {
@@ -1053,9 +5923,6 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
-                },
                 "projectID": {
                     "type": "string"
                 },
@@ -1070,7 +5937,7 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CreateUserResponse": {
+        "model.UpdateUserResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1085,28 +5952,42 @@ const docTemplate = `{
                 }
             }
         },
-        "model.DeleteEventResponse": {
+        "model.UpdateWebhookRequest": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
-                },
-                "error": {
+                "active": {
                     "type": "boolean"
                 },
-                "message": {
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhookID": {
                     "type": "string"
                 }
             }
         },
-        "model.DeleteProjectResponse": {
+        "model.UpdateWebhookResponse": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
                     "format": "int32"
                 },
+                "data": {
+                    "$ref": "#/definitions/model.Webhook"
+                },
                 "error": {
                     "type": "boolean"
                 },
@@ -1115,25 +5996,80 @@ const docTemplate = `{
                 }
             }
         },
-        "model.DetailEventResponse": {
+        "model.UserRole": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
+                "createdAt": {
+                    "type": "string"
                 },
-                "data": {
-                    "$ref": "#/definitions/model.Event"
+                "createdById": {
+                    "type": "integer"
                 },
-                "error": {
+                "eventID": {
+                    "type": "integer"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "userRoleID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
                     "type": "boolean"
                 },
-                "message": {
+                "createdAt": {
                     "type": "string"
+                },
+                "createdById": {
+                    "type": "integer"
+                },
+                "createdByName": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "disabledReason": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "string"
+                },
+                "failureCount": {
+                    "type": "integer"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "updatedById": {
+                    "type": "integer"
+                },
+                "updatedByName": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhookID": {
+                    "type": "integer"
                 }
             }
         },
-        "model.Event": {
+        "rawuh-service_internal_event_model.Event": {
             "type": "object",
             "properties": {
                 "createdAt": {
@@ -1145,6 +6081,12 @@ const docTemplate = `{
                 "createdByName": {
                     "type": "string"
                 },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "deletedById": {
+                    "type": "integer"
+                },
                 "description": {
                     "type": "string"
                 },
@@ -1180,69 +6122,33 @@ const docTemplate = `{
                 }
             }
         },
-        "model.GetGuestByIDResponse": {
+        "rawuh-service_internal_guest_model.Guest": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
-                },
-                "data": {
-                    "$ref": "#/definitions/model.Guest"
-                },
-                "error": {
-                    "type": "boolean"
-                },
-                "message": {
+                "address": {
                     "type": "string"
-                }
-            }
-        },
-        "model.GetProjectDetailResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
                 },
-                "data": {
-                    "$ref": "#/definitions/model.Project"
+                "checkedInAt": {
+                    "type": "string"
                 },
-                "error": {
-                    "type": "boolean"
+                "checkedInById": {
+                    "type": "integer"
                 },
-                "message": {
+                "checkedInByName": {
                     "type": "string"
-                }
-            }
-        },
-        "model.GetUserByIDResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
-                },
-                "data": {
-                    "$ref": "#/definitions/rawuh-service_internal_user_model.User"
                 },
-                "error": {
-                    "type": "boolean"
+                "companionCount": {
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.Guest": {
-            "type": "object",
-            "properties": {
-                "address": {
+                "createdAt": {
                     "type": "string"
                 },
-                "createdAt": {
+                "deletedAt": {
                     "type": "string"
                 },
+                "deletedById": {
+                    "type": "integer"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -1267,12 +6173,65 @@ const docTemplate = `{
                 "projectID": {
                     "type": "integer"
                 },
+                "rsvpAt": {
+                    "type": "string"
+                },
+                "rsvpAttendees": {
+                    "type": "integer"
+                },
+                "rsvpStatus": {
+                    "type": "string"
+                },
                 "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "model.ListEventResponse": {
+        "rawuh-service_internal_invitation_model.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventID": {
+                    "type": "integer"
+                },
+                "guestID": {
+                    "type": "integer"
+                },
+                "invitationBatchID": {
+                    "type": "integer"
+                },
+                "invitationDeliveryID": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "recipient": {
+                    "type": "string"
+                },
+                "sentAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "rawuh-service_internal_invitation_model.GetTemplateResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1280,23 +6239,17 @@ const docTemplate = `{
                     "format": "int32"
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Event"
-                    }
+                    "$ref": "#/definitions/rawuh-service_internal_invitation_model.Template"
                 },
                 "error": {
                     "type": "boolean"
                 },
                 "message": {
                     "type": "string"
-                },
-                "pagination": {
-                    "$ref": "#/definitions/model.PaginationResponse"
                 }
             }
         },
-        "model.ListGuestResponse": {
+        "rawuh-service_internal_invitation_model.ListDeliveriesResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1306,7 +6259,7 @@ const docTemplate = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Guest"
+                        "$ref": "#/definitions/rawuh-service_internal_invitation_model.Delivery"
                     }
                 },
                 "error": {
@@ -1320,7 +6273,73 @@ const docTemplate = `{
                 }
             }
         },
-        "model.ListProjectResponse": {
+        "rawuh-service_internal_invitation_model.Preview": {
+            "type": "object",
+            "properties": {
+                "htmlBody": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "textBody": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "rawuh-service_internal_invitation_model.Template": {
+            "type": "object",
+            "properties": {
+                "eventID": {
+                    "type": "integer"
+                },
+                "htmlBody": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "textBody": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "updatedById": {
+                    "type": "integer"
+                },
+                "updatedByName": {
+                    "type": "string"
+                }
+            }
+        },
+        "rawuh-service_internal_invitation_model.UpdateTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string"
+                },
+                "htmlBody": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "textBody": {
+                    "type": "string"
+                }
+            }
+        },
+        "rawuh-service_internal_invitation_model.UpdateTemplateResponse": {
             "type": "object",
             "properties": {
                 "code": {
@@ -1328,221 +6347,198 @@ const docTemplate = `{
                     "format": "int32"
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Project"
-                    }
+                    "$ref": "#/definitions/rawuh-service_internal_invitation_model.Template"
                 },
                 "error": {
                     "type": "boolean"
                 },
                 "message": {
                     "type": "string"
-                },
-                "pagination": {
-                    "$ref": "#/definitions/model.PaginationResponse"
                 }
             }
         },
-        "model.ListUserResponse": {
+        "rawuh-service_internal_live_model.Guest": {
             "type": "object",
             "properties": {
-                "code": {
+                "checkedInAt": {
+                    "type": "string"
+                },
+                "checkedInByName": {
+                    "type": "string"
+                },
+                "companionCount": {
                     "type": "integer",
                     "format": "int32"
                 },
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/rawuh-service_internal_user_model.User"
-                    }
-                },
-                "error": {
-                    "type": "boolean"
+                "guestID": {
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "message": {
+                "name": {
                     "type": "string"
                 },
-                "pagination": {
-                    "$ref": "#/definitions/model.PaginationResponse"
+                "rsvpAttendees": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "rsvpStatus": {
+                    "type": "string"
                 }
             }
         },
-        "model.PaginationResponse": {
+        "rawuh-service_internal_message_model.GetTemplateResponse": {
             "type": "object",
             "properties": {
-                "limit": {
+                "code": {
                     "type": "integer",
                     "format": "int32"
                 },
-                "page": {
-                    "type": "integer",
-                    "format": "int32"
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_message_model.Template"
                 },
-                "totalPages": {
-                    "type": "integer",
-                    "format": "int32"
+                "error": {
+                    "type": "boolean"
                 },
-                "totalRows": {
-                    "type": "integer",
-                    "format": "int64"
+                "message": {
+                    "type": "string"
                 }
             }
         },
-        "model.Project": {
+        "rawuh-service_internal_message_model.Message": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
-                "createdById": {
+                "eventID": {
                     "type": "integer"
                 },
-                "projectID": {
+                "guestID": {
                     "type": "integer"
                 },
-                "projectName": {
+                "lastError": {
                     "type": "string"
                 },
-                "status": {
+                "messageBroadcastID": {
                     "type": "integer"
                 },
-                "statusDesc": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
-                },
-                "updatedById": {
+                "messageID": {
                     "type": "integer"
-                }
-            }
-        },
-        "model.UpdateEventRequest": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string"
                 },
-                "endDate": {
-                    "type": "string"
+                "messageTemplateID": {
+                    "type": "integer"
                 },
-                "eventID": {
+                "nextAttemptAt": {
                     "type": "string"
                 },
-                "eventName": {
-                    "type": "string"
+                "projectID": {
+                    "type": "integer"
                 },
-                "eventOptions": {
+                "providerMessageID": {
                     "type": "string"
                 },
-                "guestOptions": {
+                "recipient": {
                     "type": "string"
                 },
-                "projectID": {
+                "sentAt": {
                     "type": "string"
                 },
-                "startDate": {
+                "status": {
                     "type": "string"
                 },
-                "userID": {
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "model.UpdateEventResponse": {
+        "rawuh-service_internal_message_model.Preview": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
-                },
-                "error": {
-                    "type": "boolean"
+                "body": {
+                    "type": "string"
                 },
-                "message": {
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "model.UpdateGuestRequest": {
+        "rawuh-service_internal_message_model.Template": {
             "type": "object",
             "properties": {
-                "address": {
+                "body": {
                     "type": "string"
                 },
-                "email": {
+                "createdAt": {
                     "type": "string"
                 },
-                "eventData": {
-                    "type": "string"
+                "createdById": {
+                    "type": "integer"
                 },
-                "eventId": {
+                "createdByName": {
                     "type": "string"
                 },
-                "guestData": {
-                    "type": "string"
+                "eventID": {
+                    "type": "integer"
                 },
-                "guestID": {
-                    "type": "string"
+                "messageTemplateID": {
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "phone": {
-                    "type": "string"
-                },
                 "projectID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
                     "type": "string"
-                }
-            }
-        },
-        "model.UpdateGuestResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "format": "int32"
                 },
-                "error": {
-                    "type": "boolean"
+                "updatedById": {
+                    "type": "integer"
                 },
-                "message": {
+                "updatedByName": {
                     "type": "string"
                 }
             }
         },
-        "model.UpdateProjectRequest": {
+        "rawuh-service_internal_message_model.UpdateTemplateRequest": {
             "type": "object",
             "properties": {
-                "options": {
+                "body": {
                     "type": "string"
                 },
-                "projectID": {
+                "eventId": {
                     "type": "string"
                 },
-                "projectName": {
+                "name": {
                     "type": "string"
                 },
-                "status": {
-                    "type": "integer",
-                    "format": "int32"
-                },
-                "statusDesc": {
+                "projectID": {
                     "type": "string"
                 },
-                "userID": {
+                "templateID": {
                     "type": "string"
                 }
             }
         },
-        "model.UpdateProjectResponse": {
+        "rawuh-service_internal_message_model.UpdateTemplateResponse": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
                     "format": "int32"
                 },
+                "data": {
+                    "$ref": "#/definitions/rawuh-service_internal_message_model.Template"
+                },
                 "error": {
                     "type": "boolean"
                 },
@@ -1551,43 +6547,46 @@ const docTemplate = `{
                 }
             }
         },
-        "model.UpdateUserRequest": {
+        "rawuh-service_internal_rsvp_model.Event": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "eventId": {
+                "description": {
                     "type": "string"
                 },
-                "name": {
+                "endDate": {
                     "type": "string"
                 },
-                "projectID": {
+                "eventName": {
                     "type": "string"
                 },
-                "userID": {
+                "eventOptions": {
                     "type": "string"
                 },
-                "userType": {
+                "guestOptions": {
                     "type": "string"
                 },
-                "username": {
+                "startDate": {
                     "type": "string"
                 }
             }
         },
-        "model.UpdateUserResponse": {
+        "rawuh-service_internal_rsvp_model.Guest": {
             "type": "object",
             "properties": {
-                "code": {
+                "guestData": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rsvpAt": {
+                    "type": "string"
+                },
+                "rsvpAttendees": {
                     "type": "integer",
                     "format": "int32"
                 },
-                "error": {
-                    "type": "boolean"
-                },
-                "message": {
+                "rsvpStatus": {
                     "type": "string"
                 }
             }
@@ -1604,6 +6603,12 @@ const docTemplate = `{
                 "createdByName": {
                     "type": "string"
                 },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "deletedById": {
+                    "type": "integer"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -1639,6 +6644,83 @@ const docTemplate = `{
                 }
             }
         },
+        "rawuh-service_internal_webhook_model.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "eventID": {
+                    "type": "integer"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "integer"
+                },
+                "responseBody": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "webhookDeliveryID": {
+                    "type": "integer"
+                },
+                "webhookID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rawuh-service_internal_webhook_model.ListDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rawuh-service_internal_webhook_model.Delivery"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/model.PaginationResponse"
+                }
+            }
+        },
         "utils.APIErrorResponse": {
             "type": "object",
             "properties": {
@@ -1648,6 +6730,13 @@ const docTemplate = `{
                 "Error": {
                     "type": "boolean"
                 },
+                "Fields": {
+                    "description": "Fields holds one message per invalid field, when the error has them.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "Message": {
                     "type": "string"
                 }
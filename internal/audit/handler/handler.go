@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/middleware"
+	"strconv"
+)
+
+type AuditHandler struct {
+	svc auditService.AuditService
+}
+
+func NewAuditHandler(svc auditService.AuditService) *AuditHandler {
+	return &AuditHandler{
+		svc: svc,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description Get paginated list of recorded creates, updates and deletes, newest first. System admins only.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param dir query string false "asc or desc (default)"
+// @Param action query string false "create, update, delete, restore, import, check_in, undo_check_in, merge, send or retry"
+// @Param entity_type query string false "project, event, guest, user, user_role, invitation_template, invitation_batch, message_template, message_broadcast, job or webhook"
+// @Param entity_id query string false "entity id"
+// @Param actor_id query int false "user id of the actor"
+// @Param project_id query int false "project id"
+// @Param event_id query int false "event id"
+// @Param request_id query string false "X-Request-ID of the request that made the change"
+// @Param from query string false "RFC 3339 time, inclusive"
+// @Param to query string false "RFC 3339 time, exclusive"
+// @Success 200 {object} auditModel.ListAuditLogResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /audit [get]
+
+func (h *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	page, _ := strconv.Atoi(queryParams.Get("page"))
+	limit, _ := strconv.Atoi(queryParams.Get("limit"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := &auditModel.ListAuditLogRequest{
+		Page:       int32(page),
+		Limit:      int32(limit),
+		Dir:        queryParams.Get("dir"),
+		Cursor:     utils.QueryCursor(queryParams),
+		Count:      queryParams.Get("count"),
+		Action:     queryParams.Get("action"),
+		EntityType: queryParams.Get("entity_type"),
+		EntityID:   queryParams.Get("entity_id"),
+		ActorID:    queryParams.Get("actor_id"),
+		ProjectID:  queryParams.Get("project_id"),
+		EventID:    queryParams.Get("event_id"),
+		RequestID:  queryParams.Get("request_id"),
+		From:       queryParams.Get("from"),
+		To:         queryParams.Get("to"),
+	}
+
+	logs, err := h.svc.ListAuditLogs(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(logs)
+}
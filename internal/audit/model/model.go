@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+type AuditLog struct {
+	AuditLogID int64      `gorm:"primaryKey;autoIncrement"`
+	ActorID    int64      `gorm:"type:bigint"`
+	ActorName  string     `gorm:"type:varchar(500)"`
+	Action     string     `gorm:"type:varchar(50)"`
+	EntityType string     `gorm:"type:varchar(50)"`
+	EntityID   string     `gorm:"type:varchar(100)"`
+	ProjectID  int64      `gorm:"type:bigint"`
+	EventID    int64      `gorm:"type:bigint"`
+	Before     *JSON      `gorm:"type:jsonb"`
+	After      *JSON      `gorm:"type:jsonb"`
+	Diff       *JSON      `gorm:"type:jsonb"`
+	RequestID  string     `gorm:"type:varchar(100)"`
+	CreatedAt  *time.Time `gorm:"type:timestamp"`
+}
+
+// JSON is a jsonb column. It is written into API responses as JSON rather
+// than as a quoted string.
+type JSON string
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// Entry describes one mutation. Before is empty for creates and After for
+// deletes; both are stored as JSON together with the fields that changed.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	ProjectID  string
+	EventID    string
+	Before     interface{}
+	After      interface{}
+}
+
+// AuditLogFilter narrows ListAuditLogs down; zero fields match everything.
+type AuditLogFilter struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	ActorID    int64
+	ProjectID  int64
+	EventID    int64
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
@@ -0,0 +1,28 @@
+package model
+
+import "rawuh-service/internal/shared/model"
+
+type ListAuditLogRequest struct {
+	Page       int32
+	Limit      int32
+	Cursor     *string
+	Count      string
+	Dir        string
+	Action     string
+	EntityType string
+	EntityID   string
+	ActorID    string
+	ProjectID  string
+	EventID    string
+	RequestID  string
+	From       string
+	To         string
+}
+
+type ListAuditLogResponse struct {
+	Error      bool
+	Code       int32
+	Message    string
+	Data       []*AuditLog
+	Pagination *model.PaginationResponse
+}
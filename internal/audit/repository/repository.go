@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+
+	auditModel "rawuh-service/internal/audit/model"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/model"
+)
+
+type AuditRepository struct {
+	provider *db.GormProvider
+}
+
+func NewAuditRepository(provider *db.GormProvider) *AuditRepository {
+	return &AuditRepository{
+		provider: provider,
+	}
+}
+
+func (p *AuditRepository) CreateAuditLog(ctx context.Context, data *auditModel.AuditLog) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.audit_logs")
+
+	if err := query.Omit("audit_log_id").Create(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *AuditRepository) ListAuditLogs(ctx context.Context, filter *auditModel.AuditLogFilter, pagination *model.PaginationResponse, sort *model.Sort) (data []*auditModel.AuditLog, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.audit_logs")
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.EventID != 0 {
+		query = query.Where("event_id = ?", filter.EventID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if pagination.Cursor != nil {
+		return db.Keyset[auditModel.AuditLog](query, pagination, sort, "audit_log_id")
+	}
+
+	query = query.Scopes(db.Paginate(data, pagination, query))
+	query = query.Scopes(
+		db.Sort(sort),
+	)
+
+	if err := query.Order("audit_log_id " + sort.Direction).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
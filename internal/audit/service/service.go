@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	auditModel "rawuh-service/internal/audit/model"
+	auditDb "rawuh-service/internal/audit/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/model"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, entry *auditModel.Entry)
+	ListAuditLogs(ctx context.Context, req *auditModel.ListAuditLogRequest) (*auditModel.ListAuditLogResponse, error)
+}
+
+type auditService struct {
+	dbProvider *auditDb.AuditRepository
+	logger     *logger.Logger
+}
+
+func NewAuditService(dbProvider *auditDb.AuditRepository, logger *logger.Logger) AuditService {
+	return &auditService{
+		dbProvider: dbProvider,
+		logger:     logger,
+	}
+}
+
+// Record stores an audit entry for the user and request in ctx. It runs after
+// the mutation succeeded; a failure to store the entry is logged and does not
+// fail the request.
+func (s *auditService) Record(ctx context.Context, entry *auditModel.Entry) {
+	span, ctx := apm.StartSpan(ctx, "Record", constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	currentUser, _ := middleware.GetAuthClaimsFromContext(ctx)
+	projectID, _ := strconv.ParseInt(entry.ProjectID, 10, 64)
+	eventID, _ := strconv.ParseInt(entry.EventID, 10, 64)
+
+	now := time.Now()
+	data := &auditModel.AuditLog{
+		ActorID:    currentUser.UserID,
+		ActorName:  currentUser.Name,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		ProjectID:  projectID,
+		EventID:    eventID,
+		RequestID:  middleware.GetRequestID(ctx),
+		CreatedAt:  &now,
+	}
+
+	before, err := toFields(entry.Before)
+	if err != nil {
+		s.logger.Error("err Record audit before ", err)
+	}
+	after, err := toFields(entry.After)
+	if err != nil {
+		s.logger.Error("err Record audit after ", err)
+	}
+	data.Before = toJSON(before)
+	data.After = toJSON(after)
+	if diff := diffFields(before, after); len(diff) > 0 {
+		data.Diff = toJSON(diff)
+	}
+
+	if err := s.dbProvider.CreateAuditLog(ctx, data); err != nil {
+		s.logger.Error("err CreateAuditLog ", err)
+	}
+}
+
+func (s *auditService) ListAuditLogs(ctx context.Context, req *auditModel.ListAuditLogRequest) (*auditModel.ListAuditLogResponse, error) {
+	funcName := "ListAuditLogs"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.AuditRead, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	filter := &auditModel.AuditLogFilter{
+		Action:     req.Action,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		RequestID:  req.RequestID,
+	}
+
+	ids := []struct {
+		value string
+		dest  *int64
+	}{
+		{req.ActorID, &filter.ActorID},
+		{req.ProjectID, &filter.ProjectID},
+		{req.EventID, &filter.EventID},
+	}
+	for _, id := range ids {
+		if id.value == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(id.value, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid id %q", id.value)
+		}
+		*id.dest = v
+	}
+
+	times := []struct {
+		value string
+		dest  **time.Time
+	}{
+		{req.From, &filter.From},
+		{req.To, &filter.To},
+	}
+	for _, t := range times {
+		if t.value == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, t.value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid time %q, use RFC 3339", t.value)
+		}
+		*t.dest = &v
+	}
+
+	direction := strings.ToLower(req.Dir)
+	switch direction {
+	case "":
+		direction = "desc"
+	case "asc", "desc":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+	}
+	sort := &model.Sort{
+		Column:    "created_at",
+		Direction: direction,
+	}
+
+	// the audit table grows without bound, so always page through it
+	if req.Page == 0 && req.Limit == 0 {
+		req.Page = 1
+	}
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	loggerZap.Info("Start ListAuditLogs")
+	logs, err := s.dbProvider.ListAuditLogs(ctx, filter, pagination, sort)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		loggerZap.Error("err ListAuditLogs ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &auditModel.ListAuditLogResponse{
+		Error:      false,
+		Code:       http.StatusOK,
+		Message:    "Success",
+		Data:       logs,
+		Pagination: pagination,
+	}
+
+	return result, nil
+}
+
+// toFields turns an entity into its JSON fields.
+func toFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// diffFields returns {"field": {"before": .., "after": ..}} for every field
+// that differs between before and after.
+func diffFields(before map[string]interface{}, after map[string]interface{}) map[string]interface{} {
+	diff := map[string]interface{}{}
+	for k, b := range before {
+		if a, ok := after[k]; !ok || !reflect.DeepEqual(a, b) {
+			diff[k] = map[string]interface{}{"before": b, "after": after[k]}
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok && a != nil {
+			diff[k] = map[string]interface{}{"before": nil, "after": a}
+		}
+	}
+	return diff
+}
+
+func toJSON(v map[string]interface{}) *auditModel.JSON {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	j := auditModel.JSON(raw)
+	return &j
+}
@@ -2,29 +2,35 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	authService "rawuh-service/internal/auth/service"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
-	"rawuh-service/internal/shared/redis"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/session"
+	userModel "rawuh-service/internal/user/model"
 	userDb "rawuh-service/internal/user/repository"
 
-	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type AuthHandler struct {
-	authSvc authService.AuthService
-	userDb  *userDb.UserRepository
-	rdb     *redis.Redis
-	logger  *logger.Logger
+	authSvc  authService.AuthService
+	userDb   *userDb.UserRepository
+	sessions *session.Store
+	logger   *logger.Logger
 }
 
-func NewAuthHandler(a authService.AuthService, u *userDb.UserRepository, r *redis.Redis, l *logger.Logger) *AuthHandler {
-	return &AuthHandler{authSvc: a, userDb: u, rdb: r, logger: l}
+func NewAuthHandler(a authService.AuthService, u *userDb.UserRepository, s *session.Store, l *logger.Logger) *AuthHandler {
+	return &AuthHandler{authSvc: a, userDb: u, sessions: s, logger: l}
 }
 
 type loginRequest struct {
@@ -33,15 +39,40 @@ type loginRequest struct {
 }
 
 type loginResponse struct {
-	Error       bool   `json:"error"`
-	Code        int    `json:"code"`
-	AccessToken string `json:"access_token"`
-	Message     string `json:"message"`
+	Error            bool                  `json:"error"`
+	Code             int                   `json:"code"`
+	AccessToken      string                `json:"access_token"`
+	RefreshToken     string                `json:"refresh_token"`
+	ExpiresIn        int64                 `json:"expires_in"`
+	RefreshExpiresIn int64                 `json:"refresh_expires_in"`
+	Message          string                `json:"message"`
+	ActiveProjectID  int64                 `json:"active_project_id,omitempty"`
+	ActiveEventID    int64                 `json:"active_event_id,omitempty"`
+	Memberships      []*membershipResponse `json:"memberships,omitempty"`
+}
+
+// membershipResponse is a role the user holds in a project, or in one event
+// of it when EventID is set.
+type membershipResponse struct {
+	ProjectID   int64  `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	EventID     int64  `json:"event_id,omitempty"`
+	EventName   string `json:"event_name,omitempty"`
+	Role        string `json:"role"`
+}
+
+type switchProjectRequest struct {
+	ProjectID int64 `json:"project_id"`
+	EventID   int64 `json:"event_id"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // Login godoc
 // @Summary Login with username and password
-// @Description Authenticate user and return an access token
+// @Description Authenticate user and return an access token, together with the projects and events the user is a member of. The session starts in the user's default project, or in the first membership when the user no longer belongs to it.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -82,39 +113,234 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	memberships, err := h.userDb.ListMemberships(ctx, userIDStr)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	projectID, eventID := authRow.ProjectID, user.EventId
+	if user.UserType != constant.UserTypeSystemAdmin && !isMember(memberships, projectID, eventID) {
+		projectID, eventID = 0, 0
+		if len(memberships) > 0 {
+			projectID = memberships[0].ProjectID
+			if memberships[0].EventID != nil {
+				eventID = *memberships[0].EventID
+			}
+		}
+	}
+
 	// prepare token payload
 	payload := map[string]interface{}{
 		"username":   authRow.Username,
 		"name":       user.Name,
 		"user_id":    authRow.UserID,
-		"project_id": authRow.ProjectID,
-		"event_id":   user.EventId,
+		"project_id": projectID,
+		"event_id":   eventID,
 		"usertype":   user.UserType,
+		"roles":      roleGrants(memberships),
 	}
 
-	// generate token
-	token := uuid.New().String()
-	key := "access_token:" + token
-	// store in redis, 24h
-	if err := h.rdb.Set(ctx, key, payload, 24*time.Hour); err != nil {
+	tokens, err := h.sessions.Create(ctx, authRow.UserID, payload, session.Meta{
+		IP:        middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
 		utils.HandleGrpcError(w, err)
 		return
 	}
 
-	res := &loginResponse{
-		Error:       false,
-		Code:        http.StatusOK,
-		AccessToken: "Bearer " + token,
-		Message:     "success",
+	res := newLoginResponse(tokens)
+	res.ActiveProjectID = projectID
+	res.ActiveEventID = eventID
+	res.Memberships = membershipResponses(memberships)
+	writeLoginResponse(w, res)
+}
+
+// Refresh godoc
+// @Summary Refresh the access token
+// @Description Swap a refresh token for a new access token and refresh token. Each refresh token works once; presenting a used one again revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body refreshRequest true "Refresh token"
+// @Success 200 {object} loginResponse
+// @Failure 401 {object} utils.APIErrorResponse
+// @Router /auth/refresh [post]
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		utils.HandleGrpcError(w, status.Error(codes.InvalidArgument, "refresh token required"))
+		return
+	}
+
+	tokens, err := h.sessions.Refresh(ctx, req.RefreshToken, session.Meta{
+		IP:        middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	switch {
+	case errors.Is(err, session.ErrRefreshTokenReused):
+		h.logger.Warn("refresh token reused, session revoked", map[string]string{"ip": middleware.ClientIP(r)})
+		utils.HandleGrpcError(w, status.Error(codes.Unauthenticated, "invalid refresh token"))
+		return
+	case errors.Is(err, session.ErrInvalidRefreshToken):
+		utils.HandleGrpcError(w, status.Error(codes.Unauthenticated, "invalid refresh token"))
+		return
+	case errors.Is(err, session.ErrRefreshInProgress):
+		utils.HandleGrpcError(w, status.Error(codes.AlreadyExists, "refresh already in progress"))
+		return
+	case err != nil:
+		h.logger.Error("err Refresh session", err)
+		utils.HandleGrpcError(w, status.Error(codes.Internal, "Internal Server Error"))
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// SwitchProject godoc
+// @Summary Switch the active project
+// @Description Move the current session to another project, and optionally one of its events, the user is a member of. Returns a new token pair; the old access and refresh tokens stop working. Roles are read again, so role changes apply without logging in.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body switchProjectRequest true "Project to switch to"
+// @Success 200 {object} loginResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /auth/switch-project [post]
+
+func (h *AuthHandler) SwitchProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	payload, _ := middleware.GetAuthPayload(ctx)
+	currentUser, ok := middleware.ParseAuthClaims(payload)
+	sessionID, _ := middleware.GetStringClaim(payload, session.PayloadSessionID)
+	if !ok || sessionID == "" {
+		utils.HandleGrpcError(w, status.Error(codes.Unauthenticated, "Unauthenticated"))
+		return
+	}
+
+	var req switchProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ProjectID <= 0 || req.EventID < 0 {
+		utils.HandleGrpcError(w, status.Error(codes.InvalidArgument, "Invalid Project Id"))
+		return
+	}
+
+	memberships, err := h.userDb.ListMemberships(ctx, strconv.FormatInt(currentUser.UserID, 10))
+	if err != nil {
+		h.logger.Error("err ListMemberships", err)
+		utils.HandleGrpcError(w, status.Error(codes.Internal, "Internal Server Error"))
+		return
+	}
+
+	if currentUser.UserType != constant.UserTypeSystemAdmin && !isMember(memberships, req.ProjectID, req.EventID) {
+		utils.HandleGrpcError(w, status.Error(codes.PermissionDenied, "not a member of this project"))
+		return
+	}
+
+	tokens, err := h.sessions.Rescope(ctx, sessionID, map[string]interface{}{
+		"project_id": req.ProjectID,
+		"event_id":   req.EventID,
+		"roles":      roleGrants(memberships),
+	})
+	switch {
+	case errors.Is(err, session.ErrSessionNotFound):
+		utils.HandleGrpcError(w, status.Error(codes.Unauthenticated, "Unauthenticated"))
+		return
+	case errors.Is(err, session.ErrRefreshInProgress):
+		utils.HandleGrpcError(w, status.Error(codes.AlreadyExists, "refresh already in progress"))
+		return
+	case err != nil:
+		h.logger.Error("err Rescope session", err)
+		utils.HandleGrpcError(w, status.Error(codes.Internal, "Internal Server Error"))
+		return
+	}
+
+	res := newLoginResponse(tokens)
+	res.ActiveProjectID = req.ProjectID
+	res.ActiveEventID = req.EventID
+	res.Memberships = membershipResponses(memberships)
+	writeLoginResponse(w, res)
+}
+
+// isMember reports whether a membership covers the project, and the event
+// when eventID is not 0.
+func isMember(memberships []*userModel.Membership, projectID int64, eventID int64) bool {
+	for _, m := range memberships {
+		if m.ProjectID != projectID {
+			continue
+		}
+		if eventID == 0 || m.EventID == nil || *m.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func roleGrants(memberships []*userModel.Membership) []middleware.RoleGrant {
+	roles := make([]middleware.RoleGrant, 0, len(memberships))
+	for _, m := range memberships {
+		grant := middleware.RoleGrant{Role: m.Role, ProjectID: m.ProjectID}
+		if m.EventID != nil {
+			grant.EventID = *m.EventID
+		}
+		roles = append(roles, grant)
 	}
+	return roles
+}
+
+func membershipResponses(memberships []*userModel.Membership) []*membershipResponse {
+	data := make([]*membershipResponse, 0, len(memberships))
+	for _, m := range memberships {
+		item := &membershipResponse{
+			ProjectID:   m.ProjectID,
+			ProjectName: m.ProjectName,
+			Role:        m.Role,
+		}
+		if m.EventID != nil {
+			item.EventID = *m.EventID
+		}
+		if m.EventName != nil {
+			item.EventName = *m.EventName
+		}
+		data = append(data, item)
+	}
+	return data
+}
+
+func writeTokens(w http.ResponseWriter, tokens *session.Tokens) {
+	writeLoginResponse(w, newLoginResponse(tokens))
+}
+
+func newLoginResponse(tokens *session.Tokens) *loginResponse {
+	now := time.Now()
+	return &loginResponse{
+		Error:            false,
+		Code:             http.StatusOK,
+		AccessToken:      "Bearer " + tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        int64(tokens.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshExpiresIn: int64(tokens.RefreshExpiresAt.Sub(now).Seconds()),
+		Message:          "success",
+	}
+}
+
+func writeLoginResponse(w http.ResponseWriter, res *loginResponse) {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(res)
 }
 
 // TokenInfo returns the payload stored for the provided Bearer token.
-// It reads the Authorization: Bearer <token> header, fetches the payload
-// from Redis and returns it as JSON.
+// It reads the Authorization: Bearer <token> header, verifies the token
+// and returns its payload as JSON, including the roles the user holds per
+// project and event.
 func (h *AuthHandler) TokenInfo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	auth := r.Header.Get("Authorization")
@@ -131,21 +357,133 @@ func (h *AuthHandler) TokenInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	key := "access_token:" + token
-	val, err := h.rdb.Get(ctx, key)
-	if err != nil || val == "" {
+	payload, err := h.sessions.Verify(ctx, token)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+	if payload == nil {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]interface{}{"error": true, "message": "token not found"})
 		return
 	}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal([]byte(val), &payload); err != nil {
-		utils.HandleGrpcError(w, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(payload)
+}
+
+type logoutResponse struct {
+	Error   bool   `json:"error"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type sessionResponse struct {
+	SessionID   string    `json:"session_id"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Current     bool      `json:"current"`
+}
+
+type listSessionsResponse struct {
+	Error   bool               `json:"error"`
+	Code    int                `json:"code"`
+	Message string             `json:"message"`
+	Data    []*sessionResponse `json:"data"`
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the access token used for this request together with its refresh token
+// @Tags auth
+// @Produce json
+// @Success 200 {object} logoutResponse
+// @Failure 401 {object} utils.APIErrorResponse
+// @Router /logout [post]
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.sessions.Revoke(ctx, middleware.BearerToken(r)); err != nil {
+		h.logger.Error("err Revoke session", err)
+		utils.HandleGrpcError(w, status.Error(codes.Internal, "Internal Server Error"))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(payload)
+	json.NewEncoder(w).Encode(&logoutResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "success",
+	})
+}
+
+// ListSessions godoc
+// @Summary List active sessions
+// @Description List the active access tokens of the current user. A system admin may pass user_id to see the sessions of another user.
+// @Tags auth
+// @Produce json
+// @Param user_id query int false "user id, system admin only"
+// @Success 200 {object} listSessionsResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /auth/sessions [get]
+
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		utils.HandleGrpcError(w, status.Error(codes.Unauthenticated, "Unauthenticated"))
+		return
+	}
+
+	userID := currentUser.UserID
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		requested, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			utils.HandleGrpcError(w, status.Error(codes.InvalidArgument, "Invalid User Id"))
+			return
+		}
+		if requested != currentUser.UserID && !authz.Can(currentUser, authz.UserManage, "", "") {
+			utils.HandleGrpcError(w, status.Error(codes.PermissionDenied, "Permission Denied"))
+			return
+		}
+		userID = requested
+	}
+
+	sessions, err := h.sessions.List(ctx, userID)
+	if err != nil {
+		h.logger.Error("err List sessions", err)
+		utils.HandleGrpcError(w, status.Error(codes.Internal, "Internal Server Error"))
+		return
+	}
+
+	payload, _ := middleware.GetAuthPayload(ctx)
+	currentID, _ := middleware.GetStringClaim(payload, session.PayloadSessionID)
+	data := make([]*sessionResponse, 0, len(sessions))
+	for _, sess := range sessions {
+		data = append(data, &sessionResponse{
+			SessionID:   sess.SessionID,
+			IP:          sess.IP,
+			UserAgent:   sess.UserAgent,
+			CreatedAt:   sess.CreatedAt,
+			RefreshedAt: sess.RefreshedAt,
+			ExpiresAt:   sess.ExpiresAt,
+			Current:     sess.SessionID == currentID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&listSessionsResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "success",
+		Data:    data,
+	})
 }
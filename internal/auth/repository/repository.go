@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	model "rawuh-service/internal/auth/model"
 	dbshared "rawuh-service/internal/shared/db"
@@ -18,7 +19,8 @@ func NewAuthRepository(provider *dbshared.GormProvider) *AuthRepository {
 	return &AuthRepository{provider: provider}
 }
 
-// GetAuthByUsername returns the auth row for the given username from public.auth
+// GetAuthByUsername returns the auth row for the given username from public.auth.
+// Deleted users cannot log in, their rows are skipped.
 func (p *AuthRepository) GetAuthByUsername(ctx context.Context, username string) (*model.Auth, error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
@@ -27,6 +29,7 @@ func (p *AuthRepository) GetAuthByUsername(ctx context.Context, username string)
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.auth")
 	query = query.Select("user_id, username, password, project_id, created_at, updated_at")
 	query = query.Where("username = ?", username)
+	query = query.Where("EXISTS (SELECT 1 FROM public.users u WHERE u.user_id = auth.user_id AND u.deleted_at IS NULL)")
 
 	if err := query.Take(&data).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -49,3 +52,45 @@ func (p *AuthRepository) CreateAuth(ctx context.Context, a *model.Auth) error {
 	}
 	return nil
 }
+
+// UpdatePassword replaces the stored password of a user
+func (p *AuthRepository) UpdatePassword(ctx context.Context, userID int64, password string) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.auth")
+	query = query.Where("user_id = ?", userID)
+
+	res := query.Updates(map[string]interface{}{
+		"password":   password,
+		"updated_at": time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CountLegacyPasswords returns the number of auth rows and how many of them
+// still hold an AES encrypted password instead of an argon2id hash
+func (p *AuthRepository) CountLegacyPasswords(ctx context.Context) (total int64, legacy int64, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var counts struct {
+		Total  int64
+		Legacy int64
+	}
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.auth")
+	query = query.Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE password NOT LIKE ?) AS legacy", "$argon2id$%")
+
+	if err := query.Scan(&counts).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return counts.Total, counts.Legacy, nil
+}
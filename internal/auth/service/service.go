@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"rawuh-service/internal/auth/model"
 	"rawuh-service/internal/auth/repository"
 	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
+	"sync"
 
 	"go.elastic.co/apm/v2"
 	"google.golang.org/grpc/codes"
@@ -46,20 +48,62 @@ func (s *authService) Authenticate(ctx context.Context, username, password strin
 	}
 	if auth == nil {
 		loggerZap.Info("auth not found for username")
+		// hash anyway so an unknown username takes as long as a wrong password
+		utils.VerifyPassword(getDummyPasswordHash(), password)
 		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 	}
 
-	decrypted, err := utils.DecryptAES(auth.Password)
+	ok, needsRehash, err := checkPassword(auth.Password, password)
 	if err != nil {
-		loggerZap.Error("err DecryptAES", err)
+		loggerZap.Error("err checkPassword", err)
 		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
 
-	if decrypted != password {
+	if !ok {
 		loggerZap.Warn("invalid password for user", nil)
 		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 	}
 
+	// the plaintext is only known right after a successful login, so this is
+	// where legacy AES passwords are upgraded
+	if needsRehash {
+		hashed, err := utils.HashPassword(password)
+		if err != nil {
+			loggerZap.Error("err HashPassword", err)
+		} else if err := s.repo.UpdatePassword(ctx, auth.UserID, hashed); err != nil {
+			loggerZap.Error("err UpdatePassword", err)
+		} else {
+			loggerZap.Info("password rehashed for user")
+		}
+	}
+
 	loggerZap.Info("authentication success for user")
 	return auth, nil
 }
+
+// checkPassword compares password with the stored one. Legacy AES passwords
+// are decrypted and compared in constant time and always need a rehash.
+func checkPassword(stored string, password string) (ok bool, needsRehash bool, err error) {
+	if !utils.IsLegacyPassword(stored) {
+		return utils.VerifyPassword(stored, password)
+	}
+
+	decrypted, err := utils.DecryptAES(stored)
+	if err != nil {
+		return false, false, err
+	}
+
+	return subtle.ConstantTimeCompare([]byte(decrypted), []byte(password)) == 1, true, nil
+}
+
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+func getDummyPasswordHash() string {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = utils.HashPassword("rawuh-dummy-password")
+	})
+	return dummyPasswordHash
+}
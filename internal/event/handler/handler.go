@@ -24,12 +24,16 @@ func NewEventHandler(svc eventService.EventService) *EventHandler {
 
 // ListEvent godoc
 // @Summary List events
-// @Description Get paginated list of events for a project
+// @Description Get paginated list of events for a project. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.
 // @Tags event
 // @Accept json
 // @Produce json
 // @Param page query int false "page"
 // @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param sort query string false "sort column, any filterable column"
+// @Param dir query string false "asc or desc"
 // @Success 200 {object} eventModel.ListEventResponse
 // @Failure 400 {object} utils.APIErrorResponse
 // @Router /{project_id}/events/list [get]
@@ -63,7 +67,9 @@ func (h *EventHandler) ListEvent(w http.ResponseWriter, r *http.Request) {
 		Limit:     int32(limit),
 		Sort:      queryParams.Get("sort"),
 		Dir:       queryParams.Get("dir"),
-		Query:     queryParams.Get("query"),
+		Filter:    queryParams,
+		Cursor:    utils.QueryCursor(queryParams),
+		Count:     queryParams.Get("count"),
 		ProjectID: mux.Vars(r)["project_id"],
 	}
 
@@ -295,3 +301,39 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
 }
+
+// RestoreEvent godoc
+// @Summary Restore a deleted event
+// @Description Restore a deleted event together with the guests that were deleted with it. The project must not be deleted.
+// @Tags event
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Success 200 {object} eventModel.RestoreEventResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 412 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/restore [post]
+
+func (h *EventHandler) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &eventModel.RestoreEventRequest{
+		EventsID:  mux.Vars(r)["event_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	event, err := h.svc.RestoreEvent(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(event)
+}
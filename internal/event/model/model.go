@@ -19,4 +19,6 @@ type Event struct {
 	CreatedByName string     `gorm:"type:varchar(500)"`
 	UpdatedById   int64      `gorm:"type:bigint"`
 	UpdatedByName string     `gorm:"type:varchar(500)"`
+	DeletedAt     *time.Time `gorm:"type:timestamp"`
+	DeletedById   int64      `gorm:"type:bigint"`
 }
@@ -1,16 +1,19 @@
 package model
 
 import (
+	"net/url"
 	"rawuh-service/internal/shared/model"
 	"time"
 )
 
 type ListEventRequest struct {
-	Page      int32  `json:"page"`
-	Limit     int32  `json:"limit"`
-	Sort      string `json:"sort"`
-	Dir       string `json:"dir"`
-	Query     string `json:"query"`
+	Page      int32      `json:"page"`
+	Limit     int32      `json:"limit"`
+	Sort      string     `json:"sort"`
+	Dir       string     `json:"dir"`
+	Filter    url.Values `json:"filter"`
+	Cursor    *string    `json:"cursor"`
+	Count     string     `json:"count"`
 	ProjectID string
 }
 
@@ -80,3 +83,15 @@ type DeleteEventResponse struct {
 	Code    int32
 	Message string
 }
+
+type RestoreEventRequest struct {
+	EventsID  string
+	ProjectID string
+}
+
+type RestoreEventResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Event
+}
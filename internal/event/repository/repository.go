@@ -4,14 +4,24 @@ import (
 	"context"
 	"errors"
 	eventModel "rawuh-service/internal/event/model"
+	jobModel "rawuh-service/internal/job/model"
+	jobDb "rawuh-service/internal/job/repository"
+	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/filter"
 	"rawuh-service/internal/shared/middleware"
 	"rawuh-service/internal/shared/model"
+	webhookDb "rawuh-service/internal/webhook/repository"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrProjectDeleted is returned when an event is restored while its project
+// is still deleted.
+var ErrProjectDeleted = errors.New("project is deleted")
+
 type EventRepository struct {
 	provider *db.GormProvider
 }
@@ -22,18 +32,28 @@ func NewEventRepository(provider *db.GormProvider) *EventRepository {
 	}
 }
 
-func (p *EventRepository) ListEvent(ctx context.Context, currentUser middleware.AuthClaims, pagination *model.PaginationResponse, sql *db.QueryBuilder, sort *model.Sort) (data []*eventModel.Event, err error) {
+// ListEvent lists the events of a project. nil eventIDs lists every event,
+// otherwise only those events.
+func (p *EventRepository) ListEvent(ctx context.Context, projectID string, eventIDs []int64, pagination *model.PaginationResponse, sql *db.QueryBuilder, sort *model.Sort) (data []*eventModel.Event, err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
 
-	query = query.Where("project_id = ?", currentUser.ProjectID)
+	query = query.Where("project_id = ? AND deleted_at IS NULL", projectID)
+
+	if eventIDs != nil {
+		query = query.Where("event_id IN ?", eventIDs)
+	}
 
 	query = query.Scopes(
-		db.QueryScoop(sql.CollectiveAnd),
+		sql.Filter.Scope(),
 	)
 
+	if pagination.Cursor != nil {
+		return db.Keyset[eventModel.Event](query, pagination, sort, "event_id")
+	}
+
 	query = query.Scopes(db.Paginate(data, pagination, query))
 	query = query.Scopes(
 		db.Sort(sort),
@@ -49,11 +69,38 @@ func (p *EventRepository) ListEvent(ctx context.Context, currentUser middleware.
 
 }
 
-func (p *EventRepository) CreateEvent(ctx context.Context, req *eventModel.CreateEventRequest, currentUser middleware.AuthClaims) error {
+// ListOptionFields returns the paths written inside event_options and
+// guest_options of the events of a project.
+func (p *EventRepository) ListOptionFields(ctx context.Context, projectID string) (map[string]map[string]filter.Type, error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
-	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.json_fields")
+
+	query = query.Where("project_id = ? AND event_id = 0", projectID)
+
+	return db.JSONFields(query, "event_options", "guest_options")
+}
+
+// CreateEvent stores the event and tells the webhooks about it in the same
+// transaction.
+func (p *EventRepository) CreateEvent(ctx context.Context, req *eventModel.CreateEventRequest, currentUser middleware.AuthClaims) (_ *eventModel.Event, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.events")
+
+	projectID, _ := strconv.ParseInt(req.ProjectID, 10, 64)
 
 	now := time.Now()
 	data := &eventModel.Event{
@@ -64,24 +111,46 @@ func (p *EventRepository) CreateEvent(ctx context.Context, req *eventModel.Creat
 		StartDate:    req.StartDate,
 		EndDate:      req.EndDate,
 		CreatedById:  currentUser.UserID,
-		ProjectID:    currentUser.ProjectID,
+		ProjectID:    projectID,
 		CreatedAt:    &now,
 	}
 
-	if err := query.Omit("event_id").Create(data).Error; err != nil {
-		return err
+	if err = query.Omit("event_id").Create(data).Error; err != nil {
+		return nil, err
+	}
+	if err = recordOptionFields(tx, projectID, data); err != nil {
+		return nil, err
+	}
+	if err = webhookDb.Enqueue(tx, data.ProjectID, data.EventID, constant.WebhookEventEventCreated, data); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
 	}
 
-	return nil
+	return data, nil
 }
 
-func (p *EventRepository) UpdateEvent(ctx context.Context, req *eventModel.UpdateEventRequest, currentUser middleware.AuthClaims) error {
+// UpdateEvent stores the changes of the event and tells the webhooks about
+// them in the same transaction.
+func (p *EventRepository) UpdateEvent(ctx context.Context, req *eventModel.UpdateEventRequest, currentUser middleware.AuthClaims) (err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
-	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.events")
 
-	query = query.Where("event_id = ?", req.EventID)
+	query = query.Where("project_id = ? and event_id = ? and deleted_at IS NULL", req.ProjectID, req.EventID)
 
 	now := time.Now()
 	data := &eventModel.Event{
@@ -91,7 +160,6 @@ func (p *EventRepository) UpdateEvent(ctx context.Context, req *eventModel.Updat
 		GuestOptions: req.GuestOptions,
 		StartDate:    req.StartDate,
 		EndDate:      req.EndDate,
-		ProjectID:    currentUser.ProjectID,
 		UpdatedAt:    &now,
 		UpdatedById:  currentUser.UserID,
 	}
@@ -104,18 +172,26 @@ func (p *EventRepository) UpdateEvent(ctx context.Context, req *eventModel.Updat
 		return gorm.ErrRecordNotFound
 	}
 
-	return nil
+	projectID, _ := strconv.ParseInt(req.ProjectID, 10, 64)
+	if err = recordOptionFields(tx, projectID, data); err != nil {
+		return err
+	}
+	if err = publishEvent(tx, req.EventID, constant.WebhookEventEventUpdated); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
-func (p *EventRepository) GetEventByID(ctx context.Context, eventID string, currentUser middleware.AuthClaims) (data *eventModel.Event, err error) {
+func (p *EventRepository) GetEventByID(ctx context.Context, projectID string, eventID string) (data *eventModel.Event, err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
 
-	query = query.Where("project_id = ? and event_id = ?", currentUser.ProjectID, eventID)
+	query = query.Where("project_id = ? and event_id = ? and deleted_at IS NULL", projectID, eventID)
 
-	if err := query.Debug().First(&data).Error; err != nil {
+	if err := query.Debug().Find(&data).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
@@ -125,15 +201,28 @@ func (p *EventRepository) GetEventByID(ctx context.Context, eventID string, curr
 
 }
 
-func (p *EventRepository) DeleteEventByID(ctx context.Context, eventID string, currentUser middleware.AuthClaims) error {
+// DeleteEventByID soft deletes the event and its guests, see
+// ProjectRepository.DeleteProject, and tells the webhooks.
+func (p *EventRepository) DeleteEventByID(ctx context.Context, projectID string, eventID string, currentUser middleware.AuthClaims) (err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
-	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	query = query.Where("project_id = ? and event_id = ?", currentUser.ProjectID, eventID)
+	deleted := map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"deleted_by_id": currentUser.UserID,
+	}
 
-	res := query.Delete(&eventModel.Event{})
+	res := tx.Debug().Table("public.events").Where("project_id = ? and event_id = ? and deleted_at IS NULL", projectID, eventID).Updates(deleted)
 	if res.Error != nil {
 		return res.Error
 	}
@@ -141,6 +230,120 @@ func (p *EventRepository) DeleteEventByID(ctx context.Context, eventID string, c
 		return gorm.ErrRecordNotFound
 	}
 
-	return nil
+	if err := tx.Debug().Table("public.guests").Where("project_id = ? and event_id = ? and deleted_at IS NULL", projectID, eventID).Updates(deleted).Error; err != nil {
+		return err
+	}
+
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil {
+		return err
+	}
+	eid, err := strconv.ParseInt(eventID, 10, 64)
+	if err != nil {
+		return err
+	}
+	err = jobDb.Enqueue(tx, constant.JobKindCancelDeliveries, &jobModel.CancelDeliveries{
+		ProjectID: pid,
+		EventID:   eid,
+		Reason:    "event deleted",
+	})
+	if err != nil {
+		return err
+	}
+	if err = publishEvent(tx, eventID, constant.WebhookEventEventDeleted); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RestoreEvent brings back a deleted event and the guests that were deleted
+// with it, and tells the webhooks. An event of a deleted project has to wait
+// for the project.
+func (p *EventRepository) RestoreEvent(ctx context.Context, projectID string, eventID string) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var deletedProjects int64
+	if err := tx.Debug().Table("public.projects").Where("project_id = ? and deleted_at IS NOT NULL", projectID).Count(&deletedProjects).Error; err != nil {
+		return err
+	}
+	if deletedProjects > 0 {
+		return ErrProjectDeleted
+	}
+
+	restored := map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": nil,
+	}
+
+	err = tx.Debug().Table("public.guests").
+		Where("project_id = ? and event_id = ? and deleted_at = (SELECT deleted_at FROM public.events WHERE event_id = ?)", projectID, eventID, eventID).
+		Updates(restored).Error
+	if err != nil {
+		return err
+	}
+
+	res := tx.Debug().Table("public.events").Where("project_id = ? and event_id = ? and deleted_at IS NOT NULL", projectID, eventID).Updates(restored)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err = publishEvent(tx, eventID, constant.WebhookEventEventRestored); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// recordOptionFields adds the paths used inside the event_options and
+// guest_options of event to the paths list filters accept for the project.
+func recordOptionFields(tx *gorm.DB, projectID int64, event *eventModel.Event) error {
+	if err := db.RecordJSONFields(tx, projectID, 0, "event_options", event.EventOptions); err != nil {
+		return err
+	}
+	return db.RecordJSONFields(tx, projectID, 0, "guest_options", event.GuestOptions)
+}
+
+// publishEvent tells the webhooks about a change of eventType to the event,
+// as the event is in tx.
+func publishEvent(tx *gorm.DB, eventID string, eventType string) error {
+	var event eventModel.Event
+	if err := tx.Debug().Table("public.events").Where("event_id = ?", eventID).Take(&event).Error; err != nil {
+		return err
+	}
+
+	return webhookDb.Enqueue(tx, event.ProjectID, event.EventID, eventType, &event)
+}
+
+// PurgeEvents permanently removes events deleted before the given time, at
+// most limit rows. Their guests go with them through the foreign key.
+func (p *EventRepository) PurgeEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	query = query.Where("event_id IN (?)", p.provider.GetDB().Table("public.events").
+		Select("event_id").Where("deleted_at < ?", before).Limit(limit))
+
+	res := query.Delete(&eventModel.Event{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
 
+	return res.RowsAffected, nil
 }
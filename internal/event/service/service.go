@@ -2,19 +2,23 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
 	eventModel "rawuh-service/internal/event/model"
 	eventDb "rawuh-service/internal/event/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/cache"
 	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/filter"
+	"rawuh-service/internal/shared/guestschema"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
 	"rawuh-service/internal/shared/middleware"
-	"rawuh-service/internal/shared/model"
 	"strconv"
 	"strings"
 
@@ -28,6 +32,7 @@ type EventService interface {
 	ListEvent(ctx context.Context, req *eventModel.ListEventRequest) (*eventModel.ListEventResponse, error)
 	DetailEvent(ctx context.Context, req *eventModel.DetailEventRequest) (*eventModel.DetailEventResponse, error)
 	DeleteEvent(ctx context.Context, req *eventModel.DeleteEventRequest) error
+	RestoreEvent(ctx context.Context, req *eventModel.RestoreEventRequest) (*eventModel.RestoreEventResponse, error)
 	AddEvent(ctx context.Context, req *eventModel.CreateEventRequest) error
 	UpdateEvent(ctx context.Context, req *eventModel.UpdateEventRequest) error
 }
@@ -35,17 +40,31 @@ type EventService interface {
 type eventService struct {
 	dbProvider *eventDb.EventRepository
 	logger     *logger.Logger
-	// redis      *redis.Redis
+	audit      auditService.AuditService
+	cache      *cache.Cache
 }
 
-func NewEventService(dbProvider *eventDb.EventRepository, logger *logger.Logger) EventService {
+func NewEventService(dbProvider *eventDb.EventRepository, audit auditService.AuditService, cache *cache.Cache, logger *logger.Logger) EventService {
 	return &eventService{
 		dbProvider: dbProvider,
 		logger:     logger,
-		// redis:      redis,
+		audit:      audit,
+		cache:      cache,
 	}
 }
 
+// eventColumns are the columns ListEvent can filter and sort on, next to the
+// event_options and guest_options paths used by the project's events.
+var eventColumns = filter.Columns{
+	"event_id":    {Type: filter.Int},
+	"event_name":  {Type: filter.String},
+	"description": {Type: filter.String},
+	"start_date":  {Type: filter.Time},
+	"end_date":    {Type: filter.Time},
+	"created_at":  {Type: filter.Time},
+	"updated_at":  {Type: filter.Time},
+}
+
 func (s *eventService) ListEvent(ctx context.Context, req *eventModel.ListEventRequest) (*eventModel.ListEventResponse, error) {
 	funcName := "ListEvent"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
@@ -59,73 +78,79 @@ func (s *eventService) ListEvent(ctx context.Context, req *eventModel.ListEventR
 		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	loggerZap.Info("Start ListEvent with req : ", req)
-	loggerZap.Info("Start Decode Filter")
-
-	decodeQuery, err := base64.RawStdEncoding.DecodeString(req.Query)
-	if err != nil {
-		loggerZap.Error("err DecodeString ", err)
-		return nil, nil
+	// roles narrowed to some events only list those events
+	eventIDs, all := authz.EventIDs(currentUser, authz.EventRead, req.ProjectID)
+	if !all && len(eventIDs) == 0 {
+		loggerZap.Error("err Check permission denied", nil)
+		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
 	}
 
-	loggerZap.Info("Success Decode Query")
+	loggerZap.Info("Start ListEvent with req : ", req)
 
 	pagination := utils.SetPagination(req.Page, req.Limit)
-
-	allowedColumns := map[string]bool{
-		"event_name": true,
-		"event_id":   true,
-		"created_at": true,
-		"updated_at": true,
-		"start_date": true,
-		"end_date":   true,
-	}
-
-	allowedDirections := map[string]bool{
-		"asc":  true,
-		"desc": true,
-	}
-
-	column := strings.ToLower(req.Sort)
-	direction := strings.ToLower(req.Dir)
-
-	if column != "" || direction != "" {
-
-		if !allowedColumns[column] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	scopes := []string{cache.ProjectScope(req.ProjectID), cache.EventsScope(req.ProjectID)}
+	listRequest := struct {
+		List     interface{}
+		EventIDs []int64
+	}{cache.ListRequest(req.Filter, req.Sort, req.Dir, pagination), eventIDs}
+
+	return cache.Fetch(ctx, s.cache, "events", scopes, listRequest, func() (*eventModel.ListEventResponse, error) {
+		loggerZap.Info("Start Parse Filter")
+
+		columns := eventColumns.WithJSON(func() (map[string]map[string]filter.Type, error) {
+			return s.dbProvider.ListOptionFields(ctx, req.ProjectID)
+		}, "event_options", "guest_options")
+
+		filters, err := filter.Parse(req.Filter, columns)
+		if err != nil {
+			loggerZap.Error("err Parse filter ", err)
+			if !errors.Is(err, filter.ErrInvalid) {
+				return nil, status.Error(codes.Internal, "Internal Server Error")
+			}
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
-		if !allowedDirections[direction] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+
+		sort, err := columns.Sort(req.Sort, req.Dir)
+		if err != nil {
+			loggerZap.Error("err Sort ", err)
+			if !errors.Is(err, filter.ErrInvalid) {
+				return nil, status.Error(codes.Internal, "Internal Server Error")
+			}
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
-	}
-	sort := &model.Sort{
-		Column:    column,
-		Direction: direction,
-	}
 
-	sqlBuilder := &db.QueryBuilder{
-		CollectiveAnd: string(decodeQuery),
-		Sort:          sort,
-	}
+		sqlBuilder := &db.QueryBuilder{
+			Filter: filters,
+			Sort:   sort,
+		}
 
-	loggerZap.Info("Start ListEvent")
-	guest, err := s.dbProvider.ListEvent(ctx, currentUser, pagination, sqlBuilder, sort)
-	if err != nil {
-		loggerZap.Error("err ListEvent ", err)
-		return nil, status.Error(codes.Internal, "Internal Server Error")
-	}
+		loggerZap.Info("Start ListEvent")
+		guest, err := s.dbProvider.ListEvent(ctx, req.ProjectID, eventIDs, pagination, sqlBuilder, sort)
+		if err != nil {
+			if errors.Is(err, db.ErrInvalidCursor) {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			loggerZap.Error("err ListEvent ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
 
-	loggerZap.Info("Start making response")
+		loggerZap.Info("Start making response")
 
-	result := &eventModel.ListEventResponse{
-		Error:      false,
-		Code:       http.StatusOK,
-		Message:    "Success",
-		Data:       guest,
-		Pagination: pagination,
-	}
+		result := &eventModel.ListEventResponse{
+			Error:      false,
+			Code:       http.StatusOK,
+			Message:    "Success",
+			Data:       guest,
+			Pagination: pagination,
+		}
 
-	return result, nil
+		return result, nil
+	})
 }
 
 func (s *eventService) DetailEvent(ctx context.Context, req *eventModel.DetailEventRequest) (*eventModel.DetailEventResponse, error) {
@@ -148,41 +173,37 @@ func (s *eventService) DetailEvent(ctx context.Context, req *eventModel.DetailEv
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return nil, status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.EventRead, req.ProjectID, req.EventsID); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
-	loggerZap.Info("Start ListEvent")
-	event, err := s.dbProvider.GetEventByID(ctx, req.EventsID, currentUser)
-	if err != nil {
-		loggerZap.Error("err ListEvent ", err)
-		return nil, status.Error(codes.Internal, "Internal Server Error")
-	}
+	scopes := []string{cache.ProjectScope(req.ProjectID), cache.EventScope(req.ProjectID, req.EventsID)}
 
-	if event == nil || event.EventID == 0 {
-		loggerZap.Info("event not found", nil)
-		return nil, status.Errorf(codes.NotFound, "event not found")
-	}
+	return cache.Fetch(ctx, s.cache, "event", scopes, req, func() (*eventModel.DetailEventResponse, error) {
+		loggerZap.Info("Start ListEvent")
+		event, err := s.dbProvider.GetEventByID(ctx, req.ProjectID, req.EventsID)
+		if err != nil {
+			loggerZap.Error("err ListEvent ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
 
-	loggerZap.Info("Start making response")
+		if event == nil || event.EventID == 0 {
+			loggerZap.Info("event not found", nil)
+			return nil, status.Errorf(codes.NotFound, "event not found")
+		}
 
-	result := &eventModel.DetailEventResponse{
-		Error:   false,
-		Code:    http.StatusOK,
-		Message: "Success",
-		Data:    event,
-	}
+		loggerZap.Info("Start making response")
 
-	return result, nil
+		result := &eventModel.DetailEventResponse{
+			Error:   false,
+			Code:    http.StatusOK,
+			Message: "Success",
+			Data:    event,
+		}
+
+		return result, nil
+	})
 }
 
 func (s *eventService) DeleteEvent(ctx context.Context, req *eventModel.DeleteEventRequest) error {
@@ -206,21 +227,19 @@ func (s *eventService) DeleteEvent(ctx context.Context, req *eventModel.DeleteEv
 		return status.Errorf(codes.PermissionDenied, "Permission Denied")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.EventDelete, req.ProjectID, req.EventsID); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
+	}
+
+	before, err := s.dbProvider.GetEventByID(ctx, req.ProjectID, req.EventsID)
+	if err != nil {
+		loggerZap.Error("err GetEventByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
 	loggerZap.Info("Start ListEvent")
-	err := s.dbProvider.DeleteEventByID(ctx, req.EventsID, currentUser)
+	err = s.dbProvider.DeleteEventByID(ctx, req.ProjectID, req.EventsID, currentUser)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			loggerZap.Warn("event not found", err)
@@ -231,9 +250,88 @@ func (s *eventService) DeleteEvent(ctx context.Context, req *eventModel.DeleteEv
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	s.invalidate(ctx, req.ProjectID, req.EventsID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityEvent,
+		EntityID:   req.EventsID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventsID,
+		Before:     before,
+	})
+
 	return nil
 }
 
+// RestoreEvent undoes DeleteEvent, including the guests that were deleted
+// with the event.
+func (s *eventService) RestoreEvent(ctx context.Context, req *eventModel.RestoreEventRequest) (*eventModel.RestoreEventResponse, error) {
+	funcName := "RestoreEvent"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.EventsID == "" {
+		loggerZap.Error("err Invalid event id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Event Id")
+	}
+
+	if err := authz.Check(currentUser, authz.EventDelete, req.ProjectID, req.EventsID); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	loggerZap.Info("Start RestoreEvent")
+	err := s.dbProvider.RestoreEvent(ctx, req.ProjectID, req.EventsID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("deleted event not found", err)
+			return nil, status.Error(codes.NotFound, "Deleted event not found")
+		}
+		if errors.Is(err, eventDb.ErrProjectDeleted) {
+			loggerZap.Warn("project of event is deleted", err)
+			return nil, status.Error(codes.FailedPrecondition, "Project is deleted, restore the project first")
+		}
+
+		loggerZap.Error("err RestoreEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	event, err := s.dbProvider.GetEventByID(ctx, req.ProjectID, req.EventsID)
+	if err != nil {
+		loggerZap.Error("err GetEventByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.invalidate(ctx, req.ProjectID, req.EventsID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionRestore,
+		EntityType: constant.AuditEntityEvent,
+		EntityID:   req.EventsID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventsID,
+		After:      event,
+	})
+
+	loggerZap.Info("Success RestoreEvent")
+
+	result := &eventModel.RestoreEventResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    event,
+	}
+
+	return result, nil
+}
+
 func (s *eventService) AddEvent(ctx context.Context, req *eventModel.CreateEventRequest) error {
 	funcName := "AddEvent"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
@@ -247,17 +345,9 @@ func (s *eventService) AddEvent(ctx context.Context, req *eventModel.CreateEvent
 		return status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.EventCreate, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	remarkLength, _ := strconv.Atoi(utils.GetEnv("EVENT_REMARK_LENGTH", "500"))
@@ -312,13 +402,28 @@ func (s *eventService) AddEvent(ctx context.Context, req *eventModel.CreateEvent
 	} else {
 		req.GuestOptions = "{}"
 	}
+	if _, err := guestschema.FromOptions(req.GuestOptions); err != nil {
+		loggerZap.Warn("err FromOptions ", err)
+		return status.Errorf(codes.InvalidArgument, "invalid guest field schema: %v", err)
+	}
 
-	err := s.dbProvider.CreateEvent(ctx, req, currentUser)
+	event, err := s.dbProvider.CreateEvent(ctx, req, currentUser)
 	if err != nil {
 		loggerZap.Error("err AddEvent ", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	eventID := strconv.FormatInt(event.EventID, 10)
+	s.invalidate(ctx, req.ProjectID, eventID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCreate,
+		EntityType: constant.AuditEntityEvent,
+		EntityID:   eventID,
+		ProjectID:  req.ProjectID,
+		EventID:    eventID,
+		After:      event,
+	})
+
 	loggerZap.Info("Success AddEvent")
 
 	return nil
@@ -337,17 +442,9 @@ func (s *eventService) UpdateEvent(ctx context.Context, req *eventModel.UpdateEv
 		return status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventID); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	remarkLength, _ := strconv.Atoi(utils.GetEnv("EVENT_REMARK_LENGTH", "500"))
@@ -401,14 +498,47 @@ func (s *eventService) UpdateEvent(ctx context.Context, req *eventModel.UpdateEv
 	} else {
 		req.GuestOptions = "{}"
 	}
+	if _, err := guestschema.FromOptions(req.GuestOptions); err != nil {
+		loggerZap.Warn("err FromOptions ", err)
+		return status.Errorf(codes.InvalidArgument, "invalid guest field schema: %v", err)
+	}
 
-	err := s.dbProvider.UpdateEvent(ctx, req, currentUser)
+	before, err := s.dbProvider.GetEventByID(ctx, req.ProjectID, req.EventID)
+	if err != nil {
+		loggerZap.Error("err GetEventByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	err = s.dbProvider.UpdateEvent(ctx, req, currentUser)
 	if err != nil {
 		loggerZap.Error("err UpdateEvent ", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	after, err := s.dbProvider.GetEventByID(ctx, req.ProjectID, req.EventID)
+	if err != nil {
+		loggerZap.Error("err GetEventByID ", err)
+	}
+	s.invalidate(ctx, req.ProjectID, req.EventID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUpdate,
+		EntityType: constant.AuditEntityEvent,
+		EntityID:   req.EventID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventID,
+		Before:     before,
+		After:      after,
+	})
+
 	loggerZap.Info("Success UpdateEvent")
 
 	return nil
 }
+
+// invalidate drops the cached event lists of a project and the cached event
+// after a change.
+func (s *eventService) invalidate(ctx context.Context, projectID string, eventID string) {
+	if err := s.cache.Invalidate(ctx, cache.EventsScope(projectID), cache.EventScope(projectID, eventID)); err != nil {
+		s.logger.Error("err Invalidate cache ", err)
+	}
+}
@@ -3,7 +3,9 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -30,6 +32,7 @@ func NewGuestHandler(svc guestService.GuestService) *GuestHandler {
 // @Accept json
 // @Produce json
 // @Param body body guestModel.CreateGuestRequest true "CreateGuestRequest"
+// @Param check_duplicates query bool false "also return the guests of the event that look like the same person"
 // @Success 200 {object} guestModel.CreateGuestResponse
 // @Failure 400 {object} utils.APIErrorResponse
 // @Router /{project_id}/events/{event_id}/guests [post]
@@ -37,11 +40,7 @@ func NewGuestHandler(svc guestService.GuestService) *GuestHandler {
 func (h *GuestHandler) AddGuest(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	result := &guestModel.CreateGuestResponse{
-		Error:   false,
-		Code:    http.StatusOK,
-		Message: "Success create new guest",
-	}
+	result := &guestModel.CreateGuestResponse{}
 
 	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
 		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
@@ -68,14 +67,15 @@ func (h *GuestHandler) AddGuest(w http.ResponseWriter, r *http.Request) {
 		EventId:   mux.Vars(r)["event_id"],
 		ProjectID: mux.Vars(r)["project_id"],
 	}
-	if err := h.svc.AddGuest(ctx, req); err != nil {
+	req.CheckDuplicates, _ = strconv.ParseBool(r.URL.Query().Get("check_duplicates"))
+
+	result, err := h.svc.AddGuest(ctx, req)
+	if err != nil {
 		utils.HandleGrpcError(w, err)
 		return
 
 	}
 
-	result.Error = false
-	result.Code = http.StatusOK
 	w.Header().Add("content-type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
@@ -141,12 +141,16 @@ func (h *GuestHandler) UpdateGuestByID(w http.ResponseWriter, r *http.Request) {
 
 // ListGuests godoc
 // @Summary List guests
-// @Description Get list of guests for an event
+// @Description Get list of guests for an event. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.
 // @Tags guest
 // @Accept json
 // @Produce json
 // @Param page query int false "page"
 // @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param sort query string false "sort column, any filterable column"
+// @Param dir query string false "asc or desc"
 // @Success 200 {object} guestModel.ListGuestResponse
 // @Failure 400 {object} utils.APIErrorResponse
 // @Router /{project_id}/events/{event_id}/guests/list [get]
@@ -180,7 +184,9 @@ func (h *GuestHandler) ListGuests(w http.ResponseWriter, r *http.Request) {
 		Limit:     int32(limit),
 		Sort:      queryParams.Get("sort"),
 		Dir:       queryParams.Get("dir"),
-		Query:     queryParams.Get("query"),
+		Filter:    queryParams,
+		Cursor:    utils.QueryCursor(queryParams),
+		Count:     queryParams.Get("count"),
 		EventId:   mux.Vars(r)["event_id"],
 		ProjectID: mux.Vars(r)["project_id"],
 	}
@@ -281,3 +287,510 @@ func (h *GuestHandler) DeleteGuestByID(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
 }
+
+// RestoreGuest godoc
+// @Summary Restore a deleted guest
+// @Description Restore a deleted guest. The event must not be deleted.
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param guest_id path string true "guest id"
+// @Success 200 {object} guestModel.RestoreGuestResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 412 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/{guest_id}/restore [post]
+
+func (h *GuestHandler) RestoreGuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &guestModel.RestoreGuestRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		GuestID:   mux.Vars(r)["guest_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	guest, err := h.svc.RestoreGuest(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(guest)
+}
+
+// CheckInGuest godoc
+// @Summary Check in a guest
+// @Description Mark a guest as arrived, recording the usher and the number of companions
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param guest_id path string true "guest id"
+// @Param body body guestModel.CheckInGuestRequest false "CheckInGuestRequest"
+// @Success 200 {object} guestModel.CheckInGuestResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 409 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/{guest_id}/checkin [post]
+
+func (h *GuestHandler) CheckInGuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &guestModel.CheckInGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	// the body is optional, a guest without companions can be checked in with an empty request
+	var p guestModel.CheckInGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &guestModel.CheckInGuestRequest{
+		EventId:        mux.Vars(r)["event_id"],
+		GuestID:        mux.Vars(r)["guest_id"],
+		ProjectID:      mux.Vars(r)["project_id"],
+		CompanionCount: p.CompanionCount,
+	}
+
+	guest, err := h.svc.CheckInGuest(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(guest)
+}
+
+// UndoCheckInGuest godoc
+// @Summary Undo a guest check-in
+// @Description Clear the attendance state of a checked in guest
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param guest_id path string true "guest id"
+// @Success 200 {object} guestModel.UndoCheckInGuestResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 412 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/{guest_id}/checkin [delete]
+
+func (h *GuestHandler) UndoCheckInGuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &guestModel.UndoCheckInGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("Success Undo Check In Guest with id %s", mux.Vars(r)["guest_id"]),
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &guestModel.UndoCheckInGuestRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		GuestID:   mux.Vars(r)["guest_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	if err := h.svc.UndoCheckInGuest(ctx, req); err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetGuestQRCode godoc
+// @Summary Get guest QR code
+// @Description Render the signed door-scanning token of a guest as a QR image
+// @Tags guest
+// @Produce png
+// @Produce image/svg+xml
+// @Param guest_id path string true "guest id"
+// @Param format query string false "png (default) or svg"
+// @Param size query int false "image size in pixels"
+// @Success 200 {file} file
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/{guest_id}/qr [get]
+
+func (h *GuestHandler) GetGuestQRCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	format := queryParams.Get("format")
+	if format == "" {
+		format = "png"
+	}
+	size, _ := strconv.Atoi(queryParams.Get("size"))
+
+	req := &guestModel.GetGuestQRCodeRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		GuestID:   mux.Vars(r)["guest_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+		Format:    format,
+		Size:      size,
+	}
+
+	code, err := h.svc.GetGuestQRCode(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", code.ContentType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(code.Image)
+}
+
+// ScanGuest godoc
+// @Summary Scan a guest QR code
+// @Description Resolve a scanned guest token and check the guest in
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param body body guestModel.ScanGuestRequest true "ScanGuestRequest"
+// @Success 200 {object} guestModel.CheckInGuestResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 409 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/scan [post]
+
+func (h *GuestHandler) ScanGuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &guestModel.CheckInGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p guestModel.ScanGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &guestModel.ScanGuestRequest{
+		EventId:        mux.Vars(r)["event_id"],
+		ProjectID:      mux.Vars(r)["project_id"],
+		Token:          p.Token,
+		CompanionCount: p.CompanionCount,
+	}
+
+	guest, err := h.svc.ScanGuest(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(guest)
+}
+
+// GetGuestInvitation godoc
+// @Summary Get guest invitation token
+// @Description Get the token and link the guest uses to RSVP without logging in
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param guest_id path string true "guest id"
+// @Success 200 {object} guestModel.GetGuestInvitationResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/{guest_id}/invitation [get]
+
+func (h *GuestHandler) GetGuestInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &guestModel.GetGuestInvitationRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		GuestID:   mux.Vars(r)["guest_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	invitation, err := h.svc.GetGuestInvitation(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitation)
+}
+
+// GetGuestSchema godoc
+// @Summary Get guest field schema
+// @Description Get the custom guest fields the event defines in GuestOptions, for rendering the guest form. GuestData of the event's guests is validated against these fields. Fields is empty when the event defines none.
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Success 200 {object} guestModel.GetGuestSchemaResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/schema [get]
+
+func (h *GuestHandler) GetGuestSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &guestModel.GetGuestSchemaRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	schema, err := h.svc.GetGuestSchema(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schema)
+}
+
+// ListGuestDuplicates godoc
+// @Summary List likely duplicate guests
+// @Description Group the guests of an event that look like the same person: the same phone number once normalized to E.164, the same email ignoring case, or similar names. Reasons lists what linked a group.
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Success 200 {object} guestModel.ListGuestDuplicatesResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/duplicates [get]
+
+func (h *GuestHandler) ListGuestDuplicates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &guestModel.ListGuestDuplicatesRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	groups, err := h.svc.ListGuestDuplicates(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(groups)
+}
+
+// MergeGuest godoc
+// @Summary Merge a duplicate into a guest
+// @Description Merge DuplicateGuestID into the guest and delete the duplicate. The guest keeps its own values except for the fields listed in Take and the ones it has empty; GuestData and EventData keep the keys of both. A check-in or RSVP answer of the duplicate is kept when the guest has none.
+// @Tags guest
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param guest_id path string true "id of the guest that is kept"
+// @Param body body guestModel.MergeGuestRequest true "MergeGuestRequest"
+// @Success 200 {object} guestModel.MergeGuestResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/{guest_id}/merge [post]
+
+func (h *GuestHandler) MergeGuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &guestModel.MergeGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p guestModel.MergeGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &guestModel.MergeGuestRequest{
+		EventId:          mux.Vars(r)["event_id"],
+		GuestID:          mux.Vars(r)["guest_id"],
+		ProjectID:        mux.Vars(r)["project_id"],
+		DuplicateGuestID: p.DuplicateGuestID,
+		Take:             p.Take,
+	}
+
+	merged, err := h.svc.MergeGuest(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(merged)
+}
+
+// ImportGuests godoc
+// @Summary Import guests from a spreadsheet
+// @Description Bulk create guests from a CSV or XLSX file. Columns name, address, phone and email map onto the guest, any other column is stored in GuestData. Nothing is written unless every row is valid.
+// @Tags guest
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX guest list"
+// @Param dry_run query bool false "validate only, do not write"
+// @Success 200 {object} guestModel.ImportGuestResponse
+// @Failure 400 {object} guestModel.ImportGuestResponse
+// @Router /{project_id}/events/{event_id}/guests/import [post]
+
+func (h *GuestHandler) ImportGuests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &guestModel.ImportGuestResponse{
+		Error: false,
+		Code:  http.StatusOK,
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	maxSize, _ := strconv.ParseInt(utils.GetEnv("GUEST_IMPORT_MAX_SIZE_MB", "10"), 10, 64)
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize<<20)
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument, upload the guest list in the file field"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+	defer file.Close()
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	req := &guestModel.ImportGuestRequest{
+		EventId:   mux.Vars(r)["event_id"],
+		ProjectID: mux.Vars(r)["project_id"],
+		FileName:  fileHeader.Filename,
+		File:      file,
+		DryRun:    dryRun,
+	}
+
+	imported, err := h.svc.ImportGuests(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(int(imported.Code))
+	json.NewEncoder(w).Encode(imported)
+}
+
+// ExportGuests godoc
+// @Summary Export guests
+// @Description Download every guest matching the filter[...] parameters of the list as CSV, XLSX or PDF. JSON keys of GuestData and EventData are exported as their own columns.
+// @Tags guest
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Param format query string false "csv (default), xlsx or pdf"
+// @Param sort query string false "sort column"
+// @Param dir query string false "asc or desc"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/guests/export [get]
+
+func (h *GuestHandler) ExportGuests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	req := &guestModel.ExportGuestRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		Format:    queryParams.Get("format"),
+		Sort:      queryParams.Get("sort"),
+		Dir:       queryParams.Get("dir"),
+		Filter:    queryParams,
+	}
+
+	export, err := h.svc.ExportGuests(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+
+	// headers are already sent, a failure halfway is only logged by the service
+	export.Write(w)
+}
@@ -12,6 +12,35 @@ type Guest struct {
 	CreatedAt *time.Time `gorm:"type:timestamp"`
 	UpdatedAt *time.Time `gorm:"type:timestamp"`
 	ProjectID int64      `gorm:"type:integer"`
-	EventData string     `gorm:"type:text"`
-	GuestData string     `gorm:"type:text"`
+	EventData string     `gorm:"type:jsonb"`
+	GuestData string     `gorm:"type:jsonb"`
+
+	CheckedInAt     *time.Time `gorm:"type:timestamp"`
+	CheckedInById   int64      `gorm:"type:bigint"`
+	CheckedInByName string     `gorm:"type:varchar(500)"`
+	CompanionCount  int32      `gorm:"type:integer"`
+
+	RsvpStatus    string     `gorm:"type:varchar(50)"`
+	RsvpAttendees int32      `gorm:"type:integer"`
+	RsvpAt        *time.Time `gorm:"type:timestamp"`
+
+	DeletedAt   *time.Time `gorm:"type:timestamp"`
+	DeletedById int64      `gorm:"type:bigint"`
+}
+
+// GuestMerge records a duplicate guest merged into another. The merged guest
+// is deleted; MergedGuest holds it as it was, GuestBefore the kept guest
+// before the merge.
+type GuestMerge struct {
+	GuestMergeID  int64      `gorm:"primaryKey;autoIncrement"`
+	ProjectID     int64      `gorm:"type:bigint"`
+	EventID       int64      `gorm:"type:bigint"`
+	GuestID       int64      `gorm:"type:bigint"`
+	MergedGuestID int64      `gorm:"type:bigint"`
+	TakenFields   string     `gorm:"type:jsonb"`
+	GuestBefore   string     `gorm:"type:jsonb"`
+	MergedGuest   string     `gorm:"type:jsonb"`
+	MergedByID    int64      `gorm:"type:bigint"`
+	MergedByName  string     `gorm:"type:varchar(500)"`
+	CreatedAt     *time.Time `gorm:"type:timestamp"`
 }
@@ -1,13 +1,21 @@
 package model
 
-import "rawuh-service/internal/shared/model"
+import (
+	"io"
+	"net/url"
+
+	"rawuh-service/internal/shared/guestschema"
+	"rawuh-service/internal/shared/model"
+)
 
 type ListGuestRequest struct {
-	Page      int32  `json:"page"`
-	Limit     int32  `json:"limit"`
-	Sort      string `json:"sort"`
-	Dir       string `json:"dir"`
-	Query     string `json:"query"`
+	Page      int32      `json:"page"`
+	Limit     int32      `json:"limit"`
+	Sort      string     `json:"sort"`
+	Dir       string     `json:"dir"`
+	Filter    url.Values `json:"filter"`
+	Cursor    *string    `json:"cursor"`
+	Count     string     `json:"count"`
 	EventId   string
 	ProjectID string
 }
@@ -21,20 +29,22 @@ type ListGuestResponse struct {
 }
 
 type CreateGuestRequest struct {
-	ProjectID string
-	Name      string
-	Address   string
-	Phone     string
-	Email     string
-	EventId   string
-	EventData string
-	GuestData string
+	ProjectID       string
+	Name            string
+	Address         string
+	Phone           string
+	Email           string
+	EventId         string
+	EventData       string
+	GuestData       string
+	CheckDuplicates bool `json:"-"`
 }
 
 type CreateGuestResponse struct {
-	Error   bool
-	Code    int32
-	Message string
+	Error      bool
+	Code       int32
+	Message    string
+	Duplicates []*GuestMatch `json:",omitempty"`
 }
 type UpdateGuestRequest struct {
 	ProjectID string
@@ -77,3 +87,174 @@ type DeleteGuestByIDResponse struct {
 	Code    int32
 	Message string
 }
+
+type RestoreGuestRequest struct {
+	ProjectID string
+	GuestID   string
+	EventId   string
+}
+
+type RestoreGuestResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Guest
+}
+
+type CheckInGuestRequest struct {
+	ProjectID      string
+	EventId        string
+	GuestID        string
+	CompanionCount int32
+}
+
+type CheckInGuestResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Guest
+}
+
+type UndoCheckInGuestRequest struct {
+	ProjectID string
+	EventId   string
+	GuestID   string
+}
+
+type UndoCheckInGuestResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+}
+
+type GetGuestQRCodeRequest struct {
+	ProjectID string
+	EventId   string
+	GuestID   string
+	Format    string
+	Size      int
+}
+
+type GetGuestQRCodeResponse struct {
+	Error       bool
+	Code        int32
+	Message     string
+	Token       string
+	ContentType string `json:"-"`
+	Image       []byte `json:"-"`
+}
+
+type ScanGuestRequest struct {
+	ProjectID      string
+	EventId        string
+	Token          string
+	CompanionCount int32
+}
+
+type GetGuestInvitationRequest struct {
+	ProjectID string
+	EventId   string
+	GuestID   string
+}
+
+type GetGuestInvitationResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Token   string
+	Url     string
+}
+
+type GetGuestSchemaRequest struct {
+	ProjectID string
+	EventId   string
+}
+
+type GetGuestSchemaResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *guestschema.Schema
+}
+
+type ImportGuestRequest struct {
+	ProjectID string
+	EventId   string
+	FileName  string
+	File      io.Reader `json:"-"`
+	DryRun    bool
+}
+
+type ImportGuestRowError struct {
+	Row     int
+	Message string
+}
+
+type ImportGuestResponse struct {
+	Error        bool
+	Code         int32
+	Message      string
+	DryRun       bool
+	TotalRows    int
+	ImportedRows int
+	Errors       []*ImportGuestRowError
+}
+
+type ExportGuestRequest struct {
+	ProjectID string
+	EventId   string
+	Format    string
+	Sort      string
+	Dir       string
+	Filter    url.Values
+}
+
+type ExportGuestResponse struct {
+	ContentType string
+	FileName    string
+	Write       func(w io.Writer) error
+}
+
+// GuestMatch is a guest that looks like the same person as another, and what
+// matched: phone, email and/or name.
+type GuestMatch struct {
+	Reasons []string
+	Guest   *Guest
+}
+
+// GuestDuplicateGroup is a set of guests that look like the same person.
+// Reasons lists what linked them.
+type GuestDuplicateGroup struct {
+	Reasons []string
+	Guests  []*Guest
+}
+
+type ListGuestDuplicatesRequest struct {
+	ProjectID string
+	EventId   string
+}
+
+type ListGuestDuplicatesResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    []*GuestDuplicateGroup
+}
+
+// MergeGuestRequest merges DuplicateGuestID into GuestID. Take lists the
+// fields whose value comes from the duplicate: Name, Address, Phone, Email,
+// GuestData.<key> or EventData.<key>.
+type MergeGuestRequest struct {
+	ProjectID        string
+	EventId          string
+	GuestID          string
+	DuplicateGuestID string
+	Take             []string
+}
+
+type MergeGuestResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Guest
+}
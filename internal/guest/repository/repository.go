@@ -7,11 +7,23 @@ import (
 	"time"
 
 	guestModel "rawuh-service/internal/guest/model"
+	jobModel "rawuh-service/internal/job/model"
+	jobDb "rawuh-service/internal/job/repository"
+	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/filter"
 	"rawuh-service/internal/shared/middleware"
 	model "rawuh-service/internal/shared/model"
+	webhookDb "rawuh-service/internal/webhook/repository"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrGuestAlreadyCheckedIn = errors.New("guest already checked in")
+	ErrGuestNotCheckedIn     = errors.New("guest not checked in")
+	ErrEventDeleted          = errors.New("event is deleted")
 )
 
 type GuestRepository struct {
@@ -24,11 +36,23 @@ func NewGuestRepository(provider *db.GormProvider) *GuestRepository {
 	}
 }
 
-func (p *GuestRepository) CreateGuest(ctx context.Context, req *guestModel.CreateGuestRequest, currentUser middleware.AuthClaims) error {
+// CreateGuest stores the guest and tells the webhooks about it in the same
+// transaction.
+func (p *GuestRepository) CreateGuest(ctx context.Context, req *guestModel.CreateGuestRequest, currentUser middleware.AuthClaims) (_ *guestModel.Guest, err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
-	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.guests")
 
 	eventInt, _ := strconv.ParseInt(req.EventId, 0, 64)
 	projectInt, _ := strconv.ParseInt(req.ProjectID, 0, 64)
@@ -46,20 +70,98 @@ func (p *GuestRepository) CreateGuest(ctx context.Context, req *guestModel.Creat
 		GuestData: req.GuestData,
 	}
 
-	if err := query.Omit("guest_id").Create(data).Error; err != nil {
+	if err = query.Omit("guest_id").Create(data).Error; err != nil {
+		return nil, err
+	}
+	if err = recordDataFields(tx, projectInt, eventInt, data); err != nil {
+		return nil, err
+	}
+	if err = webhookDb.Enqueue(tx, data.ProjectID, data.EventId, constant.WebhookEventGuestCreated, data); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// CreateGuests inserts all guests of the file fileName in one transaction,
+// either every row is stored or none is. The webhooks hear about the import
+// as a whole.
+func (p *GuestRepository) CreateGuests(ctx context.Context, reqs []*guestModel.CreateGuestRequest, fileName string, currentUser middleware.AuthClaims) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	data := make([]*guestModel.Guest, 0, len(reqs))
+	for _, req := range reqs {
+		eventInt, _ := strconv.ParseInt(req.EventId, 0, 64)
+		projectInt, _ := strconv.ParseInt(req.ProjectID, 0, 64)
+
+		data = append(data, &guestModel.Guest{
+			ProjectID: projectInt,
+			Name:      req.Name,
+			Address:   req.Address,
+			Phone:     req.Phone,
+			Email:     req.Email,
+			EventId:   eventInt,
+			CreatedAt: &now,
+			EventData: req.EventData,
+			GuestData: req.GuestData,
+		})
+	}
+
+	if err = tx.Debug().Table("public.guests").Omit("guest_id").CreateInBatches(data, 500).Error; err != nil {
 		return err
 	}
 
-	return nil
+	if len(data) > 0 {
+		if err = recordDataFields(tx, data[0].ProjectID, data[0].EventId, data...); err != nil {
+			return err
+		}
+		err = webhookDb.Enqueue(tx, data[0].ProjectID, data[0].EventId, constant.WebhookEventGuestImported, map[string]interface{}{
+			"FileName":     fileName,
+			"ImportedRows": len(data),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit().Error
 }
 
-func (p *GuestRepository) UpdateGuest(ctx context.Context, req *guestModel.UpdateGuestRequest, currentUser middleware.AuthClaims) error {
+// UpdateGuest stores the changes of the guest and tells the webhooks about
+// them in the same transaction.
+func (p *GuestRepository) UpdateGuest(ctx context.Context, req *guestModel.UpdateGuestRequest, currentUser middleware.AuthClaims) (err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
-	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	query = query.Where("project_id = ? and guest_id = ? and event_id = ?", req.ProjectID, req.GuestID, req.EventId)
+	query := tx.Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? and guest_id = ? and event_id = ? and deleted_at IS NULL", req.ProjectID, req.GuestID, req.EventId)
 
 	now := time.Now()
 	data := &guestModel.Guest{
@@ -80,7 +182,16 @@ func (p *GuestRepository) UpdateGuest(ctx context.Context, req *guestModel.Updat
 		return gorm.ErrRecordNotFound
 	}
 
-	return nil
+	projectInt, _ := strconv.ParseInt(req.ProjectID, 10, 64)
+	eventInt, _ := strconv.ParseInt(req.EventId, 10, 64)
+	if err = recordDataFields(tx, projectInt, eventInt, data); err != nil {
+		return err
+	}
+	if err = publishGuest(tx, req.GuestID, constant.WebhookEventGuestUpdated); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
 func (p *GuestRepository) GetGuestByID(ctx context.Context, req *guestModel.GetGuestByIDRequest) (*guestModel.Guest, error) {
@@ -92,7 +203,7 @@ func (p *GuestRepository) GetGuestByID(ctx context.Context, req *guestModel.GetG
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().
 		Table("public.guests")
 
-	query = query.Where("project_id = ? and guest_id = ? and event_id = ?", req.ProjectID, req.GuestID, req.EventId)
+	query = query.Where("project_id = ? and guest_id = ? and event_id = ? and deleted_at IS NULL", req.ProjectID, req.GuestID, req.EventId)
 
 	if err := query.Debug().Find(&data).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -104,25 +215,410 @@ func (p *GuestRepository) GetGuestByID(ctx context.Context, req *guestModel.GetG
 	return &data, nil
 }
 
-func (p *GuestRepository) DeleteGuestByID(ctx context.Context, req *guestModel.DeleteGuestByIDRequest) error {
+// DeleteGuestByID soft deletes the guest and enqueues a job cancelling what
+// was still to be sent to them, and one telling the webhooks.
+func (p *GuestRepository) DeleteGuestByID(ctx context.Context, req *guestModel.DeleteGuestByIDRequest, currentUser middleware.AuthClaims) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND guest_id = ? AND event_id = ? AND deleted_at IS NULL", req.ProjectID, req.GuestID, req.EventId)
+
+	res := query.Updates(map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"deleted_by_id": currentUser.UserID,
+	})
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	payload := &jobModel.CancelDeliveries{Reason: "guest deleted"}
+	if payload.ProjectID, err = strconv.ParseInt(req.ProjectID, 10, 64); err != nil {
+		return err
+	}
+	if payload.EventID, err = strconv.ParseInt(req.EventId, 10, 64); err != nil {
+		return err
+	}
+	if payload.GuestID, err = strconv.ParseInt(req.GuestID, 10, 64); err != nil {
+		return err
+	}
+	if err = jobDb.Enqueue(tx, constant.JobKindCancelDeliveries, payload); err != nil {
+		return err
+	}
+	if err = publishGuest(tx, req.GuestID, constant.WebhookEventGuestDeleted); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RestoreGuest brings back a deleted guest. A guest of a deleted event has to
+// wait for the event.
+func (p *GuestRepository) RestoreGuest(ctx context.Context, req *guestModel.RestoreGuestRequest) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var deletedEvents int64
+	err = tx.Debug().Table("public.events").
+		Where("project_id = ? AND event_id = ? AND deleted_at IS NOT NULL", req.ProjectID, req.EventId).
+		Count(&deletedEvents).Error
+	if err != nil {
+		return err
+	}
+	if deletedEvents > 0 {
+		return ErrEventDeleted
+	}
+
+	query := tx.Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND guest_id = ? AND event_id = ? AND deleted_at IS NOT NULL", req.ProjectID, req.GuestID, req.EventId)
+
+	res := query.Updates(map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": nil,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err = publishGuest(tx, req.GuestID, constant.WebhookEventGuestRestored); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PurgeGuests permanently removes guests deleted before the given time, at
+// most limit rows.
+func (p *GuestRepository) PurgeGuests(ctx context.Context, before time.Time, limit int) (int64, error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
 
-	query = query.Where("project_id = ? AND guest_id = ? AND event_id = ?", req.ProjectID, req.GuestID, req.EventId)
+	query = query.Where("guest_id IN (?)", p.provider.GetDB().Table("public.guests").
+		Select("guest_id").Where("deleted_at < ?", before).Limit(limit))
 
 	res := query.Delete(&guestModel.Guest{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
 
+// ListEventGuests returns every guest of an event, for duplicate detection.
+func (p *GuestRepository) ListEventGuests(ctx context.Context, projectID string, eventID string) ([]*guestModel.Guest, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID).Order("guest_id")
+
+	var data []*guestModel.Guest
+	if err := query.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MergeGuests locks the guest and its duplicate, asks merge for the kept
+// guest and the merge history built from them, stores the kept guest, deletes
+// the duplicate and records the merge, all in one transaction. Nothing can
+// write either guest in between, so a check-in or RSVP landing during the
+// merge is either merged or waits for it. What was still to be sent to the
+// duplicate is cancelled by a job, and another one tells the webhooks. It
+// returns the guest and the duplicate as they were before the merge. Either
+// guest missing or deleted fails with gorm.ErrRecordNotFound.
+func (p *GuestRepository) MergeGuests(ctx context.Context, req *guestModel.MergeGuestRequest, merge func(guest *guestModel.Guest, duplicate *guestModel.Guest) (*guestModel.Guest, *guestModel.GuestMerge, error), currentUser middleware.AuthClaims) (_ *guestModel.Guest, _ *guestModel.Guest, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return nil, nil, tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// locked in guest_id order so two merges of the same pair, either way
+	// round, cannot deadlock
+	var rows []*guestModel.Guest
+	err = tx.Debug().Table("public.guests").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("project_id = ? AND event_id = ? AND guest_id IN ? AND deleted_at IS NULL", req.ProjectID, req.EventId, []string{req.GuestID, req.DuplicateGuestID}).
+		Order("guest_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var guest, duplicate *guestModel.Guest
+	for _, row := range rows {
+		switch strconv.FormatInt(row.GuestID, 10) {
+		case req.GuestID:
+			guest = row
+		case req.DuplicateGuestID:
+			duplicate = row
+		}
+	}
+	if guest == nil || duplicate == nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	merged, history, err := merge(guest, duplicate)
+	if err != nil {
+		return nil, nil, err
+	}
+	duplicateID := duplicate.GuestID
+
+	now := time.Now()
+	res := tx.Debug().Table("public.guests").
+		Where("project_id = ? AND event_id = ? AND guest_id = ? AND deleted_at IS NULL", merged.ProjectID, merged.EventId, merged.GuestID).
+		Updates(map[string]interface{}{
+			"name":               merged.Name,
+			"address":            merged.Address,
+			"phone":              merged.Phone,
+			"email":              merged.Email,
+			"guest_data":         merged.GuestData,
+			"event_data":         merged.EventData,
+			"checked_in_at":      merged.CheckedInAt,
+			"checked_in_by_id":   merged.CheckedInById,
+			"checked_in_by_name": merged.CheckedInByName,
+			"companion_count":    merged.CompanionCount,
+			"rsvp_status":        merged.RsvpStatus,
+			"rsvp_attendees":     merged.RsvpAttendees,
+			"rsvp_at":            merged.RsvpAt,
+			"updated_at":         &now,
+		})
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	res = tx.Debug().Table("public.guests").
+		Where("project_id = ? AND event_id = ? AND guest_id = ? AND deleted_at IS NULL", merged.ProjectID, merged.EventId, duplicateID).
+		Updates(map[string]interface{}{
+			"deleted_at":    &now,
+			"deleted_by_id": currentUser.UserID,
+		})
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	if err = recordDataFields(tx, merged.ProjectID, merged.EventId, merged); err != nil {
+		return nil, nil, err
+	}
+
+	history.MergedByID = currentUser.UserID
+	history.MergedByName = currentUser.Name
+	history.CreatedAt = &now
+	if err = tx.Debug().Table("public.guest_merges").Omit("guest_merge_id").Create(history).Error; err != nil {
+		return nil, nil, err
+	}
+
+	err = jobDb.Enqueue(tx, constant.JobKindCancelDeliveries, &jobModel.CancelDeliveries{
+		ProjectID: merged.ProjectID,
+		EventID:   merged.EventId,
+		GuestID:   duplicateID,
+		Reason:    "guest merged",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var kept, deleted guestModel.Guest
+	if err = tx.Debug().Table("public.guests").Where("guest_id = ?", merged.GuestID).Take(&kept).Error; err != nil {
+		return nil, nil, err
+	}
+	if err = tx.Debug().Table("public.guests").Where("guest_id = ?", duplicateID).Take(&deleted).Error; err != nil {
+		return nil, nil, err
+	}
+	err = webhookDb.Enqueue(tx, merged.ProjectID, merged.EventId, constant.WebhookEventGuestMerged, map[string]interface{}{
+		"Guest":          &kept,
+		"DuplicateGuest": &deleted,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	return guest, duplicate, nil
+}
+
+// CheckInGuest marks the guest as arrived. The update only matches guests
+// that are not checked in yet so two ushers scanning the same guest cannot
+// both succeed.
+func (p *GuestRepository) CheckInGuest(ctx context.Context, req *guestModel.CheckInGuestRequest, currentUser middleware.AuthClaims) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND guest_id = ? AND event_id = ? AND checked_in_at IS NULL AND deleted_at IS NULL", req.ProjectID, req.GuestID, req.EventId)
+
+	now := time.Now()
+	res := query.Updates(map[string]interface{}{
+		"checked_in_at":      &now,
+		"checked_in_by_id":   currentUser.UserID,
+		"checked_in_by_name": currentUser.Name,
+		"companion_count":    req.CompanionCount,
+		"updated_at":         &now,
+	})
 	if res.Error != nil {
 		return res.Error
 	}
 
 	if res.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		guest, err := p.GetGuestByID(ctx, &guestModel.GetGuestByIDRequest{
+			ProjectID: req.ProjectID,
+			GuestID:   req.GuestID,
+			EventId:   req.EventId,
+		})
+		if err != nil {
+			return err
+		}
+		if guest.GuestID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return ErrGuestAlreadyCheckedIn
 	}
 
-	return nil
+	if err = publishGuest(tx, req.GuestID, constant.WebhookEventGuestCheckedIn); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (p *GuestRepository) UndoCheckInGuest(ctx context.Context, req *guestModel.UndoCheckInGuestRequest) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND guest_id = ? AND event_id = ? AND checked_in_at IS NOT NULL AND deleted_at IS NULL", req.ProjectID, req.GuestID, req.EventId)
+
+	now := time.Now()
+	res := query.Updates(map[string]interface{}{
+		"checked_in_at":      nil,
+		"checked_in_by_id":   0,
+		"checked_in_by_name": "",
+		"companion_count":    0,
+		"updated_at":         &now,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		guest, err := p.GetGuestByID(ctx, &guestModel.GetGuestByIDRequest{
+			ProjectID: req.ProjectID,
+			GuestID:   req.GuestID,
+			EventId:   req.EventId,
+		})
+		if err != nil {
+			return err
+		}
+		if guest.GuestID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return ErrGuestNotCheckedIn
+	}
+
+	if err = publishGuest(tx, req.GuestID, constant.WebhookEventGuestCheckInUndone); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// recordDataFields adds the paths used inside the guest_data and event_data
+// of guests of the event to the paths list filters accept.
+func recordDataFields(tx *gorm.DB, projectID int64, eventID int64, guests ...*guestModel.Guest) error {
+	guestData := make([]string, 0, len(guests))
+	eventData := make([]string, 0, len(guests))
+	for _, guest := range guests {
+		guestData = append(guestData, guest.GuestData)
+		eventData = append(eventData, guest.EventData)
+	}
+
+	if err := db.RecordJSONFields(tx, projectID, eventID, "guest_data", guestData...); err != nil {
+		return err
+	}
+	return db.RecordJSONFields(tx, projectID, eventID, "event_data", eventData...)
+}
+
+// publishGuest tells the webhooks about a change of eventType to the guest,
+// as the guest is in tx.
+func publishGuest(tx *gorm.DB, guestID string, eventType string) error {
+	var guest guestModel.Guest
+	if err := tx.Debug().Table("public.guests").Where("guest_id = ?", guestID).Take(&guest).Error; err != nil {
+		return err
+	}
+
+	return webhookDb.Enqueue(tx, guest.ProjectID, guest.EventId, eventType, &guest)
 }
 
 func (p *GuestRepository) ListGuests(ctx context.Context, req *guestModel.ListGuestRequest, pagination *model.PaginationResponse, sql *db.QueryBuilder, sort *model.Sort) (data []*guestModel.Guest, err error) {
@@ -131,18 +627,22 @@ func (p *GuestRepository) ListGuests(ctx context.Context, req *guestModel.ListGu
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
 
-	query = query.Where("project_id = ? AND event_id = ?", req.ProjectID, req.EventId)
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", req.ProjectID, req.EventId)
 
 	query = query.Scopes(
-		db.QueryScoop(sql.CollectiveAnd),
+		sql.Filter.Scope(),
 	)
 
+	if pagination.Cursor != nil {
+		return db.Keyset[guestModel.Guest](query, pagination, sort, "guest_id")
+	}
+
 	query = query.Scopes(db.Paginate(data, pagination, query))
 	query = query.Scopes(
 		db.Sort(sort),
 	)
 
-	if err := query.Debug().First(&data).Error; err != nil {
+	if err := query.Debug().Find(&data).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
@@ -150,3 +650,72 @@ func (p *GuestRepository) ListGuests(ctx context.Context, req *guestModel.ListGu
 
 	return data, nil
 }
+
+// GetGuestOptions returns the GuestOptions of an event, which hold its custom
+// guest field schema.
+func (p *GuestRepository) GetGuestOptions(ctx context.Context, projectID string, eventID string) (string, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	var event struct {
+		GuestOptions string
+	}
+	result := query.Select("guest_options").Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID).Limit(1).Find(&event)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+
+	return event.GuestOptions, nil
+}
+
+// ListDataFields returns the paths staff wrote inside guest_data and
+// event_data of the guests of an event.
+func (p *GuestRepository) ListDataFields(ctx context.Context, projectID string, eventID string) (map[string]map[string]filter.Type, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.json_fields")
+
+	query = query.Where("project_id = ? AND event_id = ?", projectID, eventID)
+
+	return db.JSONFields(query, "guest_data", "event_data")
+}
+
+// StreamGuests walks every guest matching the filter one row at a time so
+// exports never hold the whole result in memory.
+func (p *GuestRepository) StreamGuests(ctx context.Context, req *guestModel.ExportGuestRequest, sql *db.QueryBuilder, sort *model.Sort, fn func(guest *guestModel.Guest) error) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", req.ProjectID, req.EventId)
+
+	query = query.Scopes(
+		sql.Filter.Scope(),
+		db.Sort(sort),
+	)
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var guest guestModel.Guest
+		if err := query.ScanRows(rows, &guest); err != nil {
+			return err
+		}
+		if err := fn(&guest); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
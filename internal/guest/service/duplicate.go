@@ -0,0 +1,384 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	guestModel "rawuh-service/internal/guest/model"
+	"rawuh-service/internal/shared/lib/utils"
+)
+
+const (
+	matchPhone = "phone"
+	matchEmail = "email"
+	matchName  = "name"
+)
+
+// duplicateSettings returns the country code of phone numbers written
+// without one and how alike two names must be to match, between 0 and 1.
+func duplicateSettings() (string, float64) {
+	countryCode := utils.PhoneCountryCode()
+	similarity, err := strconv.ParseFloat(utils.GetEnv("GUEST_DUPLICATE_NAME_SIMILARITY", "0.85"), 64)
+	if err != nil || similarity <= 0 || similarity > 1 {
+		similarity = 0.85
+	}
+	return countryCode, similarity
+}
+
+// guestContact is what duplicate detection compares of a guest.
+type guestContact struct {
+	phone string
+	email string
+	name  []rune
+	// sortedName is name with its words sorted, so "santoso budi" matches
+	// "budi santoso"
+	sortedName []rune
+}
+
+func newGuestContact(name string, phone string, email string, countryCode string) *guestContact {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	normalized := strings.Join(words, " ")
+	sort.Strings(words)
+
+	return &guestContact{
+		phone:      utils.NormalizePhone(phone, countryCode),
+		email:      normalizeEmail(email),
+		name:       []rune(normalized),
+		sortedName: []rune(strings.Join(words, " ")),
+	}
+}
+
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return ""
+	}
+	return email
+}
+
+// matchReasons returns what a and b have in common: the same phone, the same
+// email and/or names at least similarity alike.
+func matchReasons(a *guestContact, b *guestContact, similarity float64) []string {
+	reasons := []string{}
+	if a.phone != "" && a.phone == b.phone {
+		reasons = append(reasons, matchPhone)
+	}
+	if a.email != "" && a.email == b.email {
+		reasons = append(reasons, matchEmail)
+	}
+	if similarNames(a, b, similarity) {
+		reasons = append(reasons, matchName)
+	}
+	return reasons
+}
+
+// similarNames reports whether the names of a and b, as written or with their
+// words sorted, start with the same letter and are at least similarity alike:
+// 1 minus their edit distance over the length of the longer name.
+func similarNames(a *guestContact, b *guestContact, similarity float64) bool {
+	if len(a.name) == 0 || len(b.name) == 0 {
+		return false
+	}
+	maxDistance := int((1 - similarity) * float64(max(len(a.name), len(b.name))))
+
+	if a.name[0] == b.name[0] && levenshtein(a.name, b.name, maxDistance) <= maxDistance {
+		return true
+	}
+	return a.sortedName[0] == b.sortedName[0] && levenshtein(a.sortedName, b.sortedName, maxDistance) <= maxDistance
+}
+
+// levenshtein returns the edit distance between a and b, or limit+1 as soon
+// as it is known to exceed limit. Only the cells within limit of the diagonal
+// are computed, the others cannot lead to a distance within limit.
+func levenshtein(a []rune, b []rune, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+
+	over := limit + 1
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = min(j, over)
+	}
+	for i := 1; i <= len(a); i++ {
+		lo, hi := max(1, i-limit), min(len(b), i+limit)
+		curr[lo-1] = over
+		if lo == 1 {
+			curr[0] = min(i, over)
+		}
+		rowMin := curr[lo-1]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost, over)
+			rowMin = min(rowMin, curr[j])
+		}
+		if hi < len(b) {
+			curr[hi+1] = over
+		}
+		if rowMin > limit {
+			return over
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// findDuplicateGroups groups guests that match each other, directly or through
+// another guest of the group. Guests without a match are left out.
+func findDuplicateGroups(guests []*guestModel.Guest, countryCode string, similarity float64) []*guestModel.GuestDuplicateGroup {
+	contacts := make([]*guestContact, len(guests))
+	for i, guest := range guests {
+		contacts[i] = newGuestContact(guest.Name, guest.Phone, guest.Email, countryCode)
+	}
+
+	parent := make([]int, len(guests))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	type link struct {
+		a, b   int
+		reason string
+	}
+	links := []link{}
+	connect := func(a int, b int, reason string) {
+		links = append(links, link{a, b, reason})
+		parent[root(a)] = root(b)
+	}
+
+	byPhone := map[string]int{}
+	byEmail := map[string]int{}
+	for i, contact := range contacts {
+		if contact.phone != "" {
+			if first, ok := byPhone[contact.phone]; ok {
+				connect(first, i, matchPhone)
+			} else {
+				byPhone[contact.phone] = i
+			}
+		}
+		if contact.email != "" {
+			if first, ok := byEmail[contact.email]; ok {
+				connect(first, i, matchEmail)
+			} else {
+				byEmail[contact.email] = i
+			}
+		}
+	}
+
+	// names are only compared with names that start with the same letter,
+	// as written or with their words sorted, and within those in order of
+	// length with names short enough to still be similar
+	byLetter := map[rune][]int{}
+	for i, contact := range contacts {
+		if len(contact.name) == 0 {
+			continue
+		}
+		byLetter[contact.name[0]] = append(byLetter[contact.name[0]], i)
+		if contact.sortedName[0] != contact.name[0] {
+			byLetter[contact.sortedName[0]] = append(byLetter[contact.sortedName[0]], i)
+		}
+	}
+	for _, bucket := range byLetter {
+		sort.SliceStable(bucket, func(x, y int) bool {
+			return len(contacts[bucket[x]].name) < len(contacts[bucket[y]].name)
+		})
+		for x, i := range bucket {
+			for _, j := range bucket[x+1:] {
+				longer := len(contacts[j].name)
+				if float64(longer-len(contacts[i].name)) > (1-similarity)*float64(longer) {
+					break
+				}
+				if similarNames(contacts[i], contacts[j], similarity) {
+					connect(i, j, matchName)
+				}
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	reasons := map[int]map[string]bool{}
+	for i := range guests {
+		members[root(i)] = append(members[root(i)], i)
+	}
+	for _, l := range links {
+		r := root(l.a)
+		if reasons[r] == nil {
+			reasons[r] = map[string]bool{}
+		}
+		reasons[r][l.reason] = true
+	}
+
+	groups := []*guestModel.GuestDuplicateGroup{}
+	for i := range guests {
+		// each group once, in the order of its first guest
+		group := members[root(i)]
+		if len(group) < 2 || group[0] != i {
+			continue
+		}
+
+		result := &guestModel.GuestDuplicateGroup{Reasons: sortedReasons(reasons[root(i)])}
+		for _, m := range group {
+			result.Guests = append(result.Guests, guests[m])
+		}
+		groups = append(groups, result)
+	}
+
+	return groups
+}
+
+// findMatches returns the guests that match contact.
+func findMatches(contact *guestContact, guests []*guestModel.Guest, countryCode string, similarity float64) []*guestModel.GuestMatch {
+	matches := []*guestModel.GuestMatch{}
+	for _, guest := range guests {
+		reasons := matchReasons(contact, newGuestContact(guest.Name, guest.Phone, guest.Email, countryCode), similarity)
+		if len(reasons) > 0 {
+			matches = append(matches, &guestModel.GuestMatch{Reasons: reasons, Guest: guest})
+		}
+	}
+	return matches
+}
+
+func sortedReasons(set map[string]bool) []string {
+	reasons := []string{}
+	for _, reason := range []string{matchPhone, matchEmail, matchName} {
+		if set[reason] {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
+
+// mergeGuest returns keep with the fields of take, and every empty field,
+// filled from duplicate. GuestData and EventData hold the keys of both, with
+// the values of keep unless taken. A check-in or RSVP answer of the duplicate
+// is kept when keep has none.
+func mergeGuest(keep *guestModel.Guest, duplicate *guestModel.Guest, take []string) (*guestModel.Guest, error) {
+	merged := *keep
+
+	fields := map[string]struct {
+		to   *string
+		from string
+	}{
+		"name":    {&merged.Name, duplicate.Name},
+		"address": {&merged.Address, duplicate.Address},
+		"phone":   {&merged.Phone, duplicate.Phone},
+		"email":   {&merged.Email, duplicate.Email},
+	}
+	for _, field := range fields {
+		if strings.TrimSpace(*field.to) == "" {
+			*field.to = field.from
+		}
+	}
+
+	guestData, err := mergeData(keep.GuestData, duplicate.GuestData)
+	if err != nil {
+		return nil, err
+	}
+	eventData, err := mergeData(keep.EventData, duplicate.EventData)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]*mergedData{"guestdata": guestData, "eventdata": eventData}
+
+	for _, name := range take {
+		if field, ok := fields[strings.ToLower(name)]; ok {
+			*field.to = field.from
+			continue
+		}
+
+		column, key, ok := strings.Cut(name, ".")
+		d, known := data[strings.ToLower(column)]
+		if !ok || !known {
+			return nil, fmt.Errorf("cannot take %q, use Name, Address, Phone, Email, GuestData.<key> or EventData.<key>", name)
+		}
+		value, ok := d.duplicate[key]
+		if !ok {
+			return nil, fmt.Errorf("cannot take %q, the duplicate guest has no such key", name)
+		}
+		d.merged[key] = value
+	}
+
+	rawGuestData, _ := json.Marshal(guestData.merged)
+	merged.GuestData = string(rawGuestData)
+	rawEventData, _ := json.Marshal(eventData.merged)
+	merged.EventData = string(rawEventData)
+
+	if merged.CheckedInAt == nil && duplicate.CheckedInAt != nil {
+		merged.CheckedInAt = duplicate.CheckedInAt
+		merged.CheckedInById = duplicate.CheckedInById
+		merged.CheckedInByName = duplicate.CheckedInByName
+		merged.CompanionCount = duplicate.CompanionCount
+	}
+	if merged.RsvpAt == nil && duplicate.RsvpAt != nil {
+		merged.RsvpStatus = duplicate.RsvpStatus
+		merged.RsvpAttendees = duplicate.RsvpAttendees
+		merged.RsvpAt = duplicate.RsvpAt
+	}
+
+	return &merged, nil
+}
+
+// mergedData is a GuestData or EventData object of the duplicate and its
+// merge with the one of the kept guest.
+type mergedData struct {
+	merged    map[string]interface{}
+	duplicate map[string]interface{}
+}
+
+// mergeData merges two GuestData or EventData objects. The result holds the
+// keys of both, with the values of keep where both have one.
+func mergeData(keep string, duplicate string) (*mergedData, error) {
+	kept, err := decodeData(keep)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeData(duplicate)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]interface{}{}
+	for k, v := range d {
+		merged[k] = v
+	}
+	for k, v := range kept {
+		merged[k] = v
+	}
+	return &mergedData{merged: merged, duplicate: d}, nil
+}
+
+func decodeData(raw string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	if strings.TrimSpace(raw) == "" {
+		return data, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, fmt.Errorf("invalid JSON format: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	guestModel "rawuh-service/internal/guest/model"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	exportFormatCSV  = "csv"
+	exportFormatXLSX = "xlsx"
+	exportFormatPDF  = "pdf"
+
+	exportTimeLayout = "2006-01-02 15:04:05"
+)
+
+var exportContentTypes = map[string]string{
+	exportFormatCSV:  "text/csv; charset=utf-8",
+	exportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	exportFormatPDF:  "application/pdf",
+}
+
+var guestExportBaseColumns = []string{
+	"guest_id",
+	"name",
+	"address",
+	"phone",
+	"email",
+	"created_at",
+	"checked_in_at",
+	"checked_in_by_name",
+	"companion_count",
+	"rsvp_status",
+	"rsvp_attendees",
+}
+
+// guestExportLayout holds the columns of an export. The JSON keys found in
+// GuestData and EventData become their own columns, prefixed with the field
+// they came from, and the widest value of each column is kept for the PDF.
+type guestExportLayout struct {
+	guestKeys map[string]bool
+	eventKeys map[string]bool
+	columns   []string
+	widths    map[string]int
+}
+
+func newGuestExportLayout() *guestExportLayout {
+	return &guestExportLayout{
+		guestKeys: map[string]bool{},
+		eventKeys: map[string]bool{},
+		widths:    map[string]int{},
+	}
+}
+
+// add records the columns and value widths of one guest.
+func (l *guestExportLayout) add(guest *guestModel.Guest) {
+	values := guestExportValues(guest)
+	for key := range values {
+		switch {
+		case strings.HasPrefix(key, "guest_data."):
+			l.guestKeys[key] = true
+		case strings.HasPrefix(key, "event_data."):
+			l.eventKeys[key] = true
+		}
+	}
+	for key, value := range values {
+		if width := utf8.RuneCountInString(value); width > l.widths[key] {
+			l.widths[key] = width
+		}
+	}
+}
+
+// finish fixes the column order: the guest columns first, then the sorted
+// GuestData and EventData keys.
+func (l *guestExportLayout) finish() {
+	guestKeys := make([]string, 0, len(l.guestKeys))
+	for key := range l.guestKeys {
+		guestKeys = append(guestKeys, key)
+	}
+	sort.Strings(guestKeys)
+
+	eventKeys := make([]string, 0, len(l.eventKeys))
+	for key := range l.eventKeys {
+		eventKeys = append(eventKeys, key)
+	}
+	sort.Strings(eventKeys)
+
+	l.columns = append(append(append([]string{}, guestExportBaseColumns...), guestKeys...), eventKeys...)
+}
+
+// row returns the cells of one guest in column order. Keys the layout does not
+// know about, added after it was built, are left out.
+func (l *guestExportLayout) row(guest *guestModel.Guest) []string {
+	values := guestExportValues(guest)
+	row := make([]string, len(l.columns))
+	for i, column := range l.columns {
+		row[i] = values[column]
+	}
+	return row
+}
+
+func guestExportValues(guest *guestModel.Guest) map[string]string {
+	values := map[string]string{
+		"guest_id":           strconv.FormatInt(guest.GuestID, 10),
+		"name":               guest.Name,
+		"address":            guest.Address,
+		"phone":              guest.Phone,
+		"email":              guest.Email,
+		"created_at":         formatExportTime(guest.CreatedAt),
+		"checked_in_at":      formatExportTime(guest.CheckedInAt),
+		"checked_in_by_name": guest.CheckedInByName,
+		"companion_count":    strconv.Itoa(int(guest.CompanionCount)),
+		"rsvp_status":        guest.RsvpStatus,
+		"rsvp_attendees":     strconv.Itoa(int(guest.RsvpAttendees)),
+	}
+	flattenJSONColumn("guest_data", guest.GuestData, values)
+	flattenJSONColumn("event_data", guest.EventData, values)
+	return values
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(exportTimeLayout)
+}
+
+// flattenJSONColumn stores every leaf of a JSON object under its dotted path.
+// Values that are not an object are ignored.
+func flattenJSONColumn(prefix string, raw string, out map[string]string) {
+	if strings.TrimSpace(raw) == "" {
+		return
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return
+	}
+	flattenJSON(prefix, data, out)
+}
+
+func flattenJSON(prefix string, value interface{}, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenJSON(prefix+"."+key, child, out)
+		}
+	case nil:
+		out[prefix] = ""
+	case string:
+		out[prefix] = v
+	case json.Number:
+		out[prefix] = v.String()
+	case bool:
+		out[prefix] = strconv.FormatBool(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		out[prefix] = string(encoded)
+	}
+}
+
+// writeGuestExport renders the guests in the requested format. rows calls fn
+// once for every guest, in export order.
+func writeGuestExport(w io.Writer, format string, layout *guestExportLayout, rows func(fn func(row []string) error) error) error {
+	switch format {
+	case exportFormatCSV:
+		return writeGuestCSV(w, layout, rows)
+	case exportFormatXLSX:
+		return writeGuestXLSX(w, layout, rows)
+	case exportFormatPDF:
+		return writeGuestPDF(w, layout, rows)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func writeGuestCSV(w io.Writer, layout *guestExportLayout, rows func(fn func(row []string) error) error) error {
+	// the byte order mark lets Excel open the file as UTF-8
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(layout.columns); err != nil {
+		return err
+	}
+
+	count := 0
+	err := rows(func(row []string) error {
+		for i, cell := range row {
+			row[i] = escapeCSVFormula(cell)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		count++
+		if count%500 == 0 {
+			writer.Flush()
+			return writer.Error()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeCSVFormula stops spreadsheet apps from running a cell as a formula.
+// Phone numbers such as +6281234 are kept as they are.
+func escapeCSVFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	switch cell[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + cell
+	case '+', '-':
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(cell[1:], " ", ""), 64); err != nil {
+			return "'" + cell
+		}
+	}
+	return cell
+}
+
+// writeGuestXLSX uses the excelize stream writer, which spills rows to a
+// temporary file instead of keeping the whole sheet in memory.
+func writeGuestXLSX(w io.Writer, layout *guestExportLayout, rows func(fn func(row []string) error) error) error {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	sheet := workbook.GetSheetName(0)
+	stream, err := workbook.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	rowNumber := 1
+	setRow := func(row []string) error {
+		cells := make([]interface{}, len(row))
+		for i, cell := range row {
+			cells[i] = cell
+		}
+		cellName, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+		rowNumber++
+		return stream.SetRow(cellName, cells)
+	}
+
+	if err := setRow(layout.columns); err != nil {
+		return err
+	}
+	if err := rows(setRow); err != nil {
+		return err
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	return workbook.Write(w)
+}
+
+const (
+	pdfMargin       = 10.0
+	pdfFontSize     = 7.0
+	pdfRowHeight    = 5.0
+	pdfMaxCellChars = 40
+)
+
+// writeGuestPDF renders a printable landscape table. Column widths follow the
+// widest value seen while building the layout and long values are cut to fit.
+// A PDF is only complete once every page is known, so unlike CSV and XLSX the
+// document is built in memory before it is written.
+func writeGuestPDF(w io.Writer, layout *guestExportLayout, rows func(fn func(row []string) error) error) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	usableWidth := pageWidth - 2*pdfMargin
+
+	weights := make([]float64, len(layout.columns))
+	totalWeight := 0.0
+	for i, column := range layout.columns {
+		chars := utf8.RuneCountInString(column)
+		if layout.widths[column] > chars {
+			chars = layout.widths[column]
+		}
+		if chars > pdfMaxCellChars {
+			chars = pdfMaxCellChars
+		}
+		weights[i] = float64(chars + 2)
+		totalWeight += weights[i]
+	}
+	widths := make([]float64, len(layout.columns))
+	for i := range weights {
+		widths[i] = usableWidth * weights[i] / totalWeight
+	}
+
+	fit := func(text string, width float64) string {
+		text = translate(strings.Join(strings.Fields(text), " "))
+		if pdf.GetStringWidth(text) <= width-1 {
+			return text
+		}
+		for len(text) > 0 && pdf.GetStringWidth(text+"...") > width-1 {
+			text = text[:len(text)-1]
+		}
+		return text + "..."
+	}
+
+	drawRow := func(row []string, fill bool) {
+		for i, cell := range row {
+			pdf.CellFormat(widths[i], pdfRowHeight, fit(cell, widths[i]), "1", 0, "L", fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	drawHeader := func() {
+		pdf.SetFont("Helvetica", "B", pdfFontSize)
+		pdf.SetFillColor(230, 230, 230)
+		drawRow(layout.columns, true)
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(usableWidth, 8, "Guest List", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(usableWidth, 5, "Generated at "+time.Now().Format(exportTimeLayout), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	drawHeader()
+
+	err := rows(func(row []string) error {
+		if pdf.GetY()+pdfRowHeight > pageHeight-pdfMargin {
+			pdf.AddPage()
+			drawHeader()
+		}
+		drawRow(row, false)
+		return pdf.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	return pdf.Output(w)
+}
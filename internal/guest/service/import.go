@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	guestModel "rawuh-service/internal/guest/model"
+	"rawuh-service/internal/shared/guestschema"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// guestImportColumns maps accepted header names onto the guest columns. Any
+// other header is stored as a key in GuestData, under the key of the event's
+// custom field whose key or label it matches.
+var guestImportColumns = map[string]string{
+	"name":         "name",
+	"nama":         "name",
+	"address":      "address",
+	"alamat":       "address",
+	"phone":        "phone",
+	"phone number": "phone",
+	"telepon":      "phone",
+	"no hp":        "phone",
+	"email":        "email",
+	"e-mail":       "email",
+}
+
+type guestImportRow struct {
+	row int
+	req *guestModel.CreateGuestRequest
+}
+
+// readGuestSheet returns the raw cells of an uploaded CSV or XLSX file. Only
+// the first sheet of a workbook is read.
+func readGuestSheet(fileName string, file io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("workbook has no sheet")
+		}
+		return workbook.GetRows(sheets[0])
+	default:
+		return nil, fmt.Errorf("unsupported file type, upload a .csv or .xlsx file")
+	}
+}
+
+// parseGuestSheet turns sheet rows into create requests. The first row is the
+// header, row numbers in the result follow the spreadsheet (header is row 1)
+// and blank rows are skipped.
+func parseGuestSheet(rows [][]string, projectID, eventID string, schema *guestschema.Schema) ([]*guestImportRow, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	header := make([]string, len(rows[0]))
+	hasName := false
+	for i, cell := range rows[0] {
+		cell = strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))
+		header[i] = cell
+		if guestImportColumns[strings.ToLower(cell)] == "name" {
+			hasName = true
+		}
+	}
+	if !hasName {
+		return nil, fmt.Errorf("header row must contain a name column")
+	}
+
+	result := make([]*guestImportRow, 0, len(rows)-1)
+	for i, cells := range rows[1:] {
+		req := &guestModel.CreateGuestRequest{
+			ProjectID: projectID,
+			EventId:   eventID,
+		}
+		guestData := map[string]interface{}{}
+		blank := true
+
+		for col, cell := range cells {
+			if col >= len(header) || header[col] == "" {
+				continue
+			}
+			cell = strings.TrimSpace(cell)
+			if cell != "" {
+				blank = false
+			}
+
+			switch guestImportColumns[strings.ToLower(header[col])] {
+			case "name":
+				req.Name = cell
+			case "address":
+				req.Address = cell
+			case "phone":
+				req.Phone = cell
+			case "email":
+				req.Email = cell
+			default:
+				key := header[col]
+				if field := schema.Field(key); field != nil {
+					key = field.Key
+				}
+				if cell != "" {
+					guestData[key] = cell
+				}
+			}
+		}
+
+		if blank {
+			continue
+		}
+
+		if len(guestData) > 0 {
+			data, _ := json.Marshal(guestData)
+			req.GuestData = string(data)
+		}
+
+		result = append(result, &guestImportRow{row: i + 2, req: req})
+	}
+
+	return result, nil
+}
@@ -2,22 +2,32 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
 	guestModel "rawuh-service/internal/guest/model"
+	liveService "rawuh-service/internal/live/service"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/cache"
 	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/lib/qr"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
 	"rawuh-service/internal/shared/middleware"
 	"rawuh-service/internal/shared/model"
 	"strconv"
 	"strings"
+	"time"
 
 	guestDb "rawuh-service/internal/guest/repository"
 	db "rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/filter"
+	"rawuh-service/internal/shared/guestschema"
 
 	"go.elastic.co/apm/v2"
 	"google.golang.org/grpc/codes"
@@ -26,28 +36,46 @@ import (
 )
 
 type GuestService interface {
-	AddGuest(ctx context.Context, p *guestModel.CreateGuestRequest) error
+	AddGuest(ctx context.Context, p *guestModel.CreateGuestRequest) (*guestModel.CreateGuestResponse, error)
 	UpdateGuestByID(ctx context.Context, p *guestModel.UpdateGuestRequest) error
 	GetGuestByID(ctx context.Context, req *guestModel.GetGuestByIDRequest) (*guestModel.GetGuestByIDResponse, error)
 	DeleteGuestByID(ctx context.Context, req *guestModel.DeleteGuestByIDRequest) error
+	RestoreGuest(ctx context.Context, req *guestModel.RestoreGuestRequest) (*guestModel.RestoreGuestResponse, error)
 	ListGuests(ctx context.Context, req *guestModel.ListGuestRequest) (*guestModel.ListGuestResponse, error)
+	CheckInGuest(ctx context.Context, req *guestModel.CheckInGuestRequest) (*guestModel.CheckInGuestResponse, error)
+	UndoCheckInGuest(ctx context.Context, req *guestModel.UndoCheckInGuestRequest) error
+	GetGuestQRCode(ctx context.Context, req *guestModel.GetGuestQRCodeRequest) (*guestModel.GetGuestQRCodeResponse, error)
+	ScanGuest(ctx context.Context, req *guestModel.ScanGuestRequest) (*guestModel.CheckInGuestResponse, error)
+	GetGuestInvitation(ctx context.Context, req *guestModel.GetGuestInvitationRequest) (*guestModel.GetGuestInvitationResponse, error)
+	GetGuestSchema(ctx context.Context, req *guestModel.GetGuestSchemaRequest) (*guestModel.GetGuestSchemaResponse, error)
+	ListGuestDuplicates(ctx context.Context, req *guestModel.ListGuestDuplicatesRequest) (*guestModel.ListGuestDuplicatesResponse, error)
+	MergeGuest(ctx context.Context, req *guestModel.MergeGuestRequest) (*guestModel.MergeGuestResponse, error)
+	ImportGuests(ctx context.Context, req *guestModel.ImportGuestRequest) (*guestModel.ImportGuestResponse, error)
+	ExportGuests(ctx context.Context, req *guestModel.ExportGuestRequest) (*guestModel.ExportGuestResponse, error)
 }
 
 type guestService struct {
 	dbProvider *guestDb.GuestRepository
 	logger     *logger.Logger
-	// redis      *redis.Redis
+	audit      auditService.AuditService
+	live       liveService.LiveService
+	cache      *cache.Cache
 }
 
-func NewGuestService(dbProvider *guestDb.GuestRepository, logger *logger.Logger) GuestService {
+func NewGuestService(dbProvider *guestDb.GuestRepository, audit auditService.AuditService, live liveService.LiveService, cache *cache.Cache, logger *logger.Logger) GuestService {
 	return &guestService{
 		dbProvider: dbProvider,
 		logger:     logger,
-		// redis:      redis,
+		audit:      audit,
+		live:       live,
+		cache:      cache,
 	}
 }
 
-func (s *guestService) AddGuest(ctx context.Context, req *guestModel.CreateGuestRequest) error {
+// AddGuest creates a guest. With CheckDuplicates set, the guests of the event
+// that look like the same person are returned too; the guest is created
+// either way.
+func (s *guestService) AddGuest(ctx context.Context, req *guestModel.CreateGuestRequest) (*guestModel.CreateGuestResponse, error) {
 	funcName := "AddGuest"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
 	span.Action = constant.SpanActionExecute
@@ -57,85 +85,70 @@ func (s *guestService) AddGuest(ctx context.Context, req *guestModel.CreateGuest
 	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
 	if !ok {
 		loggerZap.Error("err GetMeFromMD no auth claims", nil)
-		return status.Error(codes.Unauthenticated, "Unauthenticated")
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) || req.EventId != fmt.Sprintf("%d", currentUser.EventID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.GuestWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
-	remarkLength, _ := strconv.Atoi(utils.GetEnv("GUEST_REMARK_LENGTH", "500"))
-	nameLength, _ := strconv.Atoi(utils.GetEnv("GUEST_NAME_LENGTH", "255"))
-
 	loggerZap.Info("Start Validation for req ", req)
 
-	if utils.IsEmptyString(req.Name) {
-		return status.Errorf(codes.Aborted, "guest name is empty")
-	}
-	if len(req.Name) > nameLength {
-		return status.Errorf(codes.Aborted, "guest name maximum characters is %d", nameLength)
+	schema, err := s.guestSchema(ctx, req.ProjectID, req.EventId)
+	if err != nil {
+		loggerZap.Error("err guestSchema ", err)
+		return nil, err
 	}
 
-	if !utils.IsValidProductName(req.Name) {
-		return status.Errorf(codes.Aborted, "characters not allowed in guest name")
+	if err := validateCreateGuest(req, schema); err != nil {
+		return nil, err
 	}
 
-	if strings.TrimSpace(req.Address) != "" {
-
-		if len(req.Address) > remarkLength {
-			return status.Errorf(codes.Aborted, "%s", fmt.Sprintf("%s maximum characters is %d", req.Address, remarkLength))
-		}
-		if !utils.IsValidCharacter(req.Address) {
-			return status.Errorf(codes.Aborted, "%s", fmt.Sprint("characters not allowed in field Address", req.Address))
-		}
+	result := &guestModel.CreateGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success create new guest",
 	}
 
-	loggerZap.Info("Start CreateGuest with data ", req)
-
-	if req.EventData != "" {
-		var optionStr map[string]interface{}
-		if err := json.Unmarshal([]byte(req.EventData), &optionStr); err != nil {
-			return fmt.Errorf("invalid JSON format: %w", err)
+	if req.CheckDuplicates {
+		loggerZap.Info("Start ListEventGuests")
+		guests, err := s.dbProvider.ListEventGuests(ctx, req.ProjectID, req.EventId)
+		if err != nil {
+			loggerZap.Error("err ListEventGuests ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
 		}
 
-		utils.SanitizeJSON(optionStr)
-		optionData, _ := json.Marshal(optionStr)
-		req.EventData = string(optionData)
-	} else {
-		req.EventData = "{}"
-	}
-
-	if req.GuestData != "" {
-		var optionStr map[string]interface{}
-		if err := json.Unmarshal([]byte(req.GuestData), &optionStr); err != nil {
-			return fmt.Errorf("invalid JSON format: %w", err)
+		countryCode, similarity := duplicateSettings()
+		contact := newGuestContact(req.Name, req.Phone, req.Email, countryCode)
+		result.Duplicates = findMatches(contact, guests, countryCode, similarity)
+		if len(result.Duplicates) > 0 {
+			result.Message = fmt.Sprintf("Success create new guest, %d existing guests look like the same person", len(result.Duplicates))
 		}
-
-		utils.SanitizeJSON(optionStr)
-		optionData, _ := json.Marshal(optionStr)
-		req.GuestData = string(optionData)
-	} else {
-		req.GuestData = "{}"
 	}
 
-	err := s.dbProvider.CreateGuest(ctx, req, currentUser)
+	loggerZap.Info("Start CreateGuest with data ", req)
+
+	guest, err := s.dbProvider.CreateGuest(ctx, req, currentUser)
 	if err != nil {
 		loggerZap.Error("err CreateGuest ", err)
-		return status.Error(codes.Internal, "Internal Server Error")
+		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCreate,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   strconv.FormatInt(guest.GuestID, 10),
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		After:      guest,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventGuestCreated, guest)
+
 	loggerZap.Info("Success CreateGuest")
 
-	return nil
+	return result, nil
 }
 
 func (s *guestService) UpdateGuestByID(ctx context.Context, req *guestModel.UpdateGuestRequest) error {
@@ -151,17 +164,9 @@ func (s *guestService) UpdateGuestByID(ctx context.Context, req *guestModel.Upda
 		return status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) || req.EventId != fmt.Sprintf("%d", currentUser.EventID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.GuestWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	remarkLength, _ := strconv.Atoi(utils.GetEnv("GUEST_REMARK_LENGTH", "500"))
@@ -220,14 +225,50 @@ func (s *guestService) UpdateGuestByID(ctx context.Context, req *guestModel.Upda
 		req.GuestData = "{}"
 	}
 
+	schema, err := s.guestSchema(ctx, req.ProjectID, req.EventId)
+	if err != nil {
+		loggerZap.Error("err guestSchema ", err)
+		return err
+	}
+	if req.GuestData, err = checkGuestData(req.GuestData, schema); err != nil {
+		return err
+	}
+
 	loggerZap.Info("Start UpdateGuest with data ", req)
 
-	err := s.dbProvider.UpdateGuest(ctx, req, currentUser)
+	guestReq := &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	}
+	before, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	err = s.dbProvider.UpdateGuest(ctx, req, currentUser)
 	if err != nil {
 		loggerZap.Error("err UpdateGuest ", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	after, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+	}
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUpdate,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.GuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+		After:      after,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventGuestUpdated, after)
+
 	loggerZap.Info("Success UpdateGuest")
 
 	return nil
@@ -246,87 +287,59 @@ func (s *guestService) ListGuests(ctx context.Context, req *guestModel.ListGuest
 		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) || req.EventId != fmt.Sprintf("%d", currentUser.EventID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return nil, status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
 	loggerZap.Info("Start ListProducts with req : ", req)
-	loggerZap.Info("Start Decode Filter")
-
-	decodeQuery, err := base64.RawStdEncoding.DecodeString(req.Query)
-	if err != nil {
-		loggerZap.Error("err DecodeString ", err)
-		return nil, nil
-	}
-
-	loggerZap.Info("Success Decode Query")
 
 	pagination := utils.SetPagination(req.Page, req.Limit)
-
-	allowedColumns := map[string]bool{
-		"created_at": true,
-		"name":       true,
-		"address":    true,
-		"phone":      true,
-		"email":      true,
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	allowedDirections := map[string]bool{
-		"asc":  true,
-		"desc": true,
+	scopes := []string{
+		cache.ProjectScope(req.ProjectID),
+		cache.EventScope(req.ProjectID, req.EventId),
+		cache.GuestsScope(req.ProjectID, req.EventId),
 	}
+	listRequest := cache.ListRequest(req.Filter, req.Sort, req.Dir, pagination)
 
-	column := strings.ToLower(req.Sort)
-	direction := strings.ToLower(req.Dir)
-
-	if column != "" || direction != "" {
+	return cache.Fetch(ctx, s.cache, "guests", scopes, listRequest, func() (*guestModel.ListGuestResponse, error) {
+		loggerZap.Info("Start Parse Filter")
 
-		if !allowedColumns[column] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+		sqlBuilder, sort, err := buildGuestListQuery(req.Filter, s.guestListColumns(ctx, req.ProjectID, req.EventId), req.Sort, req.Dir)
+		if err != nil {
+			loggerZap.Error("err buildGuestListQuery ", err)
+			return nil, filterError(err)
 		}
-		if !allowedDirections[direction] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
-		}
-	}
-	sort := &model.Sort{
-		Column:    column,
-		Direction: direction,
-	}
-
-	sqlBuilder := &db.QueryBuilder{
-		CollectiveAnd: string(decodeQuery),
-		Sort:          sort,
-	}
-
-	loggerZap.Info("Start ListGuests")
-	guest, err := s.dbProvider.ListGuests(ctx, req, pagination, sqlBuilder, sort)
-	if err != nil {
-		s.logger.Error("err ListGuests ", err)
-		return nil, status.Error(codes.Internal, "Internal Server Error")
-	}
 
-	loggerZap.Info("Start making response")
+		loggerZap.Info("Start ListGuests")
+		guest, err := s.dbProvider.ListGuests(ctx, req, pagination, sqlBuilder, sort)
+		if err != nil {
+			if errors.Is(err, db.ErrInvalidCursor) {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			s.logger.Error("err ListGuests ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
 
-	result := &guestModel.ListGuestResponse{
-		Error:      false,
-		Code:       http.StatusOK,
-		Message:    "Success",
-		Data:       guest,
-		Pagination: pagination,
-	}
+		loggerZap.Info("Start making response")
 
-	return result, nil
+		result := &guestModel.ListGuestResponse{
+			Error:      false,
+			Code:       http.StatusOK,
+			Message:    "Success",
+			Data:       guest,
+			Pagination: pagination,
+		}
 
+		return result, nil
+	})
 }
+
 func (s *guestService) GetGuestByID(ctx context.Context, req *guestModel.GetGuestByIDRequest) (*guestModel.GetGuestByIDResponse, error) {
 	funcName := "GetGuestByID"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
@@ -345,17 +358,9 @@ func (s *guestService) GetGuestByID(ctx context.Context, req *guestModel.GetGues
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid Event Id")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) || req.EventId != fmt.Sprintf("%d", currentUser.EventID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return nil, status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
 	loggerZap.Info("Start GetGuestByID with req : ", req)
@@ -402,23 +407,25 @@ func (s *guestService) DeleteGuestByID(ctx context.Context, req *guestModel.Dele
 		return status.Errorf(codes.InvalidArgument, "Invalid Event Id")
 	}
 
-	switch currentUser.UserType {
-	case constant.UserTypeSystemAdmin:
-		// system admin can access all projects
-	case constant.UserTypeProjectUser:
-		if req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) || req.EventId != fmt.Sprintf("%d", currentUser.EventID) {
-			loggerZap.Error("err GetMeFromMD unauthorized user", nil)
-			return status.Error(codes.PermissionDenied, "Permission Denied")
-		}
-	default:
-		loggerZap.Error("err GetMeFromMD unauthorized user type", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.GuestDelete, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	loggerZap.Info("Start DeleteGuestByID with req : ", req)
 
+	before, err := s.dbProvider.GetGuestByID(ctx, &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	})
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
 	loggerZap.Info("Start DeleteGuestByID")
-	err := s.dbProvider.DeleteGuestByID(ctx, req)
+	err = s.dbProvider.DeleteGuestByID(ctx, req, currentUser)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			loggerZap.Warn("guest not found", err)
@@ -429,8 +436,1007 @@ func (s *guestService) DeleteGuestByID(ctx context.Context, req *guestModel.Dele
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.GuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventCounts, nil)
+
 	loggerZap.Info("Start making response")
 
 	return nil
 
 }
+
+// RestoreGuest undoes DeleteGuestByID.
+func (s *guestService) RestoreGuest(ctx context.Context, req *guestModel.RestoreGuestRequest) (*guestModel.RestoreGuestResponse, error) {
+	funcName := "RestoreGuest"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.GuestID == "" {
+		loggerZap.Error("err Invalid guest id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestDelete, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	loggerZap.Info("Start RestoreGuest")
+	err := s.dbProvider.RestoreGuest(ctx, req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("deleted guest not found", err)
+			return nil, status.Error(codes.NotFound, "Deleted guest not found")
+		}
+		if errors.Is(err, guestDb.ErrEventDeleted) {
+			loggerZap.Warn("event of guest is deleted", err)
+			return nil, status.Error(codes.FailedPrecondition, "Event is deleted, restore the event first")
+		}
+
+		loggerZap.Error("err RestoreGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	guest, err := s.dbProvider.GetGuestByID(ctx, &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	})
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionRestore,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.GuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		After:      guest,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventCounts, nil)
+
+	loggerZap.Info("Success RestoreGuest")
+
+	result := &guestModel.RestoreGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    guest,
+	}
+
+	return result, nil
+}
+
+func (s *guestService) CheckInGuest(ctx context.Context, req *guestModel.CheckInGuestRequest) (*guestModel.CheckInGuestResponse, error) {
+	funcName := "CheckInGuest"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.GuestID == "" {
+		loggerZap.Error("err Invalid guest id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestCheckIn, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	maxCompanion, _ := strconv.Atoi(utils.GetEnv("GUEST_MAX_COMPANION", "20"))
+
+	loggerZap.Info("Start Validation for req ", req)
+
+	if req.CompanionCount < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "companion count cannot be negative")
+	}
+	if int(req.CompanionCount) > maxCompanion {
+		return nil, status.Errorf(codes.InvalidArgument, "companion count maximum is %d", maxCompanion)
+	}
+
+	guestReq := &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	}
+	before, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	loggerZap.Info("Start CheckInGuest")
+	err = s.dbProvider.CheckInGuest(ctx, req, currentUser)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("guest not found", err)
+			return nil, status.Error(codes.NotFound, "Guest not found")
+		}
+		if errors.Is(err, guestDb.ErrGuestAlreadyCheckedIn) {
+			loggerZap.Warn("guest already checked in", err)
+			return nil, status.Error(codes.AlreadyExists, "Guest already checked in")
+		}
+
+		loggerZap.Error("err CheckInGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	guest, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCheckIn,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.GuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+		After:      guest,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventGuestCheckedIn, guest)
+
+	loggerZap.Info("Success CheckInGuest")
+
+	result := &guestModel.CheckInGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    guest,
+	}
+
+	return result, nil
+}
+
+func (s *guestService) UndoCheckInGuest(ctx context.Context, req *guestModel.UndoCheckInGuestRequest) error {
+	funcName := "UndoCheckInGuest"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.GuestID == "" {
+		loggerZap.Error("err Invalid guest id : ", nil)
+		return status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestCheckIn, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
+	}
+
+	guestReq := &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	}
+	before, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	loggerZap.Info("Start UndoCheckInGuest")
+	err = s.dbProvider.UndoCheckInGuest(ctx, req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("guest not found", err)
+			return status.Error(codes.NotFound, "Guest not found")
+		}
+		if errors.Is(err, guestDb.ErrGuestNotCheckedIn) {
+			loggerZap.Warn("guest not checked in", err)
+			return status.Error(codes.FailedPrecondition, "Guest is not checked in")
+		}
+
+		loggerZap.Error("err UndoCheckInGuest ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	after, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+	}
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUndoCheckIn,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.GuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+		After:      after,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventGuestUpdated, after)
+
+	loggerZap.Info("Success UndoCheckInGuest")
+
+	return nil
+}
+
+func (s *guestService) GetGuestQRCode(ctx context.Context, req *guestModel.GetGuestQRCodeRequest) (*guestModel.GetGuestQRCodeResponse, error) {
+	funcName := "GetGuestQRCode"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.GuestID == "" {
+		loggerZap.Error("err Invalid guest id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	format := strings.ToLower(req.Format)
+	if format != qr.FormatPNG && format != qr.FormatSVG {
+		return nil, status.Errorf(codes.InvalidArgument, "qr format must be %s or %s", qr.FormatPNG, qr.FormatSVG)
+	}
+
+	size := req.Size
+	if size <= 0 {
+		size, _ = strconv.Atoi(utils.GetEnv("GUEST_QR_SIZE", "256"))
+	}
+	if size < 64 || size > 1024 {
+		return nil, status.Errorf(codes.InvalidArgument, "qr size must be between 64 and 1024")
+	}
+
+	loggerZap.Info("Start GetGuestByID")
+	guest, err := s.dbProvider.GetGuestByID(ctx, &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	})
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	if guest == nil || guest.GuestID == 0 {
+		loggerZap.Info("GetGuestByID not found", nil)
+		return nil, status.Errorf(codes.NotFound, "guest not found")
+	}
+
+	token, err := utils.SignToken(constant.TokenPurposeGuestQR, guest.ProjectID, guest.EventId, guest.GuestID)
+	if err != nil {
+		loggerZap.Error("err SignToken ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	image, contentType, err := qr.Encode(token, format, size)
+	if err != nil {
+		loggerZap.Error("err Encode QR ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	loggerZap.Info("Success GetGuestQRCode")
+
+	result := &guestModel.GetGuestQRCodeResponse{
+		Error:       false,
+		Code:        http.StatusOK,
+		Message:     "Success",
+		Token:       token,
+		ContentType: contentType,
+		Image:       image,
+	}
+
+	return result, nil
+}
+
+// ScanGuest resolves a token read from a guest QR code and checks the guest
+// in. The token has to be minted for the event in the request path.
+func (s *guestService) ScanGuest(ctx context.Context, req *guestModel.ScanGuestRequest) (*guestModel.CheckInGuestResponse, error) {
+	funcName := "ScanGuest"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"project_id": req.ProjectID, "event_id": req.EventId})
+
+	if utils.IsEmptyString(req.Token) {
+		return nil, status.Errorf(codes.InvalidArgument, "token is empty")
+	}
+
+	ids, err := utils.VerifyToken(req.Token, constant.TokenPurposeGuestQR)
+	if err != nil || len(ids) != 3 {
+		loggerZap.Warn("invalid guest qr token", err)
+		return nil, status.Error(codes.InvalidArgument, "Invalid QR code")
+	}
+
+	projectID, eventID, guestID := ids[0], ids[1], ids[2]
+	if req.ProjectID != fmt.Sprintf("%d", projectID) || req.EventId != fmt.Sprintf("%d", eventID) {
+		loggerZap.Warn("guest qr token scanned at another event", nil)
+		return nil, status.Error(codes.InvalidArgument, "QR code does not belong to this event")
+	}
+
+	return s.CheckInGuest(ctx, &guestModel.CheckInGuestRequest{
+		ProjectID:      req.ProjectID,
+		EventId:        req.EventId,
+		GuestID:        strconv.FormatInt(guestID, 10),
+		CompanionCount: req.CompanionCount,
+	})
+}
+
+// GetGuestInvitation returns the token a guest uses on the public RSVP pages.
+func (s *guestService) GetGuestInvitation(ctx context.Context, req *guestModel.GetGuestInvitationRequest) (*guestModel.GetGuestInvitationResponse, error) {
+	funcName := "GetGuestInvitation"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.GuestID == "" {
+		loggerZap.Error("err Invalid guest id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	loggerZap.Info("Start GetGuestByID")
+	guest, err := s.dbProvider.GetGuestByID(ctx, &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	})
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	if guest == nil || guest.GuestID == 0 {
+		loggerZap.Info("GetGuestByID not found", nil)
+		return nil, status.Errorf(codes.NotFound, "guest not found")
+	}
+
+	token, err := utils.SignToken(constant.TokenPurposeRsvp, guest.ProjectID, guest.EventId, guest.GuestID)
+	if err != nil {
+		loggerZap.Error("err SignToken ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	url := ""
+	if baseURL := utils.GetEnv("RSVP_BASE_URL", ""); baseURL != "" {
+		url = strings.TrimRight(baseURL, "/") + "/" + token
+	}
+
+	loggerZap.Info("Success GetGuestInvitation")
+
+	result := &guestModel.GetGuestInvitationResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Token:   token,
+		Url:     url,
+	}
+
+	return result, nil
+}
+
+// GetGuestSchema returns the custom guest fields of the event so forms can be
+// rendered from it. An event without a schema has no fields.
+func (s *guestService) GetGuestSchema(ctx context.Context, req *guestModel.GetGuestSchemaRequest) (*guestModel.GetGuestSchemaResponse, error) {
+	funcName := "GetGuestSchema"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	loggerZap.Info("Start guestSchema")
+	schema, err := s.guestSchema(ctx, req.ProjectID, req.EventId)
+	if err != nil {
+		loggerZap.Error("err guestSchema ", err)
+		return nil, err
+	}
+	if schema == nil {
+		schema = &guestschema.Schema{Fields: []*guestschema.Field{}}
+	}
+
+	loggerZap.Info("Success GetGuestSchema")
+
+	result := &guestModel.GetGuestSchemaResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    schema,
+	}
+
+	return result, nil
+}
+
+// ListGuestDuplicates groups the guests of an event that look like the same
+// person: the same phone number, the same email or similar names.
+func (s *guestService) ListGuestDuplicates(ctx context.Context, req *guestModel.ListGuestDuplicatesRequest) (*guestModel.ListGuestDuplicatesResponse, error) {
+	funcName := "ListGuestDuplicates"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	loggerZap.Info("Start ListEventGuests")
+	guests, err := s.dbProvider.ListEventGuests(ctx, req.ProjectID, req.EventId)
+	if err != nil {
+		loggerZap.Error("err ListEventGuests ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	countryCode, similarity := duplicateSettings()
+	groups := findDuplicateGroups(guests, countryCode, similarity)
+
+	loggerZap.Info("Success ListGuestDuplicates ", len(groups))
+
+	result := &guestModel.ListGuestDuplicatesResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    groups,
+	}
+
+	return result, nil
+}
+
+// MergeGuest merges a duplicate into a guest and deletes the duplicate. The
+// merge is recorded in public.guest_merges.
+func (s *guestService) MergeGuest(ctx context.Context, req *guestModel.MergeGuestRequest) (*guestModel.MergeGuestResponse, error) {
+	funcName := "MergeGuest"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if req.GuestID == "" || req.DuplicateGuestID == "" {
+		loggerZap.Error("err Invalid guest id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+	if req.GuestID == req.DuplicateGuestID {
+		return nil, status.Errorf(codes.InvalidArgument, "a guest cannot be merged into itself")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+	if err := authz.Check(currentUser, authz.GuestDelete, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	schema, err := s.guestSchema(ctx, req.ProjectID, req.EventId)
+	if err != nil {
+		loggerZap.Error("err guestSchema ", err)
+		return nil, err
+	}
+
+	take := req.Take
+	if take == nil {
+		take = []string{}
+	}
+	rawTake, _ := json.Marshal(take)
+
+	// the merge is worked out from the guests as locked by MergeGuests, so
+	// a write landing meanwhile is not lost
+	merge := func(guest *guestModel.Guest, duplicate *guestModel.Guest) (*guestModel.Guest, *guestModel.GuestMerge, error) {
+		merged, err := mergeGuest(guest, duplicate, req.Take)
+		if err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if merged.GuestData, err = checkGuestData(merged.GuestData, schema); err != nil {
+			return nil, nil, err
+		}
+
+		rawGuest, _ := json.Marshal(guest)
+		rawDuplicate, _ := json.Marshal(duplicate)
+		return merged, &guestModel.GuestMerge{
+			ProjectID:     guest.ProjectID,
+			EventID:       guest.EventId,
+			GuestID:       guest.GuestID,
+			MergedGuestID: duplicate.GuestID,
+			TakenFields:   string(rawTake),
+			GuestBefore:   string(rawGuest),
+			MergedGuest:   string(rawDuplicate),
+		}, nil
+	}
+
+	loggerZap.Info("Start MergeGuests")
+	guest, duplicate, err := s.dbProvider.MergeGuests(ctx, req, merge, currentUser)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("guest or duplicate not found", err)
+			return nil, status.Error(codes.NotFound, "Guest not found")
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		loggerZap.Error("err MergeGuests ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	guestReq := &guestModel.GetGuestByIDRequest{
+		ProjectID: req.ProjectID,
+		GuestID:   req.GuestID,
+		EventId:   req.EventId,
+	}
+	after, err := s.dbProvider.GetGuestByID(ctx, guestReq)
+	if err != nil {
+		loggerZap.Error("err GetGuestByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionMerge,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.GuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     guest,
+		After:      after,
+	})
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityGuest,
+		EntityID:   req.DuplicateGuestID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     duplicate,
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventGuestUpdated, after)
+
+	loggerZap.Info("Success MergeGuest")
+
+	result := &guestModel.MergeGuestResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    after,
+	}
+
+	return result, nil
+}
+
+// ImportGuests validates every row of an uploaded guest list with the same
+// rules as AddGuest. Rows are only written when the whole file is valid and
+// DryRun is not set.
+func (s *guestService) ImportGuests(ctx context.Context, req *guestModel.ImportGuestRequest) (*guestModel.ImportGuestResponse, error) {
+	funcName := "ImportGuests"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestImport, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	maxRows, _ := strconv.Atoi(utils.GetEnv("GUEST_IMPORT_MAX_ROWS", "5000"))
+
+	loggerZap.Info("Start readGuestSheet")
+	sheet, err := readGuestSheet(req.FileName, req.File)
+	if err != nil {
+		loggerZap.Warn("err readGuestSheet", err)
+		return nil, status.Errorf(codes.InvalidArgument, "cannot read file: %v", err)
+	}
+
+	schema, err := s.guestSchema(ctx, req.ProjectID, req.EventId)
+	if err != nil {
+		loggerZap.Error("err guestSchema ", err)
+		return nil, err
+	}
+
+	rows, err := parseGuestSheet(sheet, req.ProjectID, req.EventId, schema)
+	if err != nil {
+		loggerZap.Warn("err parseGuestSheet", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if len(rows) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "file has no guest rows")
+	}
+	if len(rows) > maxRows {
+		return nil, status.Errorf(codes.InvalidArgument, "file has %d guest rows, maximum is %d", len(rows), maxRows)
+	}
+
+	loggerZap.Info("Start Validation for rows ", len(rows))
+
+	result := &guestModel.ImportGuestResponse{
+		Error:     false,
+		Code:      http.StatusOK,
+		DryRun:    req.DryRun,
+		TotalRows: len(rows),
+		Errors:    []*guestModel.ImportGuestRowError{},
+	}
+
+	guests := make([]*guestModel.CreateGuestRequest, 0, len(rows))
+	for _, row := range rows {
+		if err := validateCreateGuest(row.req, schema); err != nil {
+			result.Errors = append(result.Errors, &guestModel.ImportGuestRowError{
+				Row:     row.row,
+				Message: status.Convert(err).Message(),
+			})
+			continue
+		}
+		guests = append(guests, row.req)
+	}
+
+	if len(result.Errors) > 0 {
+		loggerZap.Info("ImportGuests rows failed validation ", len(result.Errors))
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = fmt.Sprintf("%d of %d rows failed validation, nothing was imported", len(result.Errors), len(rows))
+		return result, nil
+	}
+
+	if req.DryRun {
+		result.Message = fmt.Sprintf("%d rows are valid, nothing was imported (dry run)", len(rows))
+		return result, nil
+	}
+
+	loggerZap.Info("Start CreateGuests")
+	if err := s.dbProvider.CreateGuests(ctx, guests, req.FileName, currentUser); err != nil {
+		loggerZap.Error("err CreateGuests ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	// one entry for the whole file, the imported rows are not listed one by one
+	s.invalidate(ctx, req.ProjectID, req.EventId)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionImport,
+		EntityType: constant.AuditEntityGuest,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		After: map[string]interface{}{
+			"FileName":     req.FileName,
+			"ImportedRows": len(guests),
+		},
+	})
+	s.live.Publish(ctx, req.ProjectID, req.EventId, constant.LiveEventCounts, nil)
+
+	loggerZap.Info("Success ImportGuests")
+
+	result.ImportedRows = len(guests)
+	result.Message = fmt.Sprintf("Success import %d guests", len(guests))
+
+	return result, nil
+}
+
+// validateCreateGuest runs the field rules shared by AddGuest and ImportGuests
+// and normalizes the JSON columns of req in place.
+func validateCreateGuest(req *guestModel.CreateGuestRequest, schema *guestschema.Schema) error {
+	remarkLength, _ := strconv.Atoi(utils.GetEnv("GUEST_REMARK_LENGTH", "500"))
+	nameLength, _ := strconv.Atoi(utils.GetEnv("GUEST_NAME_LENGTH", "255"))
+
+	if utils.IsEmptyString(req.Name) {
+		return status.Errorf(codes.Aborted, "guest name is empty")
+	}
+	if len(req.Name) > nameLength {
+		return status.Errorf(codes.Aborted, "guest name maximum characters is %d", nameLength)
+	}
+
+	if !utils.IsValidProductName(req.Name) {
+		return status.Errorf(codes.Aborted, "characters not allowed in guest name")
+	}
+
+	if strings.TrimSpace(req.Address) != "" {
+
+		if len(req.Address) > remarkLength {
+			return status.Errorf(codes.Aborted, "%s", fmt.Sprintf("%s maximum characters is %d", req.Address, remarkLength))
+		}
+		if !utils.IsValidCharacter(req.Address) {
+			return status.Errorf(codes.Aborted, "%s", fmt.Sprint("characters not allowed in field Address", req.Address))
+		}
+	}
+
+	if req.EventData != "" {
+		var optionStr map[string]interface{}
+		if err := json.Unmarshal([]byte(req.EventData), &optionStr); err != nil {
+			return fmt.Errorf("invalid JSON format: %w", err)
+		}
+
+		utils.SanitizeJSON(optionStr)
+		optionData, _ := json.Marshal(optionStr)
+		req.EventData = string(optionData)
+	} else {
+		req.EventData = "{}"
+	}
+
+	if req.GuestData != "" {
+		var optionStr map[string]interface{}
+		if err := json.Unmarshal([]byte(req.GuestData), &optionStr); err != nil {
+			return fmt.Errorf("invalid JSON format: %w", err)
+		}
+
+		utils.SanitizeJSON(optionStr)
+		optionData, _ := json.Marshal(optionStr)
+		req.GuestData = string(optionData)
+	} else {
+		req.GuestData = "{}"
+	}
+
+	var err error
+	if req.GuestData, err = checkGuestData(req.GuestData, schema); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// guestSchema loads the custom field schema of an event, nil when it has
+// none.
+func (s *guestService) guestSchema(ctx context.Context, projectID string, eventID string) (*guestschema.Schema, error) {
+	options, err := s.dbProvider.GetGuestOptions(ctx, projectID, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "event not found")
+		}
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	schema, err := guestschema.FromOptions(options)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "guest field schema of the event is invalid: %v", err)
+	}
+
+	return schema, nil
+}
+
+// checkGuestData checks GuestData against the event's schema and returns it
+// with typed values. Each failing field is reported as GuestData.<key>.
+func checkGuestData(guestData string, schema *guestschema.Schema) (string, error) {
+	if schema == nil {
+		return guestData, nil
+	}
+
+	data := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(guestData), &data); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid JSON format: %v", err)
+	}
+
+	checked, errs := schema.Check(data, false)
+	if len(errs) > 0 {
+		return "", utils.InvalidFields("invalid guest data: "+errs.Error(), errs.Prefixed("GuestData."))
+	}
+
+	raw, _ := json.Marshal(checked)
+	return string(raw), nil
+}
+
+// guestColumns are the columns ListGuests and ExportGuests can filter and sort
+// on, next to the guest_data and event_data paths added by guestListColumns.
+var guestColumns = filter.Columns{
+	"guest_id":           {Type: filter.Int},
+	"name":               {Type: filter.String},
+	"address":            {Type: filter.String},
+	"phone":              {Type: filter.String},
+	"email":              {Type: filter.String},
+	"created_at":         {Type: filter.Time},
+	"updated_at":         {Type: filter.Time},
+	"checked_in_at":      {Type: filter.Time},
+	"checked_in_by_id":   {Type: filter.Int},
+	"checked_in_by_name": {Type: filter.String},
+	"companion_count":    {Type: filter.Int},
+	"rsvp_status":        {Type: filter.String},
+	"rsvp_attendees":     {Type: filter.Int},
+	"rsvp_at":            {Type: filter.Time},
+}
+
+// guestListColumns adds the guest_data and event_data paths staff wrote for
+// the guests of the event to guestColumns. When the event declares custom
+// guest fields, those are the guest_data paths instead. Answers of guests to
+// their invitation never add a path.
+func (s *guestService) guestListColumns(ctx context.Context, projectID string, eventID string) filter.Columns {
+	return guestColumns.WithJSON(func() (map[string]map[string]filter.Type, error) {
+		fields, err := s.dbProvider.ListDataFields(ctx, projectID, eventID)
+		if err != nil {
+			return nil, err
+		}
+
+		// a declared schema replaces the keys found in the data
+		schema, err := s.guestSchema(ctx, projectID, eventID)
+		if err != nil {
+			return nil, err
+		}
+		if schema != nil {
+			fields["guest_data"] = schema.FilterTypes()
+		}
+
+		return fields, nil
+	}, "guest_data", "event_data")
+}
+
+// buildGuestListQuery parses the list filter and checks the sort column and
+// direction against columns.
+func buildGuestListQuery(values url.Values, columns filter.Columns, sortColumn string, sortDir string) (*db.QueryBuilder, *model.Sort, error) {
+	filters, err := filter.Parse(values, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort, err := columns.Sort(sortColumn, sortDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sqlBuilder := &db.QueryBuilder{
+		Filter: filters,
+		Sort:   sort,
+	}
+
+	return sqlBuilder, sort, nil
+}
+
+// filterError answers a bad filter with InvalidArgument and a failure to load
+// the JSON fields with Internal, unless it already is a status error.
+func filterError(err error) error {
+	if errors.Is(err, filter.ErrInvalid) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, "Internal Server Error")
+}
+
+func (s *guestService) ExportGuests(ctx context.Context, req *guestModel.ExportGuestRequest) (*guestModel.ExportGuestResponse, error) {
+	funcName := "ExportGuests"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestExport, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = exportFormatCSV
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "export format must be csv, xlsx or pdf")
+	}
+
+	sqlBuilder, sort, err := buildGuestListQuery(req.Filter, s.guestListColumns(ctx, req.ProjectID, req.EventId), req.Sort, req.Dir)
+	if err != nil {
+		loggerZap.Error("err buildGuestListQuery ", err)
+		return nil, filterError(err)
+	}
+	if sort.Column == "" {
+		sort = &model.Sort{Column: "guest_id", Direction: "asc"}
+	}
+
+	// first pass only collects the JSON keys so every row of the export has
+	// the same columns
+	loggerZap.Info("Start StreamGuests for layout")
+	layout := newGuestExportLayout()
+	err = s.dbProvider.StreamGuests(ctx, req, sqlBuilder, sort, func(guest *guestModel.Guest) error {
+		layout.add(guest)
+		return nil
+	})
+	if err != nil {
+		loggerZap.Error("err StreamGuests ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	layout.finish()
+
+	result := &guestModel.ExportGuestResponse{
+		ContentType: contentType,
+		FileName:    fmt.Sprintf("guests-%s-%s.%s", req.EventId, time.Now().Format("20060102"), format),
+		Write: func(w io.Writer) error {
+			err := writeGuestExport(w, format, layout, func(fn func(row []string) error) error {
+				return s.dbProvider.StreamGuests(ctx, req, sqlBuilder, sort, func(guest *guestModel.Guest) error {
+					return fn(layout.row(guest))
+				})
+			})
+			if err != nil {
+				loggerZap.Error("err writeGuestExport ", err)
+				return err
+			}
+
+			loggerZap.Info("Success ExportGuests")
+			return nil
+		},
+	}
+
+	return result, nil
+}
+
+// invalidate drops the cached guest lists of an event after a change.
+func (s *guestService) invalidate(ctx context.Context, projectID string, eventID string) {
+	if err := s.cache.Invalidate(ctx, cache.GuestsScope(projectID, eventID)); err != nil {
+		s.logger.Error("err Invalidate cache ", err)
+	}
+}
@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	invitationModel "rawuh-service/internal/invitation/model"
+	invitationService "rawuh-service/internal/invitation/service"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/middleware"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type InvitationHandler struct {
+	svc invitationService.InvitationService
+}
+
+func NewInvitationHandler(svc invitationService.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		svc: svc,
+	}
+}
+
+// GetTemplate godoc
+// @Summary Get the invitation template of an event
+// @Description Get the subject and bodies of the invitation email, with their merge fields.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Success 200 {object} invitationModel.GetTemplateResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/invitations/template [get]
+
+func (h *InvitationHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &invitationModel.GetTemplateRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+	}
+
+	template, err := h.svc.GetTemplate(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// UpdateTemplate godoc
+// @Summary Save the invitation template of an event
+// @Description Create or replace the invitation email. Subject, HtmlBody and TextBody may use the merge fields {{Name}}, {{Address}}, {{Email}}, {{Phone}}, {{EventName}}, {{RsvpUrl}}, {{GuestData.key}} and {{EventOptions.key}}; unknown fields are rejected. Values are HTML escaped in HtmlBody.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param body body invitationModel.UpdateTemplateRequest true "UpdateTemplateRequest"
+// @Success 200 {object} invitationModel.UpdateTemplateResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/invitations/template [put]
+
+func (h *InvitationHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &invitationModel.UpdateTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p invitationModel.UpdateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &invitationModel.UpdateTemplateRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		Subject:   p.Subject,
+		HtmlBody:  p.HtmlBody,
+		TextBody:  p.TextBody,
+	}
+
+	template, err := h.svc.UpdateTemplate(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// PreviewInvitation godoc
+// @Summary Preview the invitation of a guest
+// @Description Render the invitation email of GuestID without sending it. Send Subject, HtmlBody and TextBody to preview a template before saving it, or leave them out to preview the saved one.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param body body invitationModel.PreviewInvitationRequest true "PreviewInvitationRequest"
+// @Success 200 {object} invitationModel.PreviewInvitationResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/invitations/preview [post]
+
+func (h *InvitationHandler) PreviewInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &invitationModel.PreviewInvitationResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p invitationModel.PreviewInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &invitationModel.PreviewInvitationRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		GuestID:   p.GuestID,
+		Subject:   p.Subject,
+		HtmlBody:  p.HtmlBody,
+		TextBody:  p.TextBody,
+	}
+
+	preview, err := h.svc.PreviewInvitation(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(preview)
+}
+
+// SendInvitations godoc
+// @Summary Send invitations by email
+// @Description Queue the invitation email for GuestIDs, or for every guest of the event when GuestIDs is empty, and return before it is sent. Guests without a valid email, with an invitation still queued, or already sent one are skipped; set Resend to send those again. Follow the batch with GET .../invitations/batches/{batch_id}.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param body body invitationModel.SendInvitationsRequest false "SendInvitationsRequest"
+// @Success 202 {object} invitationModel.SendInvitationsResponse
+// @Success 200 {object} invitationModel.SendInvitationsResponse "nothing to send"
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 412 {object} utils.APIErrorResponse "email is not configured or the event has no template"
+// @Router /{project_id}/events/{event_id}/invitations/send [post]
+
+func (h *InvitationHandler) SendInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &invitationModel.SendInvitationsResponse{
+		Error:   false,
+		Code:    http.StatusAccepted,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	// an empty body sends to the whole event
+	var p invitationModel.SendInvitationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &invitationModel.SendInvitationsRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		GuestIDs:  p.GuestIDs,
+		Resend:    p.Resend,
+	}
+
+	queued, err := h.svc.SendInvitations(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(int(queued.Code))
+	json.NewEncoder(w).Encode(queued)
+}
+
+// ListDeliveries godoc
+// @Summary List invitation deliveries
+// @Description Get paginated list of the invitation emails of an event with their status, newest first. LastError holds why the last attempt failed.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param dir query string false "asc or desc (default)"
+// @Param batch_id query int false "batch id"
+// @Param guest_id query int false "guest id"
+// @Param status query string false "PENDING, SENDING, SENT or FAILED"
+// @Success 200 {object} invitationModel.ListDeliveriesResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/invitations/list [get]
+
+func (h *InvitationHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	page, _ := strconv.Atoi(queryParams.Get("page"))
+	limit, _ := strconv.Atoi(queryParams.Get("limit"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := &invitationModel.ListDeliveriesRequest{
+		Page:      int32(page),
+		Limit:     int32(limit),
+		Dir:       queryParams.Get("dir"),
+		Cursor:    utils.QueryCursor(queryParams),
+		Count:     queryParams.Get("count"),
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		BatchID:   queryParams.Get("batch_id"),
+		GuestID:   queryParams.Get("guest_id"),
+		Status:    queryParams.Get("status"),
+	}
+
+	deliveries, err := h.svc.ListDeliveries(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// GetBatch godoc
+// @Summary Get an invitation batch
+// @Description Get a batch of invitations with its deliveries counted per status, to follow its progress.
+// @Tags invitation
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param batch_id path string true "batch id"
+// @Success 200 {object} invitationModel.GetBatchResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/invitations/batches/{batch_id} [get]
+
+func (h *InvitationHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &invitationModel.GetBatchRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		BatchID:   mux.Vars(r)["batch_id"],
+	}
+
+	batch, err := h.svc.GetBatch(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(batch)
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// Template is the invitation email of an event. Subject and both bodies may
+// hold merge fields such as {{Name}} or {{GuestData.table}}.
+type Template struct {
+	EventID       int64      `gorm:"primaryKey"`
+	ProjectID     int64      `gorm:"type:bigint"`
+	Subject       string     `gorm:"type:varchar(500)"`
+	HtmlBody      string     `gorm:"type:text"`
+	TextBody      string     `gorm:"type:text"`
+	UpdatedById   int64      `gorm:"type:bigint"`
+	UpdatedByName string     `gorm:"type:varchar(500)"`
+	UpdatedAt     *time.Time `gorm:"type:timestamp"`
+}
+
+// Batch is one request to send invitations. Total counts the deliveries it
+// queued.
+type Batch struct {
+	InvitationBatchID int64      `gorm:"primaryKey;autoIncrement"`
+	ProjectID         int64      `gorm:"type:bigint"`
+	EventID           int64      `gorm:"type:bigint"`
+	Total             int32      `gorm:"type:integer"`
+	CreatedById       int64      `gorm:"type:bigint"`
+	CreatedByName     string     `gorm:"type:varchar(500)"`
+	CreatedAt         *time.Time `gorm:"type:timestamp"`
+}
+
+// Delivery is the invitation of one guest in a batch. It is PENDING until the
+// sender claims it, SENDING while it is being sent, then SENT, or FAILED once
+// every attempt failed; LastError holds the reason of the last failure.
+type Delivery struct {
+	InvitationDeliveryID int64      `gorm:"primaryKey;autoIncrement"`
+	InvitationBatchID    int64      `gorm:"type:bigint"`
+	ProjectID            int64      `gorm:"type:bigint"`
+	EventID              int64      `gorm:"type:bigint"`
+	GuestID              int64      `gorm:"type:bigint"`
+	Recipient            string     `gorm:"type:varchar(500)"`
+	Status               string     `gorm:"type:varchar(20)"`
+	Attempts             int32      `gorm:"type:integer"`
+	LastError            string     `gorm:"type:text"`
+	NextAttemptAt        *time.Time `gorm:"type:timestamp"`
+	CreatedAt            *time.Time `gorm:"type:timestamp"`
+	UpdatedAt            *time.Time `gorm:"type:timestamp"`
+	SentAt               *time.Time `gorm:"type:timestamp"`
+}
+
+// DeliveryFilter narrows ListDeliveries down; zero fields match everything
+// in the event.
+type DeliveryFilter struct {
+	ProjectID int64
+	EventID   int64
+	BatchID   int64
+	GuestID   int64
+	Status    string
+}
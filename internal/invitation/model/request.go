@@ -0,0 +1,121 @@
+package model
+
+import "rawuh-service/internal/shared/model"
+
+type GetTemplateRequest struct {
+	ProjectID string
+	EventId   string
+}
+
+type GetTemplateResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Template
+}
+
+type UpdateTemplateRequest struct {
+	ProjectID string
+	EventId   string
+	Subject   string
+	HtmlBody  string
+	TextBody  string
+}
+
+type UpdateTemplateResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Template
+}
+
+// PreviewInvitationRequest renders the invitation of one guest. Subject,
+// HtmlBody and TextBody preview a template before it is saved; when all three
+// are empty the saved template is used.
+type PreviewInvitationRequest struct {
+	ProjectID string
+	EventId   string
+	GuestID   string
+	Subject   string
+	HtmlBody  string
+	TextBody  string
+}
+
+// Preview is an invitation as the guest would receive it.
+type Preview struct {
+	To       string
+	Subject  string
+	HtmlBody string
+	TextBody string
+}
+
+type PreviewInvitationResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Preview
+}
+
+// SendInvitationsRequest queues the invitations of GuestIDs, or of every
+// guest of the event when it is empty. Guests already sent an invitation are
+// skipped unless Resend is set.
+type SendInvitationsRequest struct {
+	ProjectID string
+	EventId   string
+	GuestIDs  []string
+	Resend    bool
+}
+
+// SendInvitationsResponse counts the guests skipped per reason: not_found,
+// no_email, queued for a delivery not sent yet, or sent already.
+type SendInvitationsResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	BatchID int64
+	Queued  int32
+	Skipped map[string]int32
+}
+
+type ListDeliveriesRequest struct {
+	Page      int32
+	Limit     int32
+	Cursor    *string
+	Count     string
+	Dir       string
+	ProjectID string
+	EventId   string
+	BatchID   string
+	GuestID   string
+	Status    string
+}
+
+type ListDeliveriesResponse struct {
+	Error      bool
+	Code       int32
+	Message    string
+	Data       []*Delivery
+	Pagination *model.PaginationResponse
+}
+
+type GetBatchRequest struct {
+	ProjectID string
+	EventId   string
+	BatchID   string
+}
+
+// BatchStatus is a batch with its deliveries counted per status.
+type BatchStatus struct {
+	*Batch
+	Pending int64
+	Sending int64
+	Sent    int64
+	Failed  int64
+}
+
+type GetBatchResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *BatchStatus
+}
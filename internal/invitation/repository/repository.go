@@ -0,0 +1,353 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	eventModel "rawuh-service/internal/event/model"
+	guestModel "rawuh-service/internal/guest/model"
+	invitationModel "rawuh-service/internal/invitation/model"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvitationRepository struct {
+	provider *db.GormProvider
+}
+
+func NewInvitationRepository(provider *db.GormProvider) *InvitationRepository {
+	return &InvitationRepository{
+		provider: provider,
+	}
+}
+
+func (p *InvitationRepository) GetEvent(ctx context.Context, projectID, eventID int64) (*eventModel.Event, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data eventModel.Event
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (p *InvitationRepository) GetGuest(ctx context.Context, projectID, eventID, guestID int64) (*guestModel.Guest, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data guestModel.Guest
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND guest_id = ? AND deleted_at IS NULL", projectID, eventID, guestID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// GetTemplate returns the template of the event, or nil when it has none.
+func (p *InvitationRepository) GetTemplate(ctx context.Context, projectID, eventID int64) (*invitationModel.Template, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data invitationModel.Template
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_templates")
+
+	query = query.Where("project_id = ? AND event_id = ?", projectID, eventID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// SaveTemplate creates the template of the event or replaces it.
+func (p *InvitationRepository) SaveTemplate(ctx context.Context, data *invitationModel.Template) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_templates")
+
+	return query.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "html_body", "text_body", "updated_by_id", "updated_by_name", "updated_at"}),
+	}).Create(data).Error
+}
+
+// CreateBatch queues a batch in one transaction. The event row stays locked
+// until it commits, so two batches of the same event never both queue a
+// guest. plan gets the guests of guestIDs, or of the whole event when it is
+// empty, with the status of the last delivery of each guest that has one,
+// and returns the deliveries to queue. A batch with nothing to queue is not
+// stored.
+func (p *InvitationRepository) CreateBatch(ctx context.Context, batch *invitationModel.Batch, guestIDs []int64, plan func(guests []*guestModel.Guest, statuses map[int64]string) []*invitationModel.Delivery) (deliveries []*invitationModel.Delivery, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var eventIDs []int64
+	err = tx.Debug().Table("public.events").
+		Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", batch.ProjectID, batch.EventID).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("event_id", &eventIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(eventIDs) == 0 {
+		err = gorm.ErrRecordNotFound
+		return nil, err
+	}
+
+	var guests []*guestModel.Guest
+	query := tx.Debug().Table("public.guests").
+		Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", batch.ProjectID, batch.EventID)
+	if len(guestIDs) > 0 {
+		query = query.Where("guest_id IN ?", guestIDs)
+	}
+	if err = query.Order("guest_id").Find(&guests).Error; err != nil {
+		return nil, err
+	}
+
+	var last []*invitationModel.Delivery
+	query = tx.Debug().Table("public.invitation_deliveries").
+		Select("DISTINCT ON (guest_id) guest_id, status").
+		Where("project_id = ? AND event_id = ?", batch.ProjectID, batch.EventID)
+	if len(guestIDs) > 0 {
+		query = query.Where("guest_id IN ?", guestIDs)
+	}
+	if err = query.Order("guest_id, invitation_delivery_id DESC").Find(&last).Error; err != nil {
+		return nil, err
+	}
+	statuses := make(map[int64]string, len(last))
+	for _, d := range last {
+		statuses[d.GuestID] = d.Status
+	}
+
+	deliveries = plan(guests, statuses)
+	if len(deliveries) == 0 {
+		return nil, tx.Commit().Error
+	}
+
+	now := time.Now()
+	batch.Total = int32(len(deliveries))
+	batch.CreatedAt = &now
+	if err = tx.Debug().Table("public.invitation_batches").Omit("invitation_batch_id").Create(batch).Error; err != nil {
+		return nil, err
+	}
+
+	for _, d := range deliveries {
+		d.InvitationBatchID = batch.InvitationBatchID
+		d.ProjectID = batch.ProjectID
+		d.EventID = batch.EventID
+		d.Status = constant.DeliveryStatusPending
+		d.NextAttemptAt = &now
+		d.CreatedAt = &now
+	}
+	if err = tx.Debug().Table("public.invitation_deliveries").Omit("invitation_delivery_id").CreateInBatches(deliveries, 500).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, tx.Commit().Error
+}
+
+func (p *InvitationRepository) GetBatch(ctx context.Context, projectID, eventID, batchID int64) (*invitationModel.Batch, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data invitationModel.Batch
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_batches")
+
+	query = query.Where("project_id = ? AND event_id = ? AND invitation_batch_id = ?", projectID, eventID, batchID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// CountBatchDeliveries counts the deliveries of a batch per status.
+func (p *InvitationRepository) CountBatchDeliveries(ctx context.Context, batchID int64) (map[string]int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_deliveries")
+
+	err := query.Select("status, COUNT(*) AS count").
+		Where("invitation_batch_id = ?", batchID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (p *InvitationRepository) ListDeliveries(ctx context.Context, filter *invitationModel.DeliveryFilter, pagination *model.PaginationResponse, sort *model.Sort) (data []*invitationModel.Delivery, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_deliveries")
+
+	query = query.Where("project_id = ? AND event_id = ?", filter.ProjectID, filter.EventID)
+	if filter.BatchID != 0 {
+		query = query.Where("invitation_batch_id = ?", filter.BatchID)
+	}
+	if filter.GuestID != 0 {
+		query = query.Where("guest_id = ?", filter.GuestID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if pagination.Cursor != nil {
+		return db.Keyset[invitationModel.Delivery](query, pagination, sort, "invitation_delivery_id")
+	}
+
+	query = query.Scopes(db.Paginate(data, pagination, query))
+	query = query.Scopes(
+		db.Sort(sort),
+	)
+
+	if err := query.Order("invitation_delivery_id " + sort.Direction).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ClaimDeliveries marks up to limit deliveries that are due as SENDING and
+// returns them. Deliveries left SENDING since before stale, by a sender that
+// stopped halfway, are claimed again. Rows claimed by another sender are
+// skipped, so several servers can send at once.
+func (p *InvitationRepository) ClaimDeliveries(ctx context.Context, limit int, stale time.Time) ([]*invitationModel.Delivery, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	now := time.Now()
+	var data []*invitationModel.Delivery
+
+	err := p.provider.GetDB().WithContext(timeoutctx).Debug().Raw(`
+		UPDATE public.invitation_deliveries
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE invitation_delivery_id IN (
+			SELECT invitation_delivery_id FROM public.invitation_deliveries
+			WHERE (status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at < ?)
+			ORDER BY invitation_delivery_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		constant.DeliveryStatusSending, now,
+		constant.DeliveryStatusPending, now, constant.DeliveryStatusSending, stale,
+		limit,
+	).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FinishDelivery stores the outcome of sending a claimed delivery: SENT, or
+// PENDING again until nextAttemptAt, or FAILED. It does nothing when the
+// delivery is no longer SENDING.
+func (p *InvitationRepository) FinishDelivery(ctx context.Context, deliveryID int64, status string, lastError string, nextAttemptAt *time.Time) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_deliveries")
+
+	query = query.Where("invitation_delivery_id = ? AND status = ?", deliveryID, constant.DeliveryStatusSending)
+
+	now := time.Now()
+	values := map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+		"updated_at": &now,
+	}
+	if status == constant.DeliveryStatusSent {
+		values["sent_at"] = &now
+	}
+	if nextAttemptAt != nil {
+		values["next_attempt_at"] = nextAttemptAt
+	}
+
+	return query.Updates(values).Error
+}
+
+// CancelDeliveries fails the deliveries still PENDING of the guest guestID,
+// or of every guest of the event eventID, or of the whole project when both
+// are 0. Deliveries being sent are left to finish.
+func (p *InvitationRepository) CancelDeliveries(ctx context.Context, projectID, eventID, guestID int64, reason string) (int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.invitation_deliveries")
+
+	query = query.Where("project_id = ? AND status = ?", projectID, constant.DeliveryStatusPending)
+	if eventID != 0 {
+		query = query.Where("event_id = ?", eventID)
+	}
+	if guestID != 0 {
+		query = query.Where("guest_id = ?", guestID)
+	}
+
+	res := query.Updates(map[string]interface{}{
+		"status":     constant.DeliveryStatusFailed,
+		"last_error": reason,
+		"updated_at": time.Now(),
+	})
+
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	eventModel "rawuh-service/internal/event/model"
+	invitationModel "rawuh-service/internal/invitation/model"
+	invitationDb "rawuh-service/internal/invitation/repository"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/mailer"
+)
+
+type SenderConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+// SenderConfigFromEnv reads the invitation sender settings:
+//
+//	INVITATION_SEND_INTERVAL  10s, how often queued invitations are sent, 0 turns sending off
+//	INVITATION_BATCH_SIZE     50 invitations claimed per run
+//	INVITATION_MAX_ATTEMPTS   3 attempts before an invitation is FAILED
+//	INVITATION_RETRY_DELAY    1m before the second attempt, doubled for each one after
+func SenderConfigFromEnv() (SenderConfig, error) {
+	cfg := SenderConfig{}
+
+	interval, err := time.ParseDuration(utils.GetEnv("INVITATION_SEND_INTERVAL", "10s"))
+	if err != nil || interval < 0 {
+		return cfg, fmt.Errorf("invalid INVITATION_SEND_INTERVAL")
+	}
+	batchSize, err := strconv.Atoi(utils.GetEnv("INVITATION_BATCH_SIZE", "50"))
+	if err != nil || batchSize <= 0 {
+		return cfg, fmt.Errorf("invalid INVITATION_BATCH_SIZE")
+	}
+	maxAttempts, err := strconv.Atoi(utils.GetEnv("INVITATION_MAX_ATTEMPTS", "3"))
+	if err != nil || maxAttempts <= 0 {
+		return cfg, fmt.Errorf("invalid INVITATION_MAX_ATTEMPTS")
+	}
+	retryDelay, err := time.ParseDuration(utils.GetEnv("INVITATION_RETRY_DELAY", "1m"))
+	if err != nil || retryDelay <= 0 {
+		return cfg, fmt.Errorf("invalid INVITATION_RETRY_DELAY")
+	}
+
+	cfg.Interval = interval
+	cfg.BatchSize = batchSize
+	cfg.MaxAttempts = maxAttempts
+	cfg.RetryDelay = retryDelay
+
+	return cfg, nil
+}
+
+// Sender sends queued invitations. Each delivery is rendered when it is
+// sent, from the guest, event and template as they are then. A delivery is
+// sent at least once: if the server stops between handing a message to the
+// mail server and marking it SENT, it is sent again.
+type Sender struct {
+	dbProvider *invitationDb.InvitationRepository
+	mailer     mailer.Mailer
+	timeout    time.Duration
+	cfg        SenderConfig
+	logger     *logger.Logger
+}
+
+func NewSender(dbProvider *invitationDb.InvitationRepository, mailer mailer.Mailer, mailerCfg mailer.Config, cfg SenderConfig, logger *logger.Logger) *Sender {
+	return &Sender{
+		dbProvider: dbProvider,
+		mailer:     mailer,
+		timeout:    mailerCfg.Timeout,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Run claims due deliveries and sends them until none are left, and returns
+// how many were sent.
+func (s *Sender) Run(ctx context.Context) (int, error) {
+	// a delivery SENDING for longer than a whole batch of emails can take
+	// belongs to a sender that stopped halfway
+	stale := time.Duration(s.cfg.BatchSize)*s.timeout + time.Minute
+
+	sent := 0
+	for {
+		deliveries, err := s.dbProvider.ClaimDeliveries(ctx, s.cfg.BatchSize, time.Now().Add(-stale))
+		if err != nil {
+			return sent, fmt.Errorf("claim invitations: %w", err)
+		}
+
+		// deliveries of the same event share its template
+		invitations := map[int64]*eventInvitation{}
+		for _, d := range deliveries {
+			inv, ok := invitations[d.EventID]
+			if !ok {
+				inv, err = s.loadInvitation(ctx, d.ProjectID, d.EventID)
+				if err != nil {
+					return sent, err
+				}
+				invitations[d.EventID] = inv
+			}
+
+			if int(d.Attempts) > s.cfg.MaxAttempts {
+				s.fail(ctx, d, fmt.Errorf("sending stopped halfway on the last attempt"))
+				continue
+			}
+			if err := s.send(ctx, d, inv); err != nil {
+				s.fail(ctx, d, err)
+				continue
+			}
+			if err := s.dbProvider.FinishDelivery(ctx, d.InvitationDeliveryID, constant.DeliveryStatusSent, "", nil); err != nil {
+				s.logger.Error("err FinishDelivery ", err)
+				continue
+			}
+			sent++
+		}
+
+		if len(deliveries) < s.cfg.BatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// Start runs the sender every interval until ctx is done.
+func (s *Sender) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := s.Run(ctx)
+			if err != nil {
+				s.logger.Error("err SendInvitations ", err)
+				continue
+			}
+			if sent > 0 {
+				s.logger.Info("Success SendInvitations ", sent)
+			}
+		}
+	}
+}
+
+// eventInvitation is what the invitations of one event are made from. err
+// is set when they cannot be made, such as when the template was removed.
+type eventInvitation struct {
+	event      *eventModel.Event
+	invitation *compiledInvitation
+	err        error
+}
+
+func (s *Sender) loadInvitation(ctx context.Context, projectID, eventID int64) (*eventInvitation, error) {
+	event, err := s.dbProvider.GetEvent(ctx, projectID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event: %w", err)
+	}
+	if event == nil {
+		return &eventInvitation{err: fmt.Errorf("event not found")}, nil
+	}
+
+	template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("get invitation template: %w", err)
+	}
+	if template == nil {
+		return &eventInvitation{err: fmt.Errorf("the event has no invitation template")}, nil
+	}
+
+	invitation, err := compileInvitation(template.Subject, template.HtmlBody, template.TextBody)
+	if err != nil {
+		return &eventInvitation{err: fmt.Errorf("invalid invitation template: %w", err)}, nil
+	}
+
+	return &eventInvitation{event: event, invitation: invitation}, nil
+}
+
+func (s *Sender) send(ctx context.Context, d *invitationModel.Delivery, inv *eventInvitation) error {
+	if inv.err != nil {
+		return inv.err
+	}
+
+	guest, err := s.dbProvider.GetGuest(ctx, d.ProjectID, d.EventID, d.GuestID)
+	if err != nil {
+		return fmt.Errorf("get guest: %w", err)
+	}
+	if guest == nil {
+		return fmt.Errorf("guest not found")
+	}
+
+	values, err := invitationValues(guest, inv.event)
+	if err != nil {
+		return err
+	}
+	subject, htmlBody, textBody := inv.invitation.render(values)
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      d.Recipient,
+		Subject: subject,
+		HTML:    htmlBody,
+		Text:    textBody,
+	})
+}
+
+// fail puts a delivery back in the queue after a delay that doubles with
+// every attempt, or marks it FAILED once it used up its attempts.
+func (s *Sender) fail(ctx context.Context, d *invitationModel.Delivery, sendErr error) {
+	s.logger.Warn("err Send invitation "+strconv.FormatInt(d.InvitationDeliveryID, 10)+" ", sendErr)
+
+	status := constant.DeliveryStatusFailed
+	var next *time.Time
+	if int(d.Attempts) < s.cfg.MaxAttempts {
+		status = constant.DeliveryStatusPending
+		at := time.Now().Add(s.cfg.RetryDelay << min(d.Attempts-1, 16))
+		next = &at
+	}
+
+	if err := s.dbProvider.FinishDelivery(ctx, d.InvitationDeliveryID, status, sendErr.Error(), next); err != nil {
+		s.logger.Error("err FinishDelivery ", err)
+	}
+}
@@ -0,0 +1,634 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
+	eventModel "rawuh-service/internal/event/model"
+	guestModel "rawuh-service/internal/guest/model"
+	invitationModel "rawuh-service/internal/invitation/model"
+	invitationDb "rawuh-service/internal/invitation/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/mailer"
+	"rawuh-service/internal/shared/mergefield"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/model"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	// templates are stored whole and rendered once per guest, keep them to a
+	// size an email client renders comfortably
+	maxTemplateBody = 256 << 10
+
+	skipNotFound = "not_found"
+	skipNoEmail  = "no_email"
+	skipQueued   = "queued"
+	skipSent     = "sent"
+)
+
+// InvitationService keeps the invitation email of each event and queues it
+// for guests. Queued invitations are sent in the background by a Sender.
+type InvitationService interface {
+	GetTemplate(ctx context.Context, req *invitationModel.GetTemplateRequest) (*invitationModel.GetTemplateResponse, error)
+	UpdateTemplate(ctx context.Context, req *invitationModel.UpdateTemplateRequest) (*invitationModel.UpdateTemplateResponse, error)
+	PreviewInvitation(ctx context.Context, req *invitationModel.PreviewInvitationRequest) (*invitationModel.PreviewInvitationResponse, error)
+	SendInvitations(ctx context.Context, req *invitationModel.SendInvitationsRequest) (*invitationModel.SendInvitationsResponse, error)
+	ListDeliveries(ctx context.Context, req *invitationModel.ListDeliveriesRequest) (*invitationModel.ListDeliveriesResponse, error)
+	GetBatch(ctx context.Context, req *invitationModel.GetBatchRequest) (*invitationModel.GetBatchResponse, error)
+}
+
+type invitationService struct {
+	dbProvider *invitationDb.InvitationRepository
+	mailer     mailer.Mailer
+	audit      auditService.AuditService
+	logger     *logger.Logger
+}
+
+// NewInvitationService returns the service. mailer may be nil when email is
+// not configured; templates can then be edited and previewed but not sent.
+func NewInvitationService(dbProvider *invitationDb.InvitationRepository, mailer mailer.Mailer, audit auditService.AuditService, logger *logger.Logger) InvitationService {
+	return &invitationService{
+		dbProvider: dbProvider,
+		mailer:     mailer,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+func (s *invitationService) GetTemplate(ctx context.Context, req *invitationModel.GetTemplateRequest) (*invitationModel.GetTemplateResponse, error) {
+	funcName := "GetTemplate"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerZap.Info("Start GetTemplate")
+	template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if template == nil {
+		return nil, status.Errorf(codes.NotFound, "the event has no invitation template")
+	}
+
+	result := &invitationModel.GetTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    template,
+	}
+
+	return result, nil
+}
+
+// UpdateTemplate creates or replaces the template of the event. Every merge
+// field in it must exist.
+func (s *invitationService) UpdateTemplate(ctx context.Context, req *invitationModel.UpdateTemplateRequest) (*invitationModel.UpdateTemplateResponse, error) {
+	funcName := "UpdateTemplate"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "EventId": req.EventId, "Subject": req.Subject})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerZap.Info("Start Validation for req ", nil)
+	if _, err := validateTemplate(req.Subject, req.HtmlBody, req.TextBody); err != nil {
+		return nil, err
+	}
+
+	event, err := s.dbProvider.GetEvent(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if event == nil {
+		return nil, status.Errorf(codes.NotFound, "event not found")
+	}
+
+	before, err := s.dbProvider.GetTemplate(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	now := time.Now()
+	template := &invitationModel.Template{
+		EventID:       eventID,
+		ProjectID:     projectID,
+		Subject:       strings.TrimSpace(req.Subject),
+		HtmlBody:      req.HtmlBody,
+		TextBody:      req.TextBody,
+		UpdatedById:   currentUser.UserID,
+		UpdatedByName: currentUser.Name,
+		UpdatedAt:     &now,
+	}
+
+	loggerZap.Info("Start SaveTemplate")
+	if err := s.dbProvider.SaveTemplate(ctx, template); err != nil {
+		loggerZap.Error("err SaveTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	action := constant.AuditActionUpdate
+	if before == nil {
+		action = constant.AuditActionCreate
+	}
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     action,
+		EntityType: constant.AuditEntityInvitationTemplate,
+		EntityID:   req.EventId,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+		After:      template,
+	})
+
+	result := &invitationModel.UpdateTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success update invitation template",
+		Data:    template,
+	}
+
+	return result, nil
+}
+
+// PreviewInvitation renders the invitation of one guest without sending it.
+func (s *invitationService) PreviewInvitation(ctx context.Context, req *invitationModel.PreviewInvitationRequest) (*invitationModel.PreviewInvitationResponse, error) {
+	funcName := "PreviewInvitation"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "EventId": req.EventId, "GuestID": req.GuestID})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	guestID, err := strconv.ParseInt(req.GuestID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	var invitation *compiledInvitation
+	if req.Subject == "" && req.HtmlBody == "" && req.TextBody == "" {
+		template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID)
+		if err != nil {
+			loggerZap.Error("err GetTemplate ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+		if template == nil {
+			return nil, status.Errorf(codes.NotFound, "the event has no invitation template")
+		}
+		if invitation, err = compileInvitation(template.Subject, template.HtmlBody, template.TextBody); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, "the invitation template of this event is invalid: "+err.Error())
+		}
+	} else if invitation, err = validateTemplate(req.Subject, req.HtmlBody, req.TextBody); err != nil {
+		return nil, err
+	}
+
+	event, err := s.dbProvider.GetEvent(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if event == nil {
+		return nil, status.Errorf(codes.NotFound, "event not found")
+	}
+	guest, err := s.dbProvider.GetGuest(ctx, projectID, eventID, guestID)
+	if err != nil {
+		loggerZap.Error("err GetGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if guest == nil {
+		return nil, status.Errorf(codes.NotFound, "guest not found")
+	}
+
+	values, err := invitationValues(guest, event)
+	if err != nil {
+		loggerZap.Error("err invitationValues ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	subject, htmlBody, textBody := invitation.render(values)
+
+	result := &invitationModel.PreviewInvitationResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data: &invitationModel.Preview{
+			To:       guest.Email,
+			Subject:  subject,
+			HtmlBody: htmlBody,
+			TextBody: textBody,
+		},
+	}
+
+	return result, nil
+}
+
+// SendInvitations queues the invitations of a batch of guests and returns
+// before they are sent. Guests without a valid email, with an invitation
+// still queued, or already sent one without Resend are skipped.
+func (s *invitationService) SendInvitations(ctx context.Context, req *invitationModel.SendInvitationsRequest) (*invitationModel.SendInvitationsResponse, error) {
+	funcName := "SendInvitations"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.InvitationSend, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	if s.mailer == nil {
+		return nil, status.Error(codes.FailedPrecondition, "email is not configured, set SMTP_HOST")
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	guestIDs := make([]int64, 0, len(req.GuestIDs))
+	seen := map[int64]bool{}
+	for _, id := range req.GuestIDs {
+		guestID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid guest id %q", id)
+		}
+		if !seen[guestID] {
+			seen[guestID] = true
+			guestIDs = append(guestIDs, guestID)
+		}
+	}
+
+	template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if template == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the event has no invitation template")
+	}
+	if _, err := compileInvitation(template.Subject, template.HtmlBody, template.TextBody); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "the invitation template of this event is invalid: "+err.Error())
+	}
+
+	skipped := map[string]int32{}
+	plan := func(guests []*guestModel.Guest, statuses map[int64]string) []*invitationModel.Delivery {
+		deliveries := []*invitationModel.Delivery{}
+		if len(guestIDs) > len(guests) {
+			skipped[skipNotFound] = int32(len(guestIDs) - len(guests))
+		}
+		for _, guest := range guests {
+			recipient, ok := recipient(guest)
+			switch last := statuses[guest.GuestID]; {
+			case !ok:
+				skipped[skipNoEmail]++
+			case last == constant.DeliveryStatusPending || last == constant.DeliveryStatusSending:
+				skipped[skipQueued]++
+			case last == constant.DeliveryStatusSent && !req.Resend:
+				skipped[skipSent]++
+			default:
+				deliveries = append(deliveries, &invitationModel.Delivery{GuestID: guest.GuestID, Recipient: recipient})
+			}
+		}
+		return deliveries
+	}
+
+	batch := &invitationModel.Batch{
+		ProjectID:     projectID,
+		EventID:       eventID,
+		CreatedById:   currentUser.UserID,
+		CreatedByName: currentUser.Name,
+	}
+
+	loggerZap.Info("Start CreateBatch")
+	deliveries, err := s.dbProvider.CreateBatch(ctx, batch, guestIDs, plan)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "event not found")
+		}
+		loggerZap.Error("err CreateBatch ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &invitationModel.SendInvitationsResponse{
+		Error:   false,
+		Code:    http.StatusAccepted,
+		Message: fmt.Sprintf("%d invitations queued", len(deliveries)),
+		Queued:  int32(len(deliveries)),
+		Skipped: skipped,
+	}
+	if len(deliveries) == 0 {
+		result.Code = http.StatusOK
+		result.Message = "No invitations to send"
+		return result, nil
+	}
+	result.BatchID = batch.InvitationBatchID
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionSend,
+		EntityType: constant.AuditEntityInvitationBatch,
+		EntityID:   strconv.FormatInt(batch.InvitationBatchID, 10),
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		After: map[string]interface{}{
+			"Batch":   batch,
+			"Resend":  req.Resend,
+			"Skipped": skipped,
+		},
+	})
+
+	return result, nil
+}
+
+func (s *invitationService) ListDeliveries(ctx context.Context, req *invitationModel.ListDeliveriesRequest) (*invitationModel.ListDeliveriesResponse, error) {
+	funcName := "ListDeliveries"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &invitationModel.DeliveryFilter{
+		ProjectID: projectID,
+		EventID:   eventID,
+		Status:    strings.ToUpper(req.Status),
+	}
+	switch filter.Status {
+	case "", constant.DeliveryStatusPending, constant.DeliveryStatusSending, constant.DeliveryStatusSent, constant.DeliveryStatusFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %s, %s, %s or %s", constant.DeliveryStatusPending, constant.DeliveryStatusSending, constant.DeliveryStatusSent, constant.DeliveryStatusFailed)
+	}
+
+	ids := []struct {
+		value string
+		dest  *int64
+	}{
+		{req.BatchID, &filter.BatchID},
+		{req.GuestID, &filter.GuestID},
+	}
+	for _, id := range ids {
+		if id.value == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(id.value, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid id %q", id.value)
+		}
+		*id.dest = v
+	}
+
+	direction := strings.ToLower(req.Dir)
+	switch direction {
+	case "":
+		direction = "desc"
+	case "asc", "desc":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+	}
+	sort := &model.Sort{
+		Column:    "created_at",
+		Direction: direction,
+	}
+
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	loggerZap.Info("Start ListDeliveries")
+	deliveries, err := s.dbProvider.ListDeliveries(ctx, filter, pagination, sort)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		loggerZap.Error("err ListDeliveries ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &invitationModel.ListDeliveriesResponse{
+		Error:      false,
+		Code:       http.StatusOK,
+		Message:    "Success",
+		Data:       deliveries,
+		Pagination: pagination,
+	}
+
+	return result, nil
+}
+
+// GetBatch returns a batch with its deliveries counted per status, to follow
+// its progress.
+func (s *invitationService) GetBatch(ctx context.Context, req *invitationModel.GetBatchRequest) (*invitationModel.GetBatchResponse, error) {
+	funcName := "GetBatch"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	batchID, err := strconv.ParseInt(req.BatchID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Batch Id")
+	}
+
+	loggerZap.Info("Start GetBatch")
+	batch, err := s.dbProvider.GetBatch(ctx, projectID, eventID, batchID)
+	if err != nil {
+		loggerZap.Error("err GetBatch ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if batch == nil {
+		return nil, status.Errorf(codes.NotFound, "batch not found")
+	}
+
+	counts, err := s.dbProvider.CountBatchDeliveries(ctx, batchID)
+	if err != nil {
+		loggerZap.Error("err CountBatchDeliveries ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &invitationModel.GetBatchResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data: &invitationModel.BatchStatus{
+			Batch:   batch,
+			Pending: counts[constant.DeliveryStatusPending],
+			Sending: counts[constant.DeliveryStatusSending],
+			Sent:    counts[constant.DeliveryStatusSent],
+			Failed:  counts[constant.DeliveryStatusFailed],
+		},
+	}
+
+	return result, nil
+}
+
+func parseEventIDs(projectID string, eventID string) (int64, int64, error) {
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+	eid, err := strconv.ParseInt(eventID, 10, 64)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "Invalid Event Id")
+	}
+	return pid, eid, nil
+}
+
+// validateTemplate checks a template sent by a client and parses it.
+func validateTemplate(subject, htmlBody, textBody string) (*compiledInvitation, error) {
+	if strings.TrimSpace(subject) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Subject is required")
+	}
+	if len(subject) > 500 {
+		return nil, status.Errorf(codes.InvalidArgument, "Subject is longer than 500 characters")
+	}
+	if strings.TrimSpace(htmlBody) == "" && strings.TrimSpace(textBody) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "HtmlBody or TextBody is required")
+	}
+	if len(htmlBody) > maxTemplateBody || len(textBody) > maxTemplateBody {
+		return nil, status.Errorf(codes.InvalidArgument, "a body is larger than %d KB", maxTemplateBody>>10)
+	}
+
+	invitation, err := compileInvitation(subject, htmlBody, textBody)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return invitation, nil
+}
+
+// recipient returns the address to send the invitation of guest to, with
+// the guest's name, and false when the guest has no valid email.
+func recipient(guest *guestModel.Guest) (string, bool) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(guest.Email))
+	if err != nil {
+		return "", false
+	}
+	addr.Name = guest.Name
+	return addr.String(), true
+}
+
+// invitationValues returns the merge values of the invitation of guest. The
+// RSVP link is the one GetGuestInvitation hands out, empty when
+// RSVP_BASE_URL is not set.
+func invitationValues(guest *guestModel.Guest, event *eventModel.Event) (*mergefield.Values, error) {
+	rsvpURL := ""
+	if baseURL := utils.GetEnv("RSVP_BASE_URL", ""); baseURL != "" {
+		token, err := utils.SignToken(constant.TokenPurposeRsvp, guest.ProjectID, guest.EventId, guest.GuestID)
+		if err != nil {
+			return nil, err
+		}
+		rsvpURL = strings.TrimRight(baseURL, "/") + "/" + token
+	}
+
+	values := &mergefield.Values{
+		Name:         guest.Name,
+		Address:      guest.Address,
+		Email:        guest.Email,
+		Phone:        guest.Phone,
+		EventName:    event.EventName,
+		RsvpUrl:      rsvpURL,
+		GuestData:    guest.GuestData,
+		EventOptions: event.EventOptions,
+	}
+	return values, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+
+	"rawuh-service/internal/shared/mergefield"
+)
+
+// compiledInvitation is a template with its subject and bodies parsed.
+type compiledInvitation struct {
+	subject mergefield.Template
+	html    mergefield.Template
+	text    mergefield.Template
+}
+
+func compileInvitation(subject, htmlBody, textBody string) (*compiledInvitation, error) {
+	var c compiledInvitation
+	var err error
+	if c.subject, err = mergefield.Parse(subject); err != nil {
+		return nil, fmt.Errorf("Subject: %w", err)
+	}
+	if c.html, err = mergefield.Parse(htmlBody); err != nil {
+		return nil, fmt.Errorf("HtmlBody: %w", err)
+	}
+	if c.text, err = mergefield.Parse(textBody); err != nil {
+		return nil, fmt.Errorf("TextBody: %w", err)
+	}
+	return &c, nil
+}
+
+// render returns the subject, HTML body and text body for values. Values
+// are HTML escaped in the HTML body only.
+func (c *compiledInvitation) render(values *mergefield.Values) (string, string, string) {
+	return c.subject.Render(values), c.html.RenderHTML(values), c.text.Render(values)
+}
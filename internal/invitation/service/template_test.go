@@ -0,0 +1,107 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"rawuh-service/internal/shared/mergefield"
+)
+
+func TestRenderInvitation(t *testing.T) {
+	values := &mergefield.Values{
+		Name:         "Budi & Sari",
+		Email:        "budi@example.com",
+		EventName:    "Pernikahan",
+		RsvpUrl:      "https://rawuh.test/rsvp/abc",
+		GuestData:    `{"table": 5, "side": "groom", "diet": ["vegan", "no nuts"], "seat": {"row": "B"}, "vip": true}`,
+		EventOptions: `{"venue": "Gedung <Sate>"}`,
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		text     string
+		html     string
+	}{
+		{
+			name:     "standard fields",
+			template: "{{Name}} <{{Email}}> to {{EventName}}",
+			text:     "Budi & Sari <budi@example.com> to Pernikahan",
+			html:     "Budi &amp; Sari <budi@example.com> to Pernikahan",
+		},
+		{
+			name:     "case and spaces are ignored",
+			template: "{{ name }} {{RSVPURL}}",
+			text:     "Budi & Sari https://rawuh.test/rsvp/abc",
+			html:     "Budi &amp; Sari https://rawuh.test/rsvp/abc",
+		},
+		{
+			name:     "guest data keys",
+			template: "table {{GuestData.table}}, {{GuestData.side}}, row {{GuestData.seat.row}}, vip {{GuestData.vip}}",
+			text:     "table 5, groom, row B, vip true",
+			html:     "table 5, groom, row B, vip true",
+		},
+		{
+			name:     "lists are joined",
+			template: "{{GuestData.diet}}",
+			text:     "vegan, no nuts",
+			html:     "vegan, no nuts",
+		},
+		{
+			name:     "event options are escaped in HTML only",
+			template: "<b>{{EventOptions.venue}}</b>",
+			text:     "<b>Gedung <Sate></b>",
+			html:     "<b>Gedung &lt;Sate&gt;</b>",
+		},
+		{
+			name:     "missing keys render as nothing",
+			template: "[{{GuestData.missing}}][{{GuestData.seat.row.x}}][{{Phone}}]",
+			text:     "[][][]",
+			html:     "[][][]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := compileInvitation(tt.template, tt.template, tt.template)
+			if err != nil {
+				t.Fatalf("compileInvitation: %v", err)
+			}
+
+			subject, html, text := c.render(values)
+			if subject != tt.text {
+				t.Errorf("subject = %q, want %q", subject, tt.text)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if html != tt.html {
+				t.Errorf("html = %q, want %q", html, tt.html)
+			}
+		})
+	}
+}
+
+func TestCompileInvitationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		html    string
+		text    string
+		want    string
+	}{
+		{"unknown field in subject", "{{Nmae}}", "", "", "Subject: unknown merge field {{Nmae}}"},
+		{"key on a plain field", "", "{{Name.first}}", "", "HtmlBody: merge field {{Name}} has no keys"},
+		{"guest data without key", "", "", "{{GuestData}}", "TextBody: merge field {{GuestData}} needs a key"},
+		{"empty key", "", "", "{{GuestData..table}}", "TextBody: merge field {{GuestData..table}} has an empty key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileInvitation(tt.subject, tt.html, tt.text)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("compileInvitation = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	jobModel "rawuh-service/internal/job/model"
+	jobService "rawuh-service/internal/job/service"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/middleware"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type JobHandler struct {
+	svc jobService.JobService
+}
+
+func NewJobHandler(svc jobService.JobService) *JobHandler {
+	return &JobHandler{
+		svc: svc,
+	}
+}
+
+// ListJobs godoc
+// @Summary List queued jobs
+// @Description Get paginated list of the background jobs waiting or running, the first due first. System admins only.
+// @Tags job
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param dir query string false "asc (default) or desc"
+// @Param kind query string false "job kind, such as deliveries.cancel"
+// @Param status query string false "PENDING or RUNNING"
+// @Success 200 {object} jobModel.ListJobsResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /jobs/list [get]
+
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	page, _ := strconv.Atoi(queryParams.Get("page"))
+	limit, _ := strconv.Atoi(queryParams.Get("limit"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := &jobModel.ListJobsRequest{
+		Page:   int32(page),
+		Limit:  int32(limit),
+		Dir:    queryParams.Get("dir"),
+		Cursor: utils.QueryCursor(queryParams),
+		Count:  queryParams.Get("count"),
+		Kind:   queryParams.Get("kind"),
+		Status: queryParams.Get("status"),
+	}
+
+	jobs, err := h.svc.ListJobs(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// ListDeadJobs godoc
+// @Summary List dead jobs
+// @Description Get paginated list of the background jobs that failed every attempt, the last failed first. System admins only.
+// @Tags job
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param dir query string false "asc or desc (default)"
+// @Param kind query string false "job kind, such as deliveries.cancel"
+// @Success 200 {object} jobModel.ListDeadJobsResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /jobs/dead [get]
+
+func (h *JobHandler) ListDeadJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	page, _ := strconv.Atoi(queryParams.Get("page"))
+	limit, _ := strconv.Atoi(queryParams.Get("limit"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := &jobModel.ListDeadJobsRequest{
+		Page:   int32(page),
+		Limit:  int32(limit),
+		Dir:    queryParams.Get("dir"),
+		Cursor: utils.QueryCursor(queryParams),
+		Count:  queryParams.Get("count"),
+		Kind:   queryParams.Get("kind"),
+	}
+
+	jobs, err := h.svc.ListDeadJobs(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// GetDeadJob godoc
+// @Summary Get a dead job
+// @Description Get a dead job with its payload and the error of its last attempt. System admins only.
+// @Tags job
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param job_id path string true "job id"
+// @Success 200 {object} jobModel.GetDeadJobResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /jobs/dead/{job_id} [get]
+
+func (h *JobHandler) GetDeadJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &jobModel.GetDeadJobRequest{
+		JobID: mux.Vars(r)["job_id"],
+	}
+
+	job, err := h.svc.GetDeadJob(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// RetryDeadJob godoc
+// @Summary Retry a dead job
+// @Description Put a dead job back in the queue under the same id, with its attempts reset. System admins only.
+// @Tags job
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param job_id path string true "job id"
+// @Success 200 {object} jobModel.RetryDeadJobResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /jobs/dead/{job_id}/retry [post]
+
+func (h *JobHandler) RetryDeadJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &jobModel.RetryDeadJobRequest{
+		JobID: mux.Vars(r)["job_id"],
+	}
+
+	job, err := h.svc.RetryDeadJob(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// DeleteDeadJob godoc
+// @Summary Delete a dead job
+// @Description Drop a dead job for good. System admins only.
+// @Tags job
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param job_id path string true "job id"
+// @Success 200 {object} jobModel.DeleteDeadJobResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /jobs/dead/{job_id} [delete]
+
+func (h *JobHandler) DeleteDeadJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &jobModel.DeleteDeadJobRequest{
+		JobID: mux.Vars(r)["job_id"],
+	}
+
+	result, err := h.svc.DeleteDeadJob(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
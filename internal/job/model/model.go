@@ -0,0 +1,75 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work of a Kind, with the Payload its handler
+// reads. It is PENDING until RunAt, RUNNING while a worker holds it, and
+// removed once it succeeded. A job that failed every attempt moves to the
+// dead jobs.
+type Job struct {
+	JobID     int64      `gorm:"primaryKey;autoIncrement"`
+	Kind      string     `gorm:"type:varchar(100)"`
+	Payload   JSON       `gorm:"type:jsonb"`
+	Status    string     `gorm:"type:varchar(20)"`
+	Attempts  int32      `gorm:"type:integer"`
+	LastError string     `gorm:"type:text"`
+	RunAt     *time.Time `gorm:"type:timestamp"`
+	LockedBy  string     `gorm:"type:varchar(200)"`
+	LockedAt  *time.Time `gorm:"type:timestamp"`
+	CreatedAt *time.Time `gorm:"type:timestamp"`
+	UpdatedAt *time.Time `gorm:"type:timestamp"`
+}
+
+// DeadJob is a job that failed every attempt. LastError holds the reason of
+// the last failure; retrying puts it back in the queue under the same id.
+type DeadJob struct {
+	JobID     int64      `gorm:"primaryKey"`
+	Kind      string     `gorm:"type:varchar(100)"`
+	Payload   JSON       `gorm:"type:jsonb"`
+	Attempts  int32      `gorm:"type:integer"`
+	LastError string     `gorm:"type:text"`
+	CreatedAt *time.Time `gorm:"type:timestamp"`
+	FailedAt  *time.Time `gorm:"type:timestamp"`
+}
+
+// JSON is a jsonb column. It is written into API responses as JSON rather
+// than as a quoted string.
+type JSON string
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// JobFilter narrows ListJobs and ListDeadJobs down; zero fields match every
+// job.
+type JobFilter struct {
+	Kind   string
+	Status string
+}
+
+// CancelDeliveries is the payload of constant.JobKindCancelDeliveries. It
+// covers the guest GuestID, or every guest of the event EventID, or every
+// event of the project when both are 0.
+type CancelDeliveries struct {
+	ProjectID int64
+	EventID   int64
+	GuestID   int64
+	Reason    string
+}
+
+// PublishWebhook is the payload of constant.JobKindPublishWebhook: a change
+// of EventType to tell the webhooks of the project about, with Data as it
+// was committed.
+type PublishWebhook struct {
+	ProjectID  int64
+	EventID    int64
+	EventType  string
+	Data       json.RawMessage
+	OccurredAt time.Time
+}
@@ -0,0 +1,70 @@
+package model
+
+import "rawuh-service/internal/shared/model"
+
+type ListJobsRequest struct {
+	Page   int32
+	Limit  int32
+	Cursor *string
+	Count  string
+	Dir    string
+	Kind   string
+	Status string
+}
+
+type ListJobsResponse struct {
+	Error      bool
+	Code       int32
+	Message    string
+	Data       []*Job
+	Pagination *model.PaginationResponse
+}
+
+type ListDeadJobsRequest struct {
+	Page   int32
+	Limit  int32
+	Cursor *string
+	Count  string
+	Dir    string
+	Kind   string
+}
+
+type ListDeadJobsResponse struct {
+	Error      bool
+	Code       int32
+	Message    string
+	Data       []*DeadJob
+	Pagination *model.PaginationResponse
+}
+
+type GetDeadJobRequest struct {
+	JobID string
+}
+
+type GetDeadJobResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *DeadJob
+}
+
+type RetryDeadJobRequest struct {
+	JobID string
+}
+
+type RetryDeadJobResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Job
+}
+
+type DeleteDeadJobRequest struct {
+	JobID string
+}
+
+type DeleteDeadJobResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+}
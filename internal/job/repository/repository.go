@@ -0,0 +1,296 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	jobModel "rawuh-service/internal/job/model"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository struct {
+	provider *db.GormProvider
+}
+
+func NewJobRepository(provider *db.GormProvider) *JobRepository {
+	return &JobRepository{
+		provider: provider,
+	}
+}
+
+// Enqueue writes a job of kind in tx, the transaction of the change that
+// needs it, so the job exists if and only if the change is committed. The
+// worker runs it once tx commits.
+func Enqueue(tx *gorm.DB, kind string, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	job := &jobModel.Job{
+		Kind:      kind,
+		Payload:   jobModel.JSON(raw),
+		Status:    constant.JobStatusPending,
+		RunAt:     &now,
+		CreatedAt: &now,
+	}
+
+	return tx.Debug().Table("public.jobs").Omit("job_id").Create(job).Error
+}
+
+// ClaimJobs marks up to limit jobs that are due as RUNNING by workerID and
+// returns them. Jobs left RUNNING since before stale, by a worker that
+// stopped halfway, are claimed again. Rows claimed by another worker are
+// skipped, so several workers can run at once.
+func (p *JobRepository) ClaimJobs(ctx context.Context, workerID string, limit int, stale time.Time) ([]*jobModel.Job, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	now := time.Now()
+	var data []*jobModel.Job
+
+	err := p.provider.GetDB().WithContext(timeoutctx).Debug().Raw(`
+		UPDATE public.jobs
+		SET status = ?, attempts = attempts + 1, locked_by = ?, locked_at = ?, updated_at = ?
+		WHERE job_id IN (
+			SELECT job_id FROM public.jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)
+			ORDER BY run_at, job_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		constant.JobStatusRunning, workerID, now, now,
+		constant.JobStatusPending, now, constant.JobStatusRunning, stale,
+		limit,
+	).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// CompleteJob removes a job that ran. It does nothing when workerID no
+// longer holds the job.
+func (p *JobRepository) CompleteJob(ctx context.Context, jobID int64, workerID string) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.jobs")
+
+	query = query.Where("job_id = ? AND status = ? AND locked_by = ?", jobID, constant.JobStatusRunning, workerID)
+
+	return query.Delete(&jobModel.Job{}).Error
+}
+
+// RescheduleJob puts a job that failed back in the queue until runAt. It
+// does nothing when workerID no longer holds the job.
+func (p *JobRepository) RescheduleJob(ctx context.Context, jobID int64, workerID string, lastError string, runAt time.Time) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.jobs")
+
+	query = query.Where("job_id = ? AND status = ? AND locked_by = ?", jobID, constant.JobStatusRunning, workerID)
+
+	return query.Updates(map[string]interface{}{
+		"status":     constant.JobStatusPending,
+		"last_error": lastError,
+		"run_at":     runAt,
+		"locked_by":  "",
+		"locked_at":  nil,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// BuryJob moves a job that failed for good to the dead jobs. It does
+// nothing when workerID no longer holds the job.
+func (p *JobRepository) BuryJob(ctx context.Context, job *jobModel.Job, workerID string, lastError string) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	res := tx.Debug().Table("public.jobs").
+		Where("job_id = ? AND status = ? AND locked_by = ?", job.JobID, constant.JobStatusRunning, workerID).
+		Delete(&jobModel.Job{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return tx.Commit().Error
+	}
+
+	now := time.Now()
+	dead := &jobModel.DeadJob{
+		JobID:     job.JobID,
+		Kind:      job.Kind,
+		Payload:   job.Payload,
+		Attempts:  job.Attempts,
+		LastError: lastError,
+		CreatedAt: job.CreatedAt,
+		FailedAt:  &now,
+	}
+	if err = tx.Debug().Table("public.dead_jobs").Create(dead).Error; err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (p *JobRepository) ListJobs(ctx context.Context, filter *jobModel.JobFilter, pagination *model.PaginationResponse, sort *model.Sort) (data []*jobModel.Job, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.jobs")
+
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if pagination.Cursor != nil {
+		return db.Keyset[jobModel.Job](query, pagination, sort, "job_id")
+	}
+
+	query = query.Scopes(db.Paginate(data, pagination, query))
+	query = query.Scopes(
+		db.Sort(sort),
+	)
+
+	if err := query.Order("job_id " + sort.Direction).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (p *JobRepository) ListDeadJobs(ctx context.Context, filter *jobModel.JobFilter, pagination *model.PaginationResponse, sort *model.Sort) (data []*jobModel.DeadJob, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.dead_jobs")
+
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+
+	if pagination.Cursor != nil {
+		return db.Keyset[jobModel.DeadJob](query, pagination, sort, "job_id")
+	}
+
+	query = query.Scopes(db.Paginate(data, pagination, query))
+	query = query.Scopes(
+		db.Sort(sort),
+	)
+
+	if err := query.Order("job_id " + sort.Direction).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (p *JobRepository) GetDeadJob(ctx context.Context, jobID int64) (*jobModel.DeadJob, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data jobModel.DeadJob
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.dead_jobs")
+
+	if err := query.Where("job_id = ?", jobID).Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// RetryDeadJob moves a dead job back to the queue under the same id, with
+// its attempts reset, and returns it. It returns gorm.ErrRecordNotFound when
+// there is no such dead job.
+func (p *JobRepository) RetryDeadJob(ctx context.Context, jobID int64) (job *jobModel.Job, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var dead jobModel.DeadJob
+	err = tx.Debug().Table("public.dead_jobs").
+		Where("job_id = ?", jobID).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Take(&dead).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Debug().Table("public.dead_jobs").Where("job_id = ?", jobID).Delete(&jobModel.DeadJob{}).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job = &jobModel.Job{
+		JobID:     dead.JobID,
+		Kind:      dead.Kind,
+		Payload:   dead.Payload,
+		Status:    constant.JobStatusPending,
+		LastError: dead.LastError,
+		RunAt:     &now,
+		CreatedAt: dead.CreatedAt,
+		UpdatedAt: &now,
+	}
+	if err = tx.Debug().Table("public.jobs").Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	return job, tx.Commit().Error
+}
+
+// DeleteDeadJob drops a dead job for good. It returns gorm.ErrRecordNotFound
+// when there is no such dead job.
+func (p *JobRepository) DeleteDeadJob(ctx context.Context, jobID int64) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.dead_jobs")
+
+	res := query.Where("job_id = ?", jobID).Delete(&jobModel.DeadJob{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	invitationDb "rawuh-service/internal/invitation/repository"
+	jobModel "rawuh-service/internal/job/model"
+	messageDb "rawuh-service/internal/message/repository"
+	webhookDb "rawuh-service/internal/webhook/repository"
+)
+
+// CancelDeliveries handles constant.JobKindCancelDeliveries: the invitations
+// and messages still waiting for guests that were deleted are failed rather
+// than sent.
+func CancelDeliveries(invitationDB *invitationDb.InvitationRepository, messageDB *messageDb.MessageRepository) Handler {
+	return func(ctx context.Context, job *jobModel.Job) error {
+		var payload jobModel.CancelDeliveries
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+
+		if _, err := invitationDB.CancelDeliveries(ctx, payload.ProjectID, payload.EventID, payload.GuestID, payload.Reason); err != nil {
+			return fmt.Errorf("cancel invitations: %w", err)
+		}
+		if _, err := messageDB.CancelMessages(ctx, payload.ProjectID, payload.EventID, payload.GuestID, payload.Reason); err != nil {
+			return fmt.Errorf("cancel messages: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// PublishWebhook handles constant.JobKindPublishWebhook: the change is queued
+// as a delivery for every webhook subscribed to it, for the dispatcher to
+// send.
+func PublishWebhook(webhookDB *webhookDb.WebhookRepository) Handler {
+	return func(ctx context.Context, job *jobModel.Job) error {
+		var payload jobModel.PublishWebhook
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+
+		if _, err := webhookDB.CreateJobDeliveries(ctx, job.JobID, &payload); err != nil {
+			return fmt.Errorf("create deliveries: %w", err)
+		}
+
+		return nil
+	}
+}
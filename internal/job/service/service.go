@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
+	jobModel "rawuh-service/internal/job/model"
+	jobDb "rawuh-service/internal/job/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/model"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// JobService lets system admins look into the job queue and retry or drop
+// dead jobs. Jobs themselves are run by a Worker.
+type JobService interface {
+	ListJobs(ctx context.Context, req *jobModel.ListJobsRequest) (*jobModel.ListJobsResponse, error)
+	ListDeadJobs(ctx context.Context, req *jobModel.ListDeadJobsRequest) (*jobModel.ListDeadJobsResponse, error)
+	GetDeadJob(ctx context.Context, req *jobModel.GetDeadJobRequest) (*jobModel.GetDeadJobResponse, error)
+	RetryDeadJob(ctx context.Context, req *jobModel.RetryDeadJobRequest) (*jobModel.RetryDeadJobResponse, error)
+	DeleteDeadJob(ctx context.Context, req *jobModel.DeleteDeadJobRequest) (*jobModel.DeleteDeadJobResponse, error)
+}
+
+type jobService struct {
+	dbProvider *jobDb.JobRepository
+	audit      auditService.AuditService
+	logger     *logger.Logger
+}
+
+func NewJobService(dbProvider *jobDb.JobRepository, audit auditService.AuditService, logger *logger.Logger) JobService {
+	return &jobService{
+		dbProvider: dbProvider,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// ListJobs lists the jobs waiting or running, the oldest due first.
+func (s *jobService) ListJobs(ctx context.Context, req *jobModel.ListJobsRequest) (*jobModel.ListJobsResponse, error) {
+	funcName := "ListJobs"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.JobManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	filter := &jobModel.JobFilter{
+		Kind:   req.Kind,
+		Status: strings.ToUpper(req.Status),
+	}
+	switch filter.Status {
+	case "", constant.JobStatusPending, constant.JobStatusRunning:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %s or %s", constant.JobStatusPending, constant.JobStatusRunning)
+	}
+
+	sort, err := jobSort(req.Dir, "run_at", "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	loggerZap.Info("Start ListJobs")
+	jobs, err := s.dbProvider.ListJobs(ctx, filter, pagination, sort)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		loggerZap.Error("err ListJobs ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &jobModel.ListJobsResponse{
+		Error:      false,
+		Code:       http.StatusOK,
+		Message:    "Success",
+		Data:       jobs,
+		Pagination: pagination,
+	}
+
+	return result, nil
+}
+
+// ListDeadJobs lists the jobs that failed every attempt, the last failed
+// first.
+func (s *jobService) ListDeadJobs(ctx context.Context, req *jobModel.ListDeadJobsRequest) (*jobModel.ListDeadJobsResponse, error) {
+	funcName := "ListDeadJobs"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.JobManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	filter := &jobModel.JobFilter{
+		Kind: req.Kind,
+	}
+
+	sort, err := jobSort(req.Dir, "failed_at", "desc")
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	loggerZap.Info("Start ListDeadJobs")
+	jobs, err := s.dbProvider.ListDeadJobs(ctx, filter, pagination, sort)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		loggerZap.Error("err ListDeadJobs ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &jobModel.ListDeadJobsResponse{
+		Error:      false,
+		Code:       http.StatusOK,
+		Message:    "Success",
+		Data:       jobs,
+		Pagination: pagination,
+	}
+
+	return result, nil
+}
+
+func (s *jobService) GetDeadJob(ctx context.Context, req *jobModel.GetDeadJobRequest) (*jobModel.GetDeadJobResponse, error) {
+	funcName := "GetDeadJob"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.JobManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	jobID, err := strconv.ParseInt(req.JobID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Job Id")
+	}
+
+	loggerZap.Info("Start GetDeadJob")
+	job, err := s.dbProvider.GetDeadJob(ctx, jobID)
+	if err != nil {
+		loggerZap.Error("err GetDeadJob ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if job == nil {
+		return nil, status.Errorf(codes.NotFound, "dead job not found")
+	}
+
+	result := &jobModel.GetDeadJobResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    job,
+	}
+
+	return result, nil
+}
+
+// RetryDeadJob puts a dead job back in the queue with its attempts reset.
+// Workers pick it up on their next poll.
+func (s *jobService) RetryDeadJob(ctx context.Context, req *jobModel.RetryDeadJobRequest) (*jobModel.RetryDeadJobResponse, error) {
+	funcName := "RetryDeadJob"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.JobManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	jobID, err := strconv.ParseInt(req.JobID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Job Id")
+	}
+
+	loggerZap.Info("Start RetryDeadJob")
+	job, err := s.dbProvider.RetryDeadJob(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "dead job not found")
+		}
+		loggerZap.Error("err RetryDeadJob ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionRetry,
+		EntityType: constant.AuditEntityJob,
+		EntityID:   req.JobID,
+		After:      job,
+	})
+
+	result := &jobModel.RetryDeadJobResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success retry job",
+		Data:    job,
+	}
+
+	return result, nil
+}
+
+// DeleteDeadJob drops a dead job for good.
+func (s *jobService) DeleteDeadJob(ctx context.Context, req *jobModel.DeleteDeadJobRequest) (*jobModel.DeleteDeadJobResponse, error) {
+	funcName := "DeleteDeadJob"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.JobManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	jobID, err := strconv.ParseInt(req.JobID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Job Id")
+	}
+
+	before, err := s.dbProvider.GetDeadJob(ctx, jobID)
+	if err != nil {
+		loggerZap.Error("err GetDeadJob ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if before == nil {
+		return nil, status.Errorf(codes.NotFound, "dead job not found")
+	}
+
+	loggerZap.Info("Start DeleteDeadJob")
+	if err := s.dbProvider.DeleteDeadJob(ctx, jobID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "dead job not found")
+		}
+		loggerZap.Error("err DeleteDeadJob ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityJob,
+		EntityID:   req.JobID,
+		Before:     before,
+	})
+
+	result := &jobModel.DeleteDeadJobResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success delete job",
+	}
+
+	return result, nil
+}
+
+// jobSort sorts on column in dir, or in def when dir is empty.
+func jobSort(dir string, column string, def string) (*model.Sort, error) {
+	direction := strings.ToLower(dir)
+	switch direction {
+	case "":
+		direction = def
+	case "asc", "desc":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+	}
+	return &model.Sort{Column: column, Direction: direction}, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	jobModel "rawuh-service/internal/job/model"
+	jobDb "rawuh-service/internal/job/repository"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+
+	"github.com/google/uuid"
+)
+
+// Handler runs one job. An error puts the job back in the queue, or buries
+// it once it is out of attempts. Handlers may run more than once for the same
+// job, so they have to be safe to repeat.
+type Handler func(ctx context.Context, job *jobModel.Job) error
+
+type WorkerConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	RetryDelay  time.Duration
+	Timeout     time.Duration
+}
+
+// WorkerConfigFromEnv reads the job worker settings:
+//
+//	JOB_POLL_INTERVAL  1s, how often the queue is polled when it is empty
+//	JOB_BATCH_SIZE     10 jobs claimed at a time
+//	JOB_MAX_ATTEMPTS   5 attempts before a job is dead
+//	JOB_RETRY_DELAY    10s before the second attempt, doubled for each one after
+//	JOB_TIMEOUT        5m for one job
+func WorkerConfigFromEnv() (WorkerConfig, error) {
+	cfg := WorkerConfig{}
+
+	interval, err := time.ParseDuration(utils.GetEnv("JOB_POLL_INTERVAL", "1s"))
+	if err != nil || interval <= 0 {
+		return cfg, fmt.Errorf("invalid JOB_POLL_INTERVAL")
+	}
+	batchSize, err := strconv.Atoi(utils.GetEnv("JOB_BATCH_SIZE", "10"))
+	if err != nil || batchSize <= 0 {
+		return cfg, fmt.Errorf("invalid JOB_BATCH_SIZE")
+	}
+	maxAttempts, err := strconv.Atoi(utils.GetEnv("JOB_MAX_ATTEMPTS", "5"))
+	if err != nil || maxAttempts <= 0 {
+		return cfg, fmt.Errorf("invalid JOB_MAX_ATTEMPTS")
+	}
+	retryDelay, err := time.ParseDuration(utils.GetEnv("JOB_RETRY_DELAY", "10s"))
+	if err != nil || retryDelay <= 0 {
+		return cfg, fmt.Errorf("invalid JOB_RETRY_DELAY")
+	}
+	timeout, err := time.ParseDuration(utils.GetEnv("JOB_TIMEOUT", "5m"))
+	if err != nil || timeout <= 0 {
+		return cfg, fmt.Errorf("invalid JOB_TIMEOUT")
+	}
+
+	cfg.Interval = interval
+	cfg.BatchSize = batchSize
+	cfg.MaxAttempts = maxAttempts
+	cfg.RetryDelay = retryDelay
+	cfg.Timeout = timeout
+
+	return cfg, nil
+}
+
+// Worker runs the queued jobs with the handler registered for their kind.
+// Jobs are claimed with SKIP LOCKED, so any number of workers can share the
+// queue. A job is run at least once: if the worker stops halfway, the job is
+// claimed again once its lock is older than the job timeout.
+type Worker struct {
+	dbProvider *jobDb.JobRepository
+	handlers   map[string]Handler
+	id         string
+	cfg        WorkerConfig
+	logger     *logger.Logger
+}
+
+func NewWorker(dbProvider *jobDb.JobRepository, cfg WorkerConfig, logger *logger.Logger) *Worker {
+	id := uuid.NewString()
+	if hostname, err := os.Hostname(); err == nil {
+		id = fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	}
+
+	return &Worker{
+		dbProvider: dbProvider,
+		handlers:   map[string]Handler{},
+		id:         id,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Register sets the handler of kind. It is not safe to call once the worker
+// started.
+func (w *Worker) Register(kind string, handler Handler) {
+	w.handlers[kind] = handler
+}
+
+// Run claims due jobs and runs them until none are left, and returns how
+// many succeeded.
+func (w *Worker) Run(ctx context.Context) (int, error) {
+	// a job RUNNING for longer than a whole batch of jobs can take belongs
+	// to a worker that stopped halfway
+	stale := time.Duration(w.cfg.BatchSize)*w.cfg.Timeout + time.Minute
+
+	done := 0
+	for {
+		jobs, err := w.dbProvider.ClaimJobs(ctx, w.id, w.cfg.BatchSize, time.Now().Add(-stale))
+		if err != nil {
+			return done, fmt.Errorf("claim jobs: %w", err)
+		}
+
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				return done, ctx.Err()
+			}
+			if w.run(ctx, job) {
+				done++
+			}
+		}
+
+		if len(jobs) < w.cfg.BatchSize {
+			return done, nil
+		}
+	}
+}
+
+// Start runs the worker every interval until ctx is done.
+func (w *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			done, err := w.Run(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				w.logger.Error("err RunJobs ", err)
+				continue
+			}
+			if done > 0 {
+				w.logger.Info("Success RunJobs ", done)
+			}
+		}
+	}
+}
+
+// run runs one claimed job and stores its outcome. It reports whether the
+// job succeeded.
+func (w *Worker) run(ctx context.Context, job *jobModel.Job) bool {
+	start := time.Now()
+	queueMessage := fmt.Sprintf("job %s %d attempt %d", job.Kind, job.JobID, job.Attempts)
+
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		w.logger.QueueMessageError(queueMessage+" no handler", job, time.Since(start))
+		w.bury(ctx, job, "no handler for job kind "+job.Kind)
+		return false
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	err := handler(runCtx, job)
+	cancel()
+
+	if err == nil {
+		w.logger.QueueMessageInfo(queueMessage+" done", job, time.Since(start))
+		if err := w.dbProvider.CompleteJob(ctx, job.JobID, w.id); err != nil {
+			w.logger.Error("err CompleteJob ", err)
+		}
+		return true
+	}
+
+	w.logger.QueueMessageError(queueMessage+" failed: "+err.Error(), job, time.Since(start))
+	if int(job.Attempts) >= w.cfg.MaxAttempts {
+		w.bury(ctx, job, err.Error())
+		return false
+	}
+
+	// 10s, 20s, 40s... capped so the shift cannot overflow
+	delay := w.cfg.RetryDelay << min(job.Attempts-1, 16)
+	if err := w.dbProvider.RescheduleJob(ctx, job.JobID, w.id, err.Error(), time.Now().Add(delay)); err != nil {
+		w.logger.Error("err RescheduleJob ", err)
+	}
+	return false
+}
+
+func (w *Worker) bury(ctx context.Context, job *jobModel.Job, lastError string) {
+	if err := w.dbProvider.BuryJob(ctx, job, w.id, lastError); err != nil {
+		w.logger.Error("err BuryJob ", err)
+	}
+}
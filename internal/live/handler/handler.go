@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	liveModel "rawuh-service/internal/live/model"
+	liveService "rawuh-service/internal/live/service"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/middleware"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// heartbeat is how often an idle stream sends a comment, so proxies and load
+// balancers do not close it, and checks that its token is still valid.
+const heartbeat = 15 * time.Second
+
+type LiveHandler struct {
+	svc      liveService.LiveService
+	sessions middleware.Verifier
+}
+
+func NewLiveHandler(svc liveService.LiveService, sessions middleware.Verifier) *LiveHandler {
+	return &LiveHandler{
+		svc:      svc,
+		sessions: sessions,
+	}
+}
+
+// StreamEvent godoc
+// @Summary Stream the live dashboard of an event
+// @Description Server-Sent Events for a dashboard of the event. The first event is a snapshot with the counters and the latest check-ins, then every guest.created, guest.updated and guest.checked_in comes with the guest and the counters, and every other change to the guests as a counts event with the counters only. The stream ends when the session does; reconnect with a fresh token.
+// @Tags live
+// @Produce text/event-stream
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param limit query int false "latest check-ins in the snapshot, default 10, at most 50"
+// @Success 200 {object} liveModel.Snapshot
+// @Failure 403 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/live [get]
+
+func (h *LiveHandler) StreamEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	req := &liveModel.SubscribeRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventID:   mux.Vars(r)["event_id"],
+		Limit:     int32(limit),
+	}
+
+	sub, err := h.svc.Subscribe(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// keeps nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// EventSource clients reconnect after this many milliseconds
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return
+	}
+	if err := writeEvent(w, sub.Snapshot); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	token := middleware.BearerToken(r)
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-sub.Messages:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, message); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			// a session that ended, was revoked or lost its roles ends
+			// the stream too; a failed check leaves it open
+			if payload, err := h.sessions.Verify(ctx, token); err == nil && payload == nil {
+				return
+			}
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, message *liveModel.Message) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", message.Event, message.Data)
+	return err
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Counts are the counters of an event dashboard. Deleted guests are not
+// counted.
+type Counts struct {
+	Guests    int64 // guests of the event
+	CheckedIn int64 // guests checked in
+	Arrived   int64 // people checked in, the guests and their companions
+	Accepted  int64 // guests that accepted the RSVP
+	Declined  int64 // guests that declined it
+	Pending   int64 // guests that have not answered
+	Expected  int64 // attendees of the guests that accepted
+}
+
+// Guest is the part of a guest a dashboard shows.
+type Guest struct {
+	GuestID         int64
+	Name            string
+	CheckedInAt     *time.Time
+	CheckedInByName string
+	CompanionCount  int32
+	RsvpStatus      string
+	RsvpAttendees   int32
+}
+
+// Update is published on every change to the guests of an event, with the
+// counters as they were right after it. Guest is nil for changes that only
+// move the counters, such as a delete or an import.
+type Update struct {
+	Type      string
+	ProjectID int64
+	EventID   int64
+	Guest     *Guest
+	Counts    *Counts
+	At        time.Time
+}
+
+// Snapshot is the first message of a stream: the counters and the guests
+// checked in last, newest first.
+type Snapshot struct {
+	ProjectID      int64
+	EventID        int64
+	Counts         *Counts
+	RecentCheckIns []*Guest
+	At             time.Time
+}
+
+// Message is one event of a stream, Data being its JSON.
+type Message struct {
+	Event string
+	Data  []byte
+}
@@ -0,0 +1,9 @@
+package model
+
+// SubscribeRequest opens the stream of an event. Limit is how many of the
+// latest check-ins the snapshot holds.
+type SubscribeRequest struct {
+	ProjectID string
+	EventID   string
+	Limit     int32
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+
+	liveModel "rawuh-service/internal/live/model"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+)
+
+type LiveRepository struct {
+	provider *db.GormProvider
+}
+
+func NewLiveRepository(provider *db.GormProvider) *LiveRepository {
+	return &LiveRepository{
+		provider: provider,
+	}
+}
+
+func (p *LiveRepository) EventExists(ctx context.Context, projectID int64, eventID int64) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var count int64
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetCounts counts the guests of an event in one pass. A guest without an
+// RSVP status has not answered yet.
+func (p *LiveRepository) GetCounts(ctx context.Context, projectID int64, eventID int64) (*liveModel.Counts, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data liveModel.Counts
+
+	err := p.provider.GetDB().WithContext(timeoutctx).Debug().Raw(`
+		SELECT
+			COUNT(*) AS guests,
+			COUNT(checked_in_at) AS checked_in,
+			COALESCE(SUM(1 + companion_count) FILTER (WHERE checked_in_at IS NOT NULL), 0) AS arrived,
+			COUNT(*) FILTER (WHERE rsvp_status = ?) AS accepted,
+			COUNT(*) FILTER (WHERE rsvp_status = ?) AS declined,
+			COUNT(*) FILTER (WHERE rsvp_status NOT IN (?, ?)) AS pending,
+			COALESCE(SUM(rsvp_attendees) FILTER (WHERE rsvp_status = ?), 0) AS expected
+		FROM public.guests
+		WHERE project_id = ? AND event_id = ? AND deleted_at IS NULL`,
+		constant.RsvpStatusAccepted,
+		constant.RsvpStatusDeclined,
+		constant.RsvpStatusAccepted, constant.RsvpStatusDeclined,
+		constant.RsvpStatusAccepted,
+		projectID, eventID,
+	).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// ListRecentCheckIns returns the limit guests of an event checked in last,
+// newest first.
+func (p *LiveRepository) ListRecentCheckIns(ctx context.Context, projectID int64, eventID int64, limit int) ([]*liveModel.Guest, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND checked_in_at IS NOT NULL AND deleted_at IS NULL", projectID, eventID)
+
+	var data []*liveModel.Guest
+	err := query.Select("guest_id, name, checked_in_at, checked_in_by_name, companion_count, rsvp_status, rsvp_attendees").
+		Order("checked_in_at DESC, guest_id DESC").
+		Limit(limit).
+		Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
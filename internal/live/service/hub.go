@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	liveModel "rawuh-service/internal/live/model"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// streamBuffer is how many updates a stream may fall behind before it is
+// dropped.
+const streamBuffer = 64
+
+// Hub fans the updates published through Redis out to the streams open on
+// this server, so a change made on one server reaches the dashboards
+// connected to any of them. It holds a single Redis connection, subscribed
+// to the channels of the events that have a stream open here.
+type Hub struct {
+	rdb    *redis.Redis
+	pubsub *goredis.PubSub
+	logger *logger.Logger
+
+	mu      sync.Mutex
+	streams map[string]map[*stream]struct{}
+}
+
+type stream struct {
+	channel  string
+	messages chan *liveModel.Message
+}
+
+func NewHub(rdb *redis.Redis, logger *logger.Logger) *Hub {
+	return &Hub{
+		rdb:     rdb,
+		pubsub:  rdb.Subscribe(context.Background()),
+		logger:  logger,
+		streams: map[string]map[*stream]struct{}{},
+	}
+}
+
+// channelName is the Redis channel of an event. It names the project too,
+// so a stream opened under another project's id never hears of the event.
+func channelName(projectID int64, eventID int64) string {
+	return fmt.Sprintf("live:%d:%d", projectID, eventID)
+}
+
+func (h *Hub) publish(ctx context.Context, update *liveModel.Update) error {
+	return h.rdb.Publish(ctx, channelName(update.ProjectID, update.EventID), update)
+}
+
+// subscribe opens a stream on channel, subscribing to it in Redis when it is
+// the first one on this server.
+func (h *Hub) subscribe(ctx context.Context, channel string) (*stream, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.streams[channel]) == 0 {
+		if err := h.pubsub.Subscribe(ctx, channel); err != nil {
+			return nil, err
+		}
+		h.streams[channel] = map[*stream]struct{}{}
+	}
+
+	s := &stream{
+		channel:  channel,
+		messages: make(chan *liveModel.Message, streamBuffer),
+	}
+	h.streams[channel][s] = struct{}{}
+
+	return s, nil
+}
+
+// unsubscribe closes a stream, unsubscribing from its channel in Redis when
+// it was the last one on this server.
+func (h *Hub) unsubscribe(s *stream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.drop(s)
+
+	if _, ok := h.streams[s.channel]; ok && len(h.streams[s.channel]) == 0 {
+		delete(h.streams, s.channel)
+		if err := h.pubsub.Unsubscribe(context.Background(), s.channel); err != nil {
+			h.logger.Error("err Unsubscribe live ", err)
+		}
+	}
+}
+
+// drop closes the messages of a stream once. The caller holds mu.
+func (h *Hub) drop(s *stream) {
+	if _, ok := h.streams[s.channel][s]; !ok {
+		return
+	}
+	delete(h.streams[s.channel], s)
+	close(s.messages)
+}
+
+func (h *Hub) broadcast(msg *goredis.Message) {
+	var update struct {
+		Type string
+	}
+	if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+		h.logger.Error("err Unmarshal live update ", err)
+		return
+	}
+	message := &liveModel.Message{
+		Event: update.Type,
+		Data:  []byte(msg.Payload),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.streams[msg.Channel] {
+		select {
+		case s.messages <- message:
+		default:
+			// too far behind; the client reconnects and starts over from
+			// a snapshot
+			h.drop(s)
+		}
+	}
+}
+
+// Start passes on what Redis delivers until ctx is done.
+func (h *Hub) Start(ctx context.Context) {
+	messages := h.pubsub.Channel()
+	defer h.pubsub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			h.broadcast(msg)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	guestModel "rawuh-service/internal/guest/model"
+	liveModel "rawuh-service/internal/live/model"
+	liveDb "rawuh-service/internal/live/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/middleware"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRecentCheckIns = 10
+	maxRecentCheckIns     = 50
+)
+
+type LiveService interface {
+	Publish(ctx context.Context, projectID string, eventID string, eventType string, guest *guestModel.Guest)
+	Subscribe(ctx context.Context, req *liveModel.SubscribeRequest) (*Subscription, error)
+}
+
+// Subscription is an open stream of an event: the snapshot to send first,
+// then every update. Messages is closed when the stream falls too far
+// behind. Close it when the client goes away.
+type Subscription struct {
+	Snapshot *liveModel.Message
+	Messages <-chan *liveModel.Message
+
+	hub    *Hub
+	stream *stream
+}
+
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s.stream)
+}
+
+type liveService struct {
+	dbProvider *liveDb.LiveRepository
+	hub        *Hub
+	logger     *logger.Logger
+}
+
+func NewLiveService(dbProvider *liveDb.LiveRepository, hub *Hub, logger *logger.Logger) LiveService {
+	return &liveService{
+		dbProvider: dbProvider,
+		hub:        hub,
+		logger:     logger,
+	}
+}
+
+// Publish tells the dashboards of an event about a change to its guests,
+// with the counters as they are now. guest is nil for changes that only move
+// the counters. Like audit entries it runs after the change succeeded; a
+// failure is logged and does not fail the request.
+func (s *liveService) Publish(ctx context.Context, projectID string, eventID string, eventType string, guest *guestModel.Guest) {
+	span, ctx := apm.StartSpan(ctx, "PublishLive", constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil {
+		s.logger.Error("err PublishLive invalid project id ", err)
+		return
+	}
+	eid, err := strconv.ParseInt(eventID, 10, 64)
+	if err != nil {
+		s.logger.Error("err PublishLive invalid event id ", err)
+		return
+	}
+
+	counts, err := s.dbProvider.GetCounts(ctx, pid, eid)
+	if err != nil {
+		s.logger.Error("err GetCounts ", err)
+		return
+	}
+
+	update := &liveModel.Update{
+		Type:      eventType,
+		ProjectID: pid,
+		EventID:   eid,
+		Counts:    counts,
+		At:        time.Now(),
+	}
+	if guest != nil {
+		update.Guest = toLiveGuest(guest)
+	}
+
+	if err := s.hub.publish(ctx, update); err != nil {
+		s.logger.Error("err PublishLive ", err)
+	}
+}
+
+// Subscribe opens the stream of an event for anyone who may read its guests.
+// It listens before reading the snapshot, so no change made in between is
+// missed.
+func (s *liveService) Subscribe(ctx context.Context, req *liveModel.SubscribeRequest) (*Subscription, error) {
+	funcName := "Subscribe"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventID); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, err := strconv.ParseInt(req.ProjectID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+	eventID, err := strconv.ParseInt(req.EventID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Event Id")
+	}
+
+	exists, err := s.dbProvider.EventExists(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err EventExists ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if !exists {
+		return nil, status.Error(codes.NotFound, "Event not found")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultRecentCheckIns
+	}
+	limit = min(limit, maxRecentCheckIns)
+
+	stream, err := s.hub.subscribe(ctx, channelName(projectID, eventID))
+	if err != nil {
+		loggerZap.Error("err Subscribe live ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	sub := &Subscription{
+		Messages: stream.messages,
+		hub:      s.hub,
+		stream:   stream,
+	}
+
+	snapshot, err := s.snapshot(ctx, projectID, eventID, limit)
+	if err != nil {
+		sub.Close()
+		loggerZap.Error("err Snapshot live ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	sub.Snapshot = snapshot
+
+	loggerZap.Info("Success Subscribe")
+
+	return sub, nil
+}
+
+func (s *liveService) snapshot(ctx context.Context, projectID int64, eventID int64, limit int) (*liveModel.Message, error) {
+	counts, err := s.dbProvider.GetCounts(ctx, projectID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.dbProvider.ListRecentCheckIns(ctx, projectID, eventID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, guest := range recent {
+		guest.RsvpStatus = rsvpStatus(guest.RsvpStatus)
+	}
+
+	data, err := json.Marshal(&liveModel.Snapshot{
+		ProjectID:      projectID,
+		EventID:        eventID,
+		Counts:         counts,
+		RecentCheckIns: recent,
+		At:             time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &liveModel.Message{
+		Event: constant.LiveEventSnapshot,
+		Data:  data,
+	}, nil
+}
+
+func toLiveGuest(guest *guestModel.Guest) *liveModel.Guest {
+	return &liveModel.Guest{
+		GuestID:         guest.GuestID,
+		Name:            guest.Name,
+		CheckedInAt:     guest.CheckedInAt,
+		CheckedInByName: guest.CheckedInByName,
+		CompanionCount:  guest.CompanionCount,
+		RsvpStatus:      rsvpStatus(guest.RsvpStatus),
+		RsvpAttendees:   guest.RsvpAttendees,
+	}
+}
+
+// rsvpStatus shows guests that have not answered as PENDING.
+func rsvpStatus(status string) string {
+	if status == "" {
+		return constant.RsvpStatusPending
+	}
+	return status
+}
@@ -0,0 +1,466 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	messageModel "rawuh-service/internal/message/model"
+	messageService "rawuh-service/internal/message/service"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/middleware"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type MessageHandler struct {
+	svc messageService.MessageService
+}
+
+func NewMessageHandler(svc messageService.MessageService) *MessageHandler {
+	return &MessageHandler{
+		svc: svc,
+	}
+}
+
+// ListTemplates godoc
+// @Summary List message templates
+// @Description Get the WhatsApp and SMS templates of an event, by name.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Success 200 {object} messageModel.ListTemplatesResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/messages/templates [get]
+
+func (h *MessageHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &messageModel.ListTemplatesRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+	}
+
+	templates, err := h.svc.ListTemplates(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// GetTemplate godoc
+// @Summary Get a message template
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param template_id path string true "template id"
+// @Success 200 {object} messageModel.GetTemplateResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/messages/templates/{template_id} [get]
+
+func (h *MessageHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &messageModel.GetTemplateRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		EventId:    mux.Vars(r)["event_id"],
+		TemplateID: mux.Vars(r)["template_id"],
+	}
+
+	template, err := h.svc.GetTemplate(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// CreateTemplate godoc
+// @Summary Create a message template
+// @Description Add a WhatsApp or SMS text to the event. Name is unique per event. Body may use the merge fields {{Name}}, {{Address}}, {{Email}}, {{Phone}}, {{EventName}}, {{RsvpUrl}}, {{GuestData.key}} and {{EventOptions.key}}; unknown fields are rejected.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param body body messageModel.CreateTemplateRequest true "CreateTemplateRequest"
+// @Success 201 {object} messageModel.CreateTemplateResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 409 {object} utils.APIErrorResponse "name already used"
+// @Router /{project_id}/events/{event_id}/messages/templates [post]
+
+func (h *MessageHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &messageModel.CreateTemplateResponse{
+		Error:   false,
+		Code:    http.StatusCreated,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p messageModel.CreateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &messageModel.CreateTemplateRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		EventId:   mux.Vars(r)["event_id"],
+		Name:      p.Name,
+		Body:      p.Body,
+	}
+
+	template, err := h.svc.CreateTemplate(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// UpdateTemplate godoc
+// @Summary Update a message template
+// @Description Replace the name and body of a template. Messages already queued keep the text they were rendered with.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param template_id path string true "template id"
+// @Param body body messageModel.UpdateTemplateRequest true "UpdateTemplateRequest"
+// @Success 200 {object} messageModel.UpdateTemplateResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 409 {object} utils.APIErrorResponse "name already used"
+// @Router /{project_id}/events/{event_id}/messages/templates/{template_id} [put]
+
+func (h *MessageHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &messageModel.UpdateTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p messageModel.UpdateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &messageModel.UpdateTemplateRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		EventId:    mux.Vars(r)["event_id"],
+		TemplateID: mux.Vars(r)["template_id"],
+		Name:       p.Name,
+		Body:       p.Body,
+	}
+
+	template, err := h.svc.UpdateTemplate(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a message template
+// @Description Remove a template for good. Messages already queued from it are still sent.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param template_id path string true "template id"
+// @Success 200 {object} messageModel.DeleteTemplateResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/messages/templates/{template_id} [delete]
+
+func (h *MessageHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &messageModel.DeleteTemplateRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		EventId:    mux.Vars(r)["event_id"],
+		TemplateID: mux.Vars(r)["template_id"],
+	}
+
+	deleted, err := h.svc.DeleteTemplate(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deleted)
+}
+
+// PreviewMessage godoc
+// @Summary Preview the message of a guest
+// @Description Render a message for GuestID without sending it, from the template TemplateID, or from Body to preview a text before saving it. To is the phone number it would go to, empty when the guest has no valid one.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param body body messageModel.PreviewMessageRequest true "PreviewMessageRequest"
+// @Success 200 {object} messageModel.PreviewMessageResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/messages/preview [post]
+
+func (h *MessageHandler) PreviewMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &messageModel.PreviewMessageResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p messageModel.PreviewMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &messageModel.PreviewMessageRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		EventId:    mux.Vars(r)["event_id"],
+		GuestID:    p.GuestID,
+		TemplateID: p.TemplateID,
+		Body:       p.Body,
+	}
+
+	preview, err := h.svc.PreviewMessage(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(preview)
+}
+
+// Broadcast godoc
+// @Summary Broadcast a message by WhatsApp or SMS
+// @Description Queue the template TemplateID on Channel, whatsapp or sms, for every guest matching the filter and return before it is sent. Filter guests as on the guest list, with filter[column][op]=value query parameters; without any the whole event is sent to. Guests without a valid phone number, with a message of the template still queued on the channel, or already sent it are skipped; set Resend to send those again. Follow the broadcast with GET .../messages/broadcasts/{broadcast_id}.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param body body messageModel.BroadcastRequest true "BroadcastRequest"
+// @Success 202 {object} messageModel.BroadcastResponse
+// @Success 200 {object} messageModel.BroadcastResponse "nothing to send"
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 412 {object} utils.APIErrorResponse "the channel is not configured"
+// @Router /{project_id}/events/{event_id}/messages/broadcast [post]
+
+func (h *MessageHandler) Broadcast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &messageModel.BroadcastResponse{
+		Error:   false,
+		Code:    http.StatusAccepted,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p messageModel.BroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &messageModel.BroadcastRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		EventId:    mux.Vars(r)["event_id"],
+		Channel:    p.Channel,
+		TemplateID: p.TemplateID,
+		Resend:     p.Resend,
+		Filter:     r.URL.Query(),
+	}
+
+	queued, err := h.svc.Broadcast(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(int(queued.Code))
+	json.NewEncoder(w).Encode(queued)
+}
+
+// ListMessages godoc
+// @Summary List WhatsApp and SMS messages
+// @Description Get paginated list of the messages of an event with their status, newest first. LastError holds why the last attempt failed.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param dir query string false "asc or desc (default)"
+// @Param broadcast_id query int false "broadcast id"
+// @Param guest_id query int false "guest id"
+// @Param status query string false "PENDING, SENDING, SENT or FAILED"
+// @Param channel query string false "whatsapp or sms"
+// @Success 200 {object} messageModel.ListMessagesResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/messages/list [get]
+
+func (h *MessageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	page, _ := strconv.Atoi(queryParams.Get("page"))
+	limit, _ := strconv.Atoi(queryParams.Get("limit"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := &messageModel.ListMessagesRequest{
+		Page:        int32(page),
+		Limit:       int32(limit),
+		Dir:         queryParams.Get("dir"),
+		Cursor:      utils.QueryCursor(queryParams),
+		Count:       queryParams.Get("count"),
+		ProjectID:   mux.Vars(r)["project_id"],
+		EventId:     mux.Vars(r)["event_id"],
+		BroadcastID: queryParams.Get("broadcast_id"),
+		GuestID:     queryParams.Get("guest_id"),
+		Status:      queryParams.Get("status"),
+		Channel:     queryParams.Get("channel"),
+	}
+
+	messages, err := h.svc.ListMessages(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(messages)
+}
+
+// GetBroadcast godoc
+// @Summary Get a message broadcast
+// @Description Get a broadcast with its messages counted per status, to follow its progress.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param event_id path string true "event id"
+// @Param broadcast_id path string true "broadcast id"
+// @Success 200 {object} messageModel.GetBroadcastResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /{project_id}/events/{event_id}/messages/broadcasts/{broadcast_id} [get]
+
+func (h *MessageHandler) GetBroadcast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &messageModel.GetBroadcastRequest{
+		ProjectID:   mux.Vars(r)["project_id"],
+		EventId:     mux.Vars(r)["event_id"],
+		BroadcastID: mux.Vars(r)["broadcast_id"],
+	}
+
+	broadcast, err := h.svc.GetBroadcast(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(broadcast)
+}
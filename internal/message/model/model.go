@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Template is a reusable WhatsApp or SMS text of an event. Body may hold
+// merge fields such as {{Name}} or {{GuestData.table}}.
+type Template struct {
+	MessageTemplateID int64      `gorm:"primaryKey;autoIncrement"`
+	ProjectID         int64      `gorm:"type:bigint"`
+	EventID           int64      `gorm:"type:bigint"`
+	Name              string     `gorm:"type:varchar(200)"`
+	Body              string     `gorm:"type:text"`
+	CreatedById       int64      `gorm:"type:bigint"`
+	CreatedByName     string     `gorm:"type:varchar(500)"`
+	CreatedAt         *time.Time `gorm:"type:timestamp"`
+	UpdatedById       int64      `gorm:"type:bigint"`
+	UpdatedByName     string     `gorm:"type:varchar(500)"`
+	UpdatedAt         *time.Time `gorm:"type:timestamp"`
+}
+
+// Broadcast is one request to send a template to the guests matching Filter,
+// the filter[...] parameters of the guest list. Total counts the messages it
+// queued.
+type Broadcast struct {
+	MessageBroadcastID int64      `gorm:"primaryKey;autoIncrement"`
+	ProjectID          int64      `gorm:"type:bigint"`
+	EventID            int64      `gorm:"type:bigint"`
+	Channel            string     `gorm:"type:varchar(20)"`
+	MessageTemplateID  *int64     `gorm:"type:bigint"`
+	Filter             string     `gorm:"type:jsonb"`
+	Total              int32      `gorm:"type:integer"`
+	CreatedById        int64      `gorm:"type:bigint"`
+	CreatedByName      string     `gorm:"type:varchar(500)"`
+	CreatedAt          *time.Time `gorm:"type:timestamp"`
+}
+
+// Message is the text of one guest in a broadcast, rendered when it was
+// queued. It is PENDING until a sender claims it, SENDING while it is being
+// sent, then SENT, or FAILED once every attempt failed or the gateway
+// rejected it; LastError holds the reason of the last failure.
+type Message struct {
+	MessageID          int64      `gorm:"primaryKey;autoIncrement"`
+	MessageBroadcastID int64      `gorm:"type:bigint"`
+	MessageTemplateID  *int64     `gorm:"type:bigint"`
+	ProjectID          int64      `gorm:"type:bigint"`
+	EventID            int64      `gorm:"type:bigint"`
+	GuestID            int64      `gorm:"type:bigint"`
+	Channel            string     `gorm:"type:varchar(20)"`
+	Recipient          string     `gorm:"type:varchar(32)"`
+	Body               string     `gorm:"type:text"`
+	Status             string     `gorm:"type:varchar(20)"`
+	Attempts           int32      `gorm:"type:integer"`
+	LastError          string     `gorm:"type:text"`
+	ProviderMessageID  string     `gorm:"type:varchar(200)"`
+	NextAttemptAt      *time.Time `gorm:"type:timestamp"`
+	CreatedAt          *time.Time `gorm:"type:timestamp"`
+	UpdatedAt          *time.Time `gorm:"type:timestamp"`
+	SentAt             *time.Time `gorm:"type:timestamp"`
+}
+
+// MessageFilter narrows ListMessages down; zero fields match everything in
+// the event.
+type MessageFilter struct {
+	ProjectID   int64
+	EventID     int64
+	BroadcastID int64
+	GuestID     int64
+	Status      string
+	Channel     string
+}
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"net/url"
+
+	"rawuh-service/internal/shared/model"
+)
+
+type ListTemplatesRequest struct {
+	ProjectID string
+	EventId   string
+}
+
+type ListTemplatesResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    []*Template
+}
+
+type GetTemplateRequest struct {
+	ProjectID  string
+	EventId    string
+	TemplateID string
+}
+
+type GetTemplateResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Template
+}
+
+type CreateTemplateRequest struct {
+	ProjectID string
+	EventId   string
+	Name      string
+	Body      string
+}
+
+type CreateTemplateResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Template
+}
+
+type UpdateTemplateRequest struct {
+	ProjectID  string
+	EventId    string
+	TemplateID string
+	Name       string
+	Body       string
+}
+
+type UpdateTemplateResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Template
+}
+
+type DeleteTemplateRequest struct {
+	ProjectID  string
+	EventId    string
+	TemplateID string
+}
+
+type DeleteTemplateResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+}
+
+// PreviewMessageRequest renders the message of one guest. Body previews a
+// text before it is saved; when it is empty the template TemplateID is used.
+type PreviewMessageRequest struct {
+	ProjectID  string
+	EventId    string
+	GuestID    string
+	TemplateID string
+	Body       string
+}
+
+// Preview is a message as the guest would receive it. To is empty when the
+// guest has no valid phone number.
+type Preview struct {
+	To   string
+	Body string
+}
+
+type PreviewMessageResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Preview
+}
+
+// BroadcastRequest queues the template TemplateID on Channel for every guest
+// matching Filter, the filter[...] parameters of the guest list. Guests
+// already sent the template on the channel are skipped unless Resend is set.
+type BroadcastRequest struct {
+	ProjectID  string
+	EventId    string
+	Channel    string
+	TemplateID string
+	Resend     bool
+	Filter     url.Values `json:"-" swaggerignore:"true"`
+}
+
+// BroadcastResponse counts the guests skipped per reason: no_phone, queued
+// for a message not sent yet, or sent already.
+type BroadcastResponse struct {
+	Error       bool
+	Code        int32
+	Message     string
+	BroadcastID int64
+	Queued      int32
+	Skipped     map[string]int32
+}
+
+type ListMessagesRequest struct {
+	Page        int32
+	Limit       int32
+	Cursor      *string
+	Count       string
+	Dir         string
+	ProjectID   string
+	EventId     string
+	BroadcastID string
+	GuestID     string
+	Status      string
+	Channel     string
+}
+
+type ListMessagesResponse struct {
+	Error      bool
+	Code       int32
+	Message    string
+	Data       []*Message
+	Pagination *model.PaginationResponse
+}
+
+type GetBroadcastRequest struct {
+	ProjectID   string
+	EventId     string
+	BroadcastID string
+}
+
+// BroadcastStatus is a broadcast with its messages counted per status.
+type BroadcastStatus struct {
+	*Broadcast
+	Pending int64
+	Sending int64
+	Sent    int64
+	Failed  int64
+}
+
+type GetBroadcastResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *BroadcastStatus
+}
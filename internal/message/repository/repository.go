@@ -0,0 +1,429 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	eventModel "rawuh-service/internal/event/model"
+	guestModel "rawuh-service/internal/guest/model"
+	messageModel "rawuh-service/internal/message/model"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageRepository struct {
+	provider *db.GormProvider
+}
+
+func NewMessageRepository(provider *db.GormProvider) *MessageRepository {
+	return &MessageRepository{
+		provider: provider,
+	}
+}
+
+func (p *MessageRepository) GetEvent(ctx context.Context, projectID, eventID int64) (*eventModel.Event, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data eventModel.Event
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (p *MessageRepository) GetGuest(ctx context.Context, projectID, eventID, guestID int64) (*guestModel.Guest, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data guestModel.Guest
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND guest_id = ? AND deleted_at IS NULL", projectID, eventID, guestID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (p *MessageRepository) ListTemplates(ctx context.Context, projectID, eventID int64) ([]*messageModel.Template, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data []*messageModel.Template
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_templates")
+
+	query = query.Where("project_id = ? AND event_id = ?", projectID, eventID)
+
+	if err := query.Order("name").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (p *MessageRepository) GetTemplate(ctx context.Context, projectID, eventID, templateID int64) (*messageModel.Template, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data messageModel.Template
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_templates")
+
+	query = query.Where("project_id = ? AND event_id = ? AND message_template_id = ?", projectID, eventID, templateID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// TemplateNameExists reports whether another template of the event than
+// exceptID is called name.
+func (p *MessageRepository) TemplateNameExists(ctx context.Context, eventID int64, name string, exceptID int64) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var count int64
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_templates")
+
+	query = query.Where("event_id = ? AND name = ? AND message_template_id <> ?", eventID, name, exceptID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (p *MessageRepository) CreateTemplate(ctx context.Context, data *messageModel.Template) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_templates")
+
+	return query.Omit("message_template_id").Create(data).Error
+}
+
+func (p *MessageRepository) UpdateTemplate(ctx context.Context, data *messageModel.Template) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_templates")
+
+	query = query.Where("message_template_id = ?", data.MessageTemplateID)
+
+	return query.Updates(map[string]interface{}{
+		"name":            data.Name,
+		"body":            data.Body,
+		"updated_by_id":   data.UpdatedById,
+		"updated_by_name": data.UpdatedByName,
+		"updated_at":      data.UpdatedAt,
+	}).Error
+}
+
+// DeleteTemplate removes a template. Broadcasts and messages keep their
+// rendered text and lose the link to it.
+func (p *MessageRepository) DeleteTemplate(ctx context.Context, templateID int64) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_templates")
+
+	return query.Where("message_template_id = ?", templateID).Delete(&messageModel.Template{}).Error
+}
+
+// CreateBroadcast queues a broadcast in one transaction. The event row stays
+// locked until it commits, so two broadcasts of the same event never both
+// queue a guest. plan gets the status of the last message of each guest that
+// was sent the template of the broadcast on its channel, and returns the
+// messages to queue. A broadcast with nothing to queue is not stored.
+func (p *MessageRepository) CreateBroadcast(ctx context.Context, broadcast *messageModel.Broadcast, plan func(statuses map[int64]string) []*messageModel.Message) (messages []*messageModel.Message, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var eventIDs []int64
+	err = tx.Debug().Table("public.events").
+		Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", broadcast.ProjectID, broadcast.EventID).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("event_id", &eventIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(eventIDs) == 0 {
+		err = gorm.ErrRecordNotFound
+		return nil, err
+	}
+
+	var last []*messageModel.Message
+	err = tx.Debug().Table("public.message_outbox").
+		Select("DISTINCT ON (guest_id) guest_id, status").
+		Where("project_id = ? AND event_id = ? AND channel = ? AND message_template_id = ?", broadcast.ProjectID, broadcast.EventID, broadcast.Channel, broadcast.MessageTemplateID).
+		Order("guest_id, message_id DESC").
+		Find(&last).Error
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[int64]string, len(last))
+	for _, m := range last {
+		statuses[m.GuestID] = m.Status
+	}
+
+	messages = plan(statuses)
+	if len(messages) == 0 {
+		return nil, tx.Commit().Error
+	}
+
+	now := time.Now()
+	broadcast.Total = int32(len(messages))
+	broadcast.CreatedAt = &now
+	if err = tx.Debug().Table("public.message_broadcasts").Omit("message_broadcast_id").Create(broadcast).Error; err != nil {
+		return nil, err
+	}
+
+	for _, m := range messages {
+		m.MessageBroadcastID = broadcast.MessageBroadcastID
+		m.MessageTemplateID = broadcast.MessageTemplateID
+		m.ProjectID = broadcast.ProjectID
+		m.EventID = broadcast.EventID
+		m.Channel = broadcast.Channel
+		m.Status = constant.DeliveryStatusPending
+		m.NextAttemptAt = &now
+		m.CreatedAt = &now
+	}
+	if err = tx.Debug().Table("public.message_outbox").Omit("message_id").CreateInBatches(messages, 500).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, tx.Commit().Error
+}
+
+func (p *MessageRepository) GetBroadcast(ctx context.Context, projectID, eventID, broadcastID int64) (*messageModel.Broadcast, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data messageModel.Broadcast
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_broadcasts")
+
+	query = query.Where("project_id = ? AND event_id = ? AND message_broadcast_id = ?", projectID, eventID, broadcastID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// CountBroadcastMessages counts the messages of a broadcast per status.
+func (p *MessageRepository) CountBroadcastMessages(ctx context.Context, broadcastID int64) (map[string]int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_outbox")
+
+	err := query.Select("status, COUNT(*) AS count").
+		Where("message_broadcast_id = ?", broadcastID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (p *MessageRepository) ListMessages(ctx context.Context, filter *messageModel.MessageFilter, pagination *model.PaginationResponse, sort *model.Sort) (data []*messageModel.Message, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_outbox")
+
+	query = query.Where("project_id = ? AND event_id = ?", filter.ProjectID, filter.EventID)
+	if filter.BroadcastID != 0 {
+		query = query.Where("message_broadcast_id = ?", filter.BroadcastID)
+	}
+	if filter.GuestID != 0 {
+		query = query.Where("guest_id = ?", filter.GuestID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+
+	if pagination.Cursor != nil {
+		return db.Keyset[messageModel.Message](query, pagination, sort, "message_id")
+	}
+
+	query = query.Scopes(db.Paginate(data, pagination, query))
+	query = query.Scopes(
+		db.Sort(sort),
+	)
+
+	if err := query.Order("message_id " + sort.Direction).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ClaimMessages marks up to limit messages of channel that are due as
+// SENDING and returns them. Messages left SENDING since before stale, by a
+// sender that stopped halfway, are claimed again. Rows claimed by another
+// sender are skipped, so several servers can send at once.
+func (p *MessageRepository) ClaimMessages(ctx context.Context, channel string, limit int, stale time.Time) ([]*messageModel.Message, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	now := time.Now()
+	var data []*messageModel.Message
+
+	err := p.provider.GetDB().WithContext(timeoutctx).Debug().Raw(`
+		UPDATE public.message_outbox
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE message_id IN (
+			SELECT message_id FROM public.message_outbox
+			WHERE channel = ? AND ((status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at < ?))
+			ORDER BY message_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		constant.DeliveryStatusSending, now,
+		channel, constant.DeliveryStatusPending, now, constant.DeliveryStatusSending, stale,
+		limit,
+	).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FinishMessage stores the outcome of sending a claimed message: SENT with
+// the id the gateway gave it, or PENDING again until nextAttemptAt, or
+// FAILED. It does nothing when the message is no longer SENDING.
+func (p *MessageRepository) FinishMessage(ctx context.Context, messageID int64, status string, lastError string, providerMessageID string, nextAttemptAt *time.Time) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_outbox")
+
+	query = query.Where("message_id = ? AND status = ?", messageID, constant.DeliveryStatusSending)
+
+	now := time.Now()
+	values := map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+		"updated_at": &now,
+	}
+	if status == constant.DeliveryStatusSent {
+		values["sent_at"] = &now
+		values["provider_message_id"] = providerMessageID
+	}
+	if nextAttemptAt != nil {
+		values["next_attempt_at"] = nextAttemptAt
+	}
+
+	return query.Updates(values).Error
+}
+
+// ReleaseMessages puts claimed messages back in the queue untried, due at
+// nextAttemptAt, when the provider is sending as fast as it may. The claim
+// does not count as an attempt.
+func (p *MessageRepository) ReleaseMessages(ctx context.Context, messageIDs []int64, nextAttemptAt time.Time) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_outbox")
+
+	query = query.Where("message_id IN ? AND status = ?", messageIDs, constant.DeliveryStatusSending)
+
+	return query.Updates(map[string]interface{}{
+		"status":          constant.DeliveryStatusPending,
+		"attempts":        gorm.Expr("attempts - 1"),
+		"next_attempt_at": nextAttemptAt,
+		"updated_at":      time.Now(),
+	}).Error
+}
+
+// CancelMessages fails the messages still PENDING to the guest guestID, or
+// to every guest of the event eventID, or of the whole project when both are
+// 0. Messages being sent are left to finish.
+func (p *MessageRepository) CancelMessages(ctx context.Context, projectID, eventID, guestID int64, reason string) (int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.message_outbox")
+
+	query = query.Where("project_id = ? AND status = ?", projectID, constant.DeliveryStatusPending)
+	if eventID != 0 {
+		query = query.Where("event_id = ?", eventID)
+	}
+	if guestID != 0 {
+		query = query.Where("guest_id = ?", guestID)
+	}
+
+	res := query.Updates(map[string]interface{}{
+		"status":     constant.DeliveryStatusFailed,
+		"last_error": reason,
+		"updated_at": time.Now(),
+	})
+
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	messageModel "rawuh-service/internal/message/model"
+	messageDb "rawuh-service/internal/message/repository"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/messenger"
+	redisPkg "rawuh-service/internal/shared/redis"
+)
+
+// throttleWindow is the window the rate of a provider is counted in.
+const throttleWindow = time.Minute
+
+type SenderConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+// SenderConfigFromEnv reads the message sender settings, shared by every
+// channel:
+//
+//	MESSAGE_SEND_INTERVAL  10s, how often queued messages are sent, 0 turns sending off
+//	MESSAGE_BATCH_SIZE     50 messages claimed at a time, fewer when the channel rate is lower
+//	MESSAGE_MAX_ATTEMPTS   3 attempts before a message is FAILED
+//	MESSAGE_RETRY_DELAY    1m before the second attempt, doubled for each one after
+func SenderConfigFromEnv() (SenderConfig, error) {
+	cfg := SenderConfig{}
+
+	interval, err := time.ParseDuration(utils.GetEnv("MESSAGE_SEND_INTERVAL", "10s"))
+	if err != nil || interval < 0 {
+		return cfg, fmt.Errorf("invalid MESSAGE_SEND_INTERVAL")
+	}
+	batchSize, err := strconv.Atoi(utils.GetEnv("MESSAGE_BATCH_SIZE", "50"))
+	if err != nil || batchSize <= 0 {
+		return cfg, fmt.Errorf("invalid MESSAGE_BATCH_SIZE")
+	}
+	maxAttempts, err := strconv.Atoi(utils.GetEnv("MESSAGE_MAX_ATTEMPTS", "3"))
+	if err != nil || maxAttempts <= 0 {
+		return cfg, fmt.Errorf("invalid MESSAGE_MAX_ATTEMPTS")
+	}
+	retryDelay, err := time.ParseDuration(utils.GetEnv("MESSAGE_RETRY_DELAY", "1m"))
+	if err != nil || retryDelay <= 0 {
+		return cfg, fmt.Errorf("invalid MESSAGE_RETRY_DELAY")
+	}
+
+	cfg.Interval = interval
+	cfg.BatchSize = batchSize
+	cfg.MaxAttempts = maxAttempts
+	cfg.RetryDelay = retryDelay
+
+	return cfg, nil
+}
+
+// messageQueue is the part of the message repository the sender uses.
+type messageQueue interface {
+	ClaimMessages(ctx context.Context, channel string, limit int, stale time.Time) ([]*messageModel.Message, error)
+	FinishMessage(ctx context.Context, messageID int64, status string, lastError string, providerMessageID string, nextAttemptAt *time.Time) error
+	ReleaseMessages(ctx context.Context, messageIDs []int64, nextAttemptAt time.Time) error
+}
+
+// rateCounter counts messages across servers, see Redis.Incr.
+type rateCounter interface {
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+}
+
+// Sender sends the queued messages of one channel through its provider, at
+// most the channel rate a minute. The rate is counted in Redis so it holds
+// across servers, and each server also spaces its messages evenly over the
+// minute. A message is sent at least once: if the server stops between
+// handing it to the gateway and marking it SENT, it is sent again.
+type Sender struct {
+	dbProvider messageQueue
+	provider   messenger.Provider
+	rdb        rateCounter
+	channel    string
+	rate       int
+	timeout    time.Duration
+	cfg        SenderConfig
+	logger     *logger.Logger
+}
+
+func NewSender(dbProvider *messageDb.MessageRepository, provider messenger.Provider, rdb *redisPkg.Redis, messengerCfg messenger.Config, cfg SenderConfig, logger *logger.Logger) *Sender {
+	return &Sender{
+		dbProvider: dbProvider,
+		provider:   provider,
+		rdb:        rdb,
+		channel:    messengerCfg.Channel,
+		rate:       messengerCfg.Rate,
+		timeout:    messengerCfg.Timeout,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Run claims due messages and sends them until none are left or the
+// provider is at its rate, and returns how many were sent.
+func (s *Sender) Run(ctx context.Context) (int, error) {
+	// claim no more than can be sent in one window, so claimed messages do
+	// not wait long for their turn
+	limit := min(s.cfg.BatchSize, s.rate)
+	gap := throttleWindow / time.Duration(s.rate)
+	// a message SENDING for longer than a whole claim can take belongs to a
+	// sender that stopped halfway
+	stale := time.Duration(limit)*(s.timeout+gap) + time.Minute
+
+	sent := 0
+	for {
+		messages, err := s.dbProvider.ClaimMessages(ctx, s.channel, limit, time.Now().Add(-stale))
+		if err != nil {
+			return sent, fmt.Errorf("claim %s messages: %w", s.channel, err)
+		}
+
+		for i, m := range messages {
+			// failed before the throttle so only messages handed to the
+			// provider count against its rate
+			if int(m.Attempts) > s.cfg.MaxAttempts {
+				s.fail(ctx, m, fmt.Errorf("sending stopped halfway on the last attempt"))
+				continue
+			}
+
+			if i > 0 && !sleep(ctx, gap) {
+				s.release(messages[i:], time.Now())
+				return sent, ctx.Err()
+			}
+			if until, ok := s.throttle(ctx); !ok {
+				s.release(messages[i:], until)
+				return sent, nil
+			}
+
+			providerMessageID, err := s.provider.Send(ctx, &messenger.Message{
+				Channel: m.Channel,
+				To:      m.Recipient,
+				Text:    m.Body,
+			})
+			if err != nil {
+				s.fail(ctx, m, err)
+				continue
+			}
+			if err := s.dbProvider.FinishMessage(ctx, m.MessageID, constant.DeliveryStatusSent, "", providerMessageID, nil); err != nil {
+				s.logger.Error("err FinishMessage ", err)
+				continue
+			}
+			sent++
+		}
+
+		if len(messages) < limit {
+			return sent, nil
+		}
+	}
+}
+
+// Start runs the sender every interval until ctx is done.
+func (s *Sender) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := s.Run(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				s.logger.Error("err SendMessages "+s.channel+" ", err)
+				continue
+			}
+			if sent > 0 {
+				s.logger.Info("Success SendMessages "+s.channel+" ", sent)
+			}
+		}
+	}
+}
+
+// throttle counts one more message against the rate of the provider in the
+// current window. It returns false with the end of the window when the rate
+// is used up. When Redis is down messages are sent anyway, paced by this
+// server alone.
+func (s *Sender) throttle(ctx context.Context) (time.Time, bool) {
+	now := time.Now()
+	bucket := now.Unix() / int64(throttleWindow.Seconds())
+	key := fmt.Sprintf("messaging:throttle:%s:%s:%d", s.channel, s.provider.Name(), bucket)
+
+	count, err := s.rdb.Incr(ctx, key, throttleWindow)
+	if err != nil {
+		s.logger.Warn("err Incr message throttle ", err)
+		return now, true
+	}
+	if count > int64(s.rate) {
+		return time.Unix((bucket+1)*int64(throttleWindow.Seconds()), 0), false
+	}
+	return now, true
+}
+
+// release puts claimed messages back in the queue untried, due at until.
+// It runs on its own context so messages are released when ctx is done too.
+func (s *Sender) release(messages []*messageModel.Message, until time.Time) {
+	ids := make([]int64, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.MessageID)
+	}
+	if err := s.dbProvider.ReleaseMessages(context.Background(), ids, until); err != nil {
+		s.logger.Error("err ReleaseMessages ", err)
+	}
+}
+
+// fail puts a message back in the queue after a delay that doubles with
+// every attempt, or marks it FAILED once it used up its attempts or the
+// gateway rejected it for good.
+func (s *Sender) fail(ctx context.Context, m *messageModel.Message, sendErr error) {
+	s.logger.Warn("err Send message "+strconv.FormatInt(m.MessageID, 10)+" ", sendErr)
+
+	status := constant.DeliveryStatusFailed
+	var next *time.Time
+	if int(m.Attempts) < s.cfg.MaxAttempts && !errors.Is(sendErr, messenger.ErrPermanent) {
+		status = constant.DeliveryStatusPending
+		at := time.Now().Add(s.cfg.RetryDelay << min(m.Attempts-1, 16))
+		next = &at
+	}
+
+	if err := s.dbProvider.FinishMessage(ctx, m.MessageID, status, sendErr.Error(), "", next); err != nil {
+		s.logger.Error("err FinishMessage ", err)
+	}
+}
+
+// sleep waits for d and reports false when ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	messageModel "rawuh-service/internal/message/model"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/messenger"
+)
+
+// memQueue is the message queue in memory.
+type memQueue struct {
+	mu       sync.Mutex
+	messages []*messageModel.Message
+	released []int64
+	until    time.Time
+}
+
+func (q *memQueue) ClaimMessages(ctx context.Context, channel string, limit int, stale time.Time) ([]*messageModel.Message, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	claimed := []*messageModel.Message{}
+	for _, m := range q.messages {
+		if len(claimed) == limit {
+			break
+		}
+		if m.Channel != channel || m.Status != constant.DeliveryStatusPending || m.NextAttemptAt.After(now) {
+			continue
+		}
+		m.Status = constant.DeliveryStatusSending
+		m.Attempts++
+		copied := *m
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (q *memQueue) FinishMessage(ctx context.Context, messageID int64, status string, lastError string, providerMessageID string, nextAttemptAt *time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	m := q.find(messageID)
+	m.Status = status
+	m.LastError = lastError
+	m.ProviderMessageID = providerMessageID
+	if nextAttemptAt != nil {
+		m.NextAttemptAt = nextAttemptAt
+	}
+	return nil
+}
+
+func (q *memQueue) ReleaseMessages(ctx context.Context, messageIDs []int64, nextAttemptAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, id := range messageIDs {
+		m := q.find(id)
+		m.Status = constant.DeliveryStatusPending
+		m.Attempts--
+		m.NextAttemptAt = &nextAttemptAt
+	}
+	q.released = append(q.released, messageIDs...)
+	q.until = nextAttemptAt
+	return nil
+}
+
+func (q *memQueue) find(messageID int64) *messageModel.Message {
+	for _, m := range q.messages {
+		if m.MessageID == messageID {
+			return m
+		}
+	}
+	panic("unknown message")
+}
+
+// memCounter counts every key together, on from count. It fails while
+// err is set, like Redis being down.
+type memCounter struct {
+	mu    sync.Mutex
+	count int64
+	err   error
+}
+
+func (c *memCounter) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.count++
+	return c.count, nil
+}
+
+func queued(n int, attempts int32) *memQueue {
+	q := &memQueue{}
+	past := time.Now().Add(-time.Second)
+	for i := 1; i <= n; i++ {
+		q.messages = append(q.messages, &messageModel.Message{
+			MessageID:     int64(i),
+			Channel:       messenger.ChannelWhatsApp,
+			Recipient:     "+6281234567890",
+			Body:          "Halo",
+			Status:        constant.DeliveryStatusPending,
+			Attempts:      attempts,
+			NextAttemptAt: &past,
+		})
+	}
+	return q
+}
+
+// newTestSender sends 6000 messages a minute, 10ms apart, so tests do not
+// wait on the spacing.
+func newTestSender(q *memQueue, provider messenger.Provider, counter *memCounter) *Sender {
+	return &Sender{
+		dbProvider: q,
+		provider:   provider,
+		rdb:        counter,
+		channel:    messenger.ChannelWhatsApp,
+		rate:       6000,
+		timeout:    time.Second,
+		cfg: SenderConfig{
+			BatchSize:   50,
+			MaxAttempts: 3,
+			RetryDelay:  time.Minute,
+		},
+		logger: logger.New(&logger.LoggerConfig{LogLevel: "fatal"}),
+	}
+}
+
+func TestSenderSends(t *testing.T) {
+	q := queued(3, 0)
+	fake := messenger.NewFake()
+	s := newTestSender(q, fake, &memCounter{})
+
+	sent, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if sent != 3 || len(fake.Sent()) != 3 {
+		t.Fatalf("sent %d, provider got %d, want 3", sent, len(fake.Sent()))
+	}
+	for _, m := range q.messages {
+		if m.Status != constant.DeliveryStatusSent || m.ProviderMessageID == "" {
+			t.Errorf("message %d is %s with id %q, want SENT with the provider id", m.MessageID, m.Status, m.ProviderMessageID)
+		}
+	}
+}
+
+func TestSenderThrottles(t *testing.T) {
+	q := queued(10, 0)
+	fake := messenger.NewFake()
+	// three messages left in this window
+	counter := &memCounter{count: 6000 - 3}
+	s := newTestSender(q, fake, counter)
+
+	sent, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if sent != 3 || len(fake.Sent()) != 3 {
+		t.Fatalf("sent %d, provider got %d, want 3", sent, len(fake.Sent()))
+	}
+
+	if len(q.released) != 7 {
+		t.Fatalf("released %d messages, want 7", len(q.released))
+	}
+	if wait := time.Until(q.until); wait <= 0 || wait > throttleWindow {
+		t.Errorf("released until %s from now, want the end of the window", wait)
+	}
+	for _, m := range q.messages[3:] {
+		if m.Status != constant.DeliveryStatusPending || m.Attempts != 0 {
+			t.Errorf("message %d is %s after %d attempts, want PENDING untried", m.MessageID, m.Status, m.Attempts)
+		}
+	}
+}
+
+func TestSenderSendsWhenRedisIsDown(t *testing.T) {
+	q := queued(2, 0)
+	fake := messenger.NewFake()
+	s := newTestSender(q, fake, &memCounter{err: errors.New("connection refused")})
+
+	sent, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if sent != 2 {
+		t.Fatalf("sent %d, want 2", sent)
+	}
+}
+
+func TestSenderBacksOff(t *testing.T) {
+	fake := messenger.NewFake()
+	fake.Err = errors.New("gateway down")
+
+	// the claim counts the attempt, so these are the first and second
+	for attempts, want := range map[int32]time.Duration{0: time.Minute, 1: 2 * time.Minute} {
+		q := queued(1, attempts)
+		s := newTestSender(q, fake, &memCounter{})
+
+		start := time.Now()
+		sent, err := s.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if sent != 0 {
+			t.Fatalf("sent %d, want 0", sent)
+		}
+
+		m := q.messages[0]
+		if m.Status != constant.DeliveryStatusPending {
+			t.Fatalf("attempt %d: message is %s, want PENDING", m.Attempts, m.Status)
+		}
+		if m.LastError != "gateway down" {
+			t.Errorf("attempt %d: LastError = %q", m.Attempts, m.LastError)
+		}
+		if delay := m.NextAttemptAt.Sub(start); delay < want || delay > want+time.Second {
+			t.Errorf("attempt %d: retried after %s, want %s", m.Attempts, delay, want)
+		}
+	}
+}
+
+func TestSenderFailsAfterMaxAttempts(t *testing.T) {
+	fake := messenger.NewFake()
+	fake.Err = errors.New("gateway down")
+
+	// claimed for the third and last attempt
+	q := queued(1, 2)
+	s := newTestSender(q, fake, &memCounter{})
+
+	if _, err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	m := q.messages[0]
+	if m.Status != constant.DeliveryStatusFailed || m.Attempts != 3 {
+		t.Fatalf("message is %s after %d attempts, want FAILED after 3", m.Status, m.Attempts)
+	}
+}
+
+func TestSenderFailsPermanentErrorsAtOnce(t *testing.T) {
+	fake := messenger.NewFake()
+	fake.Err = messenger.Permanent(errors.New("invalid number"))
+
+	q := queued(1, 0)
+	s := newTestSender(q, fake, &memCounter{})
+
+	if _, err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	m := q.messages[0]
+	if m.Status != constant.DeliveryStatusFailed || m.Attempts != 1 {
+		t.Fatalf("message is %s after %d attempts, want FAILED after 1", m.Status, m.Attempts)
+	}
+}
+
+func TestSenderDoesNotResendAfterLastAttempt(t *testing.T) {
+	// a sender stopped halfway through the last attempt, and the message
+	// was claimed again
+	q := queued(1, 3)
+	fake := messenger.NewFake()
+	counter := &memCounter{}
+	s := newTestSender(q, fake, counter)
+
+	if _, err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(fake.Sent()) != 0 {
+		t.Errorf("provider got %d messages, want none", len(fake.Sent()))
+	}
+	if m := q.messages[0]; m.Status != constant.DeliveryStatusFailed {
+		t.Errorf("message is %s, want FAILED", m.Status)
+	}
+	if counter.count != 0 {
+		t.Errorf("counted %d messages against the rate, want none", counter.count)
+	}
+}
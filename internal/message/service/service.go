@@ -0,0 +1,911 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
+	eventModel "rawuh-service/internal/event/model"
+	guestModel "rawuh-service/internal/guest/model"
+	guestService "rawuh-service/internal/guest/service"
+	messageModel "rawuh-service/internal/message/model"
+	messageDb "rawuh-service/internal/message/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/mergefield"
+	"rawuh-service/internal/shared/messenger"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/model"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const (
+	// a WhatsApp text holds at most 4096 characters; SMS gateways split
+	// longer texts into several messages
+	maxTemplateBody = 4096
+
+	// guests are read from the guest list this many at a time
+	broadcastPageSize = 100
+
+	skipNoPhone = "no_phone"
+	skipQueued  = "queued"
+	skipSent    = "sent"
+)
+
+// MessageService keeps the WhatsApp and SMS templates of each event and
+// broadcasts them to guests. Queued messages are sent in the background by a
+// Sender per channel.
+type MessageService interface {
+	ListTemplates(ctx context.Context, req *messageModel.ListTemplatesRequest) (*messageModel.ListTemplatesResponse, error)
+	GetTemplate(ctx context.Context, req *messageModel.GetTemplateRequest) (*messageModel.GetTemplateResponse, error)
+	CreateTemplate(ctx context.Context, req *messageModel.CreateTemplateRequest) (*messageModel.CreateTemplateResponse, error)
+	UpdateTemplate(ctx context.Context, req *messageModel.UpdateTemplateRequest) (*messageModel.UpdateTemplateResponse, error)
+	DeleteTemplate(ctx context.Context, req *messageModel.DeleteTemplateRequest) (*messageModel.DeleteTemplateResponse, error)
+	PreviewMessage(ctx context.Context, req *messageModel.PreviewMessageRequest) (*messageModel.PreviewMessageResponse, error)
+	Broadcast(ctx context.Context, req *messageModel.BroadcastRequest) (*messageModel.BroadcastResponse, error)
+	ListMessages(ctx context.Context, req *messageModel.ListMessagesRequest) (*messageModel.ListMessagesResponse, error)
+	GetBroadcast(ctx context.Context, req *messageModel.GetBroadcastRequest) (*messageModel.GetBroadcastResponse, error)
+}
+
+type messageService struct {
+	dbProvider *messageDb.MessageRepository
+	guests     guestService.GuestService
+	providers  map[string]messenger.Provider
+	audit      auditService.AuditService
+	logger     *logger.Logger
+}
+
+// NewMessageService returns the service. providers holds the provider of
+// each configured channel; channels missing from it can have templates but
+// cannot be broadcast on. Guests to broadcast to are found through guests,
+// with the filters of the guest list.
+func NewMessageService(dbProvider *messageDb.MessageRepository, guests guestService.GuestService, providers map[string]messenger.Provider, audit auditService.AuditService, logger *logger.Logger) MessageService {
+	return &messageService{
+		dbProvider: dbProvider,
+		guests:     guests,
+		providers:  providers,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+func (s *messageService) ListTemplates(ctx context.Context, req *messageModel.ListTemplatesRequest) (*messageModel.ListTemplatesResponse, error) {
+	funcName := "ListTemplates"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerZap.Info("Start ListTemplates")
+	templates, err := s.dbProvider.ListTemplates(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err ListTemplates ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &messageModel.ListTemplatesResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    templates,
+	}
+
+	return result, nil
+}
+
+func (s *messageService) GetTemplate(ctx context.Context, req *messageModel.GetTemplateRequest) (*messageModel.GetTemplateResponse, error) {
+	funcName := "GetTemplate"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	templateID, err := strconv.ParseInt(req.TemplateID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Template Id")
+	}
+
+	loggerZap.Info("Start GetTemplate")
+	template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID, templateID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if template == nil {
+		return nil, status.Errorf(codes.NotFound, "template not found")
+	}
+
+	result := &messageModel.GetTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    template,
+	}
+
+	return result, nil
+}
+
+// CreateTemplate adds a template to the event. Names are unique per event
+// and every merge field in the body must exist.
+func (s *messageService) CreateTemplate(ctx context.Context, req *messageModel.CreateTemplateRequest) (*messageModel.CreateTemplateResponse, error) {
+	funcName := "CreateTemplate"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "EventId": req.EventId, "Name": req.Name})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerZap.Info("Start Validation for req ", nil)
+	name := strings.TrimSpace(req.Name)
+	if err := validateTemplate(name, req.Body); err != nil {
+		return nil, err
+	}
+
+	event, err := s.dbProvider.GetEvent(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if event == nil {
+		return nil, status.Errorf(codes.NotFound, "event not found")
+	}
+
+	found, err := s.dbProvider.TemplateNameExists(ctx, eventID, name, 0)
+	if err != nil {
+		loggerZap.Error("err TemplateNameExists ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if found {
+		return nil, status.Errorf(codes.AlreadyExists, "a template called %q already exists", name)
+	}
+
+	now := time.Now()
+	template := &messageModel.Template{
+		ProjectID:     projectID,
+		EventID:       eventID,
+		Name:          name,
+		Body:          req.Body,
+		CreatedById:   currentUser.UserID,
+		CreatedByName: currentUser.Name,
+		CreatedAt:     &now,
+	}
+
+	loggerZap.Info("Start CreateTemplate")
+	if err := s.dbProvider.CreateTemplate(ctx, template); err != nil {
+		loggerZap.Error("err CreateTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCreate,
+		EntityType: constant.AuditEntityMessageTemplate,
+		EntityID:   strconv.FormatInt(template.MessageTemplateID, 10),
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		After:      template,
+	})
+
+	result := &messageModel.CreateTemplateResponse{
+		Error:   false,
+		Code:    http.StatusCreated,
+		Message: "Success create message template",
+		Data:    template,
+	}
+
+	return result, nil
+}
+
+func (s *messageService) UpdateTemplate(ctx context.Context, req *messageModel.UpdateTemplateRequest) (*messageModel.UpdateTemplateResponse, error) {
+	funcName := "UpdateTemplate"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "EventId": req.EventId, "TemplateID": req.TemplateID, "Name": req.Name})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	templateID, err := strconv.ParseInt(req.TemplateID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Template Id")
+	}
+
+	loggerZap.Info("Start Validation for req ", nil)
+	name := strings.TrimSpace(req.Name)
+	if err := validateTemplate(name, req.Body); err != nil {
+		return nil, err
+	}
+
+	before, err := s.dbProvider.GetTemplate(ctx, projectID, eventID, templateID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if before == nil {
+		return nil, status.Errorf(codes.NotFound, "template not found")
+	}
+
+	found, err := s.dbProvider.TemplateNameExists(ctx, eventID, name, templateID)
+	if err != nil {
+		loggerZap.Error("err TemplateNameExists ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if found {
+		return nil, status.Errorf(codes.AlreadyExists, "a template called %q already exists", name)
+	}
+
+	now := time.Now()
+	template := *before
+	template.Name = name
+	template.Body = req.Body
+	template.UpdatedById = currentUser.UserID
+	template.UpdatedByName = currentUser.Name
+	template.UpdatedAt = &now
+
+	loggerZap.Info("Start UpdateTemplate")
+	if err := s.dbProvider.UpdateTemplate(ctx, &template); err != nil {
+		loggerZap.Error("err UpdateTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUpdate,
+		EntityType: constant.AuditEntityMessageTemplate,
+		EntityID:   req.TemplateID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+		After:      &template,
+	})
+
+	result := &messageModel.UpdateTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success update message template",
+		Data:    &template,
+	}
+
+	return result, nil
+}
+
+// DeleteTemplate removes a template for good. Messages already queued from
+// it are still sent.
+func (s *messageService) DeleteTemplate(ctx context.Context, req *messageModel.DeleteTemplateRequest) (*messageModel.DeleteTemplateResponse, error) {
+	funcName := "DeleteTemplate"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	templateID, err := strconv.ParseInt(req.TemplateID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Template Id")
+	}
+
+	before, err := s.dbProvider.GetTemplate(ctx, projectID, eventID, templateID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if before == nil {
+		return nil, status.Errorf(codes.NotFound, "template not found")
+	}
+
+	loggerZap.Info("Start DeleteTemplate")
+	if err := s.dbProvider.DeleteTemplate(ctx, templateID); err != nil {
+		loggerZap.Error("err DeleteTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityMessageTemplate,
+		EntityID:   req.TemplateID,
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		Before:     before,
+	})
+
+	result := &messageModel.DeleteTemplateResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success delete message template",
+	}
+
+	return result, nil
+}
+
+// PreviewMessage renders the message of one guest without sending it.
+func (s *messageService) PreviewMessage(ctx context.Context, req *messageModel.PreviewMessageRequest) (*messageModel.PreviewMessageResponse, error) {
+	funcName := "PreviewMessage"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "EventId": req.EventId, "GuestID": req.GuestID, "TemplateID": req.TemplateID})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.EventWrite, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	guestID, err := strconv.ParseInt(req.GuestID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Guest Id")
+	}
+
+	body := req.Body
+	if body == "" {
+		templateID, err := strconv.ParseInt(req.TemplateID, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "TemplateID or Body is required")
+		}
+		template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID, templateID)
+		if err != nil {
+			loggerZap.Error("err GetTemplate ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+		if template == nil {
+			return nil, status.Errorf(codes.NotFound, "template not found")
+		}
+		body = template.Body
+	} else if len(body) > maxTemplateBody {
+		return nil, status.Errorf(codes.InvalidArgument, "Body is longer than %d characters", maxTemplateBody)
+	}
+	parsed, err := mergefield.Parse(body)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	event, err := s.dbProvider.GetEvent(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if event == nil {
+		return nil, status.Errorf(codes.NotFound, "event not found")
+	}
+	guest, err := s.dbProvider.GetGuest(ctx, projectID, eventID, guestID)
+	if err != nil {
+		loggerZap.Error("err GetGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if guest == nil {
+		return nil, status.Errorf(codes.NotFound, "guest not found")
+	}
+
+	values, err := messageValues(guest, event)
+	if err != nil {
+		loggerZap.Error("err messageValues ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	to := utils.NormalizePhone(guest.Phone, utils.PhoneCountryCode())
+
+	result := &messageModel.PreviewMessageResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data: &messageModel.Preview{
+			To:   to,
+			Body: parsed.Render(values),
+		},
+	}
+
+	return result, nil
+}
+
+// Broadcast renders a template for every guest matching the filter and
+// queues the messages on the channel, and returns before they are sent.
+// Guests without a valid phone number, with a message of the template still
+// queued on the channel, or already sent it without Resend are skipped.
+func (s *messageService) Broadcast(ctx context.Context, req *messageModel.BroadcastRequest) (*messageModel.BroadcastResponse, error) {
+	funcName := "Broadcast"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.MessageSend, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	channel := strings.ToLower(req.Channel)
+	switch channel {
+	case messenger.ChannelWhatsApp, messenger.ChannelSMS:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Channel must be %s or %s", messenger.ChannelWhatsApp, messenger.ChannelSMS)
+	}
+	if s.providers[channel] == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is not configured, set %s_PROVIDER", channel, strings.ToUpper(channel))
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	templateID, err := strconv.ParseInt(req.TemplateID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Template Id")
+	}
+
+	template, err := s.dbProvider.GetTemplate(ctx, projectID, eventID, templateID)
+	if err != nil {
+		loggerZap.Error("err GetTemplate ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if template == nil {
+		return nil, status.Errorf(codes.NotFound, "template not found")
+	}
+	parsed, err := mergefield.Parse(template.Body)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "the template is invalid: "+err.Error())
+	}
+
+	event, err := s.dbProvider.GetEvent(ctx, projectID, eventID)
+	if err != nil {
+		loggerZap.Error("err GetEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if event == nil {
+		return nil, status.Errorf(codes.NotFound, "event not found")
+	}
+
+	// the guest list applies the filter and checks the caller may read
+	// guests; its errors are already status errors
+	loggerZap.Info("Start ListGuests")
+	guests, err := s.listGuests(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := map[string]int32{}
+	candidates := make([]*messageModel.Message, 0, len(guests))
+	countryCode := utils.PhoneCountryCode()
+	for _, guest := range guests {
+		phone := utils.NormalizePhone(guest.Phone, countryCode)
+		if phone == "" {
+			skipped[skipNoPhone]++
+			continue
+		}
+		values, err := messageValues(guest, event)
+		if err != nil {
+			loggerZap.Error("err messageValues ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+		candidates = append(candidates, &messageModel.Message{
+			GuestID:   guest.GuestID,
+			Recipient: phone,
+			Body:      parsed.Render(values),
+		})
+	}
+
+	plan := func(statuses map[int64]string) []*messageModel.Message {
+		messages := []*messageModel.Message{}
+		for _, m := range candidates {
+			switch last := statuses[m.GuestID]; {
+			case last == constant.DeliveryStatusPending || last == constant.DeliveryStatusSending:
+				skipped[skipQueued]++
+			case last == constant.DeliveryStatusSent && !req.Resend:
+				skipped[skipSent]++
+			default:
+				messages = append(messages, m)
+			}
+		}
+		return messages
+	}
+
+	filter, err := json.Marshal(guestFilter(req.Filter))
+	if err != nil {
+		loggerZap.Error("err Marshal filter ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	broadcast := &messageModel.Broadcast{
+		ProjectID:         projectID,
+		EventID:           eventID,
+		Channel:           channel,
+		MessageTemplateID: &templateID,
+		Filter:            string(filter),
+		CreatedById:       currentUser.UserID,
+		CreatedByName:     currentUser.Name,
+	}
+
+	loggerZap.Info("Start CreateBroadcast")
+	messages, err := s.dbProvider.CreateBroadcast(ctx, broadcast, plan)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "event not found")
+		}
+		loggerZap.Error("err CreateBroadcast ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &messageModel.BroadcastResponse{
+		Error:   false,
+		Code:    http.StatusAccepted,
+		Message: fmt.Sprintf("%d messages queued", len(messages)),
+		Queued:  int32(len(messages)),
+		Skipped: skipped,
+	}
+	if len(messages) == 0 {
+		result.Code = http.StatusOK
+		result.Message = "No messages to send"
+		return result, nil
+	}
+	result.BroadcastID = broadcast.MessageBroadcastID
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionSend,
+		EntityType: constant.AuditEntityMessageBroadcast,
+		EntityID:   strconv.FormatInt(broadcast.MessageBroadcastID, 10),
+		ProjectID:  req.ProjectID,
+		EventID:    req.EventId,
+		After: map[string]interface{}{
+			"Broadcast": broadcast,
+			"Resend":    req.Resend,
+			"Skipped":   skipped,
+		},
+	})
+
+	return result, nil
+}
+
+// listGuests reads every guest matching the filter of req from the guest
+// list, a page at a time.
+func (s *messageService) listGuests(ctx context.Context, req *messageModel.BroadcastRequest) ([]*guestModel.Guest, error) {
+	filter := guestFilter(req.Filter)
+
+	guests := []*guestModel.Guest{}
+	cursor := ""
+	for {
+		page, err := s.guests.ListGuests(ctx, &guestModel.ListGuestRequest{
+			Limit:     broadcastPageSize,
+			Filter:    filter,
+			Cursor:    &cursor,
+			Count:     "false",
+			EventId:   req.EventId,
+			ProjectID: req.ProjectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, page.Data...)
+
+		if page.Pagination == nil || page.Pagination.NextCursor == "" {
+			return guests, nil
+		}
+		cursor = page.Pagination.NextCursor
+	}
+}
+
+func (s *messageService) ListMessages(ctx context.Context, req *messageModel.ListMessagesRequest) (*messageModel.ListMessagesResponse, error) {
+	funcName := "ListMessages"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &messageModel.MessageFilter{
+		ProjectID: projectID,
+		EventID:   eventID,
+		Status:    strings.ToUpper(req.Status),
+		Channel:   strings.ToLower(req.Channel),
+	}
+	switch filter.Status {
+	case "", constant.DeliveryStatusPending, constant.DeliveryStatusSending, constant.DeliveryStatusSent, constant.DeliveryStatusFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %s, %s, %s or %s", constant.DeliveryStatusPending, constant.DeliveryStatusSending, constant.DeliveryStatusSent, constant.DeliveryStatusFailed)
+	}
+	switch filter.Channel {
+	case "", messenger.ChannelWhatsApp, messenger.ChannelSMS:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "channel must be %s or %s", messenger.ChannelWhatsApp, messenger.ChannelSMS)
+	}
+
+	ids := []struct {
+		value string
+		dest  *int64
+	}{
+		{req.BroadcastID, &filter.BroadcastID},
+		{req.GuestID, &filter.GuestID},
+	}
+	for _, id := range ids {
+		if id.value == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(id.value, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid id %q", id.value)
+		}
+		*id.dest = v
+	}
+
+	direction := strings.ToLower(req.Dir)
+	switch direction {
+	case "":
+		direction = "desc"
+	case "asc", "desc":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+	}
+	sort := &model.Sort{
+		Column:    "created_at",
+		Direction: direction,
+	}
+
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	loggerZap.Info("Start ListMessages")
+	messages, err := s.dbProvider.ListMessages(ctx, filter, pagination, sort)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		loggerZap.Error("err ListMessages ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &messageModel.ListMessagesResponse{
+		Error:      false,
+		Code:       http.StatusOK,
+		Message:    "Success",
+		Data:       messages,
+		Pagination: pagination,
+	}
+
+	return result, nil
+}
+
+// GetBroadcast returns a broadcast with its messages counted per status, to
+// follow its progress.
+func (s *messageService) GetBroadcast(ctx context.Context, req *messageModel.GetBroadcastRequest) (*messageModel.GetBroadcastResponse, error) {
+	funcName := "GetBroadcast"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.GuestRead, req.ProjectID, req.EventId); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, eventID, err := parseEventIDs(req.ProjectID, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+	broadcastID, err := strconv.ParseInt(req.BroadcastID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Broadcast Id")
+	}
+
+	loggerZap.Info("Start GetBroadcast")
+	broadcast, err := s.dbProvider.GetBroadcast(ctx, projectID, eventID, broadcastID)
+	if err != nil {
+		loggerZap.Error("err GetBroadcast ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if broadcast == nil {
+		return nil, status.Errorf(codes.NotFound, "broadcast not found")
+	}
+
+	counts, err := s.dbProvider.CountBroadcastMessages(ctx, broadcastID)
+	if err != nil {
+		loggerZap.Error("err CountBroadcastMessages ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &messageModel.GetBroadcastResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data: &messageModel.BroadcastStatus{
+			Broadcast: broadcast,
+			Pending:   counts[constant.DeliveryStatusPending],
+			Sending:   counts[constant.DeliveryStatusSending],
+			Sent:      counts[constant.DeliveryStatusSent],
+			Failed:    counts[constant.DeliveryStatusFailed],
+		},
+	}
+
+	return result, nil
+}
+
+func parseEventIDs(projectID string, eventID string) (int64, int64, error) {
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+	eid, err := strconv.ParseInt(eventID, 10, 64)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "Invalid Event Id")
+	}
+	return pid, eid, nil
+}
+
+// validateTemplate checks a template sent by a client.
+func validateTemplate(name, body string) error {
+	if name == "" {
+		return status.Errorf(codes.InvalidArgument, "Name is required")
+	}
+	if len(name) > 200 {
+		return status.Errorf(codes.InvalidArgument, "Name is longer than 200 characters")
+	}
+	if strings.TrimSpace(body) == "" {
+		return status.Errorf(codes.InvalidArgument, "Body is required")
+	}
+	if len(body) > maxTemplateBody {
+		return status.Errorf(codes.InvalidArgument, "Body is longer than %d characters", maxTemplateBody)
+	}
+	if _, err := mergefield.Parse(body); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// guestFilter keeps the filter[...] parameters of values, the ones the guest
+// list reads.
+func guestFilter(values url.Values) url.Values {
+	filter := url.Values{}
+	for key, v := range values {
+		if strings.HasPrefix(key, "filter[") {
+			filter[key] = v
+		}
+	}
+	return filter
+}
+
+// messageValues returns the merge values of the message of guest. The RSVP
+// link is the one GetGuestInvitation hands out, empty when RSVP_BASE_URL is
+// not set.
+func messageValues(guest *guestModel.Guest, event *eventModel.Event) (*mergefield.Values, error) {
+	rsvpURL := ""
+	if baseURL := utils.GetEnv("RSVP_BASE_URL", ""); baseURL != "" {
+		token, err := utils.SignToken(constant.TokenPurposeRsvp, guest.ProjectID, guest.EventId, guest.GuestID)
+		if err != nil {
+			return nil, err
+		}
+		rsvpURL = strings.TrimRight(baseURL, "/") + "/" + token
+	}
+
+	values := &mergefield.Values{
+		Name:         guest.Name,
+		Address:      guest.Address,
+		Email:        guest.Email,
+		Phone:        guest.Phone,
+		EventName:    event.EventName,
+		RsvpUrl:      rsvpURL,
+		GuestData:    guest.GuestData,
+		EventOptions: event.EventOptions,
+	}
+	return values, nil
+}
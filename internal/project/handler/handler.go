@@ -25,13 +25,17 @@ func NewProjectHandler(svc projectService.ProjectService) *ProjectHandler {
 
 // ListProject godoc
 // @Summary List projects
-// @Description Get paginated list of projects
+// @Description Get paginated list of projects. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.
 // @Tags project
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param page query int false "page"
 // @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param sort query string false "sort column, any filterable column"
+// @Param dir query string false "asc or desc"
 // @Success 200 {object} projectModel.ListProjectResponse
 // @Failure 400 {object} utils.APIErrorResponse
 // @Router /project/list [get]
@@ -65,7 +69,9 @@ func (h *ProjectHandler) ListProject(w http.ResponseWriter, r *http.Request) {
 		Limit:   int32(limit),
 		Sort:    queryParams.Get("sort"),
 		Dir:     queryParams.Get("dir"),
-		Query:   queryParams.Get("query"),
+		Filter:  queryParams,
+		Cursor:  utils.QueryCursor(queryParams),
+		Count:   queryParams.Get("count"),
 		EventId: mux.Vars(r)["event_id"],
 	}
 
@@ -241,6 +247,40 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// RestoreProject godoc
+// @Summary Restore a deleted project
+// @Description Restore a deleted project together with the events and guests that were deleted with it
+// @Tags project
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param project_id path string true "project id"
+// @Success 200 {object} projectModel.RestoreProjectResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/restore [post]
+
+func (h *ProjectHandler) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &projectModel.RestoreProjectRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	project, err := h.svc.RestoreProject(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(project)
+}
+
 // DetailProject godoc
 // @Summary Get project detail
 // @Description Retrieve project details by ID
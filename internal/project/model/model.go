@@ -11,4 +11,6 @@ type Project struct {
 	UpdatedById int64      `gorm:"type:bigint"`
 	Status      int64      `gorm:"type:bigint"`
 	StatusDesc  string     `gorm:"type:varchar(500)"`
+	DeletedAt   *time.Time `gorm:"type:timestamp"`
+	DeletedById int64      `gorm:"type:bigint"`
 }
@@ -1,15 +1,19 @@
 package model
 
 import (
+	"net/url"
+
 	"rawuh-service/internal/shared/model"
 )
 
 type ListProjectRequest struct {
-	Page    int32  `json:"page"`
-	Limit   int32  `json:"limit"`
-	Sort    string `json:"sort"`
-	Dir     string `json:"dir"`
-	Query   string `json:"query"`
+	Page    int32      `json:"page"`
+	Limit   int32      `json:"limit"`
+	Sort    string     `json:"sort"`
+	Dir     string     `json:"dir"`
+	Filter  url.Values `json:"filter"`
+	Cursor  *string    `json:"cursor"`
+	Count   string     `json:"count"`
 	EventId string
 }
 
@@ -57,6 +61,17 @@ type DeleteProjectResponse struct {
 	Message string
 }
 
+type RestoreProjectRequest struct {
+	ProjectID string
+}
+
+type RestoreProjectResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Project
+}
+
 type GetProjectDetailRequest struct {
 	ProjectID string
 }
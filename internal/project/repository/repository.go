@@ -3,10 +3,14 @@ package db
 import (
 	"context"
 	"errors"
+	jobModel "rawuh-service/internal/job/model"
+	jobDb "rawuh-service/internal/job/repository"
 	projectModel "rawuh-service/internal/project/model"
+	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/db"
 	"rawuh-service/internal/shared/middleware"
 	"rawuh-service/internal/shared/model"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -22,18 +26,27 @@ func NewProjectRepository(provider *db.GormProvider) *ProjectRepository {
 	}
 }
 
-func (p *ProjectRepository) ListProject(ctx context.Context, pagination *model.PaginationResponse, sql *db.QueryBuilder, sort *model.Sort, projectID int64) (data []*projectModel.Project, err error) {
+func (p *ProjectRepository) ListProject(ctx context.Context, pagination *model.PaginationResponse, sql *db.QueryBuilder, sort *model.Sort, projectIDs []int64) (data []*projectModel.Project, err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.projects")
 
-	query = query.Where("project_id = ?", projectID)
+	query = query.Where("deleted_at IS NULL")
+
+	// nil projectIDs lists every project
+	if projectIDs != nil {
+		query = query.Where("project_id IN ?", projectIDs)
+	}
 
 	query = query.Scopes(
-		db.QueryScoop(sql.CollectiveAnd),
+		sql.Filter.Scope(),
 	)
 
+	if pagination.Cursor != nil {
+		return db.Keyset[projectModel.Project](query, pagination, sort, "project_id")
+	}
+
 	query = query.Scopes(db.Paginate(data, pagination, query))
 	query = query.Scopes(
 		db.Sort(sort),
@@ -48,7 +61,7 @@ func (p *ProjectRepository) ListProject(ctx context.Context, pagination *model.P
 	return data, nil
 }
 
-func (p *ProjectRepository) CreateProject(ctx context.Context, req *projectModel.CreateProjectRequest, currentUser middleware.AuthClaims) error {
+func (p *ProjectRepository) CreateProject(ctx context.Context, req *projectModel.CreateProjectRequest, currentUser middleware.AuthClaims) (*projectModel.Project, error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
@@ -63,10 +76,10 @@ func (p *ProjectRepository) CreateProject(ctx context.Context, req *projectModel
 	}
 
 	if err := query.Omit("event_id").Create(data).Error; err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return data, nil
 }
 
 func (p *ProjectRepository) UpdateProject(ctx context.Context, req *projectModel.UpdateProjectRequest, currentUser middleware.AuthClaims) error {
@@ -75,7 +88,7 @@ func (p *ProjectRepository) UpdateProject(ctx context.Context, req *projectModel
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.projects")
 
-	query = query.Where("project_id = ?", req.ProjectID)
+	query = query.Where("project_id = ? AND deleted_at IS NULL", req.ProjectID)
 
 	now := time.Now()
 	data := &projectModel.Project{
@@ -95,23 +108,119 @@ func (p *ProjectRepository) UpdateProject(ctx context.Context, req *projectModel
 	return nil
 }
 
-func (p *ProjectRepository) DeleteProject(ctx context.Context, req *projectModel.DeleteProjectRequest) error {
+// DeleteProject soft deletes the project together with its events and
+// guests. They all get the same deleted_at, which is how RestoreProject tells
+// them apart from events and guests that were deleted on their own before.
+// Invitations and messages still waiting to be sent are cancelled by a job
+// enqueued in the same transaction.
+func (p *ProjectRepository) DeleteProject(ctx context.Context, req *projectModel.DeleteProjectRequest, currentUser middleware.AuthClaims) (err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
-	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.projects")
-	query = query.Where("project_id = ?", req.ProjectID)
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	res := query.Delete(&projectModel.Project{})
+	deleted := map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"deleted_by_id": currentUser.UserID,
+	}
+
+	res := tx.Debug().Table("public.projects").Where("project_id = ? AND deleted_at IS NULL", req.ProjectID).Updates(deleted)
 	if res.Error != nil {
 		return res.Error
 	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	for _, table := range []string{"public.events", "public.guests"} {
+		if err := tx.Debug().Table(table).Where("project_id = ? AND deleted_at IS NULL", req.ProjectID).Updates(deleted).Error; err != nil {
+			return err
+		}
+	}
+
+	projectID, err := strconv.ParseInt(req.ProjectID, 10, 64)
+	if err != nil {
+		return err
+	}
+	err = jobDb.Enqueue(tx, constant.JobKindCancelDeliveries, &jobModel.CancelDeliveries{
+		ProjectID: projectID,
+		Reason:    "project deleted",
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RestoreProject brings back a deleted project and the events and guests
+// that were deleted with it.
+func (p *ProjectRepository) RestoreProject(ctx context.Context, projectID string) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	restored := map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": nil,
+	}
+
+	// children first, the project's deleted_at is still needed to match them
+	for _, table := range []string{"public.guests", "public.events"} {
+		err := tx.Debug().Table(table).
+			Where("project_id = ? AND deleted_at = (SELECT deleted_at FROM public.projects WHERE project_id = ?)", projectID, projectID).
+			Updates(restored).Error
+		if err != nil {
+			return err
+		}
+	}
 
+	res := tx.Debug().Table("public.projects").Where("project_id = ? AND deleted_at IS NOT NULL", projectID).Updates(restored)
+	if res.Error != nil {
+		return res.Error
+	}
 	if res.RowsAffected == 0 {
 		return gorm.ErrRecordNotFound
 	}
 
-	return nil
+	return tx.Commit().Error
+}
+
+// PurgeProjects permanently removes projects deleted before the given time,
+// at most limit rows. Their events and guests go with them through the
+// foreign keys.
+func (p *ProjectRepository) PurgeProjects(ctx context.Context, before time.Time, limit int) (int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.projects")
+
+	query = query.Where("project_id IN (?)", p.provider.GetDB().Table("public.projects").
+		Select("project_id").Where("deleted_at < ?", before).Limit(limit))
+
+	res := query.Delete(&projectModel.Project{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
 }
 
 func (p *ProjectRepository) GetProjectDetail(ctx context.Context, req *projectModel.GetProjectDetailRequest) (*projectModel.Project, error) {
@@ -120,7 +229,7 @@ func (p *ProjectRepository) GetProjectDetail(ctx context.Context, req *projectMo
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.projects")
 
-	query = query.Where("project_id = ?", req.ProjectID)
+	query = query.Where("project_id = ? AND deleted_at IS NULL", req.ProjectID)
 
 	var project projectModel.Project
 	if err := query.Debug().Find(&project).Error; err != nil {
@@ -2,20 +2,21 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
-	"fmt"
 	"net/http"
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
 	projectModel "rawuh-service/internal/project/model"
 	projectDb "rawuh-service/internal/project/repository"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/cache"
 	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/filter"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
 	"rawuh-service/internal/shared/middleware"
-	"rawuh-service/internal/shared/model"
 	"strconv"
-	"strings"
 
 	"go.elastic.co/apm/v2"
 	"google.golang.org/grpc/codes"
@@ -28,23 +29,36 @@ type ProjectService interface {
 	CreateProject(ctx context.Context, req *projectModel.CreateProjectRequest) error
 	UpdateProject(ctx context.Context, req *projectModel.UpdateProjectRequest) error
 	DeleteProject(ctx context.Context, req *projectModel.DeleteProjectRequest) error
+	RestoreProject(ctx context.Context, req *projectModel.RestoreProjectRequest) (*projectModel.RestoreProjectResponse, error)
 	GetProjectDetail(ctx context.Context, req *projectModel.GetProjectDetailRequest) (*projectModel.GetProjectDetailResponse, error)
 }
 
 type projectService struct {
 	dbProvider *projectDb.ProjectRepository
 	logger     *logger.Logger
-	// redis      *redis.Redis
+	audit      auditService.AuditService
+	cache      *cache.Cache
 }
 
-func NewProjectService(dbProvider *projectDb.ProjectRepository, logger *logger.Logger) ProjectService {
+func NewProjectService(dbProvider *projectDb.ProjectRepository, audit auditService.AuditService, cache *cache.Cache, logger *logger.Logger) ProjectService {
 	return &projectService{
 		dbProvider: dbProvider,
 		logger:     logger,
-		// redis:      redis,
+		audit:      audit,
+		cache:      cache,
 	}
 }
 
+// projectColumns are the columns ListProjects can filter and sort on.
+var projectColumns = filter.Columns{
+	"project_id":   {Type: filter.Int},
+	"project_name": {Type: filter.String},
+	"status":       {Type: filter.Int},
+	"status_desc":  {Type: filter.String},
+	"created_at":   {Type: filter.Time},
+	"updated_at":   {Type: filter.Time},
+}
+
 func (s *projectService) ListProjects(ctx context.Context, req *projectModel.ListProjectRequest) (*projectModel.ListProjectResponse, error) {
 	funcName := "ListProjects"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
@@ -61,53 +75,37 @@ func (s *projectService) ListProjects(ctx context.Context, req *projectModel.Lis
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	decodeQuery, err := base64.RawStdEncoding.DecodeString(req.Query)
-	if err != nil {
-		s.logger.Error("err DecodeString ", err)
-		return nil, nil
-	}
-
-	loggerZap.Info("Success Decode Query")
+	loggerZap.Info("Start Parse Filter")
 
-	pagination := utils.SetPagination(req.Page, req.Limit)
-
-	allowedColumns := map[string]bool{
-		"created_at":   true,
-		"updated_at":   true,
-		"status":       true,
-		"project_name": true,
+	filters, err := filter.Parse(req.Filter, projectColumns)
+	if err != nil {
+		loggerZap.Error("err Parse filter ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	allowedDirections := map[string]bool{
-		"asc":  true,
-		"desc": true,
+	sort, err := projectColumns.Sort(req.Sort, req.Dir)
+	if err != nil {
+		loggerZap.Error("err Sort ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	column := strings.ToLower(req.Sort)
-	direction := strings.ToLower(req.Dir)
-
-	if column != "" || direction != "" {
-
-		if !allowedColumns[column] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
-		}
-		if !allowedDirections[direction] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
-		}
-	}
-	sort := &model.Sort{
-		Column:    column,
-		Direction: direction,
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	sqlBuilder := &db.QueryBuilder{
-		CollectiveAnd: string(decodeQuery),
-		Sort:          sort,
+		Filter: filters,
+		Sort:   sort,
 	}
 
 	loggerZap.Info("Start ListProjects with data ", req)
-	projects, err := s.dbProvider.ListProject(ctx, pagination, sqlBuilder, sort, currentUser.ProjectID)
+	projects, err := s.dbProvider.ListProject(ctx, pagination, sqlBuilder, sort, authz.ProjectIDs(currentUser))
 	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		loggerZap.Error("err ListProjects ", err)
 		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
@@ -142,9 +140,9 @@ func (s *projectService) CreateProject(ctx context.Context, req *projectModel.Cr
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.ProjectCreate, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	nameLength, _ := strconv.Atoi(utils.GetEnv("PRODUCT_NAME_LENGTH", "255"))
@@ -164,12 +162,20 @@ func (s *projectService) CreateProject(ctx context.Context, req *projectModel.Cr
 
 	loggerZap.Info("Start CreateProject with data ", req)
 
-	err := s.dbProvider.CreateProject(ctx, req, currentUser)
+	project, err := s.dbProvider.CreateProject(ctx, req, currentUser)
 	if err != nil {
 		s.logger.Error("err CreateGuest ", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCreate,
+		EntityType: constant.AuditEntityProject,
+		EntityID:   strconv.FormatInt(project.ProjectID, 10),
+		ProjectID:  strconv.FormatInt(project.ProjectID, 10),
+		After:      project,
+	})
+
 	s.logger.Info("Success CreateProject")
 
 	return nil
@@ -197,9 +203,9 @@ func (s *projectService) UpdateProject(ctx context.Context, req *projectModel.Up
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin || req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.ProjectWrite, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	nameLength, _ := strconv.Atoi(utils.GetEnv("PRODUCT_NAME_LENGTH", "255"))
@@ -219,12 +225,33 @@ func (s *projectService) UpdateProject(ctx context.Context, req *projectModel.Up
 
 	loggerZap.Info("Start UpdateProject with data ", req)
 
-	err := s.dbProvider.UpdateProject(ctx, req, currentUser)
+	detailReq := &projectModel.GetProjectDetailRequest{ProjectID: req.ProjectID}
+	before, err := s.dbProvider.GetProjectDetail(ctx, detailReq)
+	if err != nil {
+		s.logger.Error("err GetProjectDetail ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	err = s.dbProvider.UpdateProject(ctx, req, currentUser)
 	if err != nil {
 		s.logger.Error("err UpdateProject ", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	after, err := s.dbProvider.GetProjectDetail(ctx, detailReq)
+	if err != nil {
+		s.logger.Error("err GetProjectDetail ", err)
+	}
+	s.invalidate(ctx, req.ProjectID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUpdate,
+		EntityType: constant.AuditEntityProject,
+		EntityID:   req.ProjectID,
+		ProjectID:  req.ProjectID,
+		Before:     before,
+		After:      after,
+	})
+
 	s.logger.Info("Success UpdateProject")
 
 	return nil
@@ -253,14 +280,20 @@ func (s *projectService) DeleteProject(ctx context.Context, req *projectModel.De
 		return status.Errorf(codes.Aborted, "project id is empty")
 	}
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin || req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.ProjectDelete, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	loggerZap.Info("Start DeleteProject with data ", req)
 
-	err := s.dbProvider.DeleteProject(ctx, req)
+	before, err := s.dbProvider.GetProjectDetail(ctx, &projectModel.GetProjectDetailRequest{ProjectID: req.ProjectID})
+	if err != nil {
+		s.logger.Error("err GetProjectDetail ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	err = s.dbProvider.DeleteProject(ctx, req, currentUser)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			loggerZap.Warn("project not found", err)
@@ -271,19 +304,29 @@ func (s *projectService) DeleteProject(ctx context.Context, req *projectModel.De
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	s.invalidate(ctx, req.ProjectID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityProject,
+		EntityID:   req.ProjectID,
+		ProjectID:  req.ProjectID,
+		Before:     before,
+	})
+
 	s.logger.Info("Success DeleteProject")
 
 	return nil
 }
 
-func (s *projectService) GetProjectDetail(ctx context.Context, req *projectModel.GetProjectDetailRequest) (*projectModel.GetProjectDetailResponse, error) {
-	funcName := "GetProjectDetail"
+// RestoreProject undoes DeleteProject, including the events and guests that
+// were deleted with the project.
+func (s *projectService) RestoreProject(ctx context.Context, req *projectModel.RestoreProjectRequest) (*projectModel.RestoreProjectResponse, error) {
+	funcName := "RestoreProject"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
 	span.Action = constant.SpanActionExecute
 	defer span.End()
 
 	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
-	loggerZap.Debug("Start GetMeFromMD")
 
 	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
 	if !ok {
@@ -291,34 +334,47 @@ func (s *projectService) GetProjectDetail(ctx context.Context, req *projectModel
 		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 
-	loggerZap.Info("Success GetMeFromMD ", currentUser)
-
 	if req.ProjectID == "" {
 		loggerZap.Error("invalid project id", nil)
 		return nil, status.Errorf(codes.Aborted, "project id is empty")
 	}
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin || req.ProjectID != fmt.Sprintf("%d", currentUser.ProjectID) {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.ProjectDelete, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
-	loggerZap.Info("Start GetProjectDetail with data ", req)
+	loggerZap.Info("Start RestoreProject with data ", req)
 
-	project, err := s.dbProvider.GetProjectDetail(ctx, req)
+	err := s.dbProvider.RestoreProject(ctx, req.ProjectID)
 	if err != nil {
-		s.logger.Error("err GetProjectDetail ", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("deleted project not found", err)
+			return nil, status.Error(codes.NotFound, "Deleted project not found")
+		}
+
+		loggerZap.Error("err RestoreProject ", err)
 		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
 
-	if project == nil || project.ProjectID == 0 {
-		loggerZap.Info("project not found", nil)
-		return nil, status.Errorf(codes.NotFound, "project not found")
+	project, err := s.dbProvider.GetProjectDetail(ctx, &projectModel.GetProjectDetailRequest{ProjectID: req.ProjectID})
+	if err != nil {
+		loggerZap.Error("err GetProjectDetail ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
 
-	s.logger.Info("Success GetProjectDetail")
+	s.invalidate(ctx, req.ProjectID)
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionRestore,
+		EntityType: constant.AuditEntityProject,
+		EntityID:   req.ProjectID,
+		ProjectID:  req.ProjectID,
+		After:      project,
+	})
+
+	loggerZap.Info("Success RestoreProject")
 
-	result := &projectModel.GetProjectDetailResponse{
+	result := &projectModel.RestoreProjectResponse{
 		Error:   false,
 		Code:    http.StatusOK,
 		Message: "Success",
@@ -327,3 +383,65 @@ func (s *projectService) GetProjectDetail(ctx context.Context, req *projectModel
 
 	return result, nil
 }
+
+func (s *projectService) GetProjectDetail(ctx context.Context, req *projectModel.GetProjectDetailRequest) (*projectModel.GetProjectDetailResponse, error) {
+	funcName := "GetProjectDetail"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	loggerZap.Debug("Start GetMeFromMD")
+
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	loggerZap.Info("Success GetMeFromMD ", currentUser)
+
+	if req.ProjectID == "" {
+		loggerZap.Error("invalid project id", nil)
+		return nil, status.Errorf(codes.Aborted, "project id is empty")
+	}
+
+	// members narrowed to one event still see the project they belong to
+	if !authz.CanInAny(currentUser, authz.ProjectRead, req.ProjectID) {
+		loggerZap.Error("err Check permission denied", nil)
+		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	}
+
+	loggerZap.Info("Start GetProjectDetail with data ", req)
+
+	return cache.Fetch(ctx, s.cache, "project", []string{cache.ProjectScope(req.ProjectID)}, req, func() (*projectModel.GetProjectDetailResponse, error) {
+		project, err := s.dbProvider.GetProjectDetail(ctx, req)
+		if err != nil {
+			s.logger.Error("err GetProjectDetail ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+
+		if project == nil || project.ProjectID == 0 {
+			loggerZap.Info("project not found", nil)
+			return nil, status.Errorf(codes.NotFound, "project not found")
+		}
+
+		s.logger.Info("Success GetProjectDetail")
+
+		result := &projectModel.GetProjectDetailResponse{
+			Error:   false,
+			Code:    http.StatusOK,
+			Message: "Success",
+			Data:    project,
+		}
+
+		return result, nil
+	})
+}
+
+// invalidate drops everything cached for a project after a change.
+func (s *projectService) invalidate(ctx context.Context, projectID string) {
+	if err := s.cache.Invalidate(ctx, cache.ProjectScope(projectID)); err != nil {
+		s.logger.Error("err Invalidate cache ", err)
+	}
+}
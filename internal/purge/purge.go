@@ -0,0 +1,134 @@
+// Package purge permanently removes projects, events, guests and users that
+// were soft deleted longer ago than the retention. Until then they can be
+// restored.
+package purge
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	eventDb "rawuh-service/internal/event/repository"
+	guestDb "rawuh-service/internal/guest/repository"
+	projectDb "rawuh-service/internal/project/repository"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	userDb "rawuh-service/internal/user/repository"
+)
+
+type Config struct {
+	Retention time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+// ConfigFromEnv reads the purge settings:
+//
+//	SOFT_DELETE_RETENTION  720h, how long deleted rows can be restored
+//	PURGE_INTERVAL         24h, how often the server purges, 0 turns it off
+//	PURGE_BATCH_SIZE       1000 rows per delete statement
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{}
+
+	retention, err := time.ParseDuration(utils.GetEnv("SOFT_DELETE_RETENTION", "720h"))
+	if err != nil || retention <= 0 {
+		return cfg, fmt.Errorf("invalid SOFT_DELETE_RETENTION")
+	}
+	interval, err := time.ParseDuration(utils.GetEnv("PURGE_INTERVAL", "24h"))
+	if err != nil || interval < 0 {
+		return cfg, fmt.Errorf("invalid PURGE_INTERVAL")
+	}
+	batchSize, err := strconv.Atoi(utils.GetEnv("PURGE_BATCH_SIZE", "1000"))
+	if err != nil || batchSize <= 0 {
+		return cfg, fmt.Errorf("invalid PURGE_BATCH_SIZE")
+	}
+
+	cfg.Retention = retention
+	cfg.Interval = interval
+	cfg.BatchSize = batchSize
+
+	return cfg, nil
+}
+
+// Result counts the rows removed per table. Children removed through the
+// foreign keys of a purged parent are not counted.
+type Result struct {
+	Guests   int64
+	Events   int64
+	Projects int64
+	Users    int64
+}
+
+type Job struct {
+	guestDB   *guestDb.GuestRepository
+	eventDB   *eventDb.EventRepository
+	projectDB *projectDb.ProjectRepository
+	userDB    *userDb.UserRepository
+	cfg       Config
+	logger    *logger.Logger
+}
+
+func NewJob(guestDB *guestDb.GuestRepository, eventDB *eventDb.EventRepository, projectDB *projectDb.ProjectRepository, userDB *userDb.UserRepository, cfg Config, logger *logger.Logger) *Job {
+	return &Job{
+		guestDB:   guestDB,
+		eventDB:   eventDB,
+		projectDB: projectDB,
+		userDB:    userDB,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Run removes every row deleted before now minus the retention, children
+// first, in batches so no statement holds its locks for long.
+func (j *Job) Run(ctx context.Context) (*Result, error) {
+	before := time.Now().Add(-j.cfg.Retention)
+	result := &Result{}
+
+	steps := []struct {
+		name  string
+		purge func(ctx context.Context, before time.Time, limit int) (int64, error)
+		count *int64
+	}{
+		{"guests", j.guestDB.PurgeGuests, &result.Guests},
+		{"events", j.eventDB.PurgeEvents, &result.Events},
+		{"projects", j.projectDB.PurgeProjects, &result.Projects},
+		{"users", j.userDB.PurgeUsers, &result.Users},
+	}
+
+	for _, step := range steps {
+		for {
+			n, err := step.purge(ctx, before, j.cfg.BatchSize)
+			if err != nil {
+				return result, fmt.Errorf("purge %s: %w", step.name, err)
+			}
+			*step.count += n
+			if n < int64(j.cfg.BatchSize) {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Start runs the job every interval until ctx is done.
+func (j *Job) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := j.Run(ctx)
+			if err != nil {
+				j.logger.Error("err Purge ", err)
+				continue
+			}
+			j.logger.Info("Success Purge ", result)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	rsvpModel "rawuh-service/internal/rsvp/model"
+	rsvpService "rawuh-service/internal/rsvp/service"
+	"rawuh-service/internal/shared/lib/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type RsvpHandler struct {
+	svc rsvpService.RsvpService
+}
+
+func NewRsvpHandler(svc rsvpService.RsvpService) *RsvpHandler {
+	return &RsvpHandler{svc: svc}
+}
+
+// GetInvitation godoc
+// @Summary Get an invitation
+// @Description Public endpoint returning the invited guest and the event details for an invitation token
+// @Tags rsvp
+// @Accept json
+// @Produce json
+// @Param token path string true "invitation token"
+// @Success 200 {object} rsvpModel.GetInvitationResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 429 {object} utils.APIErrorResponse
+// @Router /rsvp/{token} [get]
+
+func (h *RsvpHandler) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req := &rsvpModel.GetInvitationRequest{
+		Token: mux.Vars(r)["token"],
+	}
+
+	invitation, err := h.svc.GetInvitation(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitation)
+}
+
+// RespondInvitation godoc
+// @Summary Respond to an invitation
+// @Description Public endpoint to accept or decline an invitation, set the number of attendees and answer the guest form
+// @Tags rsvp
+// @Accept json
+// @Produce json
+// @Param token path string true "invitation token"
+// @Param body body rsvpModel.RespondInvitationRequest true "RespondInvitationRequest"
+// @Success 200 {object} rsvpModel.RespondInvitationResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Failure 429 {object} utils.APIErrorResponse
+// @Router /rsvp/{token} [put]
+
+func (h *RsvpHandler) RespondInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &rsvpModel.RespondInvitationResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	var p rsvpModel.RespondInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &rsvpModel.RespondInvitationRequest{
+		Token:     mux.Vars(r)["token"],
+		Status:    p.Status,
+		Attendees: p.Attendees,
+		GuestData: p.GuestData,
+	}
+
+	invitation, err := h.svc.RespondInvitation(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitation)
+}
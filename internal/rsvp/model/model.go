@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Guest is the slice of a guest row that the guest may see about themselves.
+type Guest struct {
+	Name          string
+	RsvpStatus    string
+	RsvpAttendees int32
+	RsvpAt        *time.Time
+	GuestData     string
+}
+
+// Event is the public view of an event, without project or staff fields.
+type Event struct {
+	EventName    string
+	Description  string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	EventOptions string
+	GuestOptions string
+}
@@ -0,0 +1,27 @@
+package model
+
+type GetInvitationRequest struct {
+	Token string
+}
+
+type GetInvitationResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Guest   *Guest
+	Event   *Event
+}
+
+type RespondInvitationRequest struct {
+	Token     string
+	Status    string
+	Attendees int32
+	GuestData string
+}
+
+type RespondInvitationResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Guest   *Guest
+}
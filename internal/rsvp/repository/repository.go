@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	eventModel "rawuh-service/internal/event/model"
+	guestModel "rawuh-service/internal/guest/model"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	webhookDb "rawuh-service/internal/webhook/repository"
+
+	"gorm.io/gorm"
+)
+
+type RsvpRepository struct {
+	provider *db.GormProvider
+}
+
+func NewRsvpRepository(provider *db.GormProvider) *RsvpRepository {
+	return &RsvpRepository{
+		provider: provider,
+	}
+}
+
+func (p *RsvpRepository) GetGuest(ctx context.Context, projectID, eventID, guestID int64) (*guestModel.Guest, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data guestModel.Guest
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND guest_id = ? AND deleted_at IS NULL", projectID, eventID, guestID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (p *RsvpRepository) GetEvent(ctx context.Context, projectID, eventID int64) (*eventModel.Event, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data eventModel.Event
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// UpdateRsvp stores the answer of the guest and tells the webhooks about it
+// in the same transaction.
+func (p *RsvpRepository) UpdateRsvp(ctx context.Context, projectID, eventID, guestID int64, rsvpStatus string, attendees int32, guestData string) (err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	tx := p.provider.NewTransaction().WithContext(timeoutctx)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := tx.Debug().Table("public.guests")
+
+	query = query.Where("project_id = ? AND event_id = ? AND guest_id = ? AND deleted_at IS NULL", projectID, eventID, guestID)
+
+	now := time.Now()
+	res := query.Updates(map[string]interface{}{
+		"rsvp_status":    rsvpStatus,
+		"rsvp_attendees": attendees,
+		"rsvp_at":        &now,
+		"guest_data":     guestData,
+		"updated_at":     &now,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	var guest guestModel.Guest
+	if err = tx.Debug().Table("public.guests").Where("guest_id = ?", guestID).Take(&guest).Error; err != nil {
+		return err
+	}
+	if err = webhookDb.Enqueue(tx, projectID, eventID, constant.WebhookEventRsvpResponded, &guest); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
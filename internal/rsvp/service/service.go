@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	guestModel "rawuh-service/internal/guest/model"
+	liveService "rawuh-service/internal/live/service"
+	rsvpModel "rawuh-service/internal/rsvp/model"
+	rsvpDb "rawuh-service/internal/rsvp/repository"
+	"rawuh-service/internal/shared/cache"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/guestschema"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// RsvpService serves the public invitation pages. Callers are guests, not
+// staff, so every lookup is scoped by the ids signed into the invitation
+// token and never by anything the caller sends.
+type RsvpService interface {
+	GetInvitation(ctx context.Context, req *rsvpModel.GetInvitationRequest) (*rsvpModel.GetInvitationResponse, error)
+	RespondInvitation(ctx context.Context, req *rsvpModel.RespondInvitationRequest) (*rsvpModel.RespondInvitationResponse, error)
+}
+
+type rsvpService struct {
+	dbProvider *rsvpDb.RsvpRepository
+	live       liveService.LiveService
+	cache      *cache.Cache
+	logger     *logger.Logger
+}
+
+func NewRsvpService(dbProvider *rsvpDb.RsvpRepository, live liveService.LiveService, cache *cache.Cache, logger *logger.Logger) RsvpService {
+	return &rsvpService{
+		dbProvider: dbProvider,
+		live:       live,
+		cache:      cache,
+		logger:     logger,
+	}
+}
+
+type invitationScope struct {
+	projectID int64
+	eventID   int64
+	guestID   int64
+}
+
+func parseInvitationToken(token string) (*invitationScope, error) {
+	ids, err := utils.VerifyToken(token, constant.TokenPurposeRsvp)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	return &invitationScope{projectID: ids[0], eventID: ids[1], guestID: ids[2]}, nil
+}
+
+func (s *rsvpService) GetInvitation(ctx context.Context, req *rsvpModel.GetInvitationRequest) (*rsvpModel.GetInvitationResponse, error) {
+	funcName := "GetInvitation"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, nil)
+
+	scope, err := parseInvitationToken(req.Token)
+	if err != nil {
+		loggerZap.Warn("invalid invitation token", err)
+		return nil, status.Error(codes.NotFound, "invitation not found")
+	}
+
+	loggerZap.Info("Start GetGuest")
+	guest, err := s.dbProvider.GetGuest(ctx, scope.projectID, scope.eventID, scope.guestID)
+	if err != nil {
+		loggerZap.Error("err GetGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if guest == nil {
+		loggerZap.Info("invitation guest not found", nil)
+		return nil, status.Error(codes.NotFound, "invitation not found")
+	}
+
+	loggerZap.Info("Start GetEvent")
+	event, err := s.dbProvider.GetEvent(ctx, scope.projectID, scope.eventID)
+	if err != nil {
+		loggerZap.Error("err GetEvent ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if event == nil {
+		loggerZap.Info("invitation event not found", nil)
+		return nil, status.Error(codes.NotFound, "invitation not found")
+	}
+
+	result := &rsvpModel.GetInvitationResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Guest:   toPublicGuest(guest),
+		Event: &rsvpModel.Event{
+			EventName:    event.EventName,
+			Description:  event.Description,
+			StartDate:    event.StartDate,
+			EndDate:      event.EndDate,
+			EventOptions: event.EventOptions,
+			GuestOptions: event.GuestOptions,
+		},
+	}
+
+	return result, nil
+}
+
+func (s *rsvpService) RespondInvitation(ctx context.Context, req *rsvpModel.RespondInvitationRequest) (*rsvpModel.RespondInvitationResponse, error) {
+	funcName := "RespondInvitation"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"status": req.Status, "attendees": req.Attendees})
+
+	scope, err := parseInvitationToken(req.Token)
+	if err != nil {
+		loggerZap.Warn("invalid invitation token", err)
+		return nil, status.Error(codes.NotFound, "invitation not found")
+	}
+
+	maxAttendees, _ := strconv.Atoi(utils.GetEnv("RSVP_MAX_ATTENDEES", "10"))
+
+	loggerZap.Info("Start Validation for req ", nil)
+
+	rsvpStatus := strings.ToUpper(strings.TrimSpace(req.Status))
+	switch rsvpStatus {
+	case constant.RsvpStatusAccepted:
+		if req.Attendees < 1 {
+			return nil, status.Errorf(codes.InvalidArgument, "number of attendees must be at least 1")
+		}
+		if int(req.Attendees) > maxAttendees {
+			return nil, status.Errorf(codes.InvalidArgument, "number of attendees maximum is %d", maxAttendees)
+		}
+	case constant.RsvpStatusDeclined:
+		req.Attendees = 0
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %s or %s", constant.RsvpStatusAccepted, constant.RsvpStatusDeclined)
+	}
+
+	guest, err := s.dbProvider.GetGuest(ctx, scope.projectID, scope.eventID, scope.guestID)
+	if err != nil {
+		loggerZap.Error("err GetGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if guest == nil {
+		loggerZap.Info("invitation guest not found", nil)
+		return nil, status.Error(codes.NotFound, "invitation not found")
+	}
+
+	// answers are merged into the stored data, and only fields the schema
+	// lets guests edit are taken, so keys managed by staff (table number,
+	// side, ...) cannot be changed by a guest submitting the form
+	guestData := map[string]interface{}{}
+	if guest.GuestData != "" {
+		if err := json.Unmarshal([]byte(guest.GuestData), &guestData); err != nil {
+			loggerZap.Warn("stored guest data is not a JSON object", err)
+			guestData = map[string]interface{}{}
+		}
+	}
+	if req.GuestData != "" {
+		var answers map[string]interface{}
+		if err := json.Unmarshal([]byte(req.GuestData), &answers); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid JSON format: %v", err)
+		}
+
+		utils.SanitizeJSON(answers)
+
+		event, err := s.dbProvider.GetEvent(ctx, scope.projectID, scope.eventID)
+		if err != nil {
+			loggerZap.Error("err GetEvent ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+		if event == nil {
+			loggerZap.Info("invitation event not found", nil)
+			return nil, status.Error(codes.NotFound, "invitation not found")
+		}
+		schema, err := guestschema.FromOptions(event.GuestOptions)
+		if err != nil {
+			loggerZap.Error("err FromOptions ", err)
+			return nil, status.Error(codes.FailedPrecondition, "the guest field schema of this event is invalid")
+		}
+
+		answers, errs := schema.CheckAnswers(answers)
+		if len(errs) > 0 {
+			return nil, utils.InvalidFields("invalid guest data: "+errs.Error(), errs.Prefixed("GuestData."))
+		}
+		for k, v := range answers {
+			guestData[k] = v
+		}
+	}
+	mergedData, _ := json.Marshal(guestData)
+
+	loggerZap.Info("Start UpdateRsvp")
+	err = s.dbProvider.UpdateRsvp(ctx, scope.projectID, scope.eventID, scope.guestID, rsvpStatus, req.Attendees, string(mergedData))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("invitation guest not found", err)
+			return nil, status.Error(codes.NotFound, "invitation not found")
+		}
+
+		loggerZap.Error("err UpdateRsvp ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	projectID, eventID := strconv.FormatInt(scope.projectID, 10), strconv.FormatInt(scope.eventID, 10)
+	if err := s.cache.Invalidate(ctx, cache.GuestsScope(projectID, eventID)); err != nil {
+		loggerZap.Error("err Invalidate cache ", err)
+	}
+
+	guest, err = s.dbProvider.GetGuest(ctx, scope.projectID, scope.eventID, scope.guestID)
+	if err != nil || guest == nil {
+		loggerZap.Error("err GetGuest ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.live.Publish(ctx, projectID, eventID, constant.LiveEventGuestUpdated, guest)
+
+	loggerZap.Info("Success RespondInvitation")
+
+	result := &rsvpModel.RespondInvitationResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Guest:   toPublicGuest(guest),
+	}
+
+	return result, nil
+}
+
+func toPublicGuest(guest *guestModel.Guest) *rsvpModel.Guest {
+	rsvpStatus := guest.RsvpStatus
+	if rsvpStatus == "" {
+		rsvpStatus = constant.RsvpStatusPending
+	}
+
+	return &rsvpModel.Guest{
+		Name:          guest.Name,
+		RsvpStatus:    rsvpStatus,
+		RsvpAttendees: guest.RsvpAttendees,
+		RsvpAt:        guest.RsvpAt,
+		GuestData:     guest.GuestData,
+	}
+}
@@ -0,0 +1,209 @@
+// Package authz decides what a user may do. System admins may do everything;
+// every other user gets the permissions of the roles they hold in a project,
+// optionally narrowed down to one event.
+package authz
+
+import (
+	"strconv"
+
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/middleware"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Permission string
+
+const (
+	ProjectCreate Permission = "project:create"
+	ProjectRead   Permission = "project:read"
+	ProjectWrite  Permission = "project:write"
+	ProjectDelete Permission = "project:delete"
+
+	EventCreate Permission = "event:create"
+	EventRead   Permission = "event:read"
+	EventWrite  Permission = "event:write"
+	EventDelete Permission = "event:delete"
+
+	GuestRead    Permission = "guest:read"
+	GuestWrite   Permission = "guest:write"
+	GuestDelete  Permission = "guest:delete"
+	GuestCheckIn Permission = "guest:checkin"
+	GuestImport  Permission = "guest:import"
+	GuestExport  Permission = "guest:export"
+
+	InvitationSend Permission = "invitation:send"
+	MessageSend    Permission = "message:send"
+
+	WebhookManage Permission = "webhook:manage"
+
+	UserManage Permission = "user:manage"
+	RoleManage Permission = "role:manage"
+
+	AuditRead Permission = "audit:read"
+	JobManage Permission = "job:manage"
+)
+
+// rolePermissions is the permission table. Permissions missing here, like
+// project:create, user:manage, audit:read or job:manage, are left to system
+// admins.
+var rolePermissions = map[string][]Permission{
+	constant.RoleProjectOwner: {
+		ProjectRead, ProjectWrite,
+		EventCreate, EventRead, EventWrite, EventDelete,
+		GuestRead, GuestWrite, GuestDelete, GuestCheckIn, GuestImport, GuestExport,
+		InvitationSend, MessageSend,
+		RoleManage, WebhookManage,
+	},
+	constant.RoleEventManager: {
+		ProjectRead,
+		EventRead, EventWrite,
+		GuestRead, GuestWrite, GuestDelete, GuestCheckIn, GuestImport, GuestExport,
+		InvitationSend, MessageSend,
+	},
+	constant.RoleUsher: {
+		ProjectRead,
+		EventRead,
+		GuestRead, GuestCheckIn,
+	},
+	constant.RoleViewer: {
+		ProjectRead,
+		EventRead,
+		GuestRead, GuestExport,
+	},
+}
+
+// IsRole reports whether role is one of the assignable roles.
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Roles returns the assignable roles and their permissions.
+func Roles() map[string][]Permission {
+	roles := make(map[string][]Permission, len(rolePermissions))
+	for role, perms := range rolePermissions {
+		roles[role] = append([]Permission(nil), perms...)
+	}
+	return roles
+}
+
+// Can reports whether user holds perm in the given project and event. An empty
+// eventID asks about the project itself, which only roles held for the whole
+// project answer; a role narrowed to one event does not reach beyond it.
+func Can(user middleware.AuthClaims, perm Permission, projectID string, eventID string) bool {
+	if user.UserType == constant.UserTypeSystemAdmin {
+		return true
+	}
+
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil || pid == 0 {
+		return false
+	}
+	var eid int64
+	if eventID != "" {
+		eid, err = strconv.ParseInt(eventID, 10, 64)
+		if err != nil {
+			return false
+		}
+	}
+
+	for _, grant := range user.Roles {
+		if grant.ProjectID != pid {
+			continue
+		}
+		if grant.EventID != 0 && grant.EventID != eid {
+			continue
+		}
+		if hasPermission(grant.Role, perm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanInAny reports whether user holds perm anywhere in the project, for the
+// whole project or for any one event of it. It is for reads that every
+// member may do, such as seeing the project they belong to.
+func CanInAny(user middleware.AuthClaims, perm Permission, projectID string) bool {
+	if user.UserType == constant.UserTypeSystemAdmin {
+		return true
+	}
+
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil || pid == 0 {
+		return false
+	}
+
+	for _, grant := range user.Roles {
+		if grant.ProjectID == pid && hasPermission(grant.Role, perm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventIDs returns the events of the project user holds perm in through
+// roles narrowed to one event. all is true when user holds perm for the whole
+// project, in which case the ids do not matter.
+func EventIDs(user middleware.AuthClaims, perm Permission, projectID string) (ids []int64, all bool) {
+	if user.UserType == constant.UserTypeSystemAdmin {
+		return nil, true
+	}
+
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil || pid == 0 {
+		return nil, false
+	}
+
+	ids = []int64{}
+	for _, grant := range user.Roles {
+		if grant.ProjectID != pid || !hasPermission(grant.Role, perm) {
+			continue
+		}
+		if grant.EventID == 0 {
+			return nil, true
+		}
+		ids = append(ids, grant.EventID)
+	}
+
+	return ids, false
+}
+
+func hasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Check is Can as a gRPC status error, ready to be returned by a service.
+func Check(user middleware.AuthClaims, perm Permission, projectID string, eventID string) error {
+	if !Can(user, perm, projectID, eventID) {
+		return status.Error(codes.PermissionDenied, "Permission Denied")
+	}
+	return nil
+}
+
+// ProjectIDs returns the projects user holds any role in, or nil for system
+// admins, who see every project.
+func ProjectIDs(user middleware.AuthClaims) []int64 {
+	if user.UserType == constant.UserTypeSystemAdmin {
+		return nil
+	}
+
+	seen := map[int64]bool{}
+	ids := []int64{}
+	for _, grant := range user.Roles {
+		if !seen[grant.ProjectID] {
+			seen[grant.ProjectID] = true
+			ids = append(ids, grant.ProjectID)
+		}
+	}
+	return ids
+}
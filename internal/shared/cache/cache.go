@@ -0,0 +1,214 @@
+// Package cache keeps read results in Redis until they expire or something in
+// their scope changes.
+//
+// Every cached result names the scopes it was read from, such as the guests of
+// one event. A scope has a version in Redis, and the versions of its scopes are
+// part of a result's key; a write replaces the version of the scopes it
+// touches, so results read before it are never found again and simply expire:
+//
+//	cache:version:<scope>                  version of a scope
+//	cache:<name>:<versions>:<request hash> a result
+//
+// Callers check permissions before asking the cache, so the key only holds
+// the request and the project or event it reads, not the user.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/model"
+	"rawuh-service/internal/shared/redis"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	keyPrefix     = "cache:"
+	versionPrefix = "cache:version:"
+
+	// minVersionTTL keeps versions well beyond the results made with them. A
+	// version must outlive those results, or an expired and recreated version
+	// could find them again.
+	minVersionTTL = 24 * time.Hour
+)
+
+// metrics counts per cached read, as <name>.hit, <name>.miss, <name>.shared
+// for misses served by a read already running, and <name>.error for Redis
+// failures. It is served with the other expvars on /debug/vars.
+var metrics = expvar.NewMap("cache")
+
+type Config struct {
+	TTL time.Duration
+}
+
+// ConfigFromEnv reads the cache settings:
+//
+//	CACHE_TTL  60s, how long a result is kept, 0 turns the cache off
+func ConfigFromEnv() (Config, error) {
+	ttl, err := time.ParseDuration(utils.GetEnv("CACHE_TTL", "60s"))
+	if err != nil || ttl < 0 {
+		return Config{}, fmt.Errorf("invalid CACHE_TTL")
+	}
+	return Config{TTL: ttl}, nil
+}
+
+// Cache is safe for concurrent use. A nil Cache, or one with a TTL of 0,
+// reads straight through.
+type Cache struct {
+	rdb        *redis.Redis
+	ttl        time.Duration
+	versionTTL time.Duration
+	group      singleflight.Group
+}
+
+func New(rdb *redis.Redis, cfg Config) *Cache {
+	versionTTL := 2 * cfg.TTL
+	if versionTTL < minVersionTTL {
+		versionTTL = minVersionTTL
+	}
+	return &Cache{rdb: rdb, ttl: cfg.TTL, versionTTL: versionTTL}
+}
+
+// ProjectScope covers a project itself.
+func ProjectScope(projectID string) string {
+	return "project:" + projectID
+}
+
+// EventsScope covers which events a project has.
+func EventsScope(projectID string) string {
+	return "events:" + projectID
+}
+
+// EventScope covers an event itself.
+func EventScope(projectID string, eventID string) string {
+	return "event:" + projectID + ":" + eventID
+}
+
+// GuestsScope covers the guests of an event.
+func GuestsScope(projectID string, eventID string) string {
+	return "guests:" + projectID + ":" + eventID
+}
+
+// Fetch returns the result cached for name and request in scopes, or calls
+// load and caches what it returns. Concurrent misses of the same key in this
+// process share one load. Errors of load are returned and not cached; when
+// Redis fails, load is called as if the cache were off.
+func Fetch[T any](ctx context.Context, c *Cache, name string, scopes []string, request interface{}, load func() (*T, error)) (*T, error) {
+	if c == nil || c.ttl <= 0 {
+		return load()
+	}
+
+	key, err := c.key(ctx, name, scopes, request)
+	if err != nil {
+		metrics.Add(name+".error", 1)
+		return load()
+	}
+
+	if raw, err := c.rdb.Get(ctx, key); err == nil {
+		var result T
+		if err := json.Unmarshal([]byte(raw), &result); err == nil {
+			metrics.Add(name+".hit", 1)
+			return &result, nil
+		}
+	} else if err != redis.Nil {
+		metrics.Add(name+".error", 1)
+	}
+
+	metrics.Add(name+".miss", 1)
+	// every caller decodes its own copy, so none of them shares a result
+	// another may change
+	raw, err, shared := c.group.Do(key, func() (interface{}, error) {
+		result, err := load()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.rdb.Set(context.WithoutCancel(ctx), key, json.RawMessage(raw), c.ttl); err != nil {
+			metrics.Add(name+".error", 1)
+		}
+		return raw, nil
+	})
+	if shared {
+		metrics.Add(name+".shared", 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := json.Unmarshal(raw.([]byte), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Invalidate drops every result read from scopes. It is best effort: if
+// Redis fails, results expire after the TTL.
+func (c *Cache) Invalidate(ctx context.Context, scopes ...string) error {
+	if c == nil || c.ttl <= 0 {
+		return nil
+	}
+
+	version := time.Now().UnixNano()
+	for _, scope := range scopes {
+		if err := c.rdb.Set(ctx, versionPrefix+scope, version, c.versionTTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) key(ctx context.Context, name string, scopes []string, request interface{}) (string, error) {
+	versionKeys := make([]string, len(scopes))
+	for i, scope := range scopes {
+		versionKeys[i] = versionPrefix + scope
+	}
+	versions, err := c.rdb.MGet(ctx, versionKeys...)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(struct {
+		Scopes  []string
+		Request interface{}
+	}{scopes, request})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+
+	return keyPrefix + name + ":" + strings.Join(versions, ".") + ":" + hex.EncodeToString(sum[:16]), nil
+}
+
+// ListRequest is what decides the result of a list request: its filter
+// parameters, sort and page.
+func ListRequest(values url.Values, sort string, dir string, pagination *model.PaginationResponse) interface{} {
+	filters := url.Values{}
+	for key, vals := range values {
+		if strings.HasPrefix(key, "filter[") {
+			filters[key] = vals
+		}
+	}
+
+	return struct {
+		Filter    url.Values
+		Sort      string
+		Dir       string
+		Page      int32
+		Limit     int32
+		Cursor    *string
+		SkipCount bool
+	}{filters, sort, strings.ToLower(dir), pagination.Page, pagination.Limit, pagination.Cursor, pagination.SkipCount}
+}
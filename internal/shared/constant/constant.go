@@ -16,4 +16,72 @@ const (
 
 	UserTypeSystemAdmin = "SYSTEM_ADMIN"
 	UserTypeProjectUser = "PROJECT_USER"
+
+	RoleProjectOwner = "PROJECT_OWNER"
+	RoleEventManager = "EVENT_MANAGER"
+	RoleUsher        = "USHER"
+	RoleViewer       = "VIEWER"
+
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete"
+	AuditActionRestore     = "restore"
+	AuditActionImport      = "import"
+	AuditActionCheckIn     = "check_in"
+	AuditActionUndoCheckIn = "undo_check_in"
+	AuditActionMerge       = "merge"
+	AuditActionSend        = "send"
+	AuditActionRetry       = "retry"
+
+	AuditEntityProject  = "project"
+	AuditEntityEvent    = "event"
+	AuditEntityGuest    = "guest"
+	AuditEntityUser     = "user"
+	AuditEntityUserRole = "user_role"
+
+	AuditEntityInvitationTemplate = "invitation_template"
+	AuditEntityInvitationBatch    = "invitation_batch"
+	AuditEntityMessageTemplate    = "message_template"
+	AuditEntityMessageBroadcast   = "message_broadcast"
+	AuditEntityJob                = "job"
+	AuditEntityWebhook            = "webhook"
+
+	TokenPurposeGuestQR = "guest_qr"
+	TokenPurposeRsvp    = "rsvp"
+
+	RsvpStatusPending  = "PENDING"
+	RsvpStatusAccepted = "ACCEPTED"
+	RsvpStatusDeclined = "DECLINED"
+
+	DeliveryStatusPending = "PENDING"
+	DeliveryStatusSending = "SENDING"
+	DeliveryStatusSent    = "SENT"
+	DeliveryStatusFailed  = "FAILED"
+
+	JobStatusPending = "PENDING"
+	JobStatusRunning = "RUNNING"
+
+	JobKindCancelDeliveries = "deliveries.cancel"
+	JobKindPublishWebhook   = "webhooks.publish"
+
+	WebhookEventGuestCreated       = "guest.created"
+	WebhookEventGuestUpdated       = "guest.updated"
+	WebhookEventGuestDeleted       = "guest.deleted"
+	WebhookEventGuestRestored      = "guest.restored"
+	WebhookEventGuestMerged        = "guest.merged"
+	WebhookEventGuestImported      = "guest.imported"
+	WebhookEventGuestCheckedIn     = "guest.checked_in"
+	WebhookEventGuestCheckInUndone = "guest.check_in_undone"
+	WebhookEventEventCreated       = "event.created"
+	WebhookEventEventUpdated       = "event.updated"
+	WebhookEventEventDeleted       = "event.deleted"
+	WebhookEventEventRestored      = "event.restored"
+	WebhookEventRsvpResponded      = "rsvp.responded"
+	WebhookEventTest               = "webhook.test"
+
+	LiveEventSnapshot       = "snapshot"
+	LiveEventCounts         = "counts"
+	LiveEventGuestCreated   = "guest.created"
+	LiveEventGuestUpdated   = "guest.updated"
+	LiveEventGuestCheckedIn = "guest.checked_in"
 )
@@ -3,15 +3,15 @@ package db
 import (
 	"fmt"
 	"math"
+	"rawuh-service/internal/shared/filter"
 	"rawuh-service/internal/shared/model"
 
-	"strings"
-
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func Paginate(value interface{}, v *model.PaginationResponse, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
-	if v.Limit > 0 || v.Page > 0 {
+	if (v.Limit > 0 || v.Page > 0) && !v.SkipCount {
 		var totalRows int64
 		db.Model(value).Count(&totalRows)
 
@@ -38,51 +38,12 @@ func Sort(s *model.Sort) func(db *gorm.DB) *gorm.DB {
 		if s == nil || s.Column == "" || s.Direction == "" {
 			return db
 		}
-		return db.Order(fmt.Sprintf("%s %s", s.Column, s.Direction))
-	}
-}
-
-func QueryScoop(v string) func(db *gorm.DB) *gorm.DB {
-	return func(db *gorm.DB) *gorm.DB {
-		if v == "" {
-			return db
-		}
-
-		parts := strings.SplitN(v, ":", 2)
-		if len(parts) != 2 {
-			return db
-		}
-
-		columns := strings.Split(parts[0], ",")
-		value := parts[1]
-
-		var condition string
-		var searchValue string
-
-		switch {
-		case strings.HasPrefix(value, "%%"):
-			condition = "LIKE"
-			searchValue = "%" + value[2:] + "%"
-		case strings.HasPrefix(value, "%!"):
-			condition = "ILIKE"
-			searchValue = "%" + value[2:] + "%"
-		default:
-			condition = "="
-			searchValue = value
+		if len(s.Path) > 0 {
+			return db.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "? " + s.Direction,
+				Vars: []interface{}{filter.JSONPath(s.Column, s.Path)},
+			}})
 		}
-
-		// Build condition: (col1 ILIKE ?) OR (col2 ILIKE ?) ...
-		orQuery := db
-		for i, col := range columns {
-			col = strings.TrimSpace(col)
-			clause := fmt.Sprintf("%s %s ?", col, condition)
-			if i == 0 {
-				orQuery = orQuery.Where(clause, searchValue)
-			} else {
-				orQuery = orQuery.Or(clause, searchValue)
-			}
-		}
-
-		return orQuery
+		return db.Order(fmt.Sprintf("%s %s", s.Column, s.Direction))
 	}
 }
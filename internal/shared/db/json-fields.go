@@ -0,0 +1,129 @@
+package db
+
+import (
+	"encoding/json"
+	"rawuh-service/internal/shared/filter"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxJSONDepth is how deep RecordJSONFields looks into nested objects.
+const MaxJSONDepth = 3
+
+// JSONField is a path used inside a jsonb column, such as "meal.type", and
+// the JSON type of its values. They are kept in public.json_fields as the
+// data is written, so list filters can check a path without reading the
+// rows. Fields of guests are kept per event, fields of events per project
+// with EventID 0.
+type JSONField struct {
+	ProjectID  int64  `gorm:"primaryKey"`
+	EventID    int64  `gorm:"primaryKey"`
+	ColumnName string `gorm:"primaryKey;type:varchar(50)"`
+	Path       string `gorm:"primaryKey;type:varchar(500)"`
+	Type       string `gorm:"type:varchar(20)"`
+}
+
+// RecordJSONFields adds the paths used inside docs, values of column written
+// in tx, to the fields of the project and event. A path holding different
+// JSON types is typed as a string. Arrays, nulls and keys containing a dot
+// are left out.
+func RecordJSONFields(tx *gorm.DB, projectID int64, eventID int64, column string, docs ...string) error {
+	types := map[string]string{}
+	for _, doc := range docs {
+		var value interface{}
+		if err := json.Unmarshal([]byte(doc), &value); err != nil {
+			continue
+		}
+		collectJSONFields(value, "", 1, types)
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	fields := make([]*JSONField, 0, len(types))
+	for path, typ := range types {
+		fields = append(fields, &JSONField{
+			ProjectID:  projectID,
+			EventID:    eventID,
+			ColumnName: column,
+			Path:       path,
+			Type:       typ,
+		})
+	}
+	// the same order in every transaction, so two writers cannot deadlock
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+
+	return tx.Debug().Table("public.json_fields").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "event_id"}, {Name: "column_name"}, {Name: "path"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"type": "string"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "json_fields.type <> excluded.type"}}},
+	}).CreateInBatches(fields, 500).Error
+}
+
+func collectJSONFields(value interface{}, prefix string, depth int, types map[string]string) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for key, v := range object {
+		if strings.Contains(key, ".") {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		var typ string
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if depth < MaxJSONDepth {
+				collectJSONFields(v, path, depth+1, types)
+			}
+			continue
+		case string:
+			typ = "string"
+		case float64:
+			typ = "number"
+		case bool:
+			typ = "boolean"
+		default:
+			continue
+		}
+
+		if seen, ok := types[path]; ok && seen != typ {
+			typ = "string"
+		}
+		types[path] = typ
+	}
+}
+
+// JSONFields returns the fields query selects from public.json_fields, keyed
+// by column and then by path.
+func JSONFields(query *gorm.DB, columns ...string) (map[string]map[string]filter.Type, error) {
+	var rows []*JSONField
+	if err := query.Where("column_name IN ?", columns).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	fields := map[string]map[string]filter.Type{}
+	for _, column := range columns {
+		fields[column] = map[string]filter.Type{}
+	}
+	for _, row := range rows {
+		typ := filter.String
+		switch row.Type {
+		case "number":
+			typ = filter.Number
+		case "boolean":
+			typ = filter.Bool
+		}
+		fields[row.ColumnName][row.Path] = typ
+	}
+
+	return fields, nil
+}
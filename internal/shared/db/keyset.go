@@ -0,0 +1,316 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"rawuh-service/internal/shared/filter"
+	"rawuh-service/internal/shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidCursor is wrapped by every error about the cursor a client sent.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the position of a row in the sort order: the value of the sort
+// column and the primary key that breaks ties. Before asks for the rows in
+// front of the position instead of after it.
+type cursor struct {
+	Sort   string      `json:"s"`
+	Value  interface{} `json:"v"`
+	Key    int64       `json:"k"`
+	Before bool        `json:"b,omitempty"`
+}
+
+var keysetSchemas sync.Map
+
+// Keyset returns one page of query in the order of sort, starting at the
+// cursor of v, and sets the next and previous cursors on v. key is the
+// primary key column; it breaks ties and is the order when sort is empty.
+// Rows are counted into v unless v.SkipCount is set.
+//
+// NULLs sort last in ascending order, as in Postgres. Columns the model reads
+// into a non-pointer field are compared with NULL as the field's zero value,
+// the same value the client sees.
+func Keyset[T any](query *gorm.DB, v *model.PaginationResponse, sort *model.Sort, key string) ([]*T, error) {
+	s, err := schema.Parse(new(T), &keysetSchemas, query.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	keyField := s.LookUpField(key)
+	if keyField == nil {
+		return nil, fmt.Errorf("keyset: no field for column %s", key)
+	}
+
+	order := keysetOrder{direction: "asc", key: keyField}
+	if sort != nil && sort.Column != "" {
+		order.direction = sort.Direction
+		order.column = s.LookUpField(sort.Column)
+		order.path = sort.Path
+		if order.column == nil {
+			return nil, fmt.Errorf("keyset: no field for column %s", sort.Column)
+		}
+	}
+
+	pos := &cursor{}
+	if v.Cursor != nil && *v.Cursor != "" {
+		if pos, err = decodeCursor(*v.Cursor, order.name()); err != nil {
+			return nil, err
+		}
+	}
+
+	if !v.SkipCount {
+		var totalRows int64
+		if err := query.Session(&gorm.Session{}).Count(&totalRows).Error; err != nil {
+			return nil, err
+		}
+		v.TotalRows = totalRows
+		v.TotalPages = int32(math.Ceil(float64(totalRows) / float64(v.Limit)))
+	}
+
+	// pages before the cursor are read backwards and turned around after
+	direction := order.direction
+	if pos.Before {
+		direction = map[string]string{"asc": "desc", "desc": "asc"}[direction]
+	}
+
+	if v.Cursor != nil && *v.Cursor != "" {
+		query = query.Where(order.after(pos, direction))
+	}
+	query = query.Order(order.orderBy(direction))
+
+	var data []*T
+	if err := query.Limit(int(v.Limit) + 1).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(data) > int(v.Limit)
+	if more {
+		data = data[:v.Limit]
+	}
+	if pos.Before {
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+
+	started := v.Cursor != nil && *v.Cursor != ""
+	if len(data) == 0 {
+		// nothing left that way, the way back starts at the cursor itself
+		if started {
+			back := *pos
+			back.Before = !pos.Before
+			if pos.Before {
+				v.NextCursor = encodeCursor(&back)
+			} else {
+				v.PrevCursor = encodeCursor(&back)
+			}
+		}
+		return data, nil
+	}
+
+	first, err := order.cursor(query.Statement.Context, data[0], true)
+	if err != nil {
+		return nil, err
+	}
+	last, err := order.cursor(query.Statement.Context, data[len(data)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	if (pos.Before && more) || (!pos.Before && started) {
+		v.PrevCursor = encodeCursor(first)
+	}
+	if (!pos.Before && more) || pos.Before {
+		v.NextCursor = encodeCursor(last)
+	}
+
+	return data, nil
+}
+
+type keysetOrder struct {
+	column    *schema.Field
+	path      []string
+	direction string
+	key       *schema.Field
+}
+
+// name identifies the sort a cursor was made for.
+func (o keysetOrder) name() string {
+	if o.column == nil {
+		return o.key.DBName + " " + o.direction
+	}
+	return strings.Join(append([]string{o.column.DBName}, o.path...), ".") + " " + o.direction
+}
+
+// nullable reports whether the sorted value can be NULL.
+func (o keysetOrder) nullable() bool {
+	return len(o.path) > 0 || o.column.FieldType.Kind() == reflect.Ptr
+}
+
+func (o keysetOrder) expr() clause.Expression {
+	col := clause.Column{Name: o.column.DBName}
+	switch {
+	case len(o.path) > 0:
+		return filter.JSONPath(o.column.DBName, o.path)
+	case o.nullable():
+		return clause.Expr{SQL: "?", Vars: []interface{}{col}}
+	}
+	zero := reflect.Zero(o.column.FieldType).Interface()
+	return clause.Expr{SQL: "COALESCE(?, ?)", Vars: []interface{}{col, zero}}
+}
+
+func (o keysetOrder) orderBy(direction string) clause.OrderBy {
+	key := clause.OrderByColumn{Column: clause.Column{Name: o.key.DBName}, Desc: direction == "desc"}
+	if o.column == nil {
+		return clause.OrderBy{Columns: []clause.OrderByColumn{key}}
+	}
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  "? " + direction + ", ? " + direction,
+		Vars: []interface{}{o.expr(), clause.Column{Name: o.key.DBName}},
+	}}
+}
+
+// after matches the rows that come after pos when read in direction.
+func (o keysetOrder) after(pos *cursor, direction string) clause.Expression {
+	op := map[string]string{"asc": ">", "desc": "<"}[direction]
+	key := clause.Column{Name: o.key.DBName}
+	keyAfter := clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{key, pos.Key}}
+	if o.column == nil {
+		return keyAfter
+	}
+
+	at := o.expr()
+	var value interface{} = pos.Value
+	if len(o.path) > 0 && pos.Value != nil {
+		value = clause.Expr{SQL: "CAST(? AS jsonb)", Vars: []interface{}{pos.Value}}
+	}
+	isNull := clause.Expr{SQL: "? IS NULL", Vars: []interface{}{at}}
+	isNotNull := clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{at}}
+
+	// NULL is greater than every value, so it comes last going up and
+	// first going down
+	if pos.Value == nil && o.nullable() {
+		if direction == "asc" {
+			return clause.And(isNull, keyAfter)
+		}
+		return clause.Or(isNotNull, clause.And(isNull, keyAfter))
+	}
+
+	exprs := []clause.Expression{
+		clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{at, value}},
+		clause.And(clause.Expr{SQL: "? = ?", Vars: []interface{}{at, value}}, keyAfter),
+	}
+	if direction == "asc" && o.nullable() {
+		exprs = append(exprs, isNull)
+	}
+	return clause.Or(exprs...)
+}
+
+// cursor returns the position of row.
+func (o keysetOrder) cursor(ctx context.Context, row interface{}, before bool) (*cursor, error) {
+	rv := reflect.ValueOf(row)
+	key, _ := o.key.ValueOf(ctx, rv)
+	keyValue, ok := key.(int64)
+	if !ok {
+		return nil, fmt.Errorf("keyset: %s is not an int64", o.key.DBName)
+	}
+
+	c := &cursor{Sort: o.name(), Key: keyValue, Before: before}
+	if o.column == nil {
+		return c, nil
+	}
+
+	value, _ := o.column.ValueOf(ctx, rv)
+	if len(o.path) > 0 {
+		value = jsonPathValue(value, o.path)
+	} else if p := reflect.ValueOf(value); p.Kind() == reflect.Ptr {
+		if p.IsNil() {
+			value = nil
+		} else {
+			value = p.Elem().Interface()
+		}
+	}
+	c.Value = value
+
+	return c, nil
+}
+
+// jsonPathValue returns the JSON found at path inside doc, or nil when there
+// is none, as #> does.
+func jsonPathValue(doc interface{}, path []string) interface{} {
+	var raw []byte
+	switch d := doc.(type) {
+	case string:
+		raw = []byte(d)
+	case []byte:
+		raw = d
+	default:
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil
+	}
+	for _, p := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = object[p]; !ok {
+			return nil
+		}
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return string(out)
+}
+
+func encodeCursor(c *cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string, sort string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	c := &cursor{}
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	if c.Sort != sort {
+		return nil, fmt.Errorf("%w: it was made for another sort", ErrInvalidCursor)
+	}
+
+	// numbers come back as json.Number, bind them as numbers
+	if n, ok := c.Value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			c.Value = i
+		} else if f, err := n.Float64(); err == nil {
+			c.Value = f
+		}
+	}
+
+	return c, nil
+}
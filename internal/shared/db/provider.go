@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"rawuh-service/internal/shared/filter"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/model"
 	"strconv"
@@ -13,9 +14,8 @@ import (
 // var location, _ = time.LoadLocation("Asia/Jakarta")
 
 type QueryBuilder struct {
-	CollectiveAnd string
-	CustomOrder   string
-	Sort          *model.Sort
+	Filter *filter.Filter
+	Sort   *model.Sort
 }
 
 type GormProvider struct {
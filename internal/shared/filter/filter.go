@@ -0,0 +1,393 @@
+// Package filter parses the filter query parameters of the list endpoints
+// into parameterized GORM conditions.
+//
+// A condition is written as filter[column][op]=value. Conditions are AND-ed:
+//
+//	filter[name][ilike]=budi&filter[created_at][gte]=2025-01-01
+//
+// Conditions that share a group under filter[or] are OR-ed with each other,
+// and the group as a whole is AND-ed with the rest:
+//
+//	filter[or][q][name][ilike]=budi&filter[or][q][email][ilike]=budi
+//
+// JSON columns are filtered by path, such as filter[guest_data.table][eq]=5;
+// see json.go.
+//
+// Only the columns of the entity's Columns may be used, and values are always
+// bound as parameters.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rawuh-service/internal/shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// MaxConditions caps the conditions of one request.
+	MaxConditions = 20
+	// MaxValues caps the values of one in, nin or between condition.
+	MaxValues = 100
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Number
+	Time
+	Bool
+	JSON
+)
+
+// ErrInvalid is wrapped by every error caused by the request itself, as
+// opposed to one returned by a FieldsFunc.
+var ErrInvalid = errors.New("invalid filter")
+
+type Op string
+
+const (
+	Eq      Op = "eq"
+	Ne      Op = "ne"
+	Gt      Op = "gt"
+	Gte     Op = "gte"
+	Lt      Op = "lt"
+	Lte     Op = "lte"
+	Like    Op = "like"
+	ILike   Op = "ilike"
+	In      Op = "in"
+	NotIn   Op = "nin"
+	Between Op = "between"
+	Null    Op = "null"
+)
+
+// FieldsFunc returns the paths inside a JSON column, such as "table" or
+// "meal.type", and the type of their values.
+type FieldsFunc func() (map[string]Type, error)
+
+// Column is a column that may be filtered and sorted on.
+type Column struct {
+	Type Type
+	// Fields lists the paths of a JSON column. It is only called once a
+	// filter or sort uses the column.
+	Fields FieldsFunc
+}
+
+// Columns is the whitelist of an entity, keyed by the column name used in the
+// query string and in SQL.
+type Columns map[string]Column
+
+// WithJSON returns a copy of c with the named JSON columns added. load returns
+// the fields of every column keyed by column name; it runs at most once, the
+// first time a filter or sort uses one of them.
+func (c Columns) WithJSON(load func() (map[string]map[string]Type, error), names ...string) Columns {
+	var (
+		once   sync.Once
+		fields map[string]map[string]Type
+		err    error
+	)
+
+	columns := make(Columns, len(c)+len(names))
+	for name, column := range c {
+		columns[name] = column
+	}
+	for _, name := range names {
+		columns[name] = Column{
+			Type: JSON,
+			Fields: func() (map[string]Type, error) {
+				once.Do(func() { fields, err = load() })
+				return fields[name], err
+			},
+		}
+	}
+	return columns
+}
+
+// Sort validates the sort and dir parameters against the whitelist. Both are
+// optional, but if one is given both must be valid.
+func (c Columns) Sort(column string, direction string) (*model.Sort, error) {
+	direction = strings.ToLower(direction)
+
+	sort := &model.Sort{Direction: direction}
+	if column != "" || direction != "" {
+		name, path, _, err := c.lookup(column)
+		if err != nil {
+			if errors.Is(err, ErrInvalid) {
+				return nil, fmt.Errorf("%w: cannot sort on %q", ErrInvalid, column)
+			}
+			return nil, err
+		}
+		if direction != "asc" && direction != "desc" {
+			return nil, fmt.Errorf("%w: invalid sort direction %q", ErrInvalid, direction)
+		}
+		sort.Column = name
+		sort.Path = path
+	}
+
+	return sort, nil
+}
+
+// lookup resolves a column, or column.path for JSON columns, to the column
+// name, the path and the type of the value.
+func (c Columns) lookup(name string) (string, []string, Type, error) {
+	base, rest, isPath := strings.Cut(name, ".")
+	base = strings.ToLower(base)
+
+	column, ok := c[base]
+	if !ok || isPath != (column.Type == JSON) {
+		return "", nil, 0, fmt.Errorf("%w: cannot filter on %q", ErrInvalid, name)
+	}
+	if !isPath {
+		return base, nil, column.Type, nil
+	}
+
+	fields := map[string]Type{}
+	if column.Fields != nil {
+		var err error
+		if fields, err = column.Fields(); err != nil {
+			return "", nil, 0, err
+		}
+	}
+	typ, ok := fields[rest]
+	if !ok {
+		return "", nil, 0, fmt.Errorf("%w: cannot filter on %q, no such field", ErrInvalid, name)
+	}
+
+	return base, strings.Split(rest, "."), typ, nil
+}
+
+// Filter is a parsed set of conditions. The zero value and nil match every
+// row.
+type Filter struct {
+	exprs []clause.Expression
+}
+
+// Parse reads every filter[...] parameter of values. Other parameters are
+// ignored.
+func Parse(values url.Values, columns Columns) (*Filter, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	// map order is random, keep the generated SQL stable
+	sort.Strings(keys)
+
+	f := &Filter{}
+	groups := map[string][]clause.Expression{}
+	groupNames := []string{}
+	count := 0
+
+	for _, key := range keys {
+		parts, err := splitKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		group := ""
+		switch {
+		case len(parts) == 2 && strings.ToLower(parts[0]) != "or":
+		case len(parts) == 4 && strings.ToLower(parts[0]) == "or" && parts[1] != "":
+			group = parts[1]
+			parts = parts[2:]
+		default:
+			return nil, fmt.Errorf("%w: %q, use filter[column][op] or filter[or][group][column][op]", ErrInvalid, key)
+		}
+
+		name, path, typ, err := columns.lookup(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		op := Op(strings.ToLower(parts[1]))
+
+		for _, value := range values[key] {
+			count++
+			if count > MaxConditions {
+				return nil, fmt.Errorf("%w: too many filters, at most %d", ErrInvalid, MaxConditions)
+			}
+
+			var expr clause.Expression
+			if path != nil {
+				expr, err = buildJSON(name, path, typ, op, value)
+			} else {
+				expr, err = build(name, typ, op, value)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if group == "" {
+				f.exprs = append(f.exprs, expr)
+				continue
+			}
+			if _, ok := groups[group]; !ok {
+				groupNames = append(groupNames, group)
+			}
+			groups[group] = append(groups[group], expr)
+		}
+	}
+
+	for _, name := range groupNames {
+		f.exprs = append(f.exprs, clause.Or(groups[name]...))
+	}
+
+	return f, nil
+}
+
+// Scope adds the conditions to a query.
+func (f *Filter) Scope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f == nil {
+			return db
+		}
+		for _, expr := range f.exprs {
+			db = db.Where(expr)
+		}
+		return db
+	}
+}
+
+// splitKey turns filter[a][b] into [a b].
+func splitKey(key string) ([]string, error) {
+	rest := strings.TrimPrefix(key, "filter")
+	parts := []string{}
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalid, key)
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return parts, nil
+}
+
+func build(name string, typ Type, op Op, value string) (clause.Expression, error) {
+	col := clause.Column{Name: name}
+
+	switch op {
+	case Null:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value %q for %s[null], use true or false", ErrInvalid, value, name)
+		}
+		if isNull {
+			return clause.Eq{Column: col, Value: nil}, nil
+		}
+		return clause.Neq{Column: col, Value: nil}, nil
+
+	case Like, ILike:
+		if typ != String {
+			return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalid, name, op)
+		}
+		pattern := "%" + escapeLike(value) + "%"
+		if op == Like {
+			return clause.Like{Column: col, Value: pattern}, nil
+		}
+		return clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{col, pattern}}, nil
+
+	case In, NotIn, Between:
+		if typ == Bool && op == Between {
+			return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalid, name, op)
+		}
+		raw := strings.Split(value, ",")
+		if len(raw) > MaxValues {
+			return nil, fmt.Errorf("%w: too many values for %s[%s], at most %d", ErrInvalid, name, op, MaxValues)
+		}
+		vals := make([]interface{}, 0, len(raw))
+		for _, r := range raw {
+			v, err := parseValue(name, typ, r)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+		switch op {
+		case In:
+			return clause.IN{Column: col, Values: vals}, nil
+		case NotIn:
+			return clause.Not(clause.IN{Column: col, Values: vals}), nil
+		}
+		if len(vals) != 2 {
+			return nil, fmt.Errorf("%w: %s[between] takes two values separated by a comma", ErrInvalid, name)
+		}
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{col, vals[0], vals[1]}}, nil
+
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+		if typ == Bool && op != Eq && op != Ne {
+			return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalid, name, op)
+		}
+		v, err := parseValue(name, typ, value)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case Eq:
+			return clause.Eq{Column: col, Value: v}, nil
+		case Ne:
+			return clause.Neq{Column: col, Value: v}, nil
+		case Gt:
+			return clause.Gt{Column: col, Value: v}, nil
+		case Gte:
+			return clause.Gte{Column: col, Value: v}, nil
+		case Lt:
+			return clause.Lt{Column: col, Value: v}, nil
+		}
+		return clause.Lte{Column: col, Value: v}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown filter operator %q", ErrInvalid, op)
+}
+
+func parseValue(name string, typ Type, value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+
+	switch typ {
+	case Number:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q for %s", ErrInvalid, value, name)
+		}
+		return v, nil
+	case Int:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q for %s", ErrInvalid, value, name)
+		}
+		return v, nil
+	case Time:
+		if v, err := time.Parse(time.RFC3339, value); err == nil {
+			return v, nil
+		}
+		v, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time %q for %s, use RFC 3339 or YYYY-MM-DD", ErrInvalid, value, name)
+		}
+		return v, nil
+	case Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid boolean %q for %s", ErrInvalid, value, name)
+		}
+		return v, nil
+	}
+
+	return value, nil
+}
+
+// escapeLike makes value match literally inside a LIKE pattern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
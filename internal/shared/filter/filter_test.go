@@ -0,0 +1,424 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var testColumns = Columns{
+	"name":       {Type: String},
+	"age":        {Type: Int},
+	"score":      {Type: Number},
+	"active":     {Type: Bool},
+	"created_at": {Type: Time},
+}.WithJSON(func() (map[string]map[string]Type, error) {
+	return map[string]map[string]Type{
+		"guest_data": {
+			"table":     Number,
+			"vip":       Bool,
+			"meal.type": String,
+			"side":      String,
+		},
+	}, nil
+}, "guest_data")
+
+// where renders the conditions of f the way Postgres gets them, without a
+// database.
+func where(t *testing.T, f *Filter) (string, []interface{}) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	stmt := db.Table("guests").Scopes(f.Scope()).Find(&[]map[string]interface{}{}).Statement
+	sql := strings.TrimPrefix(stmt.SQL.String(), `SELECT * FROM "guests"`)
+	return strings.TrimPrefix(sql, " WHERE "), stmt.Vars
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		sql    string
+		vars   []interface{}
+	}{
+		{
+			name:   "no filter",
+			values: url.Values{"page": {"2"}},
+			sql:    "",
+		},
+		{
+			name:   "eq",
+			values: url.Values{"filter[name][eq]": {"Budi"}},
+			sql:    `"name" = $1`,
+			vars:   []interface{}{"Budi"},
+		},
+		{
+			name:   "column and operator ignore case",
+			values: url.Values{"filter[NAME][EQ]": {"Budi"}},
+			sql:    `"name" = $1`,
+			vars:   []interface{}{"Budi"},
+		},
+		{
+			name:   "int gte",
+			values: url.Values{"filter[age][gte]": {" 18 "}},
+			sql:    `"age" >= $1`,
+			vars:   []interface{}{int64(18)},
+		},
+		{
+			name:   "date",
+			values: url.Values{"filter[created_at][lt]": {"2025-01-02"}},
+			sql:    `"created_at" < $1`,
+			vars:   []interface{}{time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "bool ne",
+			values: url.Values{"filter[active][ne]": {"true"}},
+			sql:    `"active" <> $1`,
+			vars:   []interface{}{true},
+		},
+		{
+			name:   "null",
+			values: url.Values{"filter[name][null]": {"true"}, "filter[age][null]": {"false"}},
+			sql:    `"age" IS NOT NULL AND "name" IS NULL`,
+		},
+		{
+			name:   "in",
+			values: url.Values{"filter[age][in]": {"1, 2,3"}},
+			sql:    `"age" IN ($1,$2,$3)`,
+			vars:   []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:   "nin",
+			values: url.Values{"filter[name][nin]": {"a,b"}},
+			sql:    `"name" NOT IN ($1,$2)`,
+			vars:   []interface{}{"a", "b"},
+		},
+		{
+			name:   "between",
+			values: url.Values{"filter[score][between]": {"1.5,3"}},
+			sql:    `"score" BETWEEN $1 AND $2`,
+			vars:   []interface{}{1.5, float64(3)},
+		},
+		{
+			name:   "between times",
+			values: url.Values{"filter[created_at][between]": {"2025-01-01,2025-01-31T23:59:59Z"}},
+			sql:    `"created_at" BETWEEN $1 AND $2`,
+			vars:   []interface{}{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)},
+		},
+		{
+			name:   "like escapes wildcards",
+			values: url.Values{"filter[name][like]": {`50%_off\now`}},
+			sql:    `"name" LIKE $1`,
+			vars:   []interface{}{`%50\%\_off\\now%`},
+		},
+		{
+			name:   "ilike",
+			values: url.Values{"filter[name][ilike]": {"budi"}},
+			sql:    `"name" ILIKE $1`,
+			vars:   []interface{}{"%budi%"},
+		},
+		{
+			name:   "repeated values are and-ed",
+			values: url.Values{"filter[name][ne]": {"a", "b"}},
+			sql:    `"name" <> $1 AND "name" <> $2`,
+			vars:   []interface{}{"a", "b"},
+		},
+		{
+			name: "or groups",
+			values: url.Values{
+				"filter[or][q][name][ilike]":   {"budi"},
+				"filter[or][q][name][eq]":      {"Sari"},
+				"filter[or][age][age][lt]":     {"18"},
+				"filter[or][age][score][gt]":   {"90"},
+				"filter[active][eq]":           {"true"},
+				"filter[or][other][age][null]": {"true"},
+			},
+			sql:  `"active" = $1 AND ("age" < $2 OR "score" > $3) AND "age" IS NULL AND ("name" = $4 OR "name" ILIKE $5)`,
+			vars: []interface{}{true, int64(18), float64(90), "Sari", "%budi%"},
+		},
+		{
+			name:   "json eq",
+			values: url.Values{"filter[guest_data.table][eq]": {"5"}},
+			sql:    `"guest_data" @> CAST($1 AS jsonb)`,
+			vars:   []interface{}{`{"table":5}`},
+		},
+		{
+			name:   "json in nested path",
+			values: url.Values{"filter[guest_data.meal.type][in]": {"vegan,halal"}},
+			sql:    `("guest_data" @> CAST($1 AS jsonb) OR "guest_data" @> CAST($2 AS jsonb))`,
+			vars:   []interface{}{`{"meal":{"type":"vegan"}}`, `{"meal":{"type":"halal"}}`},
+		},
+		{
+			name:   "json nin",
+			values: url.Values{"filter[guest_data.side][nin]": {"groom"}},
+			sql:    `"guest_data" #> CAST($1 AS text[]) IS NOT NULL AND NOT "guest_data" @> CAST($2 AS jsonb)`,
+			vars:   []interface{}{`{"side"}`, `{"side":"groom"}`},
+		},
+		{
+			name:   "json ilike",
+			values: url.Values{"filter[guest_data.meal.type][ilike]": {"100%"}},
+			sql:    `"guest_data" #>> CAST($1 AS text[]) ILIKE $2`,
+			vars:   []interface{}{`{"meal","type"}`, `%100\%%`},
+		},
+		{
+			name:   "json between",
+			values: url.Values{"filter[guest_data.table][between]": {"1,4"}},
+			sql:    `jsonb_typeof("guest_data" #> CAST($1 AS text[])) = $2 AND "guest_data" #> CAST($3 AS text[]) BETWEEN CAST($4 AS jsonb) AND CAST($5 AS jsonb)`,
+			vars:   []interface{}{`{"table"}`, "number", `{"table"}`, "1", "4"},
+		},
+		{
+			name:   "json bool ne",
+			values: url.Values{"filter[guest_data.vip][ne]": {"true"}},
+			sql:    `jsonb_typeof("guest_data" #> CAST($1 AS text[])) = $2 AND "guest_data" #> CAST($3 AS text[]) <> CAST($4 AS jsonb)`,
+			vars:   []interface{}{`{"vip"}`, "boolean", `{"vip"}`, "true"},
+		},
+		{
+			name:   "json null",
+			values: url.Values{"filter[guest_data.side][null]": {"true"}},
+			sql:    `COALESCE(jsonb_typeof("guest_data" #> CAST($1 AS text[])), 'null') = 'null'`,
+			vars:   []interface{}{`{"side"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.values, testColumns)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			sql, vars := where(t, f)
+			if sql != tt.sql {
+				t.Errorf("sql = %s\nwant  %s", sql, tt.sql)
+			}
+			if len(vars) != 0 || len(tt.vars) != 0 {
+				if !reflect.DeepEqual(vars, tt.vars) {
+					t.Errorf("vars = %#v, want %#v", vars, tt.vars)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tooMany := make([]string, MaxValues+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i)
+	}
+	conditions := make([]string, MaxConditions+1)
+	for i := range conditions {
+		conditions[i] = "a"
+	}
+
+	tests := []struct {
+		name   string
+		values url.Values
+		want   string
+	}{
+		{"unknown column", url.Values{"filter[password][eq]": {"x"}}, `invalid filter: cannot filter on "password"`},
+		{"quoted column", url.Values{`filter[name" OR 1=1 --][eq]`: {"x"}}, `invalid filter: cannot filter on "name\" OR 1=1 --"`},
+		{"unknown operator", url.Values{"filter[name][regex]": {"x"}}, `invalid filter: unknown filter operator "regex"`},
+		{"no operator", url.Values{"filter[name]": {"x"}}, `invalid filter: "filter[name]", use filter[column][op]`},
+		{"too deep", url.Values{"filter[name][eq][x]": {"x"}}, `invalid filter: "filter[name][eq][x]"`},
+		{"or without group", url.Values{"filter[or][][name][eq]": {"x"}}, `invalid filter: "filter[or][][name][eq]"`},
+		{"unclosed bracket", url.Values{"filter[name][eq": {"x"}}, `invalid filter: "filter[name][eq"`},
+		{"bad int", url.Values{"filter[age][eq]": {"1; DROP TABLE guests"}}, `invalid filter: invalid number "1; DROP TABLE guests" for age`},
+		{"bad int in list", url.Values{"filter[age][in]": {"1,x"}}, `invalid filter: invalid number "x" for age`},
+		{"bad time", url.Values{"filter[created_at][gt]": {"yesterday"}}, `invalid filter: invalid time "yesterday" for created_at`},
+		{"bad bool", url.Values{"filter[active][eq]": {"yes"}}, `invalid filter: invalid boolean "yes" for active`},
+		{"bad null", url.Values{"filter[name][null]": {"maybe"}}, `invalid filter: invalid value "maybe" for name[null]`},
+		{"between one value", url.Values{"filter[age][between]": {"1"}}, "invalid filter: age[between] takes two values"},
+		{"between three values", url.Values{"filter[age][between]": {"1,2,3"}}, "invalid filter: age[between] takes two values"},
+		{"between bools", url.Values{"filter[active][between]": {"false,true"}}, "invalid filter: active does not support between"},
+		{"gt on bool", url.Values{"filter[active][gt]": {"false"}}, "invalid filter: active does not support gt"},
+		{"like on int", url.Values{"filter[age][like]": {"1"}}, "invalid filter: age does not support like"},
+		{"too many values", url.Values{"filter[age][in]": {strings.Join(tooMany, ",")}}, "invalid filter: too many values for age[in]"},
+		{"too many conditions", url.Values{"filter[name][ne]": conditions}, "invalid filter: too many filters"},
+		{"json path not recorded", url.Values{"filter[guest_data.seat][eq]": {"1"}}, `invalid filter: cannot filter on "guest_data.seat", no such field`},
+		{"json parent of a recorded path", url.Values{"filter[guest_data.meal][eq]": {"x"}}, `invalid filter: cannot filter on "guest_data.meal", no such field`},
+		{"json path case", url.Values{"filter[guest_data.Table][eq]": {"1"}}, `invalid filter: cannot filter on "guest_data.Table", no such field`},
+		{"json column without path", url.Values{"filter[guest_data][eq]": {"{}"}}, `invalid filter: cannot filter on "guest_data"`},
+		{"path on plain column", url.Values{"filter[name.first][eq]": {"x"}}, `invalid filter: cannot filter on "name.first"`},
+		{"json bad number", url.Values{"filter[guest_data.table][eq]": {"five"}}, `invalid filter: invalid number "five" for guest_data.table`},
+		{"json like on number", url.Values{"filter[guest_data.table][like]": {"5"}}, "invalid filter: guest_data.table does not support like"},
+		{"json gt on bool", url.Values{"filter[guest_data.vip][gt]": {"true"}}, "invalid filter: guest_data.vip does not support gt"},
+		{"json between one value", url.Values{"filter[guest_data.table][between]": {"1"}}, "invalid filter: guest_data.table[between] takes two values"},
+		{"json unknown operator", url.Values{"filter[guest_data.side][regex]": {"x"}}, `invalid filter: unknown filter operator "regex"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.values, testColumns)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Fatalf("Parse = %v, want %q", err, tt.want)
+			}
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Parse = %v, want it to wrap ErrInvalid", err)
+			}
+		})
+	}
+}
+
+// TestParseKeepsInputOutOfSQL sends hostile values, and a hostile recorded
+// JSON key, through every operator. They may only reach the query as bound
+// parameters.
+func TestParseKeepsInputOutOfSQL(t *testing.T) {
+	hostile := []string{
+		`'; DROP TABLE guests; --`,
+		`") OR ("1"="1`,
+		`x' OR '1'='1`,
+		`1) OR (1=1`,
+		`?`,
+	}
+	columns := testColumns.WithJSON(func() (map[string]map[string]Type, error) {
+		return map[string]map[string]Type{
+			"event_data": {`k'); DROP TABLE guests; --`: String, `a"b`: String},
+		}, nil
+	}, "event_data")
+
+	keys := []string{
+		"filter[name][%s]",
+		"filter[or][g][name][%s]",
+		"filter[guest_data.side][%s]",
+		"filter[event_data.k'); DROP TABLE guests; --][%s]",
+		`filter[event_data.a"b][%s]`,
+	}
+	ops := []Op{Eq, Ne, Gt, Gte, Lt, Lte, Like, ILike, In, NotIn}
+
+	for _, key := range keys {
+		for _, op := range ops {
+			for _, value := range hostile {
+				values := url.Values{fmt.Sprintf(key, op): {value}}
+				f, err := Parse(values, columns)
+				if err != nil {
+					t.Fatalf("Parse(%v): %v", values, err)
+				}
+
+				sql, vars := where(t, f)
+				for _, raw := range []string{value, "DROP", `a"b`} {
+					if strings.Contains(sql, raw) {
+						t.Errorf("Parse(%v): %q is in the SQL: %s", values, raw, sql)
+					}
+				}
+				if n := strings.Count(sql, "$"); n != len(vars) {
+					t.Errorf("Parse(%v): %d placeholders for %d values: %s", values, n, len(vars), sql)
+				}
+			}
+		}
+	}
+}
+
+func TestParseFieldsError(t *testing.T) {
+	failing := errors.New("connection refused")
+	columns := Columns{}.WithJSON(func() (map[string]map[string]Type, error) {
+		return nil, failing
+	}, "guest_data")
+
+	_, err := Parse(url.Values{"filter[guest_data.table][eq]": {"1"}}, columns)
+	if !errors.Is(err, failing) || errors.Is(err, ErrInvalid) {
+		t.Errorf("Parse = %v, want the FieldsFunc error as it is", err)
+	}
+}
+
+func TestWithJSONLoadsOnce(t *testing.T) {
+	loads := 0
+	columns := Columns{"name": {Type: String}}.WithJSON(func() (map[string]map[string]Type, error) {
+		loads++
+		return map[string]map[string]Type{
+			"guest_data": {"table": Number},
+			"event_data": {"room": String},
+		}, nil
+	}, "guest_data", "event_data")
+
+	if _, err := Parse(url.Values{"filter[name][eq]": {"a"}}, columns); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if loads != 0 {
+		t.Fatalf("fields loaded %d times without a JSON filter, want 0", loads)
+	}
+
+	values := url.Values{"filter[guest_data.table][eq]": {"1"}, "filter[event_data.room][eq]": {"A"}}
+	if _, err := Parse(values, columns); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := columns.Sort("guest_data.table", "asc"); err != nil {
+		t.Fatalf("Sort: %v", err)
+	}
+	if loads != 1 {
+		t.Errorf("fields loaded %d times, want 1", loads)
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name      string
+		column    string
+		direction string
+		want      string
+		path      []string
+		wantErr   string
+	}{
+		{name: "none", want: ""},
+		{name: "column", column: "Name", direction: "DESC", want: "name"},
+		{name: "json path", column: "guest_data.meal.type", direction: "asc", want: "guest_data", path: []string{"meal", "type"}},
+		{name: "unknown column", column: "password", direction: "asc", wantErr: `invalid filter: cannot sort on "password"`},
+		{name: "json path not recorded", column: "guest_data.seat", direction: "asc", wantErr: `invalid filter: cannot sort on "guest_data.seat"`},
+		{name: "no direction", column: "name", wantErr: `invalid filter: invalid sort direction ""`},
+		{name: "bad direction", column: "name", direction: "asc; DROP TABLE guests", wantErr: "invalid filter: invalid sort direction"},
+		{name: "direction only", direction: "asc", wantErr: `invalid filter: cannot sort on ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := testColumns.Sort(tt.column, tt.direction)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) || !errors.Is(err, ErrInvalid) {
+					t.Fatalf("Sort = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sort: %v", err)
+			}
+			if sort.Column != tt.want || !reflect.DeepEqual(sort.Path, tt.path) {
+				t.Errorf("Sort = %q %v, want %q %v", sort.Column, sort.Path, tt.want, tt.path)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"budi":    "budi",
+		"100%":    `100\%`,
+		"a_b":     `a\_b`,
+		`c:\dir`:  `c:\\dir`,
+		`\%_`:     `\\\%\_`,
+		"%%":      `\%\%`,
+		"":        "",
+		"O'Brien": "O'Brien",
+	}
+
+	for value, want := range tests {
+		if got := escapeLike(value); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	_, vars := where(t, &Filter{exprs: []clause.Expression{JSONPath("guest_data", []string{"meal", `a"b\c`})}})
+	if want := []interface{}{`{"meal","a\"b\\c"}`}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("vars = %#v, want %#v", vars, want)
+	}
+}
@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// JSON paths are compared as jsonb, so numbers compare as numbers and a
+// string "5" does not equal the number 5. eq and in are written as
+// containment (@>), which the GIN indexes on the JSON columns serve.
+
+// JSONPath is the value at path inside a jsonb column, as jsonb.
+func JSONPath(column string, path []string) clause.Expression {
+	return clause.Expr{SQL: "? #> CAST(? AS text[])", Vars: []interface{}{clause.Column{Name: column}, textArray(path)}}
+}
+
+func buildJSON(column string, path []string, typ Type, op Op, value string) (clause.Expression, error) {
+	name := column + "." + strings.Join(path, ".")
+	col := clause.Column{Name: column}
+	at := JSONPath(column, path)
+
+	switch op {
+	case Null:
+		isNull, err := parseValue(name, Bool, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value %q for %s[null], use true or false", ErrInvalid, value, name)
+		}
+		// a missing key and a JSON null both count as null
+		if isNull.(bool) {
+			return clause.Expr{SQL: "COALESCE(jsonb_typeof(?), 'null') = 'null'", Vars: []interface{}{at}}, nil
+		}
+		return clause.Expr{SQL: "COALESCE(jsonb_typeof(?), 'null') <> 'null'", Vars: []interface{}{at}}, nil
+
+	case Like, ILike:
+		if typ != String {
+			return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalid, name, op)
+		}
+		text := clause.Expr{SQL: "? #>> CAST(? AS text[])", Vars: []interface{}{col, textArray(path)}}
+		return clause.Expr{SQL: "? " + strings.ToUpper(string(op)) + " ?", Vars: []interface{}{text, "%" + escapeLike(value) + "%"}}, nil
+
+	case Eq, In, NotIn:
+		raw := []string{value}
+		if op != Eq {
+			raw = strings.Split(value, ",")
+		}
+		if len(raw) > MaxValues {
+			return nil, fmt.Errorf("%w: too many values for %s[%s], at most %d", ErrInvalid, name, op, MaxValues)
+		}
+		exprs := make([]clause.Expression, 0, len(raw))
+		for _, r := range raw {
+			doc, err := jsonDoc(name, path, typ, r)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, clause.Expr{SQL: "? @> CAST(? AS jsonb)", Vars: []interface{}{col, doc}})
+		}
+		if op != NotIn {
+			return clause.Or(exprs...), nil
+		}
+		// like nin on a plain column, rows without the key do not match
+		return clause.And(
+			clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{at}},
+			clause.Not(clause.Or(exprs...)),
+		), nil
+
+	case Ne, Gt, Gte, Lt, Lte, Between:
+		if typ == Bool && op != Ne {
+			return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalid, name, op)
+		}
+		raw := []string{value}
+		if op == Between {
+			raw = strings.Split(value, ",")
+			if len(raw) != 2 {
+				return nil, fmt.Errorf("%w: %s[between] takes two values separated by a comma", ErrInvalid, name)
+			}
+		}
+		vals := make([]interface{}, 0, len(raw))
+		for _, r := range raw {
+			v, err := jsonValue(name, typ, r)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+
+		if op == Between {
+			return clause.Expr{
+				SQL:  "jsonb_typeof(?) = ? AND ? BETWEEN CAST(? AS jsonb) AND CAST(? AS jsonb)",
+				Vars: []interface{}{at, jsonTypeOf(typ), at, vals[0], vals[1]},
+			}, nil
+		}
+		operator := map[Op]string{Ne: "<>", Gt: ">", Gte: ">=", Lt: "<", Lte: "<="}[op]
+		return clause.Expr{
+			SQL:  "jsonb_typeof(?) = ? AND ? " + operator + " CAST(? AS jsonb)",
+			Vars: []interface{}{at, jsonTypeOf(typ), at, vals[0]},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown filter operator %q", ErrInvalid, op)
+}
+
+// jsonValue encodes value as the JSON of its type.
+func jsonValue(name string, typ Type, value string) (string, error) {
+	var v interface{} = value
+	if typ == Number || typ == Bool {
+		var err error
+		if v, err = parseValue(name, typ, value); err != nil {
+			return "", err
+		}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid value %q for %s", ErrInvalid, value, name)
+	}
+	return string(raw), nil
+}
+
+// jsonDoc nests value under path, {"meal": {"type": "vegan"}} for meal.type.
+func jsonDoc(name string, path []string, typ Type, value string) (string, error) {
+	doc, err := jsonValue(name, typ, value)
+	if err != nil {
+		return "", err
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		key, _ := json.Marshal(path[i])
+		doc = "{" + string(key) + ":" + doc + "}"
+	}
+	return doc, nil
+}
+
+func jsonTypeOf(typ Type) string {
+	switch typ {
+	case Number:
+		return "number"
+	case Bool:
+		return "boolean"
+	}
+	return "string"
+}
+
+// textArray writes path as a Postgres text[] literal.
+func textArray(path []string) string {
+	quoted := make([]string, len(path))
+	for i, p := range path {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
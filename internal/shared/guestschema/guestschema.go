@@ -0,0 +1,451 @@
+// Package guestschema describes the custom guest fields an event defines in
+// its GuestOptions and checks GuestData against them.
+//
+// The schema is the "fields" list of GuestOptions; other keys of GuestOptions
+// are left alone:
+//
+//	{"fields": [
+//	  {"key": "table", "label": "Table", "type": "number", "required": true, "min": 1, "max": 50},
+//	  {"key": "side", "type": "select", "options": ["bride", "groom"]},
+//	  {"key": "diet", "type": "multi_select", "options": ["vegan", "halal", "no nuts"], "max": 2, "guest_editable": true}
+//	]}
+//
+// An event without "fields" has no schema and takes any GuestData from staff.
+// Guests answering their invitation may only set the fields marked
+// guest_editable.
+package guestschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"rawuh-service/internal/shared/filter"
+)
+
+const (
+	TypeText        = "text"
+	TypeNumber      = "number"
+	TypeSelect      = "select"
+	TypeMultiSelect = "multi_select"
+	TypeDate        = "date"
+	TypeBoolean     = "boolean"
+)
+
+// MaxFields caps the fields of one event.
+const MaxFields = 100
+
+const dateLayout = "2006-01-02"
+
+type Field struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label,omitempty"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	// Min and Max bound a number, the length of a text, the number of
+	// choices of a multi_select, or a date given as YYYY-MM-DD.
+	Min interface{} `json:"min,omitempty"`
+	Max interface{} `json:"max,omitempty"`
+	// GuestEditable lets guests set the field when they answer their
+	// invitation. Other fields are managed by staff only.
+	GuestEditable bool `json:"guest_editable,omitempty"`
+}
+
+type Schema struct {
+	Fields []*Field `json:"fields"`
+}
+
+// FieldErrors maps a GuestData key to what is wrong with its value.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]string, len(keys))
+	for i, k := range keys {
+		msgs[i] = k + ": " + e[k]
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Prefixed returns the errors keyed by prefix + key, such as "GuestData.table".
+func (e FieldErrors) Prefixed(prefix string) map[string]string {
+	fields := make(map[string]string, len(e))
+	for k, msg := range e {
+		fields[prefix+k] = msg
+	}
+	return fields
+}
+
+// FromOptions reads the schema out of an event's GuestOptions. It returns nil
+// when GuestOptions has no "fields", and an error when the fields are not a
+// valid schema.
+func FromOptions(guestOptions string) (*Schema, error) {
+	if strings.TrimSpace(guestOptions) == "" {
+		return nil, nil
+	}
+
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(guestOptions), &options); err != nil {
+		return nil, fmt.Errorf("invalid JSON format: %w", err)
+	}
+	raw, ok := options["fields"]
+	if !ok {
+		return nil, nil
+	}
+
+	schema := &Schema{}
+	if err := json.Unmarshal(raw, &schema.Fields); err != nil {
+		return nil, fmt.Errorf("fields must be a list of fields: %w", err)
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// Validate checks the field definitions themselves.
+func (s *Schema) Validate() error {
+	if len(s.Fields) > MaxFields {
+		return fmt.Errorf("at most %d fields are allowed", MaxFields)
+	}
+
+	seen := map[string]bool{}
+	for i, f := range s.Fields {
+		if f == nil {
+			return fmt.Errorf("field %d is empty", i+1)
+		}
+		if strings.TrimSpace(f.Key) == "" || len(f.Key) > 100 || strings.Contains(f.Key, ".") {
+			return fmt.Errorf("field %d: key must be 1 to 100 characters without a dot", i+1)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("field %s is defined twice", f.Key)
+		}
+		seen[f.Key] = true
+
+		switch f.Type {
+		case TypeText, TypeNumber, TypeDate, TypeBoolean:
+			if len(f.Options) > 0 {
+				return fmt.Errorf("field %s: only select and multi_select take options", f.Key)
+			}
+		case TypeSelect, TypeMultiSelect:
+			if len(f.Options) == 0 {
+				return fmt.Errorf("field %s: %s needs options", f.Key, f.Type)
+			}
+			options := map[string]bool{}
+			for _, o := range f.Options {
+				if o == "" || options[o] {
+					return fmt.Errorf("field %s: options must be unique and not empty", f.Key)
+				}
+				options[o] = true
+			}
+		default:
+			return fmt.Errorf("field %s: unknown type %q, use text, number, select, multi_select, date or boolean", f.Key, f.Type)
+		}
+
+		if err := f.validateBounds(); err != nil {
+			return fmt.Errorf("field %s: %w", f.Key, err)
+		}
+	}
+
+	return nil
+}
+
+func (f *Field) validateBounds() error {
+	if f.Min == nil && f.Max == nil {
+		return nil
+	}
+
+	switch f.Type {
+	case TypeBoolean, TypeSelect:
+		return fmt.Errorf("%s takes no min or max", f.Type)
+	case TypeDate:
+		lo, hi := "", ""
+		for _, b := range []struct {
+			value interface{}
+			dest  *string
+		}{{f.Min, &lo}, {f.Max, &hi}} {
+			if b.value == nil {
+				continue
+			}
+			s, ok := b.value.(string)
+			if _, err := time.Parse(dateLayout, s); !ok || err != nil {
+				return fmt.Errorf("min and max must be dates as YYYY-MM-DD")
+			}
+			*b.dest = s
+		}
+		if lo != "" && hi != "" && lo > hi {
+			return fmt.Errorf("min is after max")
+		}
+		return nil
+	}
+
+	lo, hi := math.Inf(-1), math.Inf(1)
+	for _, b := range []struct {
+		value interface{}
+		dest  *float64
+	}{{f.Min, &lo}, {f.Max, &hi}} {
+		if b.value == nil {
+			continue
+		}
+		v, ok := b.value.(float64)
+		if !ok {
+			return fmt.Errorf("min and max must be numbers")
+		}
+		if f.Type != TypeNumber && (v < 0 || v != math.Trunc(v)) {
+			return fmt.Errorf("min and max must be whole numbers of zero or more")
+		}
+		*b.dest = v
+	}
+	if lo > hi {
+		return fmt.Errorf("min is greater than max")
+	}
+	return nil
+}
+
+// Field returns the field with the given key or label, ignoring case, or
+// nil. Imports use it to match column headers.
+func (s *Schema) Field(name string) *Field {
+	if s == nil {
+		return nil
+	}
+	name = strings.TrimSpace(name)
+	for _, f := range s.Fields {
+		if strings.EqualFold(f.Key, name) || (f.Label != "" && strings.EqualFold(f.Label, name)) {
+			return f
+		}
+	}
+	return nil
+}
+
+// CheckAnswers is Check for the answers a guest gives with the RSVP: only
+// guest_editable fields may be set, and required fields may be left out since
+// staff may have set them. A nil schema takes no answers.
+func (s *Schema) CheckAnswers(data map[string]interface{}) (map[string]interface{}, FieldErrors) {
+	errs := FieldErrors{}
+	for key := range data {
+		if f := s.Field(key); f == nil || f.Key != key || !f.GuestEditable {
+			errs[key] = "cannot be answered by guests"
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return s.Check(data, true)
+}
+
+// Check validates data against the schema and returns it with every value
+// converted to its field's type, so "12" becomes 12 for a number and "a, b"
+// becomes ["a", "b"] for a multi_select. With partial set, missing required
+// fields are not reported. A nil schema returns data as is.
+func (s *Schema) Check(data map[string]interface{}, partial bool) (map[string]interface{}, FieldErrors) {
+	if s == nil {
+		return data, nil
+	}
+
+	errs := FieldErrors{}
+	result := make(map[string]interface{}, len(data))
+
+	fields := make(map[string]*Field, len(s.Fields))
+	for _, f := range s.Fields {
+		fields[f.Key] = f
+	}
+	for key := range data {
+		if fields[key] == nil {
+			errs[key] = "is not a field of this event"
+		}
+	}
+
+	for _, f := range s.Fields {
+		value, ok := data[f.Key]
+		if !ok || isEmpty(value) {
+			if f.Required && !partial {
+				errs[f.Key] = "is required"
+			}
+			continue
+		}
+
+		v, err := f.convert(value)
+		if err != nil {
+			errs[f.Key] = err.Error()
+			continue
+		}
+		result[f.Key] = v
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
+
+// FilterTypes returns the fields that list filters may use, with their filter
+// type. multi_select fields hold lists and are left out.
+func (s *Schema) FilterTypes() map[string]filter.Type {
+	types := map[string]filter.Type{}
+	if s == nil {
+		return types
+	}
+	for _, f := range s.Fields {
+		switch f.Type {
+		case TypeNumber:
+			types[f.Key] = filter.Number
+		case TypeBoolean:
+			types[f.Key] = filter.Bool
+		case TypeText, TypeSelect, TypeDate:
+			types[f.Key] = filter.String
+		}
+	}
+	return types
+}
+
+func (f *Field) convert(value interface{}) (interface{}, error) {
+	switch f.Type {
+	case TypeText:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be text")
+		}
+		n := float64(utf8.RuneCountInString(s))
+		if lo, ok := f.Min.(float64); ok && n < lo {
+			return nil, fmt.Errorf("must be at least %v characters", lo)
+		}
+		if hi, ok := f.Max.(float64); ok && n > hi {
+			return nil, fmt.Errorf("must be at most %v characters", hi)
+		}
+		return s, nil
+
+	case TypeNumber:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("must be a number")
+			}
+			n = parsed
+		default:
+			return nil, fmt.Errorf("must be a number")
+		}
+		if lo, ok := f.Min.(float64); ok && n < lo {
+			return nil, fmt.Errorf("must be at least %v", lo)
+		}
+		if hi, ok := f.Max.(float64); ok && n > hi {
+			return nil, fmt.Errorf("must be at most %v", hi)
+		}
+		return n, nil
+
+	case TypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("must be true or false")
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("must be true or false")
+
+	case TypeDate:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a date as YYYY-MM-DD")
+		}
+		s = strings.TrimSpace(s)
+		if _, err := time.Parse(dateLayout, s); err != nil {
+			return nil, fmt.Errorf("must be a date as YYYY-MM-DD")
+		}
+		if lo, ok := f.Min.(string); ok && s < lo {
+			return nil, fmt.Errorf("must be on or after %s", lo)
+		}
+		if hi, ok := f.Max.(string); ok && s > hi {
+			return nil, fmt.Errorf("must be on or before %s", hi)
+		}
+		return s, nil
+
+	case TypeSelect:
+		s, ok := value.(string)
+		if !ok || !f.hasOption(strings.TrimSpace(s)) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(f.Options, ", "))
+		}
+		return strings.TrimSpace(s), nil
+
+	case TypeMultiSelect:
+		var choices []string
+		switch v := value.(type) {
+		case string:
+			for _, c := range strings.Split(v, ",") {
+				choices = append(choices, strings.TrimSpace(c))
+			}
+		case []interface{}:
+			for _, c := range v {
+				s, ok := c.(string)
+				if !ok {
+					return nil, fmt.Errorf("must be a list of %s", strings.Join(f.Options, ", "))
+				}
+				choices = append(choices, s)
+			}
+		default:
+			return nil, fmt.Errorf("must be a list of %s", strings.Join(f.Options, ", "))
+		}
+
+		seen := map[string]bool{}
+		result := []interface{}{}
+		for _, c := range choices {
+			if !f.hasOption(c) {
+				return nil, fmt.Errorf("%q is not one of %s", c, strings.Join(f.Options, ", "))
+			}
+			if !seen[c] {
+				seen[c] = true
+				result = append(result, c)
+			}
+		}
+		n := float64(len(result))
+		if lo, ok := f.Min.(float64); ok && n < lo {
+			return nil, fmt.Errorf("must have at least %v choices", lo)
+		}
+		if hi, ok := f.Max.(float64); ok && n > hi {
+			return nil, fmt.Errorf("must have at most %v choices", hi)
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("unknown type %q", f.Type)
+}
+
+func (f *Field) hasOption(value string) bool {
+	for _, o := range f.Options {
+		if o == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package utils
+
+import "strings"
+
+// PhoneCountryCode returns the country code of phone numbers written without
+// one, read from GUEST_PHONE_COUNTRY_CODE, 62 by default.
+func PhoneCountryCode() string {
+	return strings.TrimPrefix(GetEnv("GUEST_PHONE_COUNTRY_CODE", "62"), "+")
+}
+
+// NormalizePhone returns phone in E.164, such as +628123456789. A number
+// without a country code, with or without the trunk 0, is read as local to
+// countryCode. Anything that is not a phone number gives "".
+func NormalizePhone(phone string, countryCode string) string {
+	var digits strings.Builder
+	plus := false
+	for _, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && digits.Len() == 0 && !plus:
+			plus = true
+		case strings.ContainsRune(" -.()/", r):
+		default:
+			return ""
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case plus:
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = countryCode + number[1:]
+	case strings.HasPrefix(number, countryCode):
+	default:
+		number = countryCode + number
+	}
+
+	// E.164 allows at most 15 digits, and no real number has fewer than 8
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return ""
+	}
+	return "+" + number
+}
@@ -9,13 +9,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	paginationModel "rawuh-service/internal/shared/model"
 
 	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -66,6 +69,28 @@ type APIErrorResponse struct {
 	Error   bool   `json:"Error"`
 	Code    int    `json:"Code"`
 	Message string `json:"Message"`
+	// Fields holds one message per invalid field, when the error has them.
+	Fields map[string]string `json:"Fields,omitempty"`
+}
+
+// InvalidFields is an InvalidArgument error that carries a message per field,
+// which HandleGrpcError returns as Fields.
+func InvalidFields(message string, fields map[string]string) error {
+	st := status.New(codes.InvalidArgument, message)
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for field, description := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: description,
+		})
+	}
+
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
 }
 
 // HandleGrpcError converts a gRPC error into a proper HTTP JSON response
@@ -85,6 +110,10 @@ func HandleGrpcError(w http.ResponseWriter, err error) {
 			httpCode = http.StatusUnauthorized
 		case codes.AlreadyExists:
 			httpCode = http.StatusConflict
+		case codes.FailedPrecondition:
+			httpCode = http.StatusPreconditionFailed
+		case codes.ResourceExhausted:
+			httpCode = http.StatusTooManyRequests
 		default:
 			httpCode = http.StatusInternalServerError
 		}
@@ -94,6 +123,14 @@ func HandleGrpcError(w http.ResponseWriter, err error) {
 			Code:    httpCode,
 			Message: st.Message(), // clean message only
 		}
+		for _, detail := range st.Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				resp.Fields = map[string]string{}
+				for _, v := range badRequest.GetFieldViolations() {
+					resp.Fields[v.GetField()] = v.GetDescription()
+				}
+			}
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(httpCode)
@@ -141,6 +178,41 @@ func SetPagination(page int32, limit int32) *paginationModel.PaginationResponse
 	return res
 }
 
+// SetPaginationMode switches res to cursor mode when cursor is not nil and
+// reads the count flag, "true" or "false". Without the flag rows are counted
+// in page mode and not in cursor mode.
+func SetPaginationMode(res *paginationModel.PaginationResponse, cursor *string, count string) error {
+	res.Cursor = cursor
+	res.SkipCount = cursor != nil
+
+	if count != "" {
+		v, err := strconv.ParseBool(count)
+		if err != nil {
+			return fmt.Errorf("count must be true or false")
+		}
+		res.SkipCount = !v
+	}
+
+	if cursor != nil {
+		res.Page = 0
+		if res.Limit <= 0 {
+			res.Limit = 10
+		}
+	}
+
+	return nil
+}
+
+// QueryCursor returns the cursor query parameter, or nil when it is not
+// given. An empty cursor asks for the first page in cursor mode.
+func QueryCursor(values url.Values) *string {
+	if !values.Has("cursor") {
+		return nil
+	}
+	cursor := values.Get("cursor")
+	return &cursor
+}
+
 // AES-GCM encrypt/decrypt helpers
 // Key is read from env AUTH_AES_KEY and must be 16, 24 or 32 bytes long (AES-128/192/256)
 func EncryptAES(plain string) (string, error) {
@@ -0,0 +1,256 @@
+// Package mailer sends email. Mailer is the transport; SMTPMailer talks to an
+// SMTP server, which may be a local stand-in such as Mailpit during
+// development.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"rawuh-service/internal/shared/lib/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TLSNone     = "none"
+	TLSStartTLS = "starttls"
+	TLSImplicit = "tls"
+)
+
+// Message is one email. To may carry a display name, as in
+// "Budi <budi@example.com>". Either body may be empty, not both.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string
+	Timeout  time.Duration
+}
+
+// ConfigFromEnv reads the SMTP settings:
+//
+//	SMTP_HOST      server host, empty turns email off
+//	SMTP_PORT      587
+//	SMTP_USERNAME  login, empty sends without authenticating
+//	SMTP_PASSWORD  password of SMTP_USERNAME
+//	SMTP_FROM      sender address, such as "RAWUH <no-reply@example.com>"
+//	SMTP_TLS       starttls, tls for implicit TLS, or none
+//	SMTP_TIMEOUT   30s for one message
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Host:     utils.GetEnv("SMTP_HOST", ""),
+		Username: utils.GetEnv("SMTP_USERNAME", ""),
+		Password: utils.GetEnv("SMTP_PASSWORD", ""),
+		From:     utils.GetEnv("SMTP_FROM", ""),
+		TLS:      strings.ToLower(utils.GetEnv("SMTP_TLS", TLSStartTLS)),
+	}
+	if cfg.Host == "" {
+		return cfg, nil
+	}
+
+	port, err := strconv.Atoi(utils.GetEnv("SMTP_PORT", "587"))
+	if err != nil || port <= 0 || port > 65535 {
+		return cfg, fmt.Errorf("invalid SMTP_PORT")
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return cfg, fmt.Errorf("invalid SMTP_FROM")
+	}
+	if cfg.TLS != TLSNone && cfg.TLS != TLSStartTLS && cfg.TLS != TLSImplicit {
+		return cfg, fmt.Errorf("invalid SMTP_TLS, use starttls, tls or none")
+	}
+	timeout, err := time.ParseDuration(utils.GetEnv("SMTP_TIMEOUT", "30s"))
+	if err != nil || timeout <= 0 {
+		return cfg, fmt.Errorf("invalid SMTP_TIMEOUT")
+	}
+
+	cfg.Port = port
+	cfg.Timeout = timeout
+
+	return cfg, nil
+}
+
+// SMTPMailer opens one connection per message.
+type SMTPMailer struct {
+	cfg Config
+	// rootCAs verifies the server certificate; nil uses the system roots.
+	rootCAs *x509.CertPool
+}
+
+// New returns an SMTPMailer, or nil when cfg has no host.
+func New(cfg Config) Mailer {
+	if cfg.Host == "" {
+		return nil
+	}
+	return &SMTPMailer{cfg: cfg}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	body, err := build(from, to, msg)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(m.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: m.cfg.Host, RootCAs: m.rootCAs}
+
+	var conn net.Conn
+	if m.cfg.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if m.cfg.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not offer STARTTLS, set SMTP_TLS=none to send without it")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if m.cfg.Username != "" {
+		// PlainAuth refuses to send the password without TLS, except to
+		// localhost
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// build writes msg as a MIME message with a plain text and an HTML
+// alternative.
+func build(from *mail.Address, to *mail.Address, msg *Message) ([]byte, error) {
+	if msg.HTML == "" && msg.Text == "" {
+		return nil, errors.New("message has no body")
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	// line breaks in a header would start a new header
+	subject := strings.Join(strings.Fields(msg.Subject), " ")
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + uuid.NewString() + "@" + domain + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + parts.Boundary(),
+	}
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	// the last alternative is the preferred one
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
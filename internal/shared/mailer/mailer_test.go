@@ -0,0 +1,430 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubServer is a local SMTP stand-in. It offers STARTTLS when tlsConfig is
+// set and AUTH PLAIN when username is set, refusing AUTH before STARTTLS.
+type stubServer struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	username  string
+	password  string
+
+	mu       sync.Mutex
+	tls      bool
+	authed   bool
+	from     string
+	to       []string
+	data     string
+	commands []string
+}
+
+func newStubServer(t *testing.T, tlsConfig *tls.Config, username, password string) *stubServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &stubServer{ln: ln, tlsConfig: tlsConfig, username: username, password: password}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *stubServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *stubServer) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 stub ESMTP")
+	secure := false
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.mu.Lock()
+		s.commands = append(s.commands, verb)
+		s.mu.Unlock()
+
+		switch verb {
+		case "EHLO", "HELO":
+			ext := []string{"stub"}
+			if s.tlsConfig != nil && !secure {
+				ext = append(ext, "STARTTLS")
+			}
+			if s.username != "" {
+				ext = append(ext, "AUTH PLAIN")
+			}
+			for i, e := range ext {
+				sep := "-"
+				if i == len(ext)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, e)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(tlsConn)
+			secure = true
+			s.mu.Lock()
+			s.tls = true
+			s.mu.Unlock()
+		case "AUTH":
+			if !secure {
+				tp.PrintfLine("538 encryption required")
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 3 || strings.ToUpper(fields[1]) != "PLAIN" {
+				tp.PrintfLine("504 unsupported")
+				continue
+			}
+			raw, _ := base64.StdEncoding.DecodeString(fields[2])
+			if string(raw) != "\x00"+s.username+"\x00"+s.password {
+				tp.PrintfLine("535 authentication failed")
+				continue
+			}
+			s.mu.Lock()
+			s.authed = true
+			s.mu.Unlock()
+			tp.PrintfLine("235 ok")
+		case "MAIL":
+			if s.username != "" && !s.isAuthed() {
+				tp.PrintfLine("530 authentication required")
+				continue
+			}
+			s.mu.Lock()
+			s.from = strings.TrimPrefix(line, "MAIL FROM:")
+			s.mu.Unlock()
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			s.to = append(s.to, strings.TrimPrefix(line, "RCPT TO:"))
+			s.mu.Unlock()
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *stubServer) isAuthed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.authed
+}
+
+// selfSigned returns a certificate for 127.0.0.1 and the pool that trusts it.
+func selfSigned(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stub"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, pool
+}
+
+func newTestMailer(port int, tlsMode, username, password string, rootCAs *x509.CertPool) *SMTPMailer {
+	return &SMTPMailer{
+		cfg: Config{
+			Host:     "127.0.0.1",
+			Port:     port,
+			Username: username,
+			Password: password,
+			From:     "RAWUH <no-reply@rawuh.test>",
+			TLS:      tlsMode,
+			Timeout:  5 * time.Second,
+		},
+		rootCAs: rootCAs,
+	}
+}
+
+func TestSendStartTLSWithAuth(t *testing.T) {
+	serverTLS, pool := selfSigned(t)
+	server := newStubServer(t, serverTLS, "mailer", "s3cret")
+	m := newTestMailer(server.port(), TLSStartTLS, "mailer", "s3cret", pool)
+
+	err := m.Send(context.Background(), &Message{
+		To:      "Budi <budi@example.com>",
+		Subject: "Undangan",
+		HTML:    "<p>Halo Budi</p>",
+		Text:    "Halo Budi",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if !server.tls {
+		t.Error("message was sent without STARTTLS")
+	}
+	if !server.authed {
+		t.Error("mailer did not authenticate")
+	}
+	if server.from != "<no-reply@rawuh.test>" {
+		t.Errorf("MAIL FROM = %q", server.from)
+	}
+	if len(server.to) != 1 || server.to[0] != "<budi@example.com>" {
+		t.Errorf("RCPT TO = %q", server.to)
+	}
+	if !strings.Contains(server.data, "Halo Budi") {
+		t.Errorf("message body missing from DATA:\n%s", server.data)
+	}
+}
+
+func TestSendWrongPassword(t *testing.T) {
+	serverTLS, pool := selfSigned(t)
+	server := newStubServer(t, serverTLS, "mailer", "s3cret")
+	m := newTestMailer(server.port(), TLSStartTLS, "mailer", "wrong", pool)
+
+	err := m.Send(context.Background(), &Message{To: "budi@example.com", Subject: "Hi", Text: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "535") {
+		t.Fatalf("Send = %v, want a 535 error", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "" {
+		t.Error("message was sent after a failed login")
+	}
+}
+
+func TestSendRequiresStartTLS(t *testing.T) {
+	server := newStubServer(t, nil, "", "")
+	m := newTestMailer(server.port(), TLSStartTLS, "", "", nil)
+
+	err := m.Send(context.Background(), &Message{To: "budi@example.com", Subject: "Hi", Text: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("Send = %v, want a STARTTLS error", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "" {
+		t.Error("message was sent in the clear")
+	}
+}
+
+func TestSendUntrustedCertificate(t *testing.T) {
+	serverTLS, _ := selfSigned(t)
+	server := newStubServer(t, serverTLS, "", "")
+	m := newTestMailer(server.port(), TLSStartTLS, "", "", x509.NewCertPool())
+
+	err := m.Send(context.Background(), &Message{To: "budi@example.com", Subject: "Hi", Text: "Hi"})
+	if err == nil {
+		t.Fatal("Send succeeded against an untrusted certificate")
+	}
+}
+
+func TestSendWithoutTLS(t *testing.T) {
+	server := newStubServer(t, nil, "", "")
+	m := newTestMailer(server.port(), TLSNone, "", "", nil)
+
+	err := m.Send(context.Background(), &Message{To: "budi@example.com", Subject: "Hi", Text: "Hi"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.tls {
+		t.Error("STARTTLS was used with SMTP_TLS=none")
+	}
+	for _, verb := range server.commands {
+		if verb == "AUTH" {
+			t.Error("mailer authenticated without a username")
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	from := &mail.Address{Name: "RAWUH", Address: "no-reply@rawuh.test"}
+	to := &mail.Address{Name: "Budi", Address: "budi@example.com"}
+
+	raw, err := build(from, to, &Message{
+		Subject: "Undangan\r\nBcc: someone@example.com pernikahan — Budi",
+		HTML:    "<p>Halo Budi, meja=5</p>",
+		Text:    "Halo Budi, meja=5",
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	if got := msg.Header.Get("Bcc"); got != "" {
+		t.Errorf("subject injected a Bcc header: %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if want := "Undangan Bcc: someone@example.com pernikahan — Budi"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+	if got := msg.Header.Get("From"); got != `"RAWUH" <no-reply@rawuh.test>` {
+		t.Errorf("From = %q", got)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@rawuh.test>") {
+		t.Errorf("Message-ID = %q", msg.Header.Get("Message-ID"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := readParts(t, msg.Body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "Halo Budi, meja=5"},
+		{"text/html; charset=utf-8", "<p>Halo Budi, meja=5</p>"},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(parts), len(want))
+	}
+	for i, w := range want {
+		if parts[i].contentType != w.contentType || parts[i].body != w.body {
+			t.Errorf("part %d = %q %q, want %q %q", i, parts[i].contentType, parts[i].body, w.contentType, w.body)
+		}
+	}
+}
+
+func TestBuildTextOnly(t *testing.T) {
+	from := &mail.Address{Address: "no-reply@rawuh.test"}
+	to := &mail.Address{Address: "budi@example.com"}
+
+	raw, err := build(from, to, &Message{Subject: "Hi", Text: "Halo"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+
+	parts := readParts(t, msg.Body, params["boundary"])
+	if len(parts) != 1 || parts[0].contentType != "text/plain; charset=utf-8" {
+		t.Fatalf("parts = %+v, want only text/plain", parts)
+	}
+
+	if _, err := build(from, to, &Message{Subject: "Hi"}); err == nil {
+		t.Error("build accepted a message without a body")
+	}
+}
+
+type testPart struct {
+	contentType string
+	body        string
+}
+
+func readParts(t *testing.T, body io.Reader, boundary string) []testPart {
+	t.Helper()
+
+	var parts []testPart
+	r := multipart.NewReader(body, boundary)
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("NextRawPart: %v", err)
+		}
+		if enc := p.Header.Get("Content-Transfer-Encoding"); enc != "quoted-printable" {
+			t.Errorf("Content-Transfer-Encoding = %q", enc)
+		}
+		decoded, err := io.ReadAll(quotedprintable.NewReader(bufio.NewReader(p)))
+		if err != nil {
+			t.Fatalf("decode part: %v", err)
+		}
+		parts = append(parts, testPart{contentType: p.Header.Get("Content-Type"), body: string(decoded)})
+	}
+}
@@ -0,0 +1,212 @@
+// Package mergefield fills guest and event values into message templates.
+//
+// A merge field is written as {{Name}} or {{GuestData.table}}; names are
+// matched without regard to case and may have spaces inside the braces.
+// GuestData and EventOptions take a dotted path into the JSON object, as in
+// {{GuestData.seat.row}}.
+package mergefield
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var pattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+const (
+	fieldName         = "name"
+	fieldAddress      = "address"
+	fieldEmail        = "email"
+	fieldPhone        = "phone"
+	fieldEventName    = "eventname"
+	fieldRsvpUrl      = "rsvpurl"
+	fieldGuestData    = "guestdata"
+	fieldEventOptions = "eventoptions"
+)
+
+// Fields are the fields a template can use, as they are documented.
+var Fields = []string{"Name", "Address", "Email", "Phone", "EventName", "RsvpUrl", "GuestData.<key>", "EventOptions.<key>"}
+
+type field struct {
+	root string
+	path []string
+}
+
+// part is literal text, or a merge field when field is set.
+type part struct {
+	text  string
+	field *field
+}
+
+// Template is a parsed template. The zero Template renders as "".
+type Template []part
+
+// Parse splits s into text and merge fields and rejects fields that do not
+// exist, so a typo shows up when the template is saved and not in the
+// guests' inboxes.
+func Parse(s string) (Template, error) {
+	var t Template
+	last := 0
+	for _, m := range pattern.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			t = append(t, part{text: s[last:m[0]]})
+		}
+		f, err := parseField(s[m[2]:m[3]])
+		if err != nil {
+			return nil, err
+		}
+		t = append(t, part{field: f})
+		last = m[1]
+	}
+	if last < len(s) {
+		t = append(t, part{text: s[last:]})
+	}
+	return t, nil
+}
+
+func parseField(s string) (*field, error) {
+	segments := strings.Split(s, ".")
+	f := &field{root: strings.ToLower(segments[0]), path: segments[1:]}
+
+	switch f.root {
+	case fieldName, fieldAddress, fieldEmail, fieldPhone, fieldEventName, fieldRsvpUrl:
+		if len(f.path) > 0 {
+			return nil, fmt.Errorf("merge field {{%s}} has no keys", segments[0])
+		}
+	case fieldGuestData, fieldEventOptions:
+		if len(f.path) == 0 {
+			return nil, fmt.Errorf("merge field {{%s}} needs a key, as in {{%s.key}}", segments[0], segments[0])
+		}
+		for _, key := range f.path {
+			if key == "" {
+				return nil, fmt.Errorf("merge field {{%s}} has an empty key", s)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown merge field {{%s}}, use one of %s", s, strings.Join(Fields, ", "))
+	}
+
+	return f, nil
+}
+
+// Values are what the fields of one guest's message are filled with.
+// GuestData and EventOptions are the JSON objects stored with the guest and
+// the event.
+type Values struct {
+	Name         string
+	Address      string
+	Email        string
+	Phone        string
+	EventName    string
+	RsvpUrl      string
+	GuestData    string
+	EventOptions string
+}
+
+// values is Values ready to look fields up in.
+type values struct {
+	fields       map[string]string
+	guestData    map[string]interface{}
+	eventOptions map[string]interface{}
+}
+
+func (v *Values) prepare() *values {
+	return &values{
+		fields: map[string]string{
+			fieldName:      v.Name,
+			fieldAddress:   v.Address,
+			fieldEmail:     v.Email,
+			fieldPhone:     v.Phone,
+			fieldEventName: v.EventName,
+			fieldRsvpUrl:   v.RsvpUrl,
+		},
+		guestData:    decodeObject(v.GuestData),
+		eventOptions: decodeObject(v.EventOptions),
+	}
+}
+
+// Render fills in the merge fields. A key missing from GuestData or
+// EventOptions renders as nothing.
+func (t Template) Render(v *Values) string {
+	return t.render(v.prepare(), func(s string) string { return s })
+}
+
+// RenderHTML is Render for an HTML body: values are HTML escaped, the
+// template text is not.
+func (t Template) RenderHTML(v *Values) string {
+	return t.render(v.prepare(), html.EscapeString)
+}
+
+func (t Template) render(v *values, escape func(string) string) string {
+	var b strings.Builder
+	for _, p := range t {
+		if p.field == nil {
+			b.WriteString(p.text)
+			continue
+		}
+		b.WriteString(escape(v.lookup(p.field)))
+	}
+	return b.String()
+}
+
+func (v *values) lookup(f *field) string {
+	var value interface{}
+	switch f.root {
+	case fieldGuestData:
+		value = lookupPath(v.guestData, f.path)
+	case fieldEventOptions:
+		value = lookupPath(v.eventOptions, f.path)
+	default:
+		return v.fields[f.root]
+	}
+	return formatValue(value)
+}
+
+func lookupPath(object map[string]interface{}, path []string) interface{} {
+	var value interface{} = object
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = m[key]; !ok {
+			return nil
+		}
+	}
+	return value
+}
+
+// formatValue writes strings as they are, lists of strings and numbers
+// separated by commas and anything else as JSON.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				raw, _ := json.Marshal(v)
+				return string(raw)
+			}
+			items[i] = formatValue(item)
+		}
+		return strings.Join(items, ", ")
+	}
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+func decodeObject(s string) map[string]interface{} {
+	object := map[string]interface{}{}
+	if s != "" {
+		json.Unmarshal([]byte(s), &object)
+	}
+	return object
+}
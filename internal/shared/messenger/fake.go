@@ -0,0 +1,51 @@
+package messenger
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// fakeKeep is how many messages Fake remembers.
+const fakeKeep = 1000
+
+// Fake sends nothing. It keeps the last messages it was given, and fails
+// every send with Err while Err is set.
+type Fake struct {
+	mu   sync.Mutex
+	sent []Message
+	n    int
+	Err  error
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Name() string {
+	return ProviderFake
+}
+
+func (f *Fake) Send(ctx context.Context, msg *Message) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return "", f.Err
+	}
+
+	f.n++
+	f.sent = append(f.sent, *msg)
+	if len(f.sent) > fakeKeep {
+		f.sent = f.sent[len(f.sent)-fakeKeep:]
+	}
+	return "fake-" + strconv.Itoa(f.n), nil
+}
+
+// Sent returns the messages kept, oldest first.
+func (f *Fake) Sent() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Message(nil), f.sent...)
+}
@@ -0,0 +1,149 @@
+package messenger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxResponseBody is as much of a gateway response as is read.
+const maxResponseBody = 64 << 10
+
+// HTTPProvider posts each message as JSON to a gateway of our own or one
+// that accepts this shape:
+//
+//	POST <API_URL>
+//	Authorization: Bearer <API_TOKEN>
+//
+//	{"channel": "whatsapp", "to": "+628123456789", "text": "..."}
+//
+// Any 2xx answer is success; an "id" in a JSON answer is kept as the
+// message id.
+type HTTPProvider struct {
+	cfg    Config
+	client *http.Client
+}
+
+func NewHTTP(cfg Config) *HTTPProvider {
+	return &HTTPProvider{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (p *HTTPProvider) Name() string {
+	return ProviderHTTP
+}
+
+func (p *HTTPProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"channel": msg.Channel,
+		"to":      msg.To,
+		"text":    msg.Text,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.Token)
+	}
+
+	raw, err := do(p.client, req)
+	if err != nil {
+		return "", err
+	}
+
+	var answer struct {
+		ID interface{} `json:"id"`
+	}
+	if json.Unmarshal(raw, &answer) == nil && answer.ID != nil {
+		return fmt.Sprint(answer.ID), nil
+	}
+	return "", nil
+}
+
+// FonnteProvider sends WhatsApp messages through fonnte.com.
+type FonnteProvider struct {
+	cfg    Config
+	client *http.Client
+}
+
+func NewFonnte(cfg Config) *FonnteProvider {
+	return &FonnteProvider{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (p *FonnteProvider) Name() string {
+	return ProviderFonnte
+}
+
+func (p *FonnteProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	// the number already has its country code, countryCode 0 keeps fonnte
+	// from adding one
+	form := url.Values{
+		"target":      {strings.TrimPrefix(msg.To, "+")},
+		"message":     {msg.Text},
+		"countryCode": {"0"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", p.cfg.Token)
+
+	raw, err := do(p.client, req)
+	if err != nil {
+		return "", err
+	}
+
+	// fonnte answers 200 to rejected messages too, with status false
+	var answer struct {
+		Status bool          `json:"status"`
+		Reason string        `json:"reason"`
+		ID     []interface{} `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &answer); err != nil {
+		return "", fmt.Errorf("fonnte: unreadable answer: %w", err)
+	}
+	if !answer.Status {
+		return "", Permanent(fmt.Errorf("fonnte: %s", answer.Reason))
+	}
+	if len(answer.ID) > 0 {
+		return fmt.Sprint(answer.ID[0]), nil
+	}
+	return "", nil
+}
+
+// do sends req and returns the body of a 2xx answer. Other 4xx answers than
+// 408 and 429 are permanent errors.
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err := fmt.Errorf("%s answered %s: %s", req.URL.Host, res.Status, strings.TrimSpace(string(raw)))
+		if res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusRequestTimeout && res.StatusCode != http.StatusTooManyRequests {
+			return nil, Permanent(err)
+		}
+		return nil, err
+	}
+
+	return raw, nil
+}
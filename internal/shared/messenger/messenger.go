@@ -0,0 +1,135 @@
+// Package messenger sends WhatsApp and SMS messages through a gateway.
+// Provider is the transport; the gateways are reached over HTTP, and Fake
+// keeps what it is given for tests and local runs.
+package messenger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"rawuh-service/internal/shared/lib/utils"
+)
+
+const (
+	ChannelWhatsApp = "whatsapp"
+	ChannelSMS      = "sms"
+
+	ProviderHTTP   = "http"
+	ProviderFonnte = "fonnte"
+	ProviderFake   = "fake"
+)
+
+// Channels are the channels a message can be sent on.
+var Channels = []string{ChannelWhatsApp, ChannelSMS}
+
+// Message is one text message. To is a phone number in E.164, such as
+// +628123456789.
+type Message struct {
+	Channel string
+	To      string
+	Text    string
+}
+
+type Provider interface {
+	// Name identifies the provider in logs and throttling keys.
+	Name() string
+	// Send hands msg to the gateway and returns the id the gateway gave it,
+	// if any. Errors the gateway will give again for the same message are
+	// wrapped in ErrPermanent.
+	Send(ctx context.Context, msg *Message) (string, error)
+}
+
+// ErrPermanent marks errors sending again will not fix, such as a number the
+// gateway rejects.
+var ErrPermanent = errors.New("permanent")
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string        { return e.err.Error() }
+func (e *permanentError) Unwrap() []error      { return []error{e.err, ErrPermanent} }
+func (e *permanentError) Is(target error) bool { return target == ErrPermanent }
+
+// Permanent wraps err in ErrPermanent.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type Config struct {
+	Channel  string
+	Provider string
+	URL      string
+	Token    string
+	Rate     int
+	Timeout  time.Duration
+}
+
+// ConfigFromEnv reads the settings of channel, with WHATSAPP_ or SMS_ in
+// front of each name:
+//
+//	<CHANNEL>_PROVIDER     http, fonnte or fake, empty turns the channel off
+//	<CHANNEL>_API_URL      gateway endpoint, https://api.fonnte.com/send for fonnte
+//	<CHANNEL>_API_TOKEN    gateway token
+//	<CHANNEL>_RATE         20 messages a minute at most, across every server
+//	<CHANNEL>_API_TIMEOUT  30s for one message
+func ConfigFromEnv(channel string) (Config, error) {
+	prefix := strings.ToUpper(channel) + "_"
+	cfg := Config{
+		Channel:  channel,
+		Provider: strings.ToLower(utils.GetEnv(prefix+"PROVIDER", "")),
+		URL:      utils.GetEnv(prefix+"API_URL", ""),
+		Token:    utils.GetEnv(prefix+"API_TOKEN", ""),
+	}
+	if cfg.Provider == "" {
+		return cfg, nil
+	}
+
+	switch cfg.Provider {
+	case ProviderHTTP:
+		if cfg.URL == "" {
+			return cfg, fmt.Errorf("%sAPI_URL is required for the http provider", prefix)
+		}
+	case ProviderFonnte:
+		if cfg.URL == "" {
+			cfg.URL = "https://api.fonnte.com/send"
+		}
+		if cfg.Token == "" {
+			return cfg, fmt.Errorf("%sAPI_TOKEN is required for the fonnte provider", prefix)
+		}
+	case ProviderFake:
+	default:
+		return cfg, fmt.Errorf("invalid %sPROVIDER, use http, fonnte or fake", prefix)
+	}
+
+	rate, err := strconv.Atoi(utils.GetEnv(prefix+"RATE", "20"))
+	if err != nil || rate <= 0 {
+		return cfg, fmt.Errorf("invalid %sRATE", prefix)
+	}
+	timeout, err := time.ParseDuration(utils.GetEnv(prefix+"API_TIMEOUT", "30s"))
+	if err != nil || timeout <= 0 {
+		return cfg, fmt.Errorf("invalid %sAPI_TIMEOUT", prefix)
+	}
+
+	cfg.Rate = rate
+	cfg.Timeout = timeout
+
+	return cfg, nil
+}
+
+// New returns the provider of cfg, or nil when the channel is off.
+func New(cfg Config) Provider {
+	switch cfg.Provider {
+	case ProviderHTTP:
+		return NewHTTP(cfg)
+	case ProviderFonnte:
+		return NewFonnte(cfg)
+	case ProviderFake:
+		return NewFake()
+	}
+	return nil
+}
@@ -7,34 +7,26 @@ import (
 	"strconv"
 	"strings"
 
-	redisPkg "rawuh-service/internal/shared/redis"
+	"rawuh-service/internal/shared/constant"
 )
 
 type ContextKey string
 
 const ContextKeyAuthPayload ContextKey = "auth_payload"
 
-func AuthMiddleware(rdb *redisPkg.Redis) func(next http.Handler) http.Handler {
+// Verifier resolves an access token to its auth payload. It returns a nil
+// payload for tokens that are unknown, expired or revoked.
+type Verifier interface {
+	Verify(ctx context.Context, accessToken string) (map[string]interface{}, error)
+}
+
+func AuthMiddleware(v Verifier) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			auth := r.Header.Get("Authorization")
-			if auth != "" {
-				// Remove "Bearer " prefix if it exists
-				token := auth
-				if strings.HasPrefix(strings.ToLower(auth), "bearer ") {
-					token = strings.TrimSpace(auth[len("Bearer "):])
-				} else {
-					token = strings.TrimSpace(auth)
-				}
-				if token != "" {
-					key := "access_token:" + token
-					if val, err := rdb.Get(r.Context(), key); err == nil && val != "" {
-						var payload map[string]interface{}
-						if err := json.Unmarshal([]byte(val), &payload); err == nil {
-							ctx := context.WithValue(r.Context(), ContextKeyAuthPayload, payload)
-							r = r.WithContext(ctx)
-						}
-					}
+			if token := BearerToken(r); token != "" {
+				if payload, err := v.Verify(r.Context(), token); err == nil && payload != nil {
+					ctx := context.WithValue(r.Context(), ContextKeyAuthPayload, payload)
+					r = r.WithContext(ctx)
 				}
 			}
 			next.ServeHTTP(w, r)
@@ -42,6 +34,16 @@ func AuthMiddleware(rdb *redisPkg.Redis) func(next http.Handler) http.Handler {
 	}
 }
 
+// BearerToken returns the token of the Authorization header, with or without
+// the "Bearer " prefix.
+func BearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(strings.ToLower(auth), "bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return strings.TrimSpace(auth)
+}
+
 func RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if v := r.Context().Value(ContextKeyAuthPayload); v == nil {
@@ -54,6 +56,20 @@ func RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// RequireSystemAdmin lets only system admins through. It is for routes
+// served without a service, such as /debug/vars.
+func RequireSystemAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims, ok := GetAuthClaimsFromContext(r.Context()); !ok || claims.UserType != constant.UserTypeSystemAdmin {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": true, "message": "permission denied"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func GetAuthPayload(ctx context.Context) (map[string]interface{}, bool) {
 	v := ctx.Value(ContextKeyAuthPayload)
 	if v == nil {
@@ -97,12 +113,21 @@ func GetInt64Claim(payload map[string]interface{}, key string) (int64, bool) {
 }
 
 type AuthClaims struct {
-	Username  string `json:"username"`
-	Name      string `json:"name"`
-	UserID    int64  `json:"user_id"`
+	Username  string      `json:"username"`
+	Name      string      `json:"name"`
+	UserID    int64       `json:"user_id"`
+	ProjectID int64       `json:"project_id"`
+	EventID   int64       `json:"event_id"`
+	UserType  string      `json:"usertype"`
+	Roles     []RoleGrant `json:"roles"`
+}
+
+// RoleGrant is a role held in a project. EventID 0 means every event of the
+// project.
+type RoleGrant struct {
+	Role      string `json:"role"`
 	ProjectID int64  `json:"project_id"`
 	EventID   int64  `json:"event_id"`
-	UserType  string `json:"usertype"`
 }
 
 func ParseAuthClaims(payload map[string]interface{}) (AuthClaims, bool) {
@@ -128,6 +153,12 @@ func ParseAuthClaims(payload map[string]interface{}) (AuthClaims, bool) {
 	if s, ok := GetStringClaim(payload, "usertype"); ok {
 		c.UserType = s
 	}
+	if v, ok := payload["roles"]; ok && v != nil {
+		// roles arrive as decoded JSON, round trip them into RoleGrant
+		if raw, err := json.Marshal(v); err == nil {
+			_ = json.Unmarshal(raw, &c.Roles)
+		}
+	}
 	return c, true
 }
 
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	redisPkg "rawuh-service/internal/shared/redis"
+)
+
+// RateLimit allows at most limit requests per client IP in every window.
+// Counters live in Redis so the limit holds across service instances. While
+// Redis cannot be reached each instance counts on its own, so the limit still
+// holds per instance.
+func RateLimit(rdb *redisPkg.Redis, name string, limit int, window time.Duration) func(next http.Handler) http.Handler {
+	local := &localCounter{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bucket := time.Now().Unix() / int64(window.Seconds())
+			key := fmt.Sprintf("rate_limit:%s:%s:%d", name, ClientIP(r), bucket)
+
+			count, err := rdb.Incr(r.Context(), key, window)
+			if err != nil {
+				count = local.incr(key, bucket)
+			}
+			if count > int64(limit) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", strconv.Itoa(int(window.Seconds())))
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": true, "message": "too many requests"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// localCounter counts requests in memory for the current window only; the
+// counts of earlier windows are dropped when a new one starts.
+type localCounter struct {
+	mu     sync.Mutex
+	bucket int64
+	counts map[string]int64
+}
+
+func (c *localCounter) incr(key string, bucket int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil || bucket != c.bucket {
+		c.bucket = bucket
+		c.counts = map[string]int64{}
+	}
+	c.counts[key]++
+	return c.counts[key]
+}
+
+var (
+	trustedProxiesMu sync.RWMutex
+	trustedProxies   []*net.IPNet
+)
+
+// ParseTrustedProxies reads a comma separated list of IPs and CIDR ranges,
+// such as "10.0.0.0/8, 192.168.1.10".
+func ParseTrustedProxies(list string) ([]*net.IPNet, error) {
+	nets := []*net.IPNet{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-For is believed. It is
+// called once at startup.
+func SetTrustedProxies(nets []*net.IPNet) {
+	trustedProxiesMu.Lock()
+	defer trustedProxiesMu.Unlock()
+	trustedProxies = nets
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	trustedProxiesMu.RLock()
+	defer trustedProxiesMu.RUnlock()
+
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the originating client address. X-Forwarded-For is only
+// read when the request comes from a trusted proxy, and then from the right:
+// the client is the last address that is not a trusted proxy itself, since
+// anything left of it was written by the client and may be made up.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	remote := net.ParseIP(host)
+	if remote == nil || !isTrustedProxy(remote) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// a malformed hop cannot be traced further back
+			return host
+		}
+		if !isTrustedProxy(ip) {
+			return ip.String()
+		}
+	}
+
+	return host
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"rawuh-service/internal/shared/constant"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	ContextKeyRequestID ContextKey = "request_id"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID tags every request with an id, reusing the X-Request-ID header of
+// the caller when it looks sane. The id is echoed in the response, stored in
+// audit entries and used as the logger's process id.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(HeaderRequestID, id)
+
+		ctx := context.WithValue(r.Context(), ContextKeyRequestID, id)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(constant.ContextKeyProcessIdStr, id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ContextKeyRequestID).(string)
+	return id
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.auth;
+DROP TABLE IF EXISTS public.users;
+DROP TABLE IF EXISTS public.guests;
+DROP TABLE IF EXISTS public.events;
+DROP TABLE IF EXISTS public.projects;
@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS public.projects (
+    project_id BIGSERIAL PRIMARY KEY,
+    project_name VARCHAR(500),
+    created_at TIMESTAMP,
+    created_by_id BIGINT,
+    updated_at TIMESTAMP,
+    updated_by_id BIGINT,
+    status BIGINT,
+    status_desc VARCHAR(500)
+);
+
+CREATE TABLE IF NOT EXISTS public.events (
+    event_id BIGSERIAL PRIMARY KEY,
+    event_name VARCHAR(500),
+    description VARCHAR(500),
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    event_options JSONB,
+    guest_options JSONB,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    project_id BIGINT,
+    created_by_id BIGINT,
+    created_by_name VARCHAR(500),
+    updated_by_id BIGINT,
+    updated_by_name VARCHAR(500)
+);
+
+CREATE TABLE IF NOT EXISTS public.guests (
+    guest_id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(500),
+    address VARCHAR(500),
+    phone VARCHAR(500),
+    email VARCHAR(500),
+    event_id BIGINT,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    project_id BIGINT,
+    event_data TEXT,
+    guest_data TEXT
+);
+
+CREATE TABLE IF NOT EXISTS public.users (
+    user_id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(500),
+    user_type VARCHAR(100),
+    username VARCHAR(500),
+    email VARCHAR(500),
+    project_id BIGINT,
+    created_by_id BIGINT,
+    created_by_name VARCHAR(500),
+    updated_by_id BIGINT,
+    updated_by_name VARCHAR(500),
+    event_id BIGINT,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    status INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS public.auth (
+    user_id BIGINT PRIMARY KEY,
+    username VARCHAR(255),
+    password TEXT,
+    project_id BIGINT,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
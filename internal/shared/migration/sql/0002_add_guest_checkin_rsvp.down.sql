@@ -0,0 +1,8 @@
+ALTER TABLE public.guests
+    DROP COLUMN IF EXISTS rsvp_at,
+    DROP COLUMN IF EXISTS rsvp_attendees,
+    DROP COLUMN IF EXISTS rsvp_status,
+    DROP COLUMN IF EXISTS companion_count,
+    DROP COLUMN IF EXISTS checked_in_by_name,
+    DROP COLUMN IF EXISTS checked_in_by_id,
+    DROP COLUMN IF EXISTS checked_in_at;
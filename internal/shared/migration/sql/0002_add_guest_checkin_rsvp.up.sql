@@ -0,0 +1,8 @@
+ALTER TABLE public.guests
+    ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS checked_in_by_id BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS checked_in_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS companion_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rsvp_status VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS rsvp_attendees INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rsvp_at TIMESTAMP;
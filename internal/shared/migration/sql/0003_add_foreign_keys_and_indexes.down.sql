@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS public.auth_username_idx;
+DROP INDEX IF EXISTS public.users_username_idx;
+DROP INDEX IF EXISTS public.users_project_id_event_id_idx;
+DROP INDEX IF EXISTS public.guests_project_id_event_id_idx;
+DROP INDEX IF EXISTS public.events_project_id_idx;
+
+ALTER TABLE public.auth DROP CONSTRAINT IF EXISTS auth_user_id_fkey;
+ALTER TABLE public.guests DROP CONSTRAINT IF EXISTS guests_event_id_fkey;
+ALTER TABLE public.guests DROP CONSTRAINT IF EXISTS guests_project_id_fkey;
+ALTER TABLE public.events DROP CONSTRAINT IF EXISTS events_project_id_fkey;
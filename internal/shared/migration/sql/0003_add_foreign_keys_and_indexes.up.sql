@@ -0,0 +1,16 @@
+-- deleting a project removes its events, deleting an event removes its guests
+ALTER TABLE public.events
+    ADD CONSTRAINT events_project_id_fkey FOREIGN KEY (project_id) REFERENCES public.projects (project_id) ON DELETE CASCADE;
+
+ALTER TABLE public.guests
+    ADD CONSTRAINT guests_project_id_fkey FOREIGN KEY (project_id) REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    ADD CONSTRAINT guests_event_id_fkey FOREIGN KEY (event_id) REFERENCES public.events (event_id) ON DELETE CASCADE;
+
+ALTER TABLE public.auth
+    ADD CONSTRAINT auth_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users (user_id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS events_project_id_idx ON public.events (project_id);
+CREATE INDEX IF NOT EXISTS guests_project_id_event_id_idx ON public.guests (project_id, event_id);
+CREATE INDEX IF NOT EXISTS users_project_id_event_id_idx ON public.users (project_id, event_id);
+CREATE INDEX IF NOT EXISTS users_username_idx ON public.users (username);
+CREATE INDEX IF NOT EXISTS auth_username_idx ON public.auth (username);
@@ -0,0 +1,23 @@
+-- a NULL event_id grants the role on every event of the project
+CREATE TABLE IF NOT EXISTS public.user_roles (
+    user_role_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    event_id BIGINT REFERENCES public.events (event_id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    created_by_id BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_roles_grant_idx ON public.user_roles (user_id, project_id, COALESCE(event_id, 0), role);
+CREATE INDEX IF NOT EXISTS user_roles_project_id_idx ON public.user_roles (project_id);
+
+-- project users keep the access they had: manage guests of their event, or
+-- of the whole project when they were not tied to an event
+INSERT INTO public.user_roles (user_id, project_id, event_id, role)
+SELECT u.user_id, u.project_id, e.event_id, 'EVENT_MANAGER'
+FROM public.users u
+JOIN public.projects p ON p.project_id = u.project_id
+LEFT JOIN public.events e ON e.event_id = u.event_id AND e.project_id = u.project_id
+WHERE u.user_type = 'PROJECT_USER'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+-- audit entries outlive the rows they describe, so there are no foreign keys
+CREATE TABLE IF NOT EXISTS public.audit_logs (
+    audit_log_id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT NOT NULL DEFAULT 0,
+    actor_name VARCHAR(500) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL DEFAULT '',
+    project_id BIGINT NOT NULL DEFAULT 0,
+    event_id BIGINT NOT NULL DEFAULT 0,
+    before JSONB,
+    after JSONB,
+    diff JSONB,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_entity_idx ON public.audit_logs (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_idx ON public.audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS audit_logs_project_id_event_id_idx ON public.audit_logs (project_id, event_id);
+CREATE INDEX IF NOT EXISTS audit_logs_created_at_idx ON public.audit_logs (created_at);
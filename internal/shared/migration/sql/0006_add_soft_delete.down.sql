@@ -0,0 +1,10 @@
+-- rows still marked as deleted become visible again
+DROP INDEX IF EXISTS public.users_deleted_at_idx;
+DROP INDEX IF EXISTS public.guests_deleted_at_idx;
+DROP INDEX IF EXISTS public.events_deleted_at_idx;
+DROP INDEX IF EXISTS public.projects_deleted_at_idx;
+
+ALTER TABLE public.users DROP COLUMN IF EXISTS deleted_by_id, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE public.guests DROP COLUMN IF EXISTS deleted_by_id, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE public.events DROP COLUMN IF EXISTS deleted_by_id, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE public.projects DROP COLUMN IF EXISTS deleted_by_id, DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,23 @@
+-- deleted rows stay in place until the purge job removes them; every query
+-- skips rows with a deleted_at
+ALTER TABLE public.projects
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by_id BIGINT;
+
+ALTER TABLE public.events
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by_id BIGINT;
+
+ALTER TABLE public.guests
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by_id BIGINT;
+
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by_id BIGINT;
+
+-- only deleted rows are indexed, for restore and purge
+CREATE INDEX IF NOT EXISTS projects_deleted_at_idx ON public.projects (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS events_deleted_at_idx ON public.events (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS guests_deleted_at_idx ON public.guests (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON public.users (deleted_at) WHERE deleted_at IS NOT NULL;
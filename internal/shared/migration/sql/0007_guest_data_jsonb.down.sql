@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS public.guests_event_data_idx;
+DROP INDEX IF EXISTS public.guests_guest_data_idx;
+
+ALTER TABLE public.guests
+    ALTER COLUMN guest_data TYPE TEXT USING CAST(guest_data AS TEXT),
+    ALTER COLUMN event_data TYPE TEXT USING CAST(event_data AS TEXT);
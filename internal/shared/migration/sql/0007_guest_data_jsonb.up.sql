@@ -0,0 +1,8 @@
+-- guest_data and event_data are filtered and sorted by JSON path; the GIN
+-- indexes serve the containment (@>) the list filters use for eq and in
+ALTER TABLE public.guests
+    ALTER COLUMN guest_data TYPE JSONB USING CAST(NULLIF(btrim(guest_data), '') AS JSONB),
+    ALTER COLUMN event_data TYPE JSONB USING CAST(NULLIF(btrim(event_data), '') AS JSONB);
+
+CREATE INDEX IF NOT EXISTS guests_guest_data_idx ON public.guests USING GIN (guest_data jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS guests_event_data_idx ON public.guests USING GIN (event_data jsonb_path_ops);
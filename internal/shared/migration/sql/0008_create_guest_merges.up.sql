@@ -0,0 +1,19 @@
+-- one row per duplicate guest merged into another; the merged guest is soft
+-- deleted and its last state is kept here, so there are no foreign keys
+CREATE TABLE IF NOT EXISTS public.guest_merges (
+    guest_merge_id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    guest_id BIGINT NOT NULL,
+    merged_guest_id BIGINT NOT NULL,
+    taken_fields JSONB,
+    guest_before JSONB,
+    merged_guest JSONB,
+    merged_by_id BIGINT NOT NULL DEFAULT 0,
+    merged_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS guest_merges_project_id_event_id_idx ON public.guest_merges (project_id, event_id);
+CREATE INDEX IF NOT EXISTS guest_merges_guest_id_idx ON public.guest_merges (guest_id);
+CREATE INDEX IF NOT EXISTS guest_merges_merged_guest_id_idx ON public.guest_merges (merged_guest_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.invitation_deliveries;
+DROP TABLE IF EXISTS public.invitation_batches;
+DROP TABLE IF EXISTS public.invitation_templates;
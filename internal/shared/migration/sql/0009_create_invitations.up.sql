@@ -0,0 +1,45 @@
+-- one email template per event
+CREATE TABLE IF NOT EXISTS public.invitation_templates (
+    event_id BIGINT PRIMARY KEY REFERENCES public.events (event_id) ON DELETE CASCADE,
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    subject VARCHAR(500) NOT NULL DEFAULT '',
+    html_body TEXT NOT NULL DEFAULT '',
+    text_body TEXT NOT NULL DEFAULT '',
+    updated_by_id BIGINT NOT NULL DEFAULT 0,
+    updated_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- a batch is one send request; its deliveries are sent in the background
+CREATE TABLE IF NOT EXISTS public.invitation_batches (
+    invitation_batch_id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES public.events (event_id) ON DELETE CASCADE,
+    total INTEGER NOT NULL DEFAULT 0,
+    created_by_id BIGINT NOT NULL DEFAULT 0,
+    created_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS invitation_batches_project_id_event_id_idx ON public.invitation_batches (project_id, event_id);
+
+CREATE TABLE IF NOT EXISTS public.invitation_deliveries (
+    invitation_delivery_id BIGSERIAL PRIMARY KEY,
+    invitation_batch_id BIGINT NOT NULL REFERENCES public.invitation_batches (invitation_batch_id) ON DELETE CASCADE,
+    project_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    guest_id BIGINT NOT NULL REFERENCES public.guests (guest_id) ON DELETE CASCADE,
+    recipient VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS invitation_deliveries_project_id_event_id_guest_id_idx ON public.invitation_deliveries (project_id, event_id, guest_id);
+CREATE INDEX IF NOT EXISTS invitation_deliveries_invitation_batch_id_idx ON public.invitation_deliveries (invitation_batch_id);
+-- only deliveries still to be sent are indexed, for the sender
+CREATE INDEX IF NOT EXISTS invitation_deliveries_next_attempt_at_idx ON public.invitation_deliveries (next_attempt_at) WHERE status IN ('PENDING', 'SENDING');
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.message_outbox;
+DROP TABLE IF EXISTS public.message_broadcasts;
+DROP TABLE IF EXISTS public.message_templates;
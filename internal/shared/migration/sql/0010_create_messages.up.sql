@@ -0,0 +1,57 @@
+-- reusable WhatsApp and SMS texts of an event
+CREATE TABLE IF NOT EXISTS public.message_templates (
+    message_template_id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES public.events (event_id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    created_by_id BIGINT NOT NULL DEFAULT 0,
+    created_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_by_id BIGINT NOT NULL DEFAULT 0,
+    updated_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS message_templates_event_id_name_idx ON public.message_templates (event_id, name);
+
+-- a broadcast is one send request; its messages wait in the outbox
+CREATE TABLE IF NOT EXISTS public.message_broadcasts (
+    message_broadcast_id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES public.events (event_id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    message_template_id BIGINT REFERENCES public.message_templates (message_template_id) ON DELETE SET NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    total INTEGER NOT NULL DEFAULT 0,
+    created_by_id BIGINT NOT NULL DEFAULT 0,
+    created_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS message_broadcasts_project_id_event_id_idx ON public.message_broadcasts (project_id, event_id);
+
+CREATE TABLE IF NOT EXISTS public.message_outbox (
+    message_id BIGSERIAL PRIMARY KEY,
+    message_broadcast_id BIGINT NOT NULL REFERENCES public.message_broadcasts (message_broadcast_id) ON DELETE CASCADE,
+    message_template_id BIGINT,
+    project_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    guest_id BIGINT NOT NULL REFERENCES public.guests (guest_id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    recipient VARCHAR(32) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    provider_message_id VARCHAR(200) NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS message_outbox_project_id_event_id_guest_id_idx ON public.message_outbox (project_id, event_id, guest_id);
+CREATE INDEX IF NOT EXISTS message_outbox_message_broadcast_id_idx ON public.message_outbox (message_broadcast_id);
+-- only messages still to be sent are indexed, for the senders
+CREATE INDEX IF NOT EXISTS message_outbox_channel_next_attempt_at_idx ON public.message_outbox (channel, next_attempt_at) WHERE status IN ('PENDING', 'SENDING');
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.dead_jobs;
+DROP TABLE IF EXISTS public.jobs;
@@ -0,0 +1,31 @@
+-- background jobs, written in the transaction of the change that needs them
+-- and removed once they ran
+CREATE TABLE IF NOT EXISTS public.jobs (
+    job_id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_by VARCHAR(200) NOT NULL DEFAULT '',
+    locked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS jobs_run_at_idx ON public.jobs (run_at);
+CREATE INDEX IF NOT EXISTS jobs_kind_idx ON public.jobs (kind);
+
+-- jobs that failed every attempt, kept until an admin retries or drops them
+CREATE TABLE IF NOT EXISTS public.dead_jobs (
+    job_id BIGINT PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    failed_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS dead_jobs_kind_idx ON public.dead_jobs (kind);
@@ -0,0 +1,45 @@
+-- endpoints of a project that are told about changes of the types they
+-- subscribed to
+CREATE TABLE IF NOT EXISTS public.webhooks (
+    webhook_id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    url VARCHAR(2000) NOT NULL,
+    secret VARCHAR(200) NOT NULL,
+    event_types JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT true,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP,
+    disabled_reason TEXT NOT NULL DEFAULT '',
+    created_by_id BIGINT NOT NULL DEFAULT 0,
+    created_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_by_id BIGINT NOT NULL DEFAULT 0,
+    updated_by_name VARCHAR(500) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_project_id_idx ON public.webhooks (project_id);
+
+-- one change told to one webhook; also the delivery log
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+    webhook_delivery_id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES public.webhooks (webhook_id) ON DELETE CASCADE,
+    project_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL DEFAULT 0,
+    event_type VARCHAR(100) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    response_body TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON public.webhook_deliveries (webhook_id);
+-- only deliveries still to be sent are indexed, for the dispatcher
+CREATE INDEX IF NOT EXISTS webhook_deliveries_next_attempt_at_idx ON public.webhook_deliveries (next_attempt_at) WHERE status IN ('PENDING', 'SENDING');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.webhook_deliveries_job_id_webhook_id_idx;
+
+ALTER TABLE public.webhook_deliveries DROP COLUMN IF EXISTS job_id;
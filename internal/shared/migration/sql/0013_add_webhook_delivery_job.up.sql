@@ -0,0 +1,5 @@
+-- the job that queued a delivery, so a job that runs twice does not queue the
+-- same change twice; test deliveries have none
+ALTER TABLE public.webhook_deliveries ADD COLUMN IF NOT EXISTS job_id BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_job_id_webhook_id_idx ON public.webhook_deliveries (job_id, webhook_id);
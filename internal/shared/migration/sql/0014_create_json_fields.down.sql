@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.events_guest_options_idx;
+DROP INDEX IF EXISTS public.events_event_options_idx;
+
+DROP TABLE IF EXISTS public.json_fields;
@@ -0,0 +1,47 @@
+-- the paths list filters accept inside jsonb columns, recorded as staff write
+-- the data so a filtered list does not read every row to check its paths;
+-- guests are kept per event, events per project with event_id 0
+CREATE TABLE IF NOT EXISTS public.json_fields (
+    project_id BIGINT NOT NULL REFERENCES public.projects (project_id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL DEFAULT 0,
+    column_name VARCHAR(50) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    PRIMARY KEY (project_id, event_id, column_name, path)
+);
+
+-- the paths already in the data, three levels deep; arrays, nulls and keys
+-- containing a dot are left out, and a path holding several types is a string
+WITH RECURSIVE fields(project_id, event_id, col, path, value) AS (
+    SELECT g.project_id, g.event_id, 'guest_data', ARRAY[f.key], f.value
+    FROM public.guests g, jsonb_each(CASE WHEN jsonb_typeof(g.guest_data) = 'object' THEN g.guest_data ELSE '{}' END) f
+    WHERE g.deleted_at IS NULL AND g.project_id IS NOT NULL AND g.event_id IS NOT NULL AND position('.' in f.key) = 0
+    UNION ALL
+    SELECT g.project_id, g.event_id, 'event_data', ARRAY[f.key], f.value
+    FROM public.guests g, jsonb_each(CASE WHEN jsonb_typeof(g.event_data) = 'object' THEN g.event_data ELSE '{}' END) f
+    WHERE g.deleted_at IS NULL AND g.project_id IS NOT NULL AND g.event_id IS NOT NULL AND position('.' in f.key) = 0
+    UNION ALL
+    SELECT e.project_id, 0, 'event_options', ARRAY[f.key], f.value
+    FROM public.events e, jsonb_each(CASE WHEN jsonb_typeof(e.event_options) = 'object' THEN e.event_options ELSE '{}' END) f
+    WHERE e.deleted_at IS NULL AND e.project_id IS NOT NULL AND position('.' in f.key) = 0
+    UNION ALL
+    SELECT e.project_id, 0, 'guest_options', ARRAY[f.key], f.value
+    FROM public.events e, jsonb_each(CASE WHEN jsonb_typeof(e.guest_options) = 'object' THEN e.guest_options ELSE '{}' END) f
+    WHERE e.deleted_at IS NULL AND e.project_id IS NOT NULL AND position('.' in f.key) = 0
+    UNION ALL
+    SELECT fields.project_id, fields.event_id, fields.col, fields.path || e.key, e.value
+    FROM fields, jsonb_each(fields.value) e
+    WHERE jsonb_typeof(fields.value) = 'object' AND cardinality(fields.path) < 3 AND position('.' in e.key) = 0
+)
+INSERT INTO public.json_fields (project_id, event_id, column_name, path, type)
+SELECT project_id, event_id, col, array_to_string(path, '.'),
+    CASE WHEN count(DISTINCT jsonb_typeof(value)) = 1 THEN min(jsonb_typeof(value)) ELSE 'string' END
+FROM fields
+WHERE jsonb_typeof(value) NOT IN ('object', 'array', 'null')
+GROUP BY project_id, event_id, col, array_to_string(path, '.')
+ON CONFLICT DO NOTHING;
+
+-- the event list filters event_options and guest_options by containment (@>)
+-- the way the guest list does guest_data and event_data
+CREATE INDEX IF NOT EXISTS events_event_options_idx ON public.events USING GIN (event_options jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS events_guest_options_idx ON public.events USING GIN (guest_options jsonb_path_ops);
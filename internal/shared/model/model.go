@@ -3,6 +3,8 @@ package model
 type Sort struct {
 	Column    string
 	Direction string
+	// Path is set when sorting on a key inside the JSON Column.
+	Path []string
 }
 
 type PaginationResponse struct {
@@ -10,4 +12,13 @@ type PaginationResponse struct {
 	Page       int32
 	TotalRows  int64
 	TotalPages int32
+	// NextCursor and PrevCursor are set in cursor mode while there are rows
+	// that way.
+	NextCursor string `json:",omitempty"`
+	PrevCursor string `json:",omitempty"`
+
+	// Cursor is the cursor asked for; nil pages by Page instead.
+	Cursor *string `json:"-"`
+	// SkipCount leaves TotalRows and TotalPages at 0.
+	SkipCount bool `json:"-"`
 }
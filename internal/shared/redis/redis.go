@@ -11,6 +11,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// Nil is returned by Get when the key does not exist.
+const Nil = redis.Nil
+
 type Redis struct {
 	client *redis.Client
 }
@@ -87,9 +90,108 @@ func (r *Redis) Update(ctx context.Context, key string, val interface{}) error {
 	return nil
 }
 
-func (r *Redis) Del(ctx context.Context, key string) error {
-	if _, err := r.client.Del(ctx, key).Result(); err != nil {
+func (r *Redis) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, err := r.client.Del(ctx, keys...).Result(); err != nil {
 		return fmt.Errorf("failed to delete key: %s", err)
 	}
 	return nil
 }
+
+// Incr increments key and (re)sets its expiration in the same round trip.
+func (r *Redis) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment key: %s", err)
+	}
+	return incr.Val(), nil
+}
+
+// SAdd adds member to the set stored at key and refreshes the set expiration.
+func (r *Redis) SAdd(ctx context.Context, key string, member string, expiration time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.SAdd(ctx, key, member)
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to add set member: %s", err)
+	}
+	return nil
+}
+
+func (r *Redis) SRem(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+	if err := r.client.SRem(ctx, key, args...).Err(); err != nil {
+		return fmt.Errorf("failed to remove set member: %s", err)
+	}
+	return nil
+}
+
+func (r *Redis) SMembers(ctx context.Context, key string) ([]string, error) {
+	members, err := r.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get set members: %s", err)
+	}
+	return members, nil
+}
+
+// MGet returns the values of keys in order, with an empty string for keys
+// that do not exist.
+func (r *Redis) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	vals, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keys: %s", err)
+	}
+	result := make([]string, len(vals))
+	for i, val := range vals {
+		if s, ok := val.(string); ok {
+			result[i] = s
+		}
+	}
+	return result, nil
+}
+
+// SetNX sets key only when it does not exist yet and reports whether it did.
+func (r *Redis) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal data: %s", err)
+	}
+	ok, err := r.client.SetNX(ctx, key, data, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set key: %s", err)
+	}
+	return ok, nil
+}
+
+// Publish sends val, marshalled to JSON, to the subscribers of channel on
+// every server.
+func (r *Redis) Publish(ctx context.Context, channel string, val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %s", err)
+	}
+	if err := r.client.Publish(ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish: %s", err)
+	}
+	return nil
+}
+
+// Subscribe opens a connection that receives what is published on channels.
+// Channels can be added and removed on it later. The connection is
+// reestablished, with its channels, when it drops; Close it when done.
+func (r *Redis) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return r.client.Subscribe(ctx, channels...)
+}
@@ -3,16 +3,27 @@ package router
 
 import (
 	"encoding/json"
+	"expvar"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	auditHandler "rawuh-service/internal/audit/handler"
 	authHandler "rawuh-service/internal/auth/handler"
 	eventHandler "rawuh-service/internal/event/handler"
 	guestHandler "rawuh-service/internal/guest/handler"
+	invitationHandler "rawuh-service/internal/invitation/handler"
+	jobHandler "rawuh-service/internal/job/handler"
+	liveHandler "rawuh-service/internal/live/handler"
+	messageHandler "rawuh-service/internal/message/handler"
 	projectHandler "rawuh-service/internal/project/handler"
+	rsvpHandler "rawuh-service/internal/rsvp/handler"
 	"rawuh-service/internal/shared/middleware"
 	redisPkg "rawuh-service/internal/shared/redis"
+	"rawuh-service/internal/shared/session"
 	userHandler "rawuh-service/internal/user/handler"
+	webhookHandler "rawuh-service/internal/webhook/handler"
 
 	"rawuh-service/internal/shared/lib/utils"
 
@@ -22,11 +33,12 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(g *guestHandler.GuestHandler, e *eventHandler.EventHandler, p *projectHandler.ProjectHandler, u *userHandler.UserHandler, a *authHandler.AuthHandler, rdb *redisPkg.Redis) http.Handler {
+func NewRouter(g *guestHandler.GuestHandler, e *eventHandler.EventHandler, p *projectHandler.ProjectHandler, u *userHandler.UserHandler, a *authHandler.AuthHandler, rs *rsvpHandler.RsvpHandler, au *auditHandler.AuditHandler, in *invitationHandler.InvitationHandler, m *messageHandler.MessageHandler, j *jobHandler.JobHandler, wh *webhookHandler.WebhookHandler, l *liveHandler.LiveHandler, rdb *redisPkg.Redis, sessions *session.Store) http.Handler {
 	r := mux.NewRouter()
 	// Apply CORS middleware first so preflight and headers are set globally.
 	r.Use(middleware.CORSMiddleware)
-	r.Use(middleware.AuthMiddleware(rdb))
+	r.Use(middleware.RequestID)
+	r.Use(middleware.AuthMiddleware(sessions))
 
 	protected := r.NewRoute().Subrouter()
 	protected.Use(middleware.RequireAuth)
@@ -37,6 +49,7 @@ func NewRouter(g *guestHandler.GuestHandler, e *eventHandler.EventHandler, p *pr
 	protected.HandleFunc("/project/{project_id}", p.UpdateProject).Methods(http.MethodPut, http.MethodOptions)
 	protected.HandleFunc("/project/{project_id}", p.DeleteProject).Methods(http.MethodDelete, http.MethodOptions)
 	protected.HandleFunc("/project/{project_id}", p.DetailProject).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/restore", p.RestoreProject).Methods(http.MethodPost, http.MethodOptions)
 
 	// EVENT ROUTES (protected)
 	protected.HandleFunc("/{project_id}/events", e.AddEvent).Methods(http.MethodPost, http.MethodOptions)
@@ -44,13 +57,47 @@ func NewRouter(g *guestHandler.GuestHandler, e *eventHandler.EventHandler, p *pr
 	protected.HandleFunc("/{project_id}/events/{event_id}", e.DetailEvent).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/{project_id}/events/{event_id}", e.UpdateEvent).Methods(http.MethodPut, http.MethodOptions)
 	protected.HandleFunc("/{project_id}/events/{event_id}", e.DeleteEvent).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/restore", e.RestoreEvent).Methods(http.MethodPost, http.MethodOptions)
 
 	// GUEST ROUTES (protected)
 	protected.HandleFunc("/{project_id}/events/{event_id}/guests/list", g.ListGuests).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/{project_id}/events/{event_id}/guests", g.AddGuest).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/import", g.ImportGuests).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/export", g.ExportGuests).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/schema", g.GetGuestSchema).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/duplicates", g.ListGuestDuplicates).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}", g.UpdateGuestByID).Methods(http.MethodPut, http.MethodOptions)
 	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}", g.GetGuestByID).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}", g.DeleteGuestByID).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}/restore", g.RestoreGuest).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}/merge", g.MergeGuest).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}/checkin", g.CheckInGuest).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}/checkin", g.UndoCheckInGuest).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}/qr", g.GetGuestQRCode).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/scan", g.ScanGuest).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/guests/{guest_id}/invitation", g.GetGuestInvitation).Methods(http.MethodGet, http.MethodOptions)
+
+	// LIVE ROUTES (protected)
+	protected.HandleFunc("/{project_id}/events/{event_id}/live", l.StreamEvent).Methods(http.MethodGet, http.MethodOptions)
+
+	// INVITATION ROUTES (protected)
+	protected.HandleFunc("/{project_id}/events/{event_id}/invitations/template", in.GetTemplate).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/invitations/template", in.UpdateTemplate).Methods(http.MethodPut, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/invitations/preview", in.PreviewInvitation).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/invitations/send", in.SendInvitations).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/invitations/list", in.ListDeliveries).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/invitations/batches/{batch_id}", in.GetBatch).Methods(http.MethodGet, http.MethodOptions)
+
+	// MESSAGE ROUTES (protected)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/templates", m.ListTemplates).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/templates", m.CreateTemplate).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/templates/{template_id}", m.GetTemplate).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/templates/{template_id}", m.UpdateTemplate).Methods(http.MethodPut, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/templates/{template_id}", m.DeleteTemplate).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/preview", m.PreviewMessage).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/broadcast", m.Broadcast).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/list", m.ListMessages).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/{project_id}/events/{event_id}/messages/broadcasts/{broadcast_id}", m.GetBroadcast).Methods(http.MethodGet, http.MethodOptions)
 
 	// USER ROUTES (protected)
 	protected.HandleFunc("/users/list", u.ListUsers).Methods(http.MethodGet, http.MethodOptions)
@@ -58,10 +105,49 @@ func NewRouter(g *guestHandler.GuestHandler, e *eventHandler.EventHandler, p *pr
 	protected.HandleFunc("/users/{user_id}", u.UpdateUserByID).Methods(http.MethodPut, http.MethodOptions)
 	protected.HandleFunc("/users/{user_id}", u.GetUserByID).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/users/{user_id}", u.DeleteUserByID).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/users/{user_id}/restore", u.RestoreUser).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/users/{user_id}/sessions", u.RevokeUserSessions).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/users/{user_id}/roles", u.ListUserRoles).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/users/{user_id}/roles", u.AssignUserRole).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/users/{user_id}/roles/{user_role_id}", u.RemoveUserRole).Methods(http.MethodDelete, http.MethodOptions)
+
+	// AUDIT ROUTES (protected, system admins only)
+	protected.HandleFunc("/audit", au.ListAuditLogs).Methods(http.MethodGet, http.MethodOptions)
+
+	// JOB ROUTES (protected, system admins only)
+	protected.HandleFunc("/jobs/list", j.ListJobs).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/jobs/dead", j.ListDeadJobs).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/jobs/dead/{job_id}", j.GetDeadJob).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/jobs/dead/{job_id}", j.DeleteDeadJob).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/jobs/dead/{job_id}/retry", j.RetryDeadJob).Methods(http.MethodPost, http.MethodOptions)
+
+	// WEBHOOK ROUTES (protected)
+	protected.HandleFunc("/project/{project_id}/webhooks", wh.ListWebhooks).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/webhooks", wh.CreateWebhook).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/webhooks/{webhook_id}", wh.GetWebhook).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/webhooks/{webhook_id}", wh.UpdateWebhook).Methods(http.MethodPut, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/webhooks/{webhook_id}", wh.DeleteWebhook).Methods(http.MethodDelete, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/webhooks/{webhook_id}/test", wh.TestWebhook).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/project/{project_id}/webhooks/{webhook_id}/deliveries", wh.ListDeliveries).Methods(http.MethodGet, http.MethodOptions)
+
+	// METRICS (protected, system admins only), cache hits and misses among
+	// the Go runtime expvars
+	protected.Handle("/debug/vars", middleware.RequireSystemAdmin(expvar.Handler())).Methods(http.MethodGet, http.MethodOptions)
+
+	// RSVP ROUTES (public, keyed by the invitation token)
+	rsvpLimit, _ := strconv.Atoi(utils.GetEnv("RSVP_RATE_LIMIT", "30"))
+	public := r.PathPrefix("/rsvp").Subrouter()
+	public.Use(middleware.RateLimit(rdb, "rsvp", rsvpLimit, time.Minute))
+	public.HandleFunc("/{token}", rs.GetInvitation).Methods(http.MethodGet, http.MethodOptions)
+	public.HandleFunc("/{token}", rs.RespondInvitation).Methods(http.MethodPut, http.MethodOptions)
 
 	// AUTH ROUTES
 	r.HandleFunc("/login", a.Login).Methods(http.MethodPost, http.MethodOptions)
+	r.HandleFunc("/auth/refresh", a.Refresh).Methods(http.MethodPost, http.MethodOptions)
 	protected.HandleFunc("/auth/me", a.TokenInfo).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/auth/sessions", a.ListSessions).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/auth/switch-project", a.SwitchProject).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/logout", a.Logout).Methods(http.MethodPost, http.MethodOptions)
 
 	r.HandleFunc("/swagger/doc.json", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
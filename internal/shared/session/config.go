@@ -0,0 +1,78 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"rawuh-service/internal/shared/lib/utils"
+)
+
+const (
+	// ModeOpaque issues random access tokens that are looked up in Redis on
+	// every request.
+	ModeOpaque = "opaque"
+	// ModeJWT issues signed JWT access tokens that are verified locally. Redis
+	// is only asked whether a token was revoked.
+	ModeJWT = "jwt"
+)
+
+type Config struct {
+	Mode       string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	MaxTTL     time.Duration
+	Issuer     string
+	KeySet     *KeySet
+}
+
+// ConfigFromEnv reads the session settings:
+//
+//	AUTH_TOKEN_MODE     opaque (default) or jwt
+//	ACCESS_TOKEN_TTL    15m
+//	REFRESH_TOKEN_TTL   168h, extended on every refresh
+//	SESSION_MAX_TTL     720h, after which the user has to log in again
+//	JWT_KEYSET          key set JSON, or JWT_KEYSET_FILE with its path
+//	JWT_SIGNING_KID     kid signing new tokens, the first key by default
+//	JWT_ISSUER          rawuh-service
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Mode:       utils.GetEnv("AUTH_TOKEN_MODE", ModeOpaque),
+		AccessTTL:  durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTTL: durationEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		MaxTTL:     durationEnv("SESSION_MAX_TTL", 30*24*time.Hour),
+		Issuer:     utils.GetEnv("JWT_ISSUER", "rawuh-service"),
+	}
+
+	if cfg.Mode != ModeJWT {
+		return cfg, nil
+	}
+
+	keySet := []byte(utils.GetEnv("JWT_KEYSET", ""))
+	if path := utils.GetEnv("JWT_KEYSET_FILE", ""); len(keySet) == 0 && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read JWT_KEYSET_FILE: %s", err)
+		}
+		keySet = data
+	}
+	if len(keySet) == 0 {
+		return cfg, fmt.Errorf("AUTH_TOKEN_MODE=jwt needs JWT_KEYSET or JWT_KEYSET_FILE")
+	}
+
+	ks, err := ParseKeySet(keySet, utils.GetEnv("JWT_SIGNING_KID", ""))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.KeySet = ks
+
+	return cfg, nil
+}
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(utils.GetEnv(key, fallback.String()))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
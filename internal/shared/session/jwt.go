@@ -0,0 +1,100 @@
+package session
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is one entry of a JWKS style key set. Only symmetric "oct" keys signed
+// with HS256 are supported, K holds the base64url encoded secret.
+type Key struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg,omitempty"`
+	K   string `json:"k"`
+}
+
+// KeySet holds every key access tokens may be signed with. New tokens are
+// signed with the signing key; tokens signed with any other key of the set
+// stay valid, which is how keys are rotated.
+type KeySet struct {
+	keys       map[string][]byte
+	signingKid string
+}
+
+// ParseKeySet reads a key set in the form {"keys":[{"kid":..,"kty":"oct","k":..}]}.
+// The first key signs new tokens when signingKid is empty.
+func ParseKeySet(data []byte, signingKid string) (*KeySet, error) {
+	var set struct {
+		Keys []Key `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid key set: %s", err)
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("key set has no keys")
+	}
+
+	ks := &KeySet{keys: map[string][]byte{}, signingKid: signingKid}
+	for _, key := range set.Keys {
+		if key.Kid == "" {
+			return nil, fmt.Errorf("key without kid")
+		}
+		if key.Kty != "oct" || (key.Alg != "" && key.Alg != jwt.SigningMethodHS256.Alg()) {
+			return nil, fmt.Errorf("key %q: only oct keys for HS256 are supported", key.Kid)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(key.K)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid k: %s", key.Kid, err)
+		}
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("key %q: secret must be at least 32 bytes", key.Kid)
+		}
+		if _, ok := ks.keys[key.Kid]; ok {
+			return nil, fmt.Errorf("duplicate kid %q", key.Kid)
+		}
+		ks.keys[key.Kid] = secret
+	}
+
+	if ks.signingKid == "" {
+		ks.signingKid = set.Keys[0].Kid
+	}
+	if _, ok := ks.keys[ks.signingKid]; !ok {
+		return nil, fmt.Errorf("signing kid %q is not in the key set", ks.signingKid)
+	}
+
+	return ks, nil
+}
+
+// sign returns a JWT holding the claims, signed with the signing key.
+func (ks *KeySet) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = ks.signingKid
+	return token.SignedString(ks.keys[ks.signingKid])
+}
+
+// verify checks signature, issuer and expiry of a JWT and returns its claims.
+func (ks *KeySet) verify(tokenString string, issuer string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		secret, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		return secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(5*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,204 @@
+package session
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKey returns an HS256 key with a secret of size bytes made from kid,
+// which must not be empty.
+func testKey(kid string, size int) Key {
+	secret := []byte(strings.Repeat(kid, size))[:size]
+	return Key{Kid: kid, Kty: "oct", Alg: "HS256", K: base64.RawURLEncoding.EncodeToString(secret)}
+}
+
+func testKeySet(t *testing.T, signingKid string, keys ...Key) *KeySet {
+	t.Helper()
+	ks, err := ParseKeySet(keySetJSON(keys...), signingKid)
+	if err != nil {
+		t.Fatalf("ParseKeySet: %v", err)
+	}
+	return ks
+}
+
+func keySetJSON(keys ...Key) []byte {
+	data, _ := json.Marshal(map[string][]Key{"keys": keys})
+	return data
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"jti":     "jti-1",
+		"iss":     "rawuh-test",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"user_id": 7,
+	}
+}
+
+// signWith signs claims with method and the secret of key, under its kid.
+func signWith(t *testing.T, method jwt.SigningMethod, key Key, claims jwt.MapClaims) string {
+	t.Helper()
+	secret, _ := base64.RawURLEncoding.DecodeString(key.K)
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.Kid
+
+	var signed string
+	var err error
+	if method == jwt.SigningMethodNone {
+		signed, err = token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	} else {
+		signed, err = token.SignedString(secret)
+	}
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestParseKeySet(t *testing.T) {
+	withAlg := func(key Key, alg string) Key {
+		key.Alg = alg
+		return key
+	}
+	withKty := func(key Key, kty string) Key {
+		key.Kty = kty
+		return key
+	}
+
+	tests := []struct {
+		name       string
+		keys       []Key
+		signingKid string
+		wantErr    string
+	}{
+		{"hs256 key", []Key{testKey("a", 32)}, "", ""},
+		{"alg left out", []Key{withAlg(testKey("a", 32), "")}, "", ""},
+		{"signing kid picked", []Key{testKey("a", 32), testKey("b", 64)}, "b", ""},
+		{"key of 31 bytes", []Key{testKey("a", 31)}, "", `key "a": secret must be at least 32 bytes`},
+		{"short key after a good one", []Key{testKey("a", 32), testKey("b", 16)}, "", `key "b": secret must be at least 32 bytes`},
+		{"HS384", []Key{withAlg(testKey("a", 48), "HS384")}, "", `key "a": only oct keys for HS256 are supported`},
+		{"HS512", []Key{withAlg(testKey("a", 64), "HS512")}, "", `key "a": only oct keys for HS256 are supported`},
+		{"RS256", []Key{withAlg(testKey("a", 32), "RS256")}, "", `key "a": only oct keys for HS256 are supported`},
+		{"none", []Key{withAlg(testKey("a", 32), "none")}, "", `key "a": only oct keys for HS256 are supported`},
+		{"RSA key", []Key{withKty(testKey("a", 32), "RSA")}, "", `key "a": only oct keys for HS256 are supported`},
+		{"no keys", nil, "", "key set has no keys"},
+		{"no kid", []Key{{Kty: "oct", K: testKey("a", 32).K}}, "", "key without kid"},
+		{"duplicate kid", []Key{testKey("a", 32), testKey("a", 40)}, "", `duplicate kid "a"`},
+		{"unknown signing kid", []Key{testKey("a", 32)}, "b", `signing kid "b" is not in the key set`},
+		{"invalid k", []Key{{Kid: "a", Kty: "oct", K: "not base64!"}}, "", `key "a": invalid k`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := ParseKeySet(keySetJSON(tt.keys...), tt.signingKid)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseKeySet: %v", err)
+				}
+				want := tt.signingKid
+				if want == "" {
+					want = tt.keys[0].Kid
+				}
+				if ks.signingKid != want {
+					t.Errorf("signing kid = %q, want %q", ks.signingKid, want)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("ParseKeySet = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	a, b := testKey("a", 32), testKey("b", 32)
+
+	before := testKeySet(t, "", a)
+	oldToken, err := before.sign(testClaims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// b signs new tokens, a is kept until the tokens it signed expire
+	rotated := testKeySet(t, "b", b, a)
+	if _, err := rotated.verify(oldToken, "rawuh-test"); err != nil {
+		t.Errorf("token signed with the old key: %v", err)
+	}
+	newToken, err := rotated.sign(testClaims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	if kid := parsed.Header["kid"]; kid != "b" {
+		t.Errorf("new token kid = %v, want b", kid)
+	}
+
+	retired := testKeySet(t, "", b)
+	if _, err := retired.verify(oldToken, "rawuh-test"); err == nil {
+		t.Error("token signed with a retired key verified")
+	}
+	if _, err := retired.verify(newToken, "rawuh-test"); err != nil {
+		t.Errorf("token signed with the current key: %v", err)
+	}
+
+	// the kid picks the key, so a token claiming a under b's secret fails
+	forged := signWith(t, jwt.SigningMethodHS256, Key{Kid: "a", K: b.K}, testClaims())
+	if _, err := rotated.verify(forged, "rawuh-test"); err == nil {
+		t.Error("token with the wrong kid verified")
+	}
+	unknown := signWith(t, jwt.SigningMethodHS256, Key{Kid: "c", K: b.K}, testClaims())
+	if _, err := rotated.verify(unknown, "rawuh-test"); err == nil {
+		t.Error("token with an unknown kid verified")
+	}
+}
+
+func TestKeySetRejectsOtherAlgorithms(t *testing.T) {
+	a := testKey("a", 64)
+	ks := testKeySet(t, "", a)
+
+	if _, err := ks.verify(signWith(t, jwt.SigningMethodHS256, a, testClaims()), "rawuh-test"); err != nil {
+		t.Fatalf("HS256 token: %v", err)
+	}
+
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodHS384, jwt.SigningMethodHS512, jwt.SigningMethodNone} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			token := signWith(t, method, a, testClaims())
+			if _, err := ks.verify(token, "rawuh-test"); err == nil {
+				t.Errorf("%s token verified", method.Alg())
+			}
+		})
+	}
+}
+
+func TestKeySetChecksClaims(t *testing.T) {
+	a := testKey("a", 32)
+	ks := testKeySet(t, "", a)
+
+	tests := []struct {
+		name   string
+		change func(jwt.MapClaims)
+	}{
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "someone-else" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := testClaims()
+			tt.change(claims)
+			if _, err := ks.verify(signWith(t, jwt.SigningMethodHS256, a, claims), "rawuh-test"); err == nil {
+				t.Error("token verified")
+			}
+		})
+	}
+}
@@ -0,0 +1,536 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rawuh-service/internal/shared/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Redis layout of a login session:
+//
+//	access_token:<token>   auth payload of an opaque access token
+//	revoked_token:<jti>    deny-list entry of a revoked JWT access token
+//	session:<session id>   Session, the refresh token family
+//	user_sessions:<user>   set of the session ids of one user
+//
+// A session lives as long as its refresh token. Every refresh rotates both
+// tokens; the hashes of rotated refresh tokens are kept so a replayed one can
+// be told apart from a forged one and revokes the whole session.
+const (
+	accessTokenPrefix  = "access_token:"
+	revokedTokenPrefix = "revoked_token:"
+	sessionPrefix      = "session:"
+	sessionLockPrefix  = "session_lock:"
+	userSessionsPrefix = "user_sessions:"
+
+	// PayloadSessionID is the auth payload key holding the session id.
+	PayloadSessionID = "session_id"
+
+	maxUsedRefreshHashes = 50
+	refreshLockTTL       = 5 * time.Second
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrRefreshInProgress   = errors.New("refresh already in progress")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+type Session struct {
+	SessionID string `json:"session_id"`
+	UserID    int64  `json:"user_id"`
+	// AccessToken is the opaque access token, or the jti of a JWT
+	AccessToken     string                 `json:"access_token"`
+	AccessExpiresAt time.Time              `json:"access_expires_at"`
+	RefreshHash     string                 `json:"refresh_hash"`
+	UsedHashes      []string               `json:"used_hashes"`
+	Payload         map[string]interface{} `json:"payload"`
+	IP              string                 `json:"ip"`
+	UserAgent       string                 `json:"user_agent"`
+	CreatedAt       time.Time              `json:"created_at"`
+	RefreshedAt     time.Time              `json:"refreshed_at"`
+	ExpiresAt       time.Time              `json:"expires_at"`
+}
+
+// Tokens are handed to the client after a login or a refresh.
+type Tokens struct {
+	SessionID        string
+	AccessToken      string
+	RefreshToken     string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+}
+
+type Meta struct {
+	IP        string
+	UserAgent string
+}
+
+// kv is the part of Redis a Store uses.
+type kv interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error)
+	MGet(ctx context.Context, keys ...string) ([]string, error)
+	Del(ctx context.Context, keys ...string) error
+	SAdd(ctx context.Context, key string, member string, expiration time.Duration) error
+	SRem(ctx context.Context, key string, members ...string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+}
+
+type Store struct {
+	rdb        kv
+	mode       string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	maxTTL     time.Duration
+	issuer     string
+	keySet     *KeySet
+}
+
+func NewStore(rdb *redis.Redis, cfg Config) (*Store, error) {
+	return newStore(rdb, cfg)
+}
+
+func newStore(rdb kv, cfg Config) (*Store, error) {
+	switch cfg.Mode {
+	case ModeOpaque, "":
+		cfg.Mode = ModeOpaque
+	case ModeJWT:
+		if cfg.KeySet == nil {
+			return nil, fmt.Errorf("jwt mode needs a key set")
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth token mode %q", cfg.Mode)
+	}
+	if cfg.AccessTTL <= 0 || cfg.RefreshTTL <= 0 || cfg.MaxTTL <= 0 {
+		return nil, fmt.Errorf("token lifetimes must be positive")
+	}
+
+	return &Store{
+		rdb:        rdb,
+		mode:       cfg.Mode,
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
+		maxTTL:     cfg.MaxTTL,
+		issuer:     cfg.Issuer,
+		keySet:     cfg.KeySet,
+	}, nil
+}
+
+func (s *Store) Mode() string {
+	return s.mode
+}
+
+// Create starts a session for userID.
+func (s *Store) Create(ctx context.Context, userID int64, payload map[string]interface{}, meta Meta) (*Tokens, error) {
+	now := time.Now()
+
+	sess := &Session{
+		SessionID: uuid.New().String(),
+		UserID:    userID,
+		Payload:   map[string]interface{}{},
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+		CreatedAt: now,
+	}
+	for k, v := range payload {
+		sess.Payload[k] = v
+	}
+	sess.Payload[PayloadSessionID] = sess.SessionID
+
+	tokens, err := s.issue(ctx, sess, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rdb.SAdd(ctx, userSessionsKey(userID), sess.SessionID, s.refreshTTL); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh swaps a refresh token for a new access and refresh token pair. A
+// refresh token that was already used revokes the session it belongs to.
+func (s *Store) Refresh(ctx context.Context, refreshToken string, meta Meta) (*Tokens, error) {
+	sessionID, _, found := strings.Cut(refreshToken, ".")
+	if !found || sessionID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	locked, err := s.rdb.SetNX(ctx, sessionLockPrefix+sessionID, 1, refreshLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrRefreshInProgress
+	}
+	defer s.rdb.Del(ctx, sessionLockPrefix+sessionID)
+
+	sess, err := s.get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if sess == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	hash := hashToken(refreshToken)
+	if hash != sess.RefreshHash {
+		for _, used := range sess.UsedHashes {
+			if used == hash {
+				if err := s.revokeSession(ctx, sess); err != nil {
+					return nil, err
+				}
+				return nil, ErrRefreshTokenReused
+			}
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if !now.Before(sess.CreatedAt.Add(s.maxTTL)) {
+		if err := s.revokeSession(ctx, sess); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if meta.IP != "" {
+		sess.IP = meta.IP
+	}
+	if meta.UserAgent != "" {
+		sess.UserAgent = meta.UserAgent
+	}
+
+	return s.rotate(ctx, sess, now)
+}
+
+// Rescope merges claims into the auth payload of a session and swaps its
+// tokens for a pair carrying them. The old refresh token counts as used.
+func (s *Store) Rescope(ctx context.Context, sessionID string, claims map[string]interface{}) (*Tokens, error) {
+	locked, err := s.rdb.SetNX(ctx, sessionLockPrefix+sessionID, 1, refreshLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrRefreshInProgress
+	}
+	defer s.rdb.Del(ctx, sessionLockPrefix+sessionID)
+
+	sess, err := s.get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if sess == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	for k, v := range claims {
+		sess.Payload[k] = v
+	}
+	sess.Payload[PayloadSessionID] = sess.SessionID
+
+	return s.rotate(ctx, sess, time.Now())
+}
+
+// rotate retires the current token pair of sess and issues a new one.
+func (s *Store) rotate(ctx context.Context, sess *Session, now time.Time) (*Tokens, error) {
+	sess.UsedHashes = append(sess.UsedHashes, sess.RefreshHash)
+	if len(sess.UsedHashes) > maxUsedRefreshHashes {
+		sess.UsedHashes = sess.UsedHashes[len(sess.UsedHashes)-maxUsedRefreshHashes:]
+	}
+
+	// the old access token stops working right away so a session never has
+	// more than one live access token
+	if err := s.dropAccessToken(ctx, sess); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issue(ctx, sess, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rdb.SAdd(ctx, userSessionsKey(sess.UserID), sess.SessionID, s.refreshTTL); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// issue mints a new token pair for sess and saves it.
+func (s *Store) issue(ctx context.Context, sess *Session, now time.Time) (*Tokens, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	refreshToken := sess.SessionID + "." + base64.RawURLEncoding.EncodeToString(secret)
+
+	expiresAt := now.Add(s.refreshTTL)
+	if maxExpiresAt := sess.CreatedAt.Add(s.maxTTL); expiresAt.After(maxExpiresAt) {
+		expiresAt = maxExpiresAt
+	}
+	accessTTL := s.accessTTL
+	if accessTTL > expiresAt.Sub(now) {
+		accessTTL = expiresAt.Sub(now)
+	}
+
+	var accessToken string
+	if s.mode == ModeJWT {
+		jti := uuid.New().String()
+		claims := jwt.MapClaims{}
+		for k, v := range sess.Payload {
+			claims[k] = v
+		}
+		claims["jti"] = jti
+		claims["iss"] = s.issuer
+		claims["iat"] = now.Unix()
+		claims["exp"] = now.Add(accessTTL).Unix()
+
+		signed, err := s.keySet.sign(claims)
+		if err != nil {
+			return nil, err
+		}
+		accessToken = signed
+		sess.AccessToken = jti
+	} else {
+		accessToken = uuid.New().String()
+		if err := s.rdb.Set(ctx, accessTokenPrefix+accessToken, sess.Payload, accessTTL); err != nil {
+			return nil, err
+		}
+		sess.AccessToken = accessToken
+	}
+
+	sess.AccessExpiresAt = now.Add(accessTTL)
+	sess.RefreshHash = hashToken(refreshToken)
+	sess.RefreshedAt = now
+	sess.ExpiresAt = expiresAt
+
+	if err := s.rdb.Set(ctx, sessionPrefix+sess.SessionID, sess, expiresAt.Sub(now)); err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		SessionID:        sess.SessionID,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessExpiresAt:  now.Add(accessTTL),
+		RefreshExpiresAt: expiresAt,
+	}, nil
+}
+
+// Verify returns the auth payload of an access token, or nil when the token
+// is unknown, expired or revoked. In jwt mode a failing deny-list lookup does
+// not reject the token, so Redis being down does not log everyone out.
+func (s *Store) Verify(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	if s.mode != ModeJWT {
+		return s.opaquePayload(ctx, accessToken)
+	}
+
+	claims, err := s.keySet.verify(accessToken, s.issuer)
+	if err != nil {
+		return nil, nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	if _, err := s.rdb.Get(ctx, revokedTokenPrefix+jti); err == nil {
+		return nil, nil
+	}
+
+	payload := map[string]interface{}{}
+	for k, v := range claims {
+		switch k {
+		case "jti", "iss", "iat", "exp", "nbf":
+		default:
+			payload[k] = v
+		}
+	}
+	return payload, nil
+}
+
+func (s *Store) opaquePayload(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	val, err := s.rdb.Get(ctx, accessTokenPrefix+accessToken)
+	if err == redis.Nil || (err == nil && val == "") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(val), &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Revoke ends the session an access token belongs to, including its refresh
+// token.
+func (s *Store) Revoke(ctx context.Context, accessToken string) error {
+	var payload map[string]interface{}
+	if s.mode == ModeJWT {
+		claims, err := s.keySet.verify(accessToken, s.issuer)
+		if err != nil {
+			return nil
+		}
+		payload = claims
+	} else {
+		p, err := s.opaquePayload(ctx, accessToken)
+		if err != nil {
+			return err
+		}
+		payload = p
+	}
+
+	if sessionID, ok := payload[PayloadSessionID].(string); ok && sessionID != "" {
+		sess, err := s.get(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		if sess != nil {
+			return s.revokeSession(ctx, sess)
+		}
+	}
+
+	if s.mode == ModeJWT {
+		jti, _ := payload["jti"].(string)
+		exp, _ := payload["exp"].(float64)
+		return s.denyToken(ctx, jti, time.Unix(int64(exp), 0))
+	}
+	return s.rdb.Del(ctx, accessTokenPrefix+accessToken)
+}
+
+// RevokeAll ends every session of a user and returns how many were active.
+func (s *Store) RevokeAll(ctx context.Context, userID int64) (int, error) {
+	sessions, err := s.List(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(sessions)+1)
+	for _, sess := range sessions {
+		if err := s.dropAccessToken(ctx, sess); err != nil {
+			return 0, err
+		}
+		keys = append(keys, sessionPrefix+sess.SessionID)
+	}
+	keys = append(keys, userSessionsKey(userID))
+
+	if err := s.rdb.Del(ctx, keys...); err != nil {
+		return 0, err
+	}
+	return len(sessions), nil
+}
+
+// List returns the active sessions of a user, newest first. Ids of expired
+// sessions are dropped from the user's set on the way.
+func (s *Store) List(ctx context.Context, userID int64) ([]*Session, error) {
+	ids, err := s.rdb.SMembers(ctx, userSessionsKey(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionPrefix + id
+	}
+	vals, err := s.rdb.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*Session{}
+	expired := []string{}
+	for i, val := range vals {
+		if val == "" {
+			expired = append(expired, ids[i])
+			continue
+		}
+		var sess Session
+		if err := json.Unmarshal([]byte(val), &sess); err != nil {
+			return nil, fmt.Errorf("failed to decode session: %s", err)
+		}
+		sessions = append(sessions, &sess)
+	}
+
+	if err := s.rdb.SRem(ctx, userSessionsKey(userID), expired...); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+func (s *Store) revokeSession(ctx context.Context, sess *Session) error {
+	if err := s.dropAccessToken(ctx, sess); err != nil {
+		return err
+	}
+	if err := s.rdb.Del(ctx, sessionPrefix+sess.SessionID); err != nil {
+		return err
+	}
+	return s.rdb.SRem(ctx, userSessionsKey(sess.UserID), sess.SessionID)
+}
+
+// dropAccessToken makes the current access token of sess unusable.
+func (s *Store) dropAccessToken(ctx context.Context, sess *Session) error {
+	if sess.AccessToken == "" {
+		return nil
+	}
+	if s.mode == ModeJWT {
+		return s.denyToken(ctx, sess.AccessToken, sess.AccessExpiresAt)
+	}
+	return s.rdb.Del(ctx, accessTokenPrefix+sess.AccessToken)
+}
+
+// denyToken puts a JWT on the deny-list until it expires on its own.
+func (s *Store) denyToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt) + 5*time.Second
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return s.rdb.Set(ctx, revokedTokenPrefix+jti, 1, ttl)
+}
+
+func (s *Store) get(ctx context.Context, sessionID string) (*Session, error) {
+	val, err := s.rdb.Get(ctx, sessionPrefix+sessionID)
+	if err == redis.Nil || (err == nil && val == "") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sess Session
+	if err := json.Unmarshal([]byte(val), &sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func userSessionsKey(userID int64) string {
+	return userSessionsPrefix + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,436 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"rawuh-service/internal/shared/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memKV is Redis in memory. Values are stored as JSON the way the Redis
+// wrapper stores them, and every call fails while err is set.
+type memKV struct {
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	sets    map[string]map[string]bool
+	err     error
+}
+
+func newMemKV() *memKV {
+	return &memKV{
+		values:  map[string]string{},
+		expires: map[string]time.Time{},
+		sets:    map[string]map[string]bool{},
+	}
+}
+
+func (m *memKV) value(key string) (string, bool) {
+	if exp, ok := m.expires[key]; ok && !time.Now().Before(exp) {
+		delete(m.values, key)
+		delete(m.expires, key)
+	}
+	val, ok := m.values[key]
+	return val, ok
+}
+
+func (m *memKV) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return "", m.err
+	}
+	val, ok := m.value(key)
+	if !ok {
+		return "", redis.Nil
+	}
+	return val, nil
+}
+
+func (m *memKV) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	m.values[key] = string(data)
+	m.expires[key] = time.Now().Add(expiration)
+	return nil
+}
+
+func (m *memKV) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	if _, ok := m.value(key); ok || m.err != nil {
+		m.mu.Unlock()
+		return false, m.err
+	}
+	m.mu.Unlock()
+	return true, m.Set(ctx, key, val, expiration)
+}
+
+func (m *memKV) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	vals := make([]string, len(keys))
+	for i, key := range keys {
+		vals[i], _ = m.value(key)
+	}
+	return vals, nil
+}
+
+func (m *memKV) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	for _, key := range keys {
+		delete(m.values, key)
+		delete(m.expires, key)
+		delete(m.sets, key)
+	}
+	return nil
+}
+
+func (m *memKV) SAdd(ctx context.Context, key string, member string, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	if m.sets[key] == nil {
+		m.sets[key] = map[string]bool{}
+	}
+	m.sets[key][member] = true
+	return nil
+}
+
+func (m *memKV) SRem(ctx context.Context, key string, members ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	for _, member := range members {
+		delete(m.sets[key], member)
+	}
+	return nil
+}
+
+func (m *memKV) SMembers(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	members := []string{}
+	for member := range m.sets[key] {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// denied lists the jtis on the deny-list.
+func (m *memKV) denied() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jtis := []string{}
+	for key := range m.values {
+		if jti, ok := strings.CutPrefix(key, revokedTokenPrefix); ok {
+			if _, live := m.value(key); live {
+				jtis = append(jtis, jti)
+			}
+		}
+	}
+	return jtis
+}
+
+var modes = []string{ModeOpaque, ModeJWT}
+
+func newTestStore(t *testing.T, mode string, keySet *KeySet) (*Store, *memKV) {
+	t.Helper()
+	if mode == ModeJWT && keySet == nil {
+		keySet = testKeySet(t, "", testKey("a", 32))
+	}
+	kv := newMemKV()
+	s, err := newStore(kv, Config{
+		Mode:       mode,
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: time.Hour,
+		MaxTTL:     24 * time.Hour,
+		Issuer:     "rawuh-test",
+		KeySet:     keySet,
+	})
+	if err != nil {
+		t.Fatalf("newStore: %v", err)
+	}
+	return s, kv
+}
+
+func create(t *testing.T, s *Store) *Tokens {
+	t.Helper()
+	tokens, err := s.Create(context.Background(), 7, map[string]interface{}{"user_id": 7}, Meta{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return tokens
+}
+
+// verified reports whether accessToken is accepted.
+func verified(t *testing.T, s *Store, accessToken string) bool {
+	t.Helper()
+	payload, err := s.Verify(context.Background(), accessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return payload != nil
+}
+
+func TestNewStoreNeedsKeySetInJWTMode(t *testing.T) {
+	if _, err := newStore(newMemKV(), Config{Mode: ModeJWT, AccessTTL: time.Minute, RefreshTTL: time.Hour, MaxTTL: time.Hour}); err == nil {
+		t.Error("jwt mode started without a key set")
+	}
+}
+
+func TestStoreVerify(t *testing.T) {
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			s, _ := newTestStore(t, mode, nil)
+			tokens := create(t, s)
+
+			payload, err := s.Verify(context.Background(), tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if payload["user_id"] != float64(7) || payload[PayloadSessionID] != tokens.SessionID {
+				t.Errorf("payload = %v", payload)
+			}
+			if _, ok := payload["jti"]; ok {
+				t.Errorf("payload carries the registered claims: %v", payload)
+			}
+
+			if verified(t, s, "unknown-token") {
+				t.Error("unknown token verified")
+			}
+		})
+	}
+}
+
+func TestStoreRevoke(t *testing.T) {
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			s, kv := newTestStore(t, mode, nil)
+			tokens := create(t, s)
+			other := create(t, s)
+
+			if err := s.Revoke(context.Background(), tokens.AccessToken); err != nil {
+				t.Fatalf("Revoke: %v", err)
+			}
+			if verified(t, s, tokens.AccessToken) {
+				t.Error("revoked access token verified")
+			}
+			if _, err := s.Refresh(context.Background(), tokens.RefreshToken, Meta{}); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("Refresh after Revoke = %v, want %v", err, ErrInvalidRefreshToken)
+			}
+			if !verified(t, s, other.AccessToken) {
+				t.Error("access token of another session stopped working")
+			}
+
+			denied := kv.denied()
+			if mode == ModeJWT && len(denied) != 1 {
+				t.Errorf("deny-list = %v, want the revoked token", denied)
+			}
+			if mode == ModeOpaque && len(denied) != 0 {
+				t.Errorf("deny-list = %v, want it unused", denied)
+			}
+		})
+	}
+}
+
+func TestStoreRevokeWithoutSession(t *testing.T) {
+	// the session expired or was removed, the access token still has to go
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			s, kv := newTestStore(t, mode, nil)
+			tokens := create(t, s)
+			kv.Del(context.Background(), sessionPrefix+tokens.SessionID)
+
+			if err := s.Revoke(context.Background(), tokens.AccessToken); err != nil {
+				t.Fatalf("Revoke: %v", err)
+			}
+			if verified(t, s, tokens.AccessToken) {
+				t.Error("revoked access token verified")
+			}
+		})
+	}
+}
+
+func TestStoreRefreshDropsOldAccessToken(t *testing.T) {
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			s, kv := newTestStore(t, mode, nil)
+			first := create(t, s)
+
+			second, err := s.Refresh(context.Background(), first.RefreshToken, Meta{})
+			if err != nil {
+				t.Fatalf("Refresh: %v", err)
+			}
+			if verified(t, s, first.AccessToken) {
+				t.Error("access token verified after a refresh")
+			}
+			if !verified(t, s, second.AccessToken) {
+				t.Error("new access token rejected")
+			}
+			if mode == ModeJWT && len(kv.denied()) != 1 {
+				t.Errorf("deny-list = %v, want the old token", kv.denied())
+			}
+
+			// replaying the old refresh token ends the session
+			if _, err := s.Refresh(context.Background(), first.RefreshToken, Meta{}); !errors.Is(err, ErrRefreshTokenReused) {
+				t.Fatalf("replayed Refresh = %v, want %v", err, ErrRefreshTokenReused)
+			}
+			if verified(t, s, second.AccessToken) {
+				t.Error("access token verified after the session was revoked")
+			}
+		})
+	}
+}
+
+func TestStoreRevokeAll(t *testing.T) {
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			s, _ := newTestStore(t, mode, nil)
+			first, second := create(t, s), create(t, s)
+
+			n, err := s.RevokeAll(context.Background(), 7)
+			if err != nil {
+				t.Fatalf("RevokeAll: %v", err)
+			}
+			if n != 2 {
+				t.Errorf("revoked %d sessions, want 2", n)
+			}
+			for _, tokens := range []*Tokens{first, second} {
+				if verified(t, s, tokens.AccessToken) {
+					t.Error("access token verified after RevokeAll")
+				}
+			}
+		})
+	}
+}
+
+func TestStoreWhenRedisIsDown(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		// opaque tokens live in Redis
+		{ModeOpaque, true},
+		// a JWT is still accepted, the deny-list lookup is skipped
+		{ModeJWT, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			s, kv := newTestStore(t, tt.mode, nil)
+			tokens := create(t, s)
+			kv.err = errors.New("connection refused")
+
+			payload, err := s.Verify(context.Background(), tokens.AccessToken)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify error = %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && payload == nil {
+				t.Error("token rejected")
+			}
+		})
+	}
+}
+
+func TestStoreKeyRotation(t *testing.T) {
+	a, b := testKey("a", 32), testKey("b", 32)
+	s, _ := newTestStore(t, ModeJWT, testKeySet(t, "", a))
+	signedWithA := create(t, s)
+
+	// b signs new tokens, a is kept until the tokens it signed expire
+	s.keySet = testKeySet(t, "b", b, a)
+	signedWithB := create(t, s)
+	if !verified(t, s, signedWithA.AccessToken) {
+		t.Error("token signed with the old key rejected")
+	}
+	if !verified(t, s, signedWithB.AccessToken) {
+		t.Error("token signed with the new key rejected")
+	}
+
+	// a refresh signs the new token with b
+	refreshed, err := s.Refresh(context.Background(), signedWithA.RefreshToken, Meta{})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(refreshed.AccessToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	if kid := parsed.Header["kid"]; kid != "b" {
+		t.Errorf("refreshed token kid = %v, want b", kid)
+	}
+
+	s.keySet = testKeySet(t, "", b)
+	if !verified(t, s, refreshed.AccessToken) {
+		t.Error("refreshed token rejected after a was retired")
+	}
+	other := create(t, s)
+	s.keySet = testKeySet(t, "", a)
+	if verified(t, s, other.AccessToken) {
+		t.Error("token verified with b retired")
+	}
+}
+
+func TestStoreRejectsOtherTokens(t *testing.T) {
+	a := testKey("a", 32)
+
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			s, _ := newTestStore(t, mode, testKeySet(t, "", a))
+			tokens := create(t, s)
+
+			claims := testClaims()
+			claims[PayloadSessionID] = tokens.SessionID
+
+			tests := map[string]string{
+				"HS256 signed with a listed key": signWith(t, jwt.SigningMethodHS256, a, claims),
+				"HS512":                          signWith(t, jwt.SigningMethodHS512, a, claims),
+				"none":                           signWith(t, jwt.SigningMethodNone, a, claims),
+				"unknown kid":                    signWith(t, jwt.SigningMethodHS256, testKey("c", 32), claims),
+			}
+			for name, token := range tests {
+				// only jwt mode accepts a JWT, and only one signed with HS256
+				want := mode == ModeJWT && name == "HS256 signed with a listed key"
+				if got := verified(t, s, token); got != want {
+					t.Errorf("%s: verified = %t, want %t", name, got, want)
+				}
+			}
+		})
+	}
+}
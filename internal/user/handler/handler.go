@@ -66,6 +66,7 @@ func (h *UserHandler) AddUser(w http.ResponseWriter, r *http.Request) {
 		Password:  p.Password,
 		UserType:  p.UserType,
 		Email:     p.Email,
+		Role:      p.Role,
 	}
 	if err := h.svc.AddUser(ctx, req); err != nil {
 		utils.HandleGrpcError(w, err)
@@ -140,13 +141,17 @@ func (h *UserHandler) UpdateUserByID(w http.ResponseWriter, r *http.Request) {
 
 // ListUsers godoc
 // @Summary List users
-// @Description Get paginated list of users
+// @Description Get paginated list of users. Filter with filter[column][op]=value, and OR conditions together with filter[or][group][column][op]=value; see the readme for operators and columns.
 // @Tags user
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param page query int false "page"
 // @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param sort query string false "sort column, any filterable column"
+// @Param dir query string false "asc or desc"
 // @Success 200 {object} userModel.ListUserResponse
 // @Failure 400 {object} utils.APIErrorResponse
 // @Router /users/list [get]
@@ -181,7 +186,9 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		Limit:     int32(limit),
 		Sort:      queryParams.Get("sort"),
 		Dir:       queryParams.Get("dir"),
-		Query:     queryParams.Get("query"),
+		Filter:    queryParams,
+		Cursor:    utils.QueryCursor(queryParams),
+		Count:     queryParams.Get("count"),
 		EventId:   mux.Vars(r)["event_id"],
 		ProjectID: mux.Vars(r)["project_id"],
 	}
@@ -285,3 +292,194 @@ func (h *UserHandler) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
 }
+
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Restore a deleted user, who can then log in again
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "user id"
+// @Success 200 {object} userModel.RestoreUserResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /users/{user_id}/restore [post]
+
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &userModel.RestoreUserRequest{
+		UserID: mux.Vars(r)["user_id"],
+	}
+
+	user, err := h.svc.RestoreUser(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description Log a user out everywhere by revoking every access token. System admin only.
+// @Tags user
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "user id"
+// @Success 200 {object} userModel.RevokeUserSessionsResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /users/{user_id}/sessions [delete]
+
+func (h *UserHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &userModel.RevokeUserSessionsRequest{
+		UserID: mux.Vars(r)["user_id"],
+	}
+
+	result, err := h.svc.RevokeUserSessions(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ListUserRoles godoc
+// @Summary List the roles of a user
+// @Description Roles a user holds per project and event. Users may list their own roles; project owners see the roles held in their projects.
+// @Tags user
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "user id"
+// @Success 200 {object} userModel.ListUserRolesResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /users/{user_id}/roles [get]
+
+func (h *UserHandler) ListUserRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &userModel.ListUserRolesRequest{
+		UserID: mux.Vars(r)["user_id"],
+	}
+
+	result, err := h.svc.ListUserRoles(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// AssignUserRole godoc
+// @Summary Assign a role to a user
+// @Description Grant PROJECT_OWNER, EVENT_MANAGER, USHER or VIEWER in a project, or in one event when EventID is set. Needs role management rights on the project. The user is logged out so the next login carries the role.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "user id"
+// @Param body body userModel.AssignUserRoleRequest true "AssignUserRoleRequest"
+// @Success 200 {object} userModel.AssignUserRoleResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Failure 409 {object} utils.APIErrorResponse
+// @Router /users/{user_id}/roles [post]
+
+func (h *UserHandler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p userModel.AssignUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&userModel.AssignUserRoleResponse{
+			Error:   true,
+			Code:    http.StatusBadRequest,
+			Message: "Invalid Argument",
+		})
+		return
+	}
+
+	req := &userModel.AssignUserRoleRequest{
+		UserID:    mux.Vars(r)["user_id"],
+		Role:      p.Role,
+		ProjectID: p.ProjectID,
+		EventID:   p.EventID,
+	}
+
+	result, err := h.svc.AssignUserRole(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// RemoveUserRole godoc
+// @Summary Remove a role from a user
+// @Description Take a role away. Needs role management rights on the role's project. The user is logged out right away.
+// @Tags user
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "user id"
+// @Param user_role_id path string true "user role id"
+// @Success 200 {object} userModel.RemoveUserRoleResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /users/{user_id}/roles/{user_role_id} [delete]
+
+func (h *UserHandler) RemoveUserRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &userModel.RemoveUserRoleRequest{
+		UserID:     mux.Vars(r)["user_id"],
+		UserRoleID: mux.Vars(r)["user_role_id"],
+	}
+
+	if err := h.svc.RemoveUserRole(ctx, req); err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&userModel.RemoveUserRoleResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	})
+}
@@ -17,4 +17,28 @@ type User struct {
 	CreatedAt     *time.Time `gorm:"type:timestamp"`
 	UpdatedAt     *time.Time `gorm:"type:timestamp"`
 	Status        int64      `gorm:"type:integer"`
+	DeletedAt     *time.Time `gorm:"type:timestamp"`
+	DeletedById   int64      `gorm:"type:bigint"`
+}
+
+// UserRole grants Role in a project. A nil EventID covers every event of the
+// project.
+type UserRole struct {
+	UserRoleID  int64      `gorm:"primaryKey;autoIncrement"`
+	UserID      int64      `gorm:"type:bigint"`
+	ProjectID   int64      `gorm:"type:bigint"`
+	EventID     *int64     `gorm:"type:bigint"`
+	Role        string     `gorm:"type:varchar(50)"`
+	CreatedById int64      `gorm:"type:bigint"`
+	CreatedAt   *time.Time `gorm:"type:timestamp"`
+}
+
+// Membership is a UserRole with the names of its project and event.
+type Membership struct {
+	UserRoleID  int64
+	ProjectID   int64
+	ProjectName string
+	EventID     *int64
+	EventName   *string
+	Role        string
 }
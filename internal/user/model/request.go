@@ -1,13 +1,19 @@
 package model
 
-import "rawuh-service/internal/shared/model"
+import (
+	"net/url"
+
+	"rawuh-service/internal/shared/model"
+)
 
 type ListUserRequest struct {
-	Page      int32  `json:"page"`
-	Limit     int32  `json:"limit"`
-	Sort      string `json:"sort"`
-	Dir       string `json:"dir"`
-	Query     string `json:"query"`
+	Page      int32      `json:"page"`
+	Limit     int32      `json:"limit"`
+	Sort      string     `json:"sort"`
+	Dir       string     `json:"dir"`
+	Filter    url.Values `json:"filter"`
+	Cursor    *string    `json:"cursor"`
+	Count     string     `json:"count"`
 	EventId   string
 	ProjectID string
 }
@@ -29,6 +35,9 @@ type CreateUserRequest struct {
 	Email     string
 	UserID    string
 	EventId   string
+	// Role is granted to a PROJECT_USER on ProjectID, and on EventId when
+	// set. EVENT_MANAGER when empty.
+	Role string
 }
 
 type CreateUserResponse struct {
@@ -75,3 +84,61 @@ type DeleteUserByIDResponse struct {
 	Code    int32
 	Message string
 }
+
+type RestoreUserRequest struct {
+	UserID string
+}
+
+type RestoreUserResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *User
+}
+
+type RevokeUserSessionsRequest struct {
+	UserID string
+}
+
+type RevokeUserSessionsResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Revoked int
+}
+
+type ListUserRolesRequest struct {
+	UserID string
+}
+
+type ListUserRolesResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    []*UserRole
+}
+
+type AssignUserRoleRequest struct {
+	UserID    string
+	Role      string
+	ProjectID string
+	EventID   string
+}
+
+type AssignUserRoleResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *UserRole
+}
+
+type RemoveUserRoleRequest struct {
+	UserID     string
+	UserRoleID string
+}
+
+type RemoveUserRoleResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+}
@@ -59,7 +59,7 @@ func (p *UserRepository) UpdateUser(ctx context.Context, req *userModel.UpdateUs
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.users")
 
-	query = query.Where("user_id = ?", req.UserID)
+	query = query.Where("user_id = ? AND deleted_at IS NULL", req.UserID)
 
 	projectID, _ := strconv.ParseInt(req.ProjectID, 0, 64)
 	// eventID, _ := strconv.ParseInt(req.EventId, 0, 64)
@@ -97,7 +97,7 @@ func (p *UserRepository) GetUserByID(ctx context.Context, userID string) (*userM
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().
 		Table("public.users")
 
-	query = query.Where("user_id = ?", userID)
+	query = query.Where("user_id = ? AND deleted_at IS NULL", userID)
 
 	if err := query.Debug().Find(&data).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -108,15 +108,18 @@ func (p *UserRepository) GetUserByID(ctx context.Context, userID string) (*userM
 	return &data, nil
 }
 
-func (p *UserRepository) DeleteUserByID(ctx context.Context, userID string) error {
+func (p *UserRepository) DeleteUserByID(ctx context.Context, userID string, currentUser middleware.AuthClaims) error {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.users")
 
-	query = query.Where("user_id = ?", userID)
+	query = query.Where("user_id = ? AND deleted_at IS NULL", userID)
 
-	res := query.Delete(&userModel.User{})
+	res := query.Updates(map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"deleted_by_id": currentUser.UserID,
+	})
 
 	if res.Error != nil {
 		return res.Error
@@ -129,22 +132,69 @@ func (p *UserRepository) DeleteUserByID(ctx context.Context, userID string) erro
 	return nil
 }
 
+func (p *UserRepository) RestoreUser(ctx context.Context, userID string) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.users")
+
+	query = query.Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	res := query.Updates(map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": nil,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// PurgeUsers permanently removes users deleted before the given time, at most
+// limit rows. Their login and roles go with them through the foreign keys.
+func (p *UserRepository) PurgeUsers(ctx context.Context, before time.Time, limit int) (int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.users")
+
+	query = query.Where("user_id IN (?)", p.provider.GetDB().Table("public.users").
+		Select("user_id").Where("deleted_at < ?", before).Limit(limit))
+
+	res := query.Delete(&userModel.User{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
 func (p *UserRepository) ListUsers(ctx context.Context, projectID string, eventID string, pagination *model.PaginationResponse, sql *db.QueryBuilder, sort *model.Sort) (data []*userModel.User, err error) {
 	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
 	defer cancel()
 
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.users")
 
+	query = query.Where("deleted_at IS NULL")
+
 	query = query.Scopes(
-		db.QueryScoop(sql.CollectiveAnd),
+		sql.Filter.Scope(),
 	)
 
+	if pagination.Cursor != nil {
+		return db.Keyset[userModel.User](query, pagination, sort, "user_id")
+	}
+
 	query = query.Scopes(db.Paginate(data, pagination, query))
 	query = query.Scopes(
 		db.Sort(sort),
 	)
 
-	if err := query.Debug().First(&data).Error; err != nil {
+	if err := query.Debug().Find(&data).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
@@ -162,6 +212,8 @@ func (p *UserRepository) CheckUsernameExist(ctx context.Context, username string
 	query := p.provider.GetDB().WithContext(timeoutctx).Debug().
 		Table("public.users")
 
+	// deleted users keep their username until they are purged, so a restore
+	// never clashes with a newer account
 	query = query.Where("username = ?", username)
 
 	if err := query.Debug().Find(&data).Error; err != nil {
@@ -172,3 +224,146 @@ func (p *UserRepository) CheckUsernameExist(ctx context.Context, username string
 
 	return data.UserID != 0, nil
 }
+
+func (p *UserRepository) ListUserRoles(ctx context.Context, userID string) (data []*userModel.UserRole, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.user_roles")
+
+	query = query.Where("user_id = ?", userID).Order("project_id, event_id NULLS FIRST, role")
+
+	if err := query.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (p *UserRepository) GetUserRole(ctx context.Context, userID string, userRoleID string) (*userModel.UserRole, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data userModel.UserRole
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.user_roles")
+
+	query = query.Where("user_id = ? and user_role_id = ?", userID, userRoleID)
+
+	if err := query.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (p *UserRepository) UserRoleExists(ctx context.Context, role *userModel.UserRole) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var count int64
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.user_roles")
+
+	query = query.Where("user_id = ? and project_id = ? and role = ?", role.UserID, role.ProjectID, role.Role)
+	if role.EventID != nil {
+		query = query.Where("event_id = ?", *role.EventID)
+	} else {
+		query = query.Where("event_id IS NULL")
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IsProjectMember reports whether the user belongs to the project, through a
+// role held in it or as the project stored on the user.
+func (p *UserRepository) IsProjectMember(ctx context.Context, userID int64, projectID int64) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var count int64
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.users")
+
+	query = query.Where("user_id = ? AND deleted_at IS NULL", userID).
+		Where("project_id = ? OR EXISTS (SELECT 1 FROM public.user_roles WHERE user_roles.user_id = users.user_id AND user_roles.project_id = ?)", projectID, projectID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// EventExists reports whether the event belongs to the project.
+func (p *UserRepository) EventExists(ctx context.Context, projectID int64, eventID int64) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var count int64
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.events")
+
+	query = query.Where("project_id = ? AND event_id = ? AND deleted_at IS NULL", projectID, eventID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (p *UserRepository) CreateUserRole(ctx context.Context, role *userModel.UserRole) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.user_roles")
+
+	if err := query.Omit("user_role_id").Create(role).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *UserRepository) DeleteUserRole(ctx context.Context, userRoleID int64) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.user_roles")
+
+	query = query.Where("user_role_id = ?", userRoleID)
+
+	res := query.Delete(&userModel.UserRole{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (p *UserRepository) ListMemberships(ctx context.Context, userID string) (data []*userModel.Membership, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.user_roles ur").
+		Select("ur.user_role_id, ur.project_id, p.project_name, ur.event_id, e.event_name, ur.role").
+		Joins("JOIN public.projects p ON p.project_id = ur.project_id AND p.deleted_at IS NULL").
+		Joins("LEFT JOIN public.events e ON e.event_id = ur.event_id")
+
+	// a role on a deleted event is gone, one on the whole project stays
+	query = query.Where("ur.user_id = ? AND (ur.event_id IS NULL OR e.deleted_at IS NULL)", userID).Order("ur.project_id, ur.event_id NULLS FIRST, ur.role")
+
+	if err := query.Scan(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
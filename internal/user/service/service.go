@@ -2,22 +2,24 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"net/http"
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
 	authModel "rawuh-service/internal/auth/model"
 	repoAuth "rawuh-service/internal/auth/repository"
+	"rawuh-service/internal/shared/authz"
 	"rawuh-service/internal/shared/constant"
 	"rawuh-service/internal/shared/lib/utils"
 	"rawuh-service/internal/shared/logger"
 	"rawuh-service/internal/shared/middleware"
-	"rawuh-service/internal/shared/model"
-	"rawuh-service/internal/shared/redis"
+	"rawuh-service/internal/shared/session"
 	userModel "rawuh-service/internal/user/model"
 	"strconv"
-	"strings"
+	"time"
 
 	db "rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/filter"
 	userDb "rawuh-service/internal/user/repository"
 
 	"go.elastic.co/apm/v2"
@@ -31,25 +33,46 @@ type UserService interface {
 	UpdateUserByID(ctx context.Context, p *userModel.UpdateUserRequest) error
 	GetUserByID(ctx context.Context, req *userModel.GetUserByIDRequest) (*userModel.GetUserByIDResponse, error)
 	DeleteUserByID(ctx context.Context, req *userModel.DeleteUserByIDRequest) error
+	RestoreUser(ctx context.Context, req *userModel.RestoreUserRequest) (*userModel.RestoreUserResponse, error)
 	ListUsers(ctx context.Context, req *userModel.ListUserRequest) (*userModel.ListUserResponse, error)
+	RevokeUserSessions(ctx context.Context, req *userModel.RevokeUserSessionsRequest) (*userModel.RevokeUserSessionsResponse, error)
+	ListUserRoles(ctx context.Context, req *userModel.ListUserRolesRequest) (*userModel.ListUserRolesResponse, error)
+	AssignUserRole(ctx context.Context, req *userModel.AssignUserRoleRequest) (*userModel.AssignUserRoleResponse, error)
+	RemoveUserRole(ctx context.Context, req *userModel.RemoveUserRoleRequest) error
 }
 
 type userService struct {
 	dbProvider *userDb.UserRepository
 	logger     *logger.Logger
 	authRepo   *repoAuth.AuthRepository
-	redis      *redis.Redis
+	sessions   *session.Store
+	audit      auditService.AuditService
 }
 
-func NewUserService(dbProvider *userDb.UserRepository, authRepo *repoAuth.AuthRepository, rdb *redis.Redis, logger *logger.Logger) UserService {
+func NewUserService(dbProvider *userDb.UserRepository, authRepo *repoAuth.AuthRepository, sessions *session.Store, audit auditService.AuditService, logger *logger.Logger) UserService {
 	return &userService{
 		dbProvider: dbProvider,
 		logger:     logger,
 		authRepo:   authRepo,
-		redis:      rdb,
+		sessions:   sessions,
+		audit:      audit,
 	}
 }
 
+// userColumns are the columns ListUsers can filter and sort on.
+var userColumns = filter.Columns{
+	"user_id":    {Type: filter.Int},
+	"name":       {Type: filter.String},
+	"username":   {Type: filter.String},
+	"email":      {Type: filter.String},
+	"user_type":  {Type: filter.String},
+	"status":     {Type: filter.Int},
+	"project_id": {Type: filter.Int},
+	"event_id":   {Type: filter.Int},
+	"created_at": {Type: filter.Time},
+	"updated_at": {Type: filter.Time},
+}
+
 func (s *userService) AddUser(ctx context.Context, req *userModel.CreateUserRequest) error {
 	funcName := "AddUser"
 	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
@@ -65,9 +88,9 @@ func (s *userService) AddUser(ctx context.Context, req *userModel.CreateUserRequ
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	nameLength, _ := strconv.Atoi(utils.GetEnv("USER_NAME_LENGTH", "255"))
@@ -90,6 +113,21 @@ func (s *userService) AddUser(ctx context.Context, req *userModel.CreateUserRequ
 		return status.Errorf(codes.Aborted, "characters not allowed in user name")
 	}
 
+	var role *userModel.UserRole
+	if req.UserType == constant.UserTypeProjectUser {
+		if req.Role == "" {
+			req.Role = constant.RoleEventManager
+		}
+		if !authz.IsRole(req.Role) {
+			return status.Errorf(codes.InvalidArgument, "unknown role %q", req.Role)
+		}
+		userRole, err := newUserRole(0, req.Role, req.ProjectID, req.EventId, currentUser)
+		if err != nil {
+			return err
+		}
+		role = userRole
+	}
+
 	loggerZap.Info("Start CreateUser with data ", req)
 
 	found, err := s.dbProvider.CheckUsernameExist(ctx, req.Username)
@@ -111,16 +149,16 @@ func (s *userService) AddUser(ctx context.Context, req *userModel.CreateUserRequ
 	// // if password provided, create auth row
 	// if req.Password != "" {
 	// }
-	encrypted, err := utils.EncryptAES(req.Password)
+	hashed, err := utils.HashPassword(req.Password)
 	if err != nil {
-		loggerZap.Error("err EncryptAES", err)
+		loggerZap.Error("err HashPassword", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
 	authRow := &authModel.Auth{
 		UserID:    createdID,
 		Username:  req.Username,
-		Password:  encrypted,
+		Password:  hashed,
 		ProjectID: func() int64 { pid, _ := strconv.ParseInt(req.ProjectID, 0, 64); return pid }(),
 	}
 
@@ -129,6 +167,30 @@ func (s *userService) AddUser(ctx context.Context, req *userModel.CreateUserRequ
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	if role != nil {
+		role.UserID = createdID
+		if err := s.dbProvider.CreateUserRole(ctx, role); err != nil {
+			loggerZap.Error("err CreateUserRole", err)
+			return status.Error(codes.Internal, "Internal Server Error")
+		}
+	}
+
+	userID := strconv.FormatInt(createdID, 10)
+	user, err := s.dbProvider.GetUserByID(ctx, userID)
+	if err != nil {
+		loggerZap.Error("err GetUserByID ", err)
+	}
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCreate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   userID,
+		ProjectID:  req.ProjectID,
+		After:      user,
+	})
+	if role != nil {
+		s.audit.Record(ctx, userRoleEntry(constant.AuditActionCreate, role))
+	}
+
 	loggerZap.Info("Success CreateUser")
 
 	return nil
@@ -149,9 +211,9 @@ func (s *userService) UpdateUserByID(ctx context.Context, req *userModel.UpdateU
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	nameLength, _ := strconv.Atoi(utils.GetEnv("USER_NAME_LENGTH", "255"))
@@ -175,12 +237,30 @@ func (s *userService) UpdateUserByID(ctx context.Context, req *userModel.UpdateU
 
 	loggerZap.Info("Start UpdateUser with data ", req)
 
-	err := s.dbProvider.UpdateUser(ctx, req, currentUser)
+	before, err := s.dbProvider.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		loggerZap.Error("err GetUserByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	err = s.dbProvider.UpdateUser(ctx, req, currentUser)
 	if err != nil {
 		loggerZap.Error("err UpdateUser ", err)
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	after, err := s.dbProvider.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		loggerZap.Error("err GetUserByID ", err)
+	}
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUpdate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   req.UserID,
+		Before:     before,
+		After:      after,
+	})
+
 	loggerZap.Info("Success UpdateUser")
 
 	return nil
@@ -195,7 +275,6 @@ func (s *userService) ListUsers(ctx context.Context, req *userModel.ListUserRequ
 	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
 
 	loggerZap.Info("Start ListUsers with req : ", req)
-	loggerZap.Info("Start Decode Filter")
 
 	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
 	if !ok {
@@ -205,59 +284,42 @@ func (s *userService) ListUsers(ctx context.Context, req *userModel.ListUserRequ
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
-	decodeQuery, err := base64.RawStdEncoding.DecodeString(req.Query)
-	if err != nil {
-		loggerZap.Error("err DecodeString ", err)
-		return nil, nil
-	}
-
-	loggerZap.Info("Success Decode Query")
-
-	pagination := utils.SetPagination(req.Page, req.Limit)
+	loggerZap.Info("Start Parse Filter")
 
-	allowedColumns := map[string]bool{
-		"created_at": true,
-		"name":       true,
-		"username":   true,
-		"user_type":  true,
-		"email":      true,
+	filters, err := filter.Parse(req.Filter, userColumns)
+	if err != nil {
+		loggerZap.Error("err Parse filter ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	allowedDirections := map[string]bool{
-		"asc":  true,
-		"desc": true,
+	sort, err := userColumns.Sort(req.Sort, req.Dir)
+	if err != nil {
+		loggerZap.Error("err Sort ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	column := strings.ToLower(req.Sort)
-	direction := strings.ToLower(req.Dir)
-
-	if column != "" || direction != "" {
-
-		if !allowedColumns[column] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
-		}
-		if !allowedDirections[direction] {
-			return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
-		}
-	}
-	sort := &model.Sort{
-		Column:    column,
-		Direction: direction,
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	sqlBuilder := &db.QueryBuilder{
-		CollectiveAnd: string(decodeQuery),
-		Sort:          sort,
+		Filter: filters,
+		Sort:   sort,
 	}
 
 	loggerZap.Info("Start ListUsers")
 	users, err := s.dbProvider.ListUsers(ctx, req.EventId, req.ProjectID, pagination, sqlBuilder, sort)
 	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		s.logger.Error("err ListUsers ", err)
 		return nil, status.Error(codes.Internal, "Internal Server Error")
 	}
@@ -293,9 +355,9 @@ func (s *userService) GetUserByID(ctx context.Context, req *userModel.GetUserByI
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
 	}
 
 	if req.UserID == "" {
@@ -342,9 +404,9 @@ func (s *userService) DeleteUserByID(ctx context.Context, req *userModel.DeleteU
 
 	loggerZap.Info("Success GetMeFromMD ", currentUser)
 
-	if currentUser.UserType != constant.UserTypeSystemAdmin {
-		loggerZap.Error("err CreateProject unauthorized user", nil)
-		return status.Error(codes.PermissionDenied, "Permission Denied")
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
 	}
 
 	loggerZap.Info("Start DeleteUserByID with req : ", req)
@@ -354,8 +416,14 @@ func (s *userService) DeleteUserByID(ctx context.Context, req *userModel.DeleteU
 		return status.Errorf(codes.InvalidArgument, "Invalid User Id")
 	}
 
+	before, err := s.dbProvider.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		loggerZap.Error("err GetUserByID ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
 	loggerZap.Info("Start DeleteUserByID")
-	err := s.dbProvider.DeleteUserByID(ctx, req.UserID)
+	err = s.dbProvider.DeleteUserByID(ctx, req.UserID, currentUser)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			loggerZap.Warn("user not found", err)
@@ -366,8 +434,394 @@ func (s *userService) DeleteUserByID(ctx context.Context, req *userModel.DeleteU
 		return status.Error(codes.Internal, "Internal Server Error")
 	}
 
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   req.UserID,
+		Before:     before,
+	})
+
+	// a deleted user must not keep using tokens issued before
+	userID, _ := strconv.ParseInt(req.UserID, 10, 64)
+	if _, err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		loggerZap.Error("err RevokeAll sessions ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
 	loggerZap.Info("Start making response")
 
 	return nil
 
 }
+
+// RestoreUser undoes DeleteUserByID. The sessions ended by the delete stay
+// ended, the user logs in again.
+func (s *userService) RestoreUser(ctx context.Context, req *userModel.RestoreUserRequest) (*userModel.RestoreUserResponse, error) {
+	funcName := "RestoreUser"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	if req.UserID == "" {
+		loggerZap.Error("err Invalid user id : ", nil)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid User Id")
+	}
+
+	loggerZap.Info("Start RestoreUser")
+	if err := s.dbProvider.RestoreUser(ctx, req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			loggerZap.Warn("deleted user not found", err)
+			return nil, status.Error(codes.NotFound, "Deleted user not found")
+		}
+
+		loggerZap.Error("err RestoreUser ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	user, err := s.dbProvider.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		loggerZap.Error("err GetUserByID ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionRestore,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   req.UserID,
+		After:      user,
+	})
+
+	loggerZap.Info("Success RestoreUser")
+
+	result := &userModel.RestoreUserResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    user,
+	}
+
+	return result, nil
+}
+
+func (s *userService) RevokeUserSessions(ctx context.Context, req *userModel.RevokeUserSessionsRequest) (*userModel.RevokeUserSessionsResponse, error) {
+	funcName := "RevokeUserSessions"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.UserManage, "", ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	userID, err := strconv.ParseInt(req.UserID, 10, 64)
+	if err != nil {
+		loggerZap.Error("err Invalid user id : ", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid User Id")
+	}
+
+	loggerZap.Info("Start RevokeAll sessions")
+	revoked, err := s.sessions.RevokeAll(ctx, userID)
+	if err != nil {
+		loggerZap.Error("err RevokeAll sessions ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &userModel.RevokeUserSessionsResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Revoked: revoked,
+	}
+
+	return result, nil
+}
+
+// ListUserRoles returns the roles of a user. Users see their own roles, system
+// admins see every role and project owners see the roles held in their
+// projects.
+func (s *userService) ListUserRoles(ctx context.Context, req *userModel.ListUserRolesRequest) (*userModel.ListUserRolesResponse, error) {
+	funcName := "ListUserRoles"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if _, err := strconv.ParseInt(req.UserID, 10, 64); err != nil {
+		loggerZap.Error("err Invalid user id : ", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid User Id")
+	}
+
+	loggerZap.Info("Start ListUserRoles")
+	roles, err := s.dbProvider.ListUserRoles(ctx, req.UserID)
+	if err != nil {
+		loggerZap.Error("err ListUserRoles ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	self := req.UserID == strconv.FormatInt(currentUser.UserID, 10)
+	if !self && !authz.Can(currentUser, authz.UserManage, "", "") {
+		visible := []*userModel.UserRole{}
+		for _, role := range roles {
+			if authz.Can(currentUser, authz.RoleManage, strconv.FormatInt(role.ProjectID, 10), "") {
+				visible = append(visible, role)
+			}
+		}
+		if len(visible) == 0 {
+			loggerZap.Error("err ListUserRoles unauthorized user", nil)
+			return nil, status.Error(codes.PermissionDenied, "Permission Denied")
+		}
+		roles = visible
+	}
+
+	result := &userModel.ListUserRolesResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    roles,
+	}
+
+	return result, nil
+}
+
+// AssignUserRole grants a role in a project, or in one event of it. The
+// user's sessions are ended so the next login carries the new role.
+func (s *userService) AssignUserRole(ctx context.Context, req *userModel.AssignUserRoleRequest) (*userModel.AssignUserRoleResponse, error) {
+	funcName := "AssignUserRole"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	userID, err := strconv.ParseInt(req.UserID, 10, 64)
+	if err != nil {
+		loggerZap.Error("err Invalid user id : ", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid User Id")
+	}
+
+	if !authz.IsRole(req.Role) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown role %q", req.Role)
+	}
+
+	if err := authz.Check(currentUser, authz.RoleManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	role, err := newUserRole(userID, req.Role, req.ProjectID, req.EventID, currentUser)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRoleTarget(ctx, currentUser, userID, role.ProjectID); err != nil {
+		loggerZap.Error("err checkRoleTarget ", err)
+		return nil, err
+	}
+
+	if role.EventID != nil {
+		exists, err := s.dbProvider.EventExists(ctx, role.ProjectID, *role.EventID)
+		if err != nil {
+			loggerZap.Error("err EventExists ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+		if !exists {
+			return nil, status.Error(codes.InvalidArgument, "event does not belong to the project")
+		}
+	}
+
+	found, err := s.dbProvider.UserRoleExists(ctx, role)
+	if err != nil {
+		loggerZap.Error("err UserRoleExists ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if found {
+		return nil, status.Error(codes.AlreadyExists, "role already assigned")
+	}
+
+	loggerZap.Info("Start CreateUserRole")
+	if err := s.dbProvider.CreateUserRole(ctx, role); err != nil {
+		loggerZap.Error("err CreateUserRole ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, userRoleEntry(constant.AuditActionCreate, role))
+
+	if _, err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		loggerZap.Error("err RevokeAll sessions ", err)
+	}
+
+	result := &userModel.AssignUserRoleResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    role,
+	}
+
+	return result, nil
+}
+
+// RemoveUserRole takes a role away and ends the user's sessions, so the role
+// stops working right away.
+func (s *userService) RemoveUserRole(ctx context.Context, req *userModel.RemoveUserRoleRequest) error {
+	funcName := "RemoveUserRole"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	userID, err := strconv.ParseInt(req.UserID, 10, 64)
+	if err != nil {
+		loggerZap.Error("err Invalid user id : ", err)
+		return status.Errorf(codes.InvalidArgument, "Invalid User Id")
+	}
+
+	role, err := s.dbProvider.GetUserRole(ctx, req.UserID, req.UserRoleID)
+	if err != nil {
+		loggerZap.Error("err GetUserRole ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+	if role == nil || role.UserRoleID == 0 {
+		return status.Error(codes.NotFound, "Role not found")
+	}
+
+	if err := authz.Check(currentUser, authz.RoleManage, strconv.FormatInt(role.ProjectID, 10), ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return err
+	}
+
+	if err := s.checkRoleTarget(ctx, currentUser, userID, role.ProjectID); err != nil {
+		loggerZap.Error("err checkRoleTarget ", err)
+		return err
+	}
+
+	loggerZap.Info("Start DeleteUserRole")
+	if err := s.dbProvider.DeleteUserRole(ctx, role.UserRoleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return status.Error(codes.NotFound, "Role not found")
+		}
+		loggerZap.Error("err DeleteUserRole ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, userRoleEntry(constant.AuditActionDelete, role))
+
+	if _, err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		loggerZap.Error("err RevokeAll sessions ", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	return nil
+}
+
+// checkRoleTarget makes sure the roles of the user may be changed in the
+// project. Project owners only manage the members of their project; bringing
+// in anyone else, or changing the roles of a system admin, is left to system
+// admins, since it also ends all of the user's sessions.
+func (s *userService) checkRoleTarget(ctx context.Context, currentUser middleware.AuthClaims, userID int64, projectID int64) error {
+	user, err := s.dbProvider.GetUserByID(ctx, strconv.FormatInt(userID, 10))
+	if err != nil {
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+	if user == nil || user.UserID == 0 {
+		return status.Error(codes.NotFound, "User not found")
+	}
+
+	if authz.Can(currentUser, authz.UserManage, "", "") {
+		return nil
+	}
+	if user.UserType == constant.UserTypeSystemAdmin {
+		return status.Error(codes.PermissionDenied, "Permission Denied")
+	}
+
+	member, err := s.dbProvider.IsProjectMember(ctx, userID, projectID)
+	if err != nil {
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+	if !member {
+		return status.Error(codes.PermissionDenied, "user is not a member of the project")
+	}
+
+	return nil
+}
+
+func newUserRole(userID int64, role string, projectID string, eventID string, currentUser middleware.AuthClaims) (*userModel.UserRole, error) {
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil || pid <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+
+	now := time.Now()
+	userRole := &userModel.UserRole{
+		UserID:      userID,
+		ProjectID:   pid,
+		Role:        role,
+		CreatedById: currentUser.UserID,
+		CreatedAt:   &now,
+	}
+
+	if eventID != "" && eventID != "0" {
+		eid, err := strconv.ParseInt(eventID, 10, 64)
+		if err != nil || eid <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid Event Id")
+		}
+		userRole.EventID = &eid
+	}
+
+	return userRole, nil
+}
+
+// userRoleEntry describes a role that was granted or taken away.
+func userRoleEntry(action string, role *userModel.UserRole) *auditModel.Entry {
+	entry := &auditModel.Entry{
+		Action:     action,
+		EntityType: constant.AuditEntityUserRole,
+		EntityID:   strconv.FormatInt(role.UserRoleID, 10),
+		ProjectID:  strconv.FormatInt(role.ProjectID, 10),
+	}
+	if role.EventID != nil {
+		entry.EventID = strconv.FormatInt(*role.EventID, 10)
+	}
+	if action == constant.AuditActionDelete {
+		entry.Before = role
+	} else {
+		entry.After = role
+	}
+	return entry
+}
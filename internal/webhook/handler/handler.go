@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/middleware"
+	webhookModel "rawuh-service/internal/webhook/model"
+	webhookService "rawuh-service/internal/webhook/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	svc webhookService.WebhookService
+}
+
+func NewWebhookHandler(svc webhookService.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		svc: svc,
+	}
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description Get the webhooks of a project. Secrets are not shown.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Success 200 {object} webhookModel.ListWebhooksResponse
+// @Failure 403 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks [get]
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &webhookModel.ListWebhooksRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+	}
+
+	webhooks, err := h.svc.ListWebhooks(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook
+// @Description Get a webhook with its failures in a row and, once it was turned off, when and why.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param webhook_id path string true "webhook id"
+// @Success 200 {object} webhookModel.GetWebhookResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks/{webhook_id} [get]
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &webhookModel.GetWebhookRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		WebhookID: mux.Vars(r)["webhook_id"],
+	}
+
+	webhook, err := h.svc.GetWebhook(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook
+// @Description Subscribe a URL to changes of the project. EventTypes lists guest.created, guest.updated, guest.deleted, guest.restored, guest.merged, guest.imported, guest.checked_in, guest.check_in_undone, event.created, event.updated, event.deleted, event.restored and rsvp.responded, or holds "*" for all of them. Without a Secret one is generated; it is only shown in this answer.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param body body webhookModel.CreateWebhookRequest true "CreateWebhookRequest"
+// @Success 201 {object} webhookModel.CreateWebhookResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks [post]
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &webhookModel.CreateWebhookResponse{
+		Error:   false,
+		Code:    http.StatusCreated,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p webhookModel.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &webhookModel.CreateWebhookRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		URL:        p.URL,
+		Secret:     p.Secret,
+		EventTypes: p.EventTypes,
+	}
+
+	webhook, err := h.svc.CreateWebhook(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Replace the URL and event types of a webhook. Active turns it off, or on again after it was turned off for failing, which clears its failures; left out, it is kept. A Secret replaces the old one; left out, the old one is kept.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param webhook_id path string true "webhook id"
+// @Param body body webhookModel.UpdateWebhookRequest true "UpdateWebhookRequest"
+// @Success 200 {object} webhookModel.UpdateWebhookResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks/{webhook_id} [put]
+
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result := &webhookModel.UpdateWebhookResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+	}
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	var p webhookModel.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		result.Error = true
+		result.Code = http.StatusBadRequest
+		result.Message = "Invalid Argument"
+		w.Header().Add("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	req := &webhookModel.UpdateWebhookRequest{
+		ProjectID:  mux.Vars(r)["project_id"],
+		WebhookID:  mux.Vars(r)["webhook_id"],
+		URL:        p.URL,
+		Secret:     p.Secret,
+		EventTypes: p.EventTypes,
+		Active:     p.Active,
+	}
+
+	webhook, err := h.svc.UpdateWebhook(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Remove a webhook with its delivery log. Deliveries still queued are dropped.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param webhook_id path string true "webhook id"
+// @Success 200 {object} webhookModel.DeleteWebhookResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks/{webhook_id} [delete]
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &webhookModel.DeleteWebhookRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		WebhookID: mux.Vars(r)["webhook_id"],
+	}
+
+	result, err := h.svc.DeleteWebhook(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// TestWebhook godoc
+// @Summary Send a test event
+// @Description Send a webhook.test delivery right away and answer with it, including the status and body the endpoint answered with. It is sent even when the webhook is off and does not count as a failure.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param webhook_id path string true "webhook id"
+// @Success 200 {object} webhookModel.TestWebhookResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks/{webhook_id}/test [post]
+
+func (h *WebhookHandler) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	req := &webhookModel.TestWebhookRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		WebhookID: mux.Vars(r)["webhook_id"],
+	}
+
+	delivery, err := h.svc.TestWebhook(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Get the delivery log of a webhook, newest first: each change sent with its status, attempts, and the answer and error of its last attempt.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param project_id path string true "project id"
+// @Param webhook_id path string true "webhook id"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param cursor query string false "NextCursor or PrevCursor of the last page; send it empty to start paging by cursor instead of page"
+// @Param count query bool false "count TotalRows and TotalPages, by default true when paging by page and false by cursor"
+// @Param dir query string false "asc or desc (default)"
+// @Param event_type query string false "event type, such as guest.checked_in"
+// @Param status query string false "PENDING, SENDING, SENT or FAILED"
+// @Success 200 {object} webhookModel.ListDeliveriesResponse
+// @Failure 400 {object} utils.APIErrorResponse
+// @Failure 404 {object} utils.APIErrorResponse
+// @Router /project/{project_id}/webhooks/{webhook_id}/deliveries [get]
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if payloadMap, okp := middleware.GetAuthPayload(ctx); okp {
+		ctx = context.WithValue(ctx, middleware.ContextKeyAuthPayload, payloadMap)
+	}
+
+	queryParams := r.URL.Query()
+
+	page, _ := strconv.Atoi(queryParams.Get("page"))
+	limit, _ := strconv.Atoi(queryParams.Get("limit"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := &webhookModel.ListDeliveriesRequest{
+		ProjectID: mux.Vars(r)["project_id"],
+		WebhookID: mux.Vars(r)["webhook_id"],
+		Page:      int32(page),
+		Limit:     int32(limit),
+		Dir:       queryParams.Get("dir"),
+		Cursor:    utils.QueryCursor(queryParams),
+		Count:     queryParams.Get("count"),
+		EventType: queryParams.Get("event_type"),
+		Status:    queryParams.Get("status"),
+	}
+
+	deliveries, err := h.svc.ListDeliveries(ctx, req)
+	if err != nil {
+		utils.HandleGrpcError(w, err)
+		return
+	}
+
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"time"
+
+	"rawuh-service/internal/shared/constant"
+)
+
+// EventTypes are the changes a webhook can subscribe to. A webhook
+// subscribed to "*" gets every one of them.
+var EventTypes = []string{
+	constant.WebhookEventGuestCreated,
+	constant.WebhookEventGuestUpdated,
+	constant.WebhookEventGuestDeleted,
+	constant.WebhookEventGuestRestored,
+	constant.WebhookEventGuestMerged,
+	constant.WebhookEventGuestImported,
+	constant.WebhookEventGuestCheckedIn,
+	constant.WebhookEventGuestCheckInUndone,
+	constant.WebhookEventEventCreated,
+	constant.WebhookEventEventUpdated,
+	constant.WebhookEventEventDeleted,
+	constant.WebhookEventEventRestored,
+	constant.WebhookEventRsvpResponded,
+}
+
+// Webhook is an endpoint of a project told about the changes of EventTypes,
+// a JSON array. Secret signs every delivery and is only shown when the
+// webhook is created. FailureCount counts the failed attempts in a row; once
+// it reaches the limit the webhook is disabled until it is turned on again.
+type Webhook struct {
+	WebhookID      int64      `gorm:"primaryKey;autoIncrement"`
+	ProjectID      int64      `gorm:"type:bigint"`
+	URL            string     `gorm:"column:url;type:varchar(2000)"`
+	Secret         string     `gorm:"type:varchar(200)" json:"-"`
+	EventTypes     JSON       `gorm:"type:jsonb"`
+	Active         bool       `gorm:"type:boolean"`
+	FailureCount   int32      `gorm:"type:integer"`
+	DisabledAt     *time.Time `gorm:"type:timestamp"`
+	DisabledReason string     `gorm:"type:text"`
+	CreatedById    int64      `gorm:"type:bigint"`
+	CreatedByName  string     `gorm:"type:varchar(500)"`
+	CreatedAt      *time.Time `gorm:"type:timestamp"`
+	UpdatedById    int64      `gorm:"type:bigint"`
+	UpdatedByName  string     `gorm:"type:varchar(500)"`
+	UpdatedAt      *time.Time `gorm:"type:timestamp"`
+}
+
+// Delivery is one change told to one webhook, and its entry in the delivery
+// log. It is PENDING until the dispatcher claims it, SENDING while it is being
+// sent, then SENT, or FAILED once every attempt failed. ResponseStatus,
+// ResponseBody and DurationMs describe the last attempt.
+type Delivery struct {
+	WebhookDeliveryID int64      `gorm:"primaryKey;autoIncrement"`
+	WebhookID         int64      `gorm:"type:bigint"`
+	ProjectID         int64      `gorm:"type:bigint"`
+	EventID           int64      `gorm:"type:bigint"`
+	EventType         string     `gorm:"type:varchar(100)"`
+	Data              JSON       `gorm:"type:jsonb"`
+	Status            string     `gorm:"type:varchar(20)"`
+	Attempts          int32      `gorm:"type:integer"`
+	ResponseStatus    int32      `gorm:"type:integer"`
+	ResponseBody      string     `gorm:"type:text"`
+	LastError         string     `gorm:"type:text"`
+	DurationMs        int32      `gorm:"type:integer"`
+	NextAttemptAt     *time.Time `gorm:"type:timestamp"`
+	CreatedAt         *time.Time `gorm:"type:timestamp"`
+	UpdatedAt         *time.Time `gorm:"type:timestamp"`
+	DeliveredAt       *time.Time `gorm:"type:timestamp"`
+}
+
+// Attempt is the outcome of sending a delivery once.
+type Attempt struct {
+	Status         string
+	ResponseStatus int32
+	ResponseBody   string
+	LastError      string
+	DurationMs     int32
+	NextAttemptAt  *time.Time
+}
+
+// Payload is the body posted to a webhook. ID is the delivery id and stays
+// the same across attempts, so receivers can drop repeats.
+type Payload struct {
+	ID        int64
+	Type      string
+	ProjectID int64
+	EventID   int64
+	CreatedAt *time.Time
+	Data      JSON
+}
+
+// JSON is a jsonb column. It is written into API responses as JSON rather
+// than as a quoted string.
+type JSON string
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// DeliveryFilter narrows ListDeliveries down; zero fields match every
+// delivery of the webhook.
+type DeliveryFilter struct {
+	WebhookID int64
+	EventType string
+	Status    string
+}
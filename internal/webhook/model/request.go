@@ -0,0 +1,108 @@
+package model
+
+import "rawuh-service/internal/shared/model"
+
+type ListWebhooksRequest struct {
+	ProjectID string
+}
+
+type ListWebhooksResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    []*Webhook
+}
+
+type GetWebhookRequest struct {
+	ProjectID string
+	WebhookID string
+}
+
+type GetWebhookResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Webhook
+}
+
+// CreateWebhookRequest subscribes URL to EventTypes. Secret is generated
+// when it is empty.
+type CreateWebhookRequest struct {
+	ProjectID  string
+	URL        string
+	Secret     string
+	EventTypes []string
+}
+
+// CreateWebhookResponse carries the secret; it is not shown again.
+type CreateWebhookResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Webhook
+	Secret  string
+}
+
+// UpdateWebhookRequest replaces the URL and event types. Active turns the
+// webhook off, or on again once it was disabled; left out, it is kept as it
+// is. A new Secret replaces the old one; an empty one keeps it.
+type UpdateWebhookRequest struct {
+	ProjectID  string
+	WebhookID  string
+	URL        string
+	Secret     string
+	EventTypes []string
+	Active     *bool
+}
+
+type UpdateWebhookResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Webhook
+}
+
+type DeleteWebhookRequest struct {
+	ProjectID string
+	WebhookID string
+}
+
+type DeleteWebhookResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+}
+
+type TestWebhookRequest struct {
+	ProjectID string
+	WebhookID string
+}
+
+// TestWebhookResponse holds the test delivery as it was sent, with the
+// answer of the endpoint.
+type TestWebhookResponse struct {
+	Error   bool
+	Code    int32
+	Message string
+	Data    *Delivery
+}
+
+type ListDeliveriesRequest struct {
+	ProjectID string
+	WebhookID string
+	Page      int32
+	Limit     int32
+	Cursor    *string
+	Count     string
+	Dir       string
+	EventType string
+	Status    string
+}
+
+type ListDeliveriesResponse struct {
+	Error      bool
+	Code       int32
+	Message    string
+	Data       []*Delivery
+	Pagination *model.PaginationResponse
+}
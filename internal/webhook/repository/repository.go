@@ -0,0 +1,354 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	jobModel "rawuh-service/internal/job/model"
+	jobDb "rawuh-service/internal/job/repository"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/model"
+	webhookModel "rawuh-service/internal/webhook/model"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	provider *db.GormProvider
+}
+
+func NewWebhookRepository(provider *db.GormProvider) *WebhookRepository {
+	return &WebhookRepository{
+		provider: provider,
+	}
+}
+
+// ProjectExists reports whether the project exists and is not deleted.
+func (p *WebhookRepository) ProjectExists(ctx context.Context, projectID int64) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var count int64
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.projects")
+
+	query = query.Where("project_id = ? AND deleted_at IS NULL", projectID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (p *WebhookRepository) ListWebhooks(ctx context.Context, projectID int64) ([]*webhookModel.Webhook, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data []*webhookModel.Webhook
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	query = query.Where("project_id = ?", projectID)
+
+	if err := query.Order("webhook_id").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (p *WebhookRepository) GetWebhook(ctx context.Context, projectID, webhookID int64) (*webhookModel.Webhook, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data webhookModel.Webhook
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	query = query.Where("project_id = ? AND webhook_id = ?", projectID, webhookID)
+
+	if err := query.Take(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// GetWebhooks returns the webhooks among webhookIDs that still exist, by id.
+func (p *WebhookRepository) GetWebhooks(ctx context.Context, webhookIDs []int64) (map[int64]*webhookModel.Webhook, error) {
+	webhooks := map[int64]*webhookModel.Webhook{}
+	if len(webhookIDs) == 0 {
+		return webhooks, nil
+	}
+
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var data []*webhookModel.Webhook
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	if err := query.Where("webhook_id IN ?", webhookIDs).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	for _, w := range data {
+		webhooks[w.WebhookID] = w
+	}
+
+	return webhooks, nil
+}
+
+func (p *WebhookRepository) CreateWebhook(ctx context.Context, data *webhookModel.Webhook) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	return query.Omit("webhook_id").Create(data).Error
+}
+
+func (p *WebhookRepository) UpdateWebhook(ctx context.Context, data *webhookModel.Webhook) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	query = query.Where("webhook_id = ?", data.WebhookID)
+
+	return query.Updates(map[string]interface{}{
+		"url":             data.URL,
+		"secret":          data.Secret,
+		"event_types":     data.EventTypes,
+		"active":          data.Active,
+		"failure_count":   data.FailureCount,
+		"disabled_at":     data.DisabledAt,
+		"disabled_reason": data.DisabledReason,
+		"updated_by_id":   data.UpdatedById,
+		"updated_by_name": data.UpdatedByName,
+		"updated_at":      data.UpdatedAt,
+	}).Error
+}
+
+// DeleteWebhook removes a webhook with its delivery log.
+func (p *WebhookRepository) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	return query.Where("webhook_id = ?", webhookID).Delete(&webhookModel.Webhook{}).Error
+}
+
+func (p *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*webhookModel.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhook_deliveries")
+
+	return query.Omit("webhook_delivery_id").Create(deliveries).Error
+}
+
+// Enqueue queues a change of eventType in tx, the transaction of the change,
+// so the webhooks of the project hear about it if and only if it is
+// committed. The job turns it into a delivery for every webhook subscribed
+// to it; nothing is queued for projects without a webhook that is on.
+func Enqueue(tx *gorm.DB, projectID, eventID int64, eventType string, data interface{}) error {
+	var count int64
+	if err := tx.Debug().Table("public.webhooks").Where("project_id = ? AND active", projectID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return jobDb.Enqueue(tx, constant.JobKindPublishWebhook, &jobModel.PublishWebhook{
+		ProjectID:  projectID,
+		EventID:    eventID,
+		EventType:  eventType,
+		Data:       raw,
+		OccurredAt: time.Now(),
+	})
+}
+
+// CreateJobDeliveries queues a delivery of the change published by the job
+// jobID for every webhook of the project that is on and subscribed to it, and
+// returns how many were queued. Running it again for the same job queues
+// nothing more.
+func (p *WebhookRepository) CreateJobDeliveries(ctx context.Context, jobID int64, change *jobModel.PublishWebhook) (int64, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	eventTypes, err := json.Marshal([]string{change.EventType})
+	if err != nil {
+		return 0, err
+	}
+
+	res := p.provider.GetDB().WithContext(timeoutctx).Debug().Exec(`
+		INSERT INTO public.webhook_deliveries (webhook_id, project_id, event_id, event_type, data, status, next_attempt_at, created_at, job_id)
+		SELECT webhook_id, project_id, ?, ?, CAST(? AS JSONB), ?, ?, ?, ?
+		FROM public.webhooks
+		WHERE project_id = ? AND active AND (event_types @> CAST(? AS JSONB) OR event_types @> '["*"]')
+		ON CONFLICT (job_id, webhook_id) DO NOTHING`,
+		change.EventID, change.EventType, string(change.Data), constant.DeliveryStatusPending, time.Now(), change.OccurredAt, jobID,
+		change.ProjectID, string(eventTypes),
+	)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (p *WebhookRepository) ListDeliveries(ctx context.Context, filter *webhookModel.DeliveryFilter, pagination *model.PaginationResponse, sort *model.Sort) (data []*webhookModel.Delivery, err error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhook_deliveries")
+
+	query = query.Where("webhook_id = ?", filter.WebhookID)
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if pagination.Cursor != nil {
+		return db.Keyset[webhookModel.Delivery](query, pagination, sort, "webhook_delivery_id")
+	}
+
+	query = query.Scopes(db.Paginate(data, pagination, query))
+	query = query.Scopes(
+		db.Sort(sort),
+	)
+
+	if err := query.Order("webhook_delivery_id " + sort.Direction).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ClaimDeliveries marks up to limit deliveries that are due as SENDING and
+// returns them. Deliveries left SENDING since before stale, by a dispatcher
+// that stopped halfway, are claimed again. Deliveries of webhooks that are
+// off wait until they are turned on again. Rows claimed by another
+// dispatcher are skipped, so several servers can send at once.
+func (p *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, stale time.Time) ([]*webhookModel.Delivery, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	now := time.Now()
+	var data []*webhookModel.Delivery
+
+	err := p.provider.GetDB().WithContext(timeoutctx).Debug().Raw(`
+		UPDATE public.webhook_deliveries
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE webhook_delivery_id IN (
+			SELECT d.webhook_delivery_id FROM public.webhook_deliveries d
+			JOIN public.webhooks w ON w.webhook_id = d.webhook_id
+			WHERE w.active AND ((d.status = ? AND d.next_attempt_at <= ?) OR (d.status = ? AND d.updated_at < ?))
+			ORDER BY d.webhook_delivery_id
+			LIMIT ?
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING *`,
+		constant.DeliveryStatusSending, now,
+		constant.DeliveryStatusPending, now, constant.DeliveryStatusSending, stale,
+		limit,
+	).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FinishDelivery stores the outcome of an attempt: SENT, or PENDING again
+// until NextAttemptAt, or FAILED. It does nothing when the delivery is no
+// longer SENDING.
+func (p *WebhookRepository) FinishDelivery(ctx context.Context, deliveryID int64, attempt *webhookModel.Attempt) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhook_deliveries")
+
+	query = query.Where("webhook_delivery_id = ? AND status = ?", deliveryID, constant.DeliveryStatusSending)
+
+	now := time.Now()
+	values := map[string]interface{}{
+		"status":          attempt.Status,
+		"response_status": attempt.ResponseStatus,
+		"response_body":   attempt.ResponseBody,
+		"last_error":      attempt.LastError,
+		"duration_ms":     attempt.DurationMs,
+		"updated_at":      &now,
+	}
+	if attempt.Status == constant.DeliveryStatusSent {
+		values["delivered_at"] = &now
+	}
+	if attempt.NextAttemptAt != nil {
+		values["next_attempt_at"] = attempt.NextAttemptAt
+	}
+
+	return query.Updates(values).Error
+}
+
+// ResetFailures clears the failures in a row of a webhook after it answered.
+func (p *WebhookRepository) ResetFailures(ctx context.Context, webhookID int64) error {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	query := p.provider.GetDB().WithContext(timeoutctx).Debug().Table("public.webhooks")
+
+	query = query.Where("webhook_id = ? AND failure_count > 0", webhookID)
+
+	return query.Update("failure_count", 0).Error
+}
+
+// CountFailure adds a failed attempt to the failures in a row of a webhook,
+// and turns it off with reason once they reach disableAfter. It reports
+// whether this failure turned the webhook off. Each failure gets its own
+// count, so only one of several failing at once does.
+func (p *WebhookRepository) CountFailure(ctx context.Context, webhookID int64, disableAfter int, reason string) (bool, error) {
+	timeoutctx, cancel := context.WithTimeout(ctx, p.provider.GetTimeout())
+	defer cancel()
+
+	var counts []int64
+
+	err := p.provider.GetDB().WithContext(timeoutctx).Debug().Raw(`
+		UPDATE public.webhooks
+		SET failure_count = failure_count + 1,
+			active = CASE WHEN failure_count + 1 >= ? THEN false ELSE active END,
+			disabled_at = CASE WHEN failure_count + 1 = ? THEN ? ELSE disabled_at END,
+			disabled_reason = CASE WHEN failure_count + 1 = ? THEN ? ELSE disabled_reason END
+		WHERE webhook_id = ?
+		RETURNING failure_count`,
+		disableAfter,
+		disableAfter, time.Now(),
+		disableAfter, reason,
+		webhookID,
+	).Scan(&counts).Error
+	if err != nil {
+		return false, err
+	}
+
+	return len(counts) > 0 && counts[0] == int64(disableAfter), nil
+}
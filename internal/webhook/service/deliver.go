@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/lib/utils"
+	webhookModel "rawuh-service/internal/webhook/model"
+)
+
+// Headers of every delivery. The signature is the hex HMAC-SHA256 of the
+// timestamp, a dot and the body, keyed with the webhook secret:
+//
+//	X-Rawuh-Signature: sha256=hex(hmac_sha256(secret, "<X-Rawuh-Timestamp>.<body>"))
+//
+// Receivers should compare it in constant time and drop deliveries whose
+// timestamp is more than a few minutes old.
+const (
+	HeaderEvent     = "X-Rawuh-Event"
+	HeaderDelivery  = "X-Rawuh-Delivery"
+	HeaderTimestamp = "X-Rawuh-Timestamp"
+	HeaderSignature = "X-Rawuh-Signature"
+)
+
+// maxResponseBody is as much of an answer as is kept in the delivery log.
+const maxResponseBody = 2 << 10
+
+type Config struct {
+	Interval     time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryDelay   time.Duration
+	Timeout      time.Duration
+	DisableAfter int
+	AllowPrivate bool
+}
+
+// ConfigFromEnv reads the webhook settings:
+//
+//	WEBHOOK_SEND_INTERVAL  5s, how often queued deliveries are sent, 0 turns sending off
+//	WEBHOOK_BATCH_SIZE     50 deliveries claimed at a time
+//	WEBHOOK_MAX_ATTEMPTS   8 attempts before a delivery is FAILED
+//	WEBHOOK_RETRY_DELAY    30s before the second attempt, doubled for each one after
+//	WEBHOOK_TIMEOUT        10s for an endpoint to answer
+//	WEBHOOK_DISABLE_AFTER  50 failed attempts in a row before a webhook is turned off
+//	WEBHOOK_ALLOW_PRIVATE  false, true lets webhooks reach loopback and private addresses
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{}
+
+	interval, err := time.ParseDuration(utils.GetEnv("WEBHOOK_SEND_INTERVAL", "5s"))
+	if err != nil || interval < 0 {
+		return cfg, fmt.Errorf("invalid WEBHOOK_SEND_INTERVAL")
+	}
+	batchSize, err := strconv.Atoi(utils.GetEnv("WEBHOOK_BATCH_SIZE", "50"))
+	if err != nil || batchSize <= 0 {
+		return cfg, fmt.Errorf("invalid WEBHOOK_BATCH_SIZE")
+	}
+	maxAttempts, err := strconv.Atoi(utils.GetEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	if err != nil || maxAttempts <= 0 {
+		return cfg, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS")
+	}
+	retryDelay, err := time.ParseDuration(utils.GetEnv("WEBHOOK_RETRY_DELAY", "30s"))
+	if err != nil || retryDelay <= 0 {
+		return cfg, fmt.Errorf("invalid WEBHOOK_RETRY_DELAY")
+	}
+	timeout, err := time.ParseDuration(utils.GetEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil || timeout <= 0 {
+		return cfg, fmt.Errorf("invalid WEBHOOK_TIMEOUT")
+	}
+	disableAfter, err := strconv.Atoi(utils.GetEnv("WEBHOOK_DISABLE_AFTER", "50"))
+	if err != nil || disableAfter <= 0 {
+		return cfg, fmt.Errorf("invalid WEBHOOK_DISABLE_AFTER")
+	}
+	allowPrivate, err := strconv.ParseBool(utils.GetEnv("WEBHOOK_ALLOW_PRIVATE", "false"))
+	if err != nil {
+		return cfg, fmt.Errorf("invalid WEBHOOK_ALLOW_PRIVATE")
+	}
+
+	cfg.Interval = interval
+	cfg.BatchSize = batchSize
+	cfg.MaxAttempts = maxAttempts
+	cfg.RetryDelay = retryDelay
+	cfg.Timeout = timeout
+	cfg.DisableAfter = disableAfter
+	cfg.AllowPrivate = allowPrivate
+
+	return cfg, nil
+}
+
+// errPrivateAddress is returned for endpoints on loopback, private or link
+// local addresses, which would let a webhook reach services inside our own
+// network.
+var errPrivateAddress = errors.New("webhook endpoint resolves to a private address")
+
+// newClient returns the client deliveries are posted with. It does not
+// follow redirects: a redirect is an answer like any other that is not 2xx.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		// checked on the address actually dialed, so a name that resolves
+		// to a private address is caught too
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign returns the X-Rawuh-Signature of body sent at timestamp, in Unix
+// seconds.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver posts d to w once. The attempt is SENT on a 2xx answer and FAILED
+// otherwise; whether to try again is left to the caller.
+func deliver(ctx context.Context, client *http.Client, w *webhookModel.Webhook, d *webhookModel.Delivery) *webhookModel.Attempt {
+	attempt := &webhookModel.Attempt{Status: constant.DeliveryStatusFailed}
+
+	body, err := json.Marshal(&webhookModel.Payload{
+		ID:        d.WebhookDeliveryID,
+		Type:      d.EventType,
+		ProjectID: d.ProjectID,
+		EventID:   d.EventID,
+		CreatedAt: d.CreatedAt,
+		Data:      d.Data,
+	})
+	if err != nil {
+		attempt.LastError = err.Error()
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.LastError = err.Error()
+		return attempt
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rawuh-webhooks")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.WebhookDeliveryID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, body))
+
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		attempt.DurationMs = int32(time.Since(start).Milliseconds())
+		attempt.LastError = err.Error()
+		return attempt
+	}
+	defer res.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	attempt.DurationMs = int32(time.Since(start).Milliseconds())
+	attempt.ResponseStatus = int32(res.StatusCode)
+	// text columns take neither invalid UTF-8 nor NUL
+	attempt.ResponseBody = strings.ReplaceAll(strings.ToValidUTF8(string(raw), ""), "\x00", "")
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		attempt.LastError = fmt.Sprintf("endpoint answered %s", res.Status)
+		return attempt
+	}
+
+	attempt.Status = constant.DeliveryStatusSent
+	return attempt
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/logger"
+	webhookModel "rawuh-service/internal/webhook/model"
+	webhookDb "rawuh-service/internal/webhook/repository"
+)
+
+// Dispatcher sends the queued deliveries to their webhooks. A failed
+// delivery is tried again after a delay doubled on each attempt, and FAILED
+// after the last one. Every failed attempt counts against the webhook; once
+// too many failed in a row it is turned off, and its deliveries wait until it
+// is turned on again. A delivery is sent at least once: if the server stops
+// between posting it and storing the answer, it is posted again.
+type Dispatcher struct {
+	dbProvider *webhookDb.WebhookRepository
+	client     *http.Client
+	cfg        Config
+	logger     *logger.Logger
+}
+
+func NewDispatcher(dbProvider *webhookDb.WebhookRepository, cfg Config, logger *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		dbProvider: dbProvider,
+		client:     newClient(cfg),
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Run claims due deliveries and sends them until none are left, and returns
+// how many were sent.
+func (d *Dispatcher) Run(ctx context.Context) (int, error) {
+	// a delivery SENDING for longer than this belongs to a dispatcher that
+	// stopped halfway
+	stale := time.Duration(d.cfg.BatchSize)*d.cfg.Timeout + time.Minute
+
+	sent := 0
+	for {
+		deliveries, err := d.dbProvider.ClaimDeliveries(ctx, d.cfg.BatchSize, time.Now().Add(-stale))
+		if err != nil {
+			return sent, fmt.Errorf("claim deliveries: %w", err)
+		}
+
+		ids := make([]int64, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.WebhookID)
+		}
+		webhooks, err := d.dbProvider.GetWebhooks(ctx, ids)
+		if err != nil {
+			return sent, fmt.Errorf("get webhooks: %w", err)
+		}
+
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			// deleted webhooks take their deliveries with them
+			webhook, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				continue
+			}
+			if d.send(ctx, webhook, delivery) {
+				sent++
+			}
+		}
+
+		if len(deliveries) < d.cfg.BatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// Start runs the dispatcher every interval until ctx is done.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := d.Run(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				d.logger.Error("err SendWebhooks ", err)
+				continue
+			}
+			if sent > 0 {
+				d.logger.Info("Success SendWebhooks ", sent)
+			}
+		}
+	}
+}
+
+// send makes one attempt at a claimed delivery and stores its outcome. It
+// reports whether the delivery was sent.
+func (d *Dispatcher) send(ctx context.Context, webhook *webhookModel.Webhook, delivery *webhookModel.Delivery) bool {
+	attempt := deliver(ctx, d.client, webhook, delivery)
+
+	if attempt.Status == constant.DeliveryStatusSent {
+		if err := d.dbProvider.FinishDelivery(ctx, delivery.WebhookDeliveryID, attempt); err != nil {
+			d.logger.Error("err FinishDelivery ", err)
+		}
+		if webhook.FailureCount > 0 {
+			if err := d.dbProvider.ResetFailures(ctx, webhook.WebhookID); err != nil {
+				d.logger.Error("err ResetFailures ", err)
+			}
+			webhook.FailureCount = 0
+		}
+		return true
+	}
+
+	if int(delivery.Attempts) < d.cfg.MaxAttempts {
+		// 30s, 1m, 2m... capped so the shift cannot overflow
+		next := time.Now().Add(d.cfg.RetryDelay << min(delivery.Attempts-1, 16))
+		attempt.Status = constant.DeliveryStatusPending
+		attempt.NextAttemptAt = &next
+	}
+	if err := d.dbProvider.FinishDelivery(ctx, delivery.WebhookDeliveryID, attempt); err != nil {
+		d.logger.Error("err FinishDelivery ", err)
+	}
+
+	reason := fmt.Sprintf("%d failed attempts in a row, the last: %s", d.cfg.DisableAfter, attempt.LastError)
+	disabled, err := d.dbProvider.CountFailure(ctx, webhook.WebhookID, d.cfg.DisableAfter, reason)
+	if err != nil {
+		d.logger.Error("err CountFailure ", err)
+	}
+	webhook.FailureCount++
+	if disabled {
+		d.logger.Warn(fmt.Sprintf("webhook %d of project %d turned off: %s", webhook.WebhookID, webhook.ProjectID, reason), nil)
+	}
+	return false
+}
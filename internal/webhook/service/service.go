@@ -0,0 +1,604 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	auditModel "rawuh-service/internal/audit/model"
+	auditService "rawuh-service/internal/audit/service"
+	"rawuh-service/internal/shared/authz"
+	"rawuh-service/internal/shared/constant"
+	"rawuh-service/internal/shared/db"
+	"rawuh-service/internal/shared/lib/utils"
+	"rawuh-service/internal/shared/logger"
+	"rawuh-service/internal/shared/middleware"
+	"rawuh-service/internal/shared/model"
+	webhookModel "rawuh-service/internal/webhook/model"
+	webhookDb "rawuh-service/internal/webhook/repository"
+
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxURL    = 2000
+	minSecret = 16
+	maxSecret = 200
+)
+
+type WebhookService interface {
+	ListWebhooks(ctx context.Context, req *webhookModel.ListWebhooksRequest) (*webhookModel.ListWebhooksResponse, error)
+	GetWebhook(ctx context.Context, req *webhookModel.GetWebhookRequest) (*webhookModel.GetWebhookResponse, error)
+	CreateWebhook(ctx context.Context, req *webhookModel.CreateWebhookRequest) (*webhookModel.CreateWebhookResponse, error)
+	UpdateWebhook(ctx context.Context, req *webhookModel.UpdateWebhookRequest) (*webhookModel.UpdateWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, req *webhookModel.DeleteWebhookRequest) (*webhookModel.DeleteWebhookResponse, error)
+	TestWebhook(ctx context.Context, req *webhookModel.TestWebhookRequest) (*webhookModel.TestWebhookResponse, error)
+	ListDeliveries(ctx context.Context, req *webhookModel.ListDeliveriesRequest) (*webhookModel.ListDeliveriesResponse, error)
+}
+
+type webhookService struct {
+	dbProvider *webhookDb.WebhookRepository
+	audit      auditService.AuditService
+	client     *http.Client
+	logger     *logger.Logger
+}
+
+func NewWebhookService(dbProvider *webhookDb.WebhookRepository, audit auditService.AuditService, cfg Config, logger *logger.Logger) WebhookService {
+	return &webhookService{
+		dbProvider: dbProvider,
+		audit:      audit,
+		client:     newClient(cfg),
+		logger:     logger,
+	}
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context, req *webhookModel.ListWebhooksRequest) (*webhookModel.ListWebhooksResponse, error) {
+	funcName := "ListWebhooks"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, err := strconv.ParseInt(req.ProjectID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+
+	loggerZap.Info("Start ListWebhooks")
+	webhooks, err := s.dbProvider.ListWebhooks(ctx, projectID)
+	if err != nil {
+		loggerZap.Error("err ListWebhooks ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &webhookModel.ListWebhooksResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    webhooks,
+	}
+
+	return result, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, req *webhookModel.GetWebhookRequest) (*webhookModel.GetWebhookResponse, error) {
+	funcName := "GetWebhook"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, webhookID, err := parseWebhookIDs(req.ProjectID, req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerZap.Info("Start GetWebhook")
+	webhook, err := s.dbProvider.GetWebhook(ctx, projectID, webhookID)
+	if err != nil {
+		loggerZap.Error("err GetWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if webhook == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	result := &webhookModel.GetWebhookResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    webhook,
+	}
+
+	return result, nil
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, req *webhookModel.CreateWebhookRequest) (*webhookModel.CreateWebhookResponse, error) {
+	funcName := "CreateWebhook"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "URL": req.URL, "EventTypes": req.EventTypes})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, err := strconv.ParseInt(req.ProjectID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+
+	loggerZap.Info("Start Validation for req ", nil)
+	webhookURL := strings.TrimSpace(req.URL)
+	eventTypes, err := validateWebhook(webhookURL, req.Secret, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = newSecret(); err != nil {
+			loggerZap.Error("err newSecret ", err)
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+	}
+
+	found, err := s.dbProvider.ProjectExists(ctx, projectID)
+	if err != nil {
+		loggerZap.Error("err ProjectExists ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "project not found")
+	}
+
+	now := time.Now()
+	webhook := &webhookModel.Webhook{
+		ProjectID:     projectID,
+		URL:           webhookURL,
+		Secret:        secret,
+		EventTypes:    eventTypes,
+		Active:        true,
+		CreatedById:   currentUser.UserID,
+		CreatedByName: currentUser.Name,
+		CreatedAt:     &now,
+	}
+
+	loggerZap.Info("Start CreateWebhook")
+	if err := s.dbProvider.CreateWebhook(ctx, webhook); err != nil {
+		loggerZap.Error("err CreateWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionCreate,
+		EntityType: constant.AuditEntityWebhook,
+		EntityID:   strconv.FormatInt(webhook.WebhookID, 10),
+		ProjectID:  req.ProjectID,
+		After:      webhook,
+	})
+
+	result := &webhookModel.CreateWebhookResponse{
+		Error:   false,
+		Code:    http.StatusCreated,
+		Message: "Success create webhook",
+		Data:    webhook,
+		Secret:  secret,
+	}
+
+	return result, nil
+}
+
+// UpdateWebhook replaces the URL and event types of a webhook. Turning it on
+// again clears its failures.
+func (s *webhookService) UpdateWebhook(ctx context.Context, req *webhookModel.UpdateWebhookRequest) (*webhookModel.UpdateWebhookResponse, error) {
+	funcName := "UpdateWebhook"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, map[string]interface{}{"ProjectID": req.ProjectID, "WebhookID": req.WebhookID, "URL": req.URL, "EventTypes": req.EventTypes, "Active": req.Active})
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, webhookID, err := parseWebhookIDs(req.ProjectID, req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	loggerZap.Info("Start Validation for req ", nil)
+	webhookURL := strings.TrimSpace(req.URL)
+	eventTypes, err := validateWebhook(webhookURL, req.Secret, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.dbProvider.GetWebhook(ctx, projectID, webhookID)
+	if err != nil {
+		loggerZap.Error("err GetWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if before == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	now := time.Now()
+	webhook := *before
+	webhook.URL = webhookURL
+	webhook.EventTypes = eventTypes
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Active != nil && *req.Active != webhook.Active {
+		webhook.Active = *req.Active
+		if webhook.Active {
+			webhook.FailureCount = 0
+			webhook.DisabledAt = nil
+			webhook.DisabledReason = ""
+		} else {
+			webhook.DisabledAt = &now
+			webhook.DisabledReason = "turned off by " + currentUser.Name
+		}
+	}
+	webhook.UpdatedById = currentUser.UserID
+	webhook.UpdatedByName = currentUser.Name
+	webhook.UpdatedAt = &now
+
+	loggerZap.Info("Start UpdateWebhook")
+	if err := s.dbProvider.UpdateWebhook(ctx, &webhook); err != nil {
+		loggerZap.Error("err UpdateWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionUpdate,
+		EntityType: constant.AuditEntityWebhook,
+		EntityID:   req.WebhookID,
+		ProjectID:  req.ProjectID,
+		Before:     before,
+		After:      &webhook,
+	})
+
+	result := &webhookModel.UpdateWebhookResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success update webhook",
+		Data:    &webhook,
+	}
+
+	return result, nil
+}
+
+// DeleteWebhook removes a webhook with its delivery log. Deliveries still
+// queued are dropped.
+func (s *webhookService) DeleteWebhook(ctx context.Context, req *webhookModel.DeleteWebhookRequest) (*webhookModel.DeleteWebhookResponse, error) {
+	funcName := "DeleteWebhook"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, webhookID, err := parseWebhookIDs(req.ProjectID, req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.dbProvider.GetWebhook(ctx, projectID, webhookID)
+	if err != nil {
+		loggerZap.Error("err GetWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if before == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	loggerZap.Info("Start DeleteWebhook")
+	if err := s.dbProvider.DeleteWebhook(ctx, webhookID); err != nil {
+		loggerZap.Error("err DeleteWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	s.audit.Record(ctx, &auditModel.Entry{
+		Action:     constant.AuditActionDelete,
+		EntityType: constant.AuditEntityWebhook,
+		EntityID:   req.WebhookID,
+		ProjectID:  req.ProjectID,
+		Before:     before,
+	})
+
+	result := &webhookModel.DeleteWebhookResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success delete webhook",
+	}
+
+	return result, nil
+}
+
+// TestWebhook sends a webhook.test delivery right away, once, and returns it
+// with the answer of the endpoint. It is sent even when the webhook is off,
+// and a failure does not count against it.
+func (s *webhookService) TestWebhook(ctx context.Context, req *webhookModel.TestWebhookRequest) (*webhookModel.TestWebhookResponse, error) {
+	funcName := "TestWebhook"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, webhookID, err := parseWebhookIDs(req.ProjectID, req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.dbProvider.GetWebhook(ctx, projectID, webhookID)
+	if err != nil {
+		loggerZap.Error("err GetWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if webhook == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"WebhookID":   webhook.WebhookID,
+		"Message":     "This is a test delivery.",
+		"RequestedBy": currentUser.Name,
+	})
+	now := time.Now()
+	delivery := &webhookModel.Delivery{
+		WebhookID:     webhook.WebhookID,
+		ProjectID:     projectID,
+		EventType:     constant.WebhookEventTest,
+		Data:          webhookModel.JSON(data),
+		Status:        constant.DeliveryStatusSending,
+		Attempts:      1,
+		NextAttemptAt: &now,
+		CreatedAt:     &now,
+		UpdatedAt:     &now,
+	}
+	if err := s.dbProvider.CreateDeliveries(ctx, []*webhookModel.Delivery{delivery}); err != nil {
+		loggerZap.Error("err CreateDeliveries ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	loggerZap.Info("Start TestWebhook")
+	attempt := deliver(ctx, s.client, webhook, delivery)
+	if err := s.dbProvider.FinishDelivery(ctx, delivery.WebhookDeliveryID, attempt); err != nil {
+		loggerZap.Error("err FinishDelivery ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	finished := time.Now()
+	delivery.Status = attempt.Status
+	delivery.ResponseStatus = attempt.ResponseStatus
+	delivery.ResponseBody = attempt.ResponseBody
+	delivery.LastError = attempt.LastError
+	delivery.DurationMs = attempt.DurationMs
+	delivery.UpdatedAt = &finished
+	if attempt.Status == constant.DeliveryStatusSent {
+		delivery.DeliveredAt = &finished
+	}
+
+	result := &webhookModel.TestWebhookResponse{
+		Error:   false,
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    delivery,
+	}
+
+	return result, nil
+}
+
+// ListDeliveries is the delivery log of a webhook, newest first.
+func (s *webhookService) ListDeliveries(ctx context.Context, req *webhookModel.ListDeliveriesRequest) (*webhookModel.ListDeliveriesResponse, error) {
+	funcName := "ListDeliveries"
+	span, ctx := apm.StartSpan(ctx, funcName, constant.SpanTypeProccess)
+	span.Action = constant.SpanActionExecute
+	defer span.End()
+
+	ctx, loggerZap := s.logger.StartLogger(ctx, funcName, req)
+	currentUser, ok := middleware.GetAuthClaimsFromContext(ctx)
+	if !ok {
+		loggerZap.Error("err GetMeFromMD no auth claims", nil)
+		return nil, status.Error(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if err := authz.Check(currentUser, authz.WebhookManage, req.ProjectID, ""); err != nil {
+		loggerZap.Error("err Check permission denied", err)
+		return nil, err
+	}
+
+	projectID, webhookID, err := parseWebhookIDs(req.ProjectID, req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &webhookModel.DeliveryFilter{
+		WebhookID: webhookID,
+		EventType: strings.ToLower(req.EventType),
+		Status:    strings.ToUpper(req.Status),
+	}
+	switch filter.Status {
+	case "", constant.DeliveryStatusPending, constant.DeliveryStatusSending, constant.DeliveryStatusSent, constant.DeliveryStatusFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %s, %s, %s or %s", constant.DeliveryStatusPending, constant.DeliveryStatusSending, constant.DeliveryStatusSent, constant.DeliveryStatusFailed)
+	}
+
+	direction := strings.ToLower(req.Dir)
+	switch direction {
+	case "":
+		direction = "desc"
+	case "asc", "desc":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid Argument")
+	}
+	sort := &model.Sort{
+		Column:    "created_at",
+		Direction: direction,
+	}
+
+	pagination := utils.SetPagination(req.Page, req.Limit)
+	if err := utils.SetPaginationMode(pagination, req.Cursor, req.Count); err != nil {
+		loggerZap.Error("err SetPaginationMode ", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	webhook, err := s.dbProvider.GetWebhook(ctx, projectID, webhookID)
+	if err != nil {
+		loggerZap.Error("err GetWebhook ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+	if webhook == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	loggerZap.Info("Start ListDeliveries")
+	deliveries, err := s.dbProvider.ListDeliveries(ctx, filter, pagination, sort)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		loggerZap.Error("err ListDeliveries ", err)
+		return nil, status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	result := &webhookModel.ListDeliveriesResponse{
+		Error:      false,
+		Code:       http.StatusOK,
+		Message:    "Success",
+		Data:       deliveries,
+		Pagination: pagination,
+	}
+
+	return result, nil
+}
+
+func parseWebhookIDs(projectID string, webhookID string) (int64, int64, error) {
+	pid, err := strconv.ParseInt(projectID, 10, 64)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "Invalid Project Id")
+	}
+	wid, err := strconv.ParseInt(webhookID, 10, 64)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "Invalid Webhook Id")
+	}
+	return pid, wid, nil
+}
+
+// validateWebhook checks a webhook sent by a client and returns its event
+// types as stored.
+func validateWebhook(webhookURL string, secret string, eventTypes []string) (webhookModel.JSON, error) {
+	if webhookURL == "" {
+		return "", status.Errorf(codes.InvalidArgument, "URL is required")
+	}
+	if len(webhookURL) > maxURL {
+		return "", status.Errorf(codes.InvalidArgument, "URL is longer than %d characters", maxURL)
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", status.Errorf(codes.InvalidArgument, "URL must be an http or https URL")
+	}
+	if u.User != nil {
+		return "", status.Errorf(codes.InvalidArgument, "URL must not hold credentials, the secret signs every delivery")
+	}
+
+	if secret != "" && (len(secret) < minSecret || len(secret) > maxSecret) {
+		return "", status.Errorf(codes.InvalidArgument, "Secret must be %d to %d characters", minSecret, maxSecret)
+	}
+
+	if len(eventTypes) == 0 {
+		return "", status.Errorf(codes.InvalidArgument, "EventTypes is required, use [\"*\"] for every event type")
+	}
+	types := []string{}
+	for _, t := range eventTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "*" && !slices.Contains(webhookModel.EventTypes, t) {
+			return "", status.Errorf(codes.InvalidArgument, "unknown event type %q, use one of %s or *", t, strings.Join(webhookModel.EventTypes, ", "))
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+
+	raw, _ := json.Marshal(types)
+	return webhookModel.JSON(raw), nil
+}
+
+// newSecret returns a random signing secret.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}